@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"logistics-marketplace/internal/auth"
+	"logistics-marketplace/internal/problem"
+)
+
+// CreateAPIKeyRequest is the body of an API key creation
+type CreateAPIKeyRequest struct {
+	Name      string       `json:"name" validate:"required" example:"warehouse-sync"`
+	Scopes    []auth.Scope `json:"scopes" validate:"required"`
+	RateLimit int          `json:"rate_limit"` // Requests per minute
+}
+
+// CreateAPIKeyResponse holds a new API key and its secret, shown only once
+type CreateAPIKeyResponse struct {
+	APIKey string       `json:"api_key"`
+	Key    *auth.APIKey `json:"key"`
+}
+
+type APIKeyHandler struct {
+	keys *auth.APIKeyStore
+}
+
+func NewAPIKeyHandler(keys *auth.APIKeyStore) *APIKeyHandler {
+	return &APIKeyHandler{
+		keys: keys,
+	}
+}
+
+// CreateAPIKey handles issuing an API key for the caller's company
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	identity, ok := auth.FromContext(c)
+	if !ok {
+		problem.Write(c, http.StatusUnauthorized, "authentication required")
+		return
+	}
+
+	var req CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	key, secret, err := h.keys.Create(identity, req.Name, req.Scopes, req.RateLimit)
+	if err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	// The key is only shown once
+	c.JSON(http.StatusCreated, CreateAPIKeyResponse{
+		APIKey: secret,
+		Key:    key,
+	})
+}
+
+// ListAPIKeys handles listing the API keys of the caller's company
+func (h *APIKeyHandler) ListAPIKeys(c *gin.Context) {
+	identity, ok := auth.FromContext(c)
+	if !ok {
+		problem.Write(c, http.StatusUnauthorized, "authentication required")
+		return
+	}
+
+	c.JSON(http.StatusOK, h.keys.List(identity.CompanyID))
+}
+
+// RevokeAPIKey handles revoking one of the caller's company API keys
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	identity, ok := auth.FromContext(c)
+	if !ok {
+		problem.Write(c, http.StatusUnauthorized, "authentication required")
+		return
+	}
+
+	if err := h.keys.Revoke(identity, c.Param("id")); err != nil {
+		if errors.Is(err, auth.ErrAPIKeyNotFound) {
+			problem.Write(c, http.StatusNotFound, err.Error())
+			return
+		}
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
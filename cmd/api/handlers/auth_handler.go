@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"logistics-marketplace/internal/auth"
+	"logistics-marketplace/internal/problem"
+)
+
+// LoginRequest is the body of a login
+type LoginRequest struct {
+	Username string `json:"username" validate:"required" example:"acme-shipper"`
+	Password string `json:"password" validate:"required"`
+}
+
+// RefreshRequest is the body of a refresh token rotation
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+type AuthHandler struct {
+	sessions *auth.SessionManager
+}
+
+func NewAuthHandler(sessions *auth.SessionManager) *AuthHandler {
+	return &AuthHandler{
+		sessions: sessions,
+	}
+}
+
+// Login handles exchanging credentials for an access and refresh token
+func (h *AuthHandler) Login(c *gin.Context) {
+	var req LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	tokens, err := h.sessions.Login(req.Username, req.Password)
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidCredentials) {
+			problem.Write(c, http.StatusUnauthorized, err.Error())
+			return
+		}
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// Refresh handles rotating a refresh token into a new token pair
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	tokens, err := h.sessions.Refresh(req.RefreshToken)
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidRefreshToken) || errors.Is(err, auth.ErrRefreshTokenReused) {
+			problem.Write(c, http.StatusUnauthorized, err.Error())
+			return
+		}
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// Logout handles ending the caller's session
+func (h *AuthHandler) Logout(c *gin.Context) {
+	identity, ok := auth.FromContext(c)
+	if !ok {
+		problem.Write(c, http.StatusUnauthorized, "authentication required")
+		return
+	}
+
+	if err := h.sessions.Logout(identity); err != nil {
+		respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"logistics-marketplace/internal/models"
+	"logistics-marketplace/internal/problem"
 	"logistics-marketplace/internal/services"
 )
 
@@ -28,7 +29,7 @@ func NewCustomsRateHandler(
 func (h *CustomsRateHandler) CreateCustomsRate(c *gin.Context) {
 	var rate models.CustomsRate
 	if err := c.ShouldBindJSON(&rate); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
@@ -38,25 +39,8 @@ func (h *CustomsRateHandler) CreateCustomsRate(c *gin.Context) {
 
 	// Create rate with context
 	if err := h.customsRateService.CreateCustomsRate(c, &rate); err != nil {
-		switch err := err.(type) {
-		case *models.MembershipError:
-			c.JSON(http.StatusPaymentRequired, gin.H{
-				"error": err.Error(),
-				"code":  "MEMBERSHIP_REQUIRED",
-			})
-		case *models.LicenseVerificationError:
-			c.JSON(http.StatusForbidden, gin.H{
-				"error": err.Error(),
-				"code":  "LICENSE_INVALID",
-			})
-		case *models.CustomsBrokerRestrictionError:
-			c.JSON(http.StatusForbidden, gin.H{
-				"error": err.Error(),
-				"code":  "COUNTRY_RESTRICTED",
-			})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		}
+		// Membership, license and country errors map to their own status and code
+		respondError(c, err)
 		return
 	}
 
@@ -66,18 +50,18 @@ func (h *CustomsRateHandler) CreateCustomsRate(c *gin.Context) {
 // GetCustomsQuotation handles customs clearance quotation requests
 func (h *CustomsRateHandler) GetCustomsQuotation(c *gin.Context) {
 	var request struct {
-		BrokerID        string              `json:"broker_id"`
+		BrokerID        string              `json:"broker_id" validate:"required"`
 		BranchOfficeID  string              `json:"branch_office_id"`
-		RateID          string              `json:"rate_id"`
+		RateID          string              `json:"rate_id" validate:"required"`
 		CargoDetails    models.CargoDetails `json:"cargo_details"`
-		CargoValue      float64             `json:"cargo_value"`
-		TransportMode   models.TransportMode `json:"transport_mode"`
-		PackagingMode   models.PackagingMode `json:"packaging_mode"`
+		CargoValue      float64             `json:"cargo_value" validate:"gte=0"`
+		TransportMode   models.TransportMode `json:"transport_mode" validate:"required,oneof=SEA AIR RAIL ROAD LAND"`
+		PackagingMode   models.PackagingMode `json:"packaging_mode" validate:"required"`
 		PackagingDetails interface{}         `json:"packaging_details"`
 	}
 
 	if err := c.ShouldBindJSON(&request); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
@@ -93,7 +77,7 @@ func (h *CustomsRateHandler) GetCustomsQuotation(c *gin.Context) {
 		request.PackagingDetails,
 	)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -109,16 +93,13 @@ func (h *CustomsRateHandler) GetRatesByType(c *gin.Context) {
 
 	// Validate membership
 	if err := h.membershipService.ValidateMembership(c, models.CustomsBrokerMembership, brokerID); err != nil {
-		c.JSON(http.StatusPaymentRequired, gin.H{
-			"error": err.Error(),
-			"code":  "MEMBERSHIP_REQUIRED",
-		})
+		respondError(c, err)
 		return
 	}
 
 	rates, err := h.customsRateService.GetCustomsRatesByType(rateType)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -130,38 +111,21 @@ func (h *CustomsRateHandler) UpdateCustomsRate(c *gin.Context) {
 	rateID := c.Param("id")
 	var rate models.CustomsRate
 	if err := c.ShouldBindJSON(&rate); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
 	// Verify broker ID matches authenticated user
 	brokerID := c.GetString("user_id")
 	if rate.BrokerID != brokerID {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		problem.Write(c, http.StatusUnauthorized, "unauthorized")
 		return
 	}
 
 	rate.ID = rateID
 	if err := h.customsRateService.UpdateCustomsRate(c, &rate); err != nil {
-		switch err := err.(type) {
-		case *models.MembershipError:
-			c.JSON(http.StatusPaymentRequired, gin.H{
-				"error": err.Error(),
-				"code":  "MEMBERSHIP_REQUIRED",
-			})
-		case *models.LicenseVerificationError:
-			c.JSON(http.StatusForbidden, gin.H{
-				"error": err.Error(),
-				"code":  "LICENSE_INVALID",
-			})
-		case *models.CustomsBrokerRestrictionError:
-			c.JSON(http.StatusForbidden, gin.H{
-				"error": err.Error(),
-				"code":  "COUNTRY_RESTRICTED",
-			})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		}
+		// Membership, license and country errors map to their own status and code
+		respondError(c, err)
 		return
 	}
 
@@ -171,17 +135,17 @@ func (h *CustomsRateHandler) UpdateCustomsRate(c *gin.Context) {
 // GetRateComparison handles comparing rates across providers
 func (h *CustomsRateHandler) GetRateComparison(c *gin.Context) {
 	var request struct {
-		RateType         models.CustomsRateType `json:"rate_type"`
-		Country          string                 `json:"country"`
+		RateType         models.CustomsRateType `json:"rate_type" validate:"required,oneof=ORIGIN TRANSSHIPMENT TRANSIT DESTINATION"`
+		Country          string                 `json:"country" validate:"required,country"`
 		CargoDetails     models.CargoDetails    `json:"cargo_details"`
-		CargoValue       float64                `json:"cargo_value"`
-		TransportMode    models.TransportMode   `json:"transport_mode"`
-		PackagingMode    models.PackagingMode   `json:"packaging_mode"`
+		CargoValue       float64                `json:"cargo_value" validate:"gte=0"`
+		TransportMode    models.TransportMode   `json:"transport_mode" validate:"required,oneof=SEA AIR RAIL ROAD LAND"`
+		PackagingMode    models.PackagingMode   `json:"packaging_mode" validate:"required"`
 		PackagingDetails interface{}            `json:"packaging_details"`
 	}
 
 	if err := c.ShouldBindJSON(&request); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
@@ -190,10 +154,7 @@ func (h *CustomsRateHandler) GetRateComparison(c *gin.Context) {
 
 	// Validate membership
 	if err := h.membershipService.ValidateMembership(c, models.CustomsBrokerMembership, brokerID); err != nil {
-		c.JSON(http.StatusPaymentRequired, gin.H{
-			"error": err.Error(),
-			"code":  "MEMBERSHIP_REQUIRED",
-		})
+		respondError(c, err)
 		return
 	}
 
@@ -207,7 +168,7 @@ func (h *CustomsRateHandler) GetRateComparison(c *gin.Context) {
 		request.PackagingDetails,
 	)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -224,10 +185,7 @@ func (h *CustomsRateHandler) GetHistoricalRates(c *gin.Context) {
 
 	// Validate membership
 	if err := h.membershipService.ValidateMembership(c, models.CustomsBrokerMembership, brokerID); err != nil {
-		c.JSON(http.StatusPaymentRequired, gin.H{
-			"error": err.Error(),
-			"code":  "MEMBERSHIP_REQUIRED",
-		})
+		respondError(c, err)
 		return
 	}
 
@@ -237,19 +195,19 @@ func (h *CustomsRateHandler) GetHistoricalRates(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&request); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
 	startDate, err := time.Parse(time.RFC3339, request.StartDate)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid start date format"})
+		problem.Write(c, http.StatusBadRequest, "invalid start date format")
 		return
 	}
 
 	endDate, err := time.Parse(time.RFC3339, request.EndDate)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid end date format"})
+		problem.Write(c, http.StatusBadRequest, "invalid end date format")
 		return
 	}
 
@@ -260,7 +218,7 @@ func (h *CustomsRateHandler) GetHistoricalRates(c *gin.Context) {
 		endDate,
 	)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -269,29 +227,28 @@ func (h *CustomsRateHandler) GetHistoricalRates(c *gin.Context) {
 
 // ValidateHSCode handles HS code validation and duty rate retrieval
 func (h *CustomsRateHandler) ValidateHSCode(c *gin.Context) {
-	hsCode := c.Query("hs_code")
-	country := c.Query("country")
-
-	if hsCode == "" || country == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "HS code and country are required"})
+	var query struct {
+		HSCode  string `form:"hs_code" validate:"required,hs_code"`
+		Country string `form:"country" validate:"required,country"`
+	}
+	if err := c.ShouldBindQuery(&query); err != nil {
+		respondBadRequest(c, err)
 		return
 	}
+	hsCode, country := query.HSCode, query.Country
 
 	// Get broker ID from authenticated user
 	brokerID := c.GetString("user_id")
 
 	// Validate membership
 	if err := h.membershipService.ValidateMembership(c, models.CustomsBrokerMembership, brokerID); err != nil {
-		c.JSON(http.StatusPaymentRequired, gin.H{
-			"error": err.Error(),
-			"code":  "MEMBERSHIP_REQUIRED",
-		})
+		respondError(c, err)
 		return
 	}
 
 	dutyRate, err := h.customsRateService.ValidateHSCode(hsCode, country)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"logistics-marketplace/internal/models"
+	"logistics-marketplace/internal/problem"
+)
+
+// domainProblem maps the typed errors of the services to their problem
+func domainProblem(err error) *problem.Problem {
+	var (
+		membershipErr *models.MembershipError
+		licenseErr    *models.LicenseVerificationError
+		brokerErr     *models.CustomsBrokerRestrictionError
+		forwarderErr  *models.CountryRestrictionError
+	)
+
+	switch {
+	case errors.As(err, &membershipErr):
+		return problem.New(http.StatusPaymentRequired, problem.CodeMembershipRequired, err.Error())
+	case errors.As(err, &licenseErr):
+		return problem.New(http.StatusForbidden, problem.CodeLicenseInvalid, err.Error())
+	case errors.As(err, &brokerErr), errors.As(err, &forwarderErr):
+		return problem.New(http.StatusForbidden, problem.CodeCountryRestricted, err.Error())
+	}
+	return nil
+}
+
+// respondError answers a failed service call with the problem its error maps to
+func respondError(c *gin.Context, err error) {
+	if p := domainProblem(err); p != nil {
+		problem.Respond(c, p)
+		return
+	}
+	problem.Error(c, err)
+}
+
+// respondBadRequest answers a request rejected by binding or by a service
+func respondBadRequest(c *gin.Context, err error) {
+	if p := domainProblem(err); p != nil {
+		problem.Respond(c, p)
+		return
+	}
+	problem.BadRequest(c, err)
+}
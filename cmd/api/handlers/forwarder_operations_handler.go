@@ -22,7 +22,7 @@ func NewForwarderOperationsHandler(operationsService *services.ForwarderOperatio
 func (h *ForwarderOperationsHandler) CreateQuoteRequest(c *gin.Context) {
 	var request models.QuoteRequest
 	if err := c.ShouldBindJSON(&request); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
@@ -31,7 +31,7 @@ func (h *ForwarderOperationsHandler) CreateQuoteRequest(c *gin.Context) {
 	request.ForwarderID = forwarderID
 
 	if err := h.operationsService.CreateQuoteRequest(&request); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -42,7 +42,7 @@ func (h *ForwarderOperationsHandler) CreateQuoteRequest(c *gin.Context) {
 func (h *ForwarderOperationsHandler) GenerateFreightQuote(c *gin.Context) {
 	var request models.QuoteRequest
 	if err := c.ShouldBindJSON(&request); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
@@ -52,7 +52,7 @@ func (h *ForwarderOperationsHandler) GenerateFreightQuote(c *gin.Context) {
 
 	quote, err := h.operationsService.GenerateFreightQuote(&request)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -63,7 +63,7 @@ func (h *ForwarderOperationsHandler) GenerateFreightQuote(c *gin.Context) {
 func (h *ForwarderOperationsHandler) ConfirmRate(c *gin.Context) {
 	var quote models.FreightQuote
 	if err := c.ShouldBindJSON(&quote); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
@@ -73,7 +73,7 @@ func (h *ForwarderOperationsHandler) ConfirmRate(c *gin.Context) {
 
 	confirmation, err := h.operationsService.ConfirmRate(&quote)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -87,7 +87,7 @@ func (h *ForwarderOperationsHandler) CreateBooking(c *gin.Context) {
 		Booking        models.ShipmentBooking `json:"booking"`
 	}
 	if err := c.ShouldBindJSON(&request); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
@@ -104,7 +104,7 @@ func (h *ForwarderOperationsHandler) CreateBooking(c *gin.Context) {
 
 	booking, err := h.operationsService.CreateBooking(confirmation, &request.Booking)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -115,7 +115,7 @@ func (h *ForwarderOperationsHandler) CreateBooking(c *gin.Context) {
 func (h *ForwarderOperationsHandler) ConfirmBooking(c *gin.Context) {
 	var booking models.ShipmentBooking
 	if err := c.ShouldBindJSON(&booking); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
@@ -125,7 +125,7 @@ func (h *ForwarderOperationsHandler) ConfirmBooking(c *gin.Context) {
 
 	confirmation, err := h.operationsService.ConfirmBooking(&booking)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -22,7 +22,7 @@ func NewFreightForwarderHandler(forwarderService *services.FreightForwarderServi
 func (h *FreightForwarderHandler) CreateRateRequest(c *gin.Context) {
 	var request models.RateRequest
 	if err := c.ShouldBindJSON(&request); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
@@ -31,7 +31,7 @@ func (h *FreightForwarderHandler) CreateRateRequest(c *gin.Context) {
 	request.ForwarderID = forwarderID
 
 	if err := h.forwarderService.CreateRateRequest(&request); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -42,7 +42,7 @@ func (h *FreightForwarderHandler) CreateRateRequest(c *gin.Context) {
 func (h *FreightForwarderHandler) CreateRateQuotation(c *gin.Context) {
 	var quotation models.RateQuotation
 	if err := c.ShouldBindJSON(&quotation); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
@@ -51,7 +51,7 @@ func (h *FreightForwarderHandler) CreateRateQuotation(c *gin.Context) {
 	quotation.ForwarderID = forwarderID
 
 	if err := h.forwarderService.CreateRateQuotation(&quotation); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -62,7 +62,7 @@ func (h *FreightForwarderHandler) CreateRateQuotation(c *gin.Context) {
 func (h *FreightForwarderHandler) ConfirmRate(c *gin.Context) {
 	var confirmation models.RateConfirmation
 	if err := c.ShouldBindJSON(&confirmation); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
@@ -71,7 +71,7 @@ func (h *FreightForwarderHandler) ConfirmRate(c *gin.Context) {
 	confirmation.ForwarderID = forwarderID
 
 	if err := h.forwarderService.ConfirmRate(&confirmation); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -82,7 +82,7 @@ func (h *FreightForwarderHandler) ConfirmRate(c *gin.Context) {
 func (h *FreightForwarderHandler) CreateBooking(c *gin.Context) {
 	var booking models.ShipmentBooking
 	if err := c.ShouldBindJSON(&booking); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
@@ -91,7 +91,7 @@ func (h *FreightForwarderHandler) CreateBooking(c *gin.Context) {
 	booking.ForwarderID = forwarderID
 
 	if err := h.forwarderService.CreateBooking(&booking); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -102,12 +102,12 @@ func (h *FreightForwarderHandler) CreateBooking(c *gin.Context) {
 func (h *FreightForwarderHandler) ConfirmBooking(c *gin.Context) {
 	var confirmation models.BookingConfirmation
 	if err := c.ShouldBindJSON(&confirmation); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
 	if err := h.forwarderService.ConfirmBooking(&confirmation); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -118,7 +118,7 @@ func (h *FreightForwarderHandler) ConfirmBooking(c *gin.Context) {
 func (h *FreightForwarderHandler) CalculateRates(c *gin.Context) {
 	var request models.RateRequest
 	if err := c.ShouldBindJSON(&request); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
@@ -128,7 +128,7 @@ func (h *FreightForwarderHandler) CalculateRates(c *gin.Context) {
 
 	calculation, err := h.forwarderService.CalculateRates(&request)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -139,7 +139,7 @@ func (h *FreightForwarderHandler) CalculateRates(c *gin.Context) {
 func (h *FreightForwarderHandler) AddBookingInstruction(c *gin.Context) {
 	var instruction models.BookingInstruction
 	if err := c.ShouldBindJSON(&instruction); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
@@ -148,7 +148,7 @@ func (h *FreightForwarderHandler) AddBookingInstruction(c *gin.Context) {
 	instruction.UpdatedBy = userID
 
 	if err := h.forwarderService.AddBookingInstruction(&instruction); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -159,12 +159,12 @@ func (h *FreightForwarderHandler) AddBookingInstruction(c *gin.Context) {
 func (h *FreightForwarderHandler) UpdateDocumentRequirement(c *gin.Context) {
 	var requirement models.DocumentRequirement
 	if err := c.ShouldBindJSON(&requirement); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
 	if err := h.forwarderService.UpdateDocumentRequirement(&requirement); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -2,12 +2,14 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
-	"github.com/gorilla/mux"
-
+	"github.com/gin-gonic/gin"
 	"logistics-marketplace/internal/models"
+	"logistics-marketplace/internal/problem"
 	"logistics-marketplace/internal/services"
+	"logistics-marketplace/internal/validation"
 )
 
 type GovernanceHandler struct {
@@ -20,129 +22,185 @@ func NewGovernanceHandler(governanceService *services.GovernanceService) *Govern
 	}
 }
 
-// RegisterRoutes registers the governance routes
-func (h *GovernanceHandler) RegisterRoutes(router *mux.Router) {
-	router.HandleFunc("/api/v1/governance/proposals", h.CreateProposal).Methods("POST")
-	router.HandleFunc("/api/v1/governance/proposals", h.ListProposals).Methods("GET")
-	router.HandleFunc("/api/v1/governance/proposals/{id}", h.GetProposal).Methods("GET")
-	router.HandleFunc("/api/v1/governance/proposals/{id}/vote", h.CastVote).Methods("POST")
-	router.HandleFunc("/api/v1/governance/proposals/{id}/execute", h.ExecuteProposal).Methods("POST")
-	router.HandleFunc("/api/v1/governance/proposals/{id}/votes/{voter}", h.GetVote).Methods("GET")
-	router.HandleFunc("/api/v1/governance/parameters/{name}", h.GetParameter).Methods("GET")
-}
-
 // CreateProposal handles proposal creation requests
-func (h *GovernanceHandler) CreateProposal(w http.ResponseWriter, r *http.Request) {
+func (h *GovernanceHandler) CreateProposal(c *gin.Context) {
 	var req models.ProposalCreateRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+	if err := validation.Struct(req); err != nil {
+		problem.Respond(c, problem.FromBindingError(err))
 		return
 	}
 
 	// Get creator address from authenticated user
-	creatorAddress := r.Context().Value("user_address").(string)
+	creatorAddress := c.GetString("user_address")
+	if creatorAddress == "" {
+		problem.Write(c, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
 
-	response, err := h.governanceService.CreateProposal(r.Context(), req, creatorAddress)
+	response, err := h.governanceService.CreateProposal(c, req, creatorAddress)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		respondError(c, err)
 		return
 	}
 
-	json.NewEncoder(w).Encode(response)
+	c.JSON(http.StatusOK, response)
 }
 
 // ListProposals handles listing proposals with optional filters
-func (h *GovernanceHandler) ListProposals(w http.ResponseWriter, r *http.Request) {
-	status := models.ProposalStatus(r.URL.Query().Get("status"))
-	proposalType := models.ProposalType(r.URL.Query().Get("type"))
+func (h *GovernanceHandler) ListProposals(c *gin.Context) {
+	status := models.ProposalStatus(c.Query("status"))
+	proposalType := models.ProposalType(c.Query("type"))
 
-	proposals, err := h.governanceService.ListProposals(r.Context(), status, proposalType)
+	proposals, err := h.governanceService.ListProposals(c, status, proposalType)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		respondError(c, err)
 		return
 	}
 
-	json.NewEncoder(w).Encode(proposals)
+	c.JSON(http.StatusOK, proposals)
 }
 
 // GetProposal handles getting a specific proposal
-func (h *GovernanceHandler) GetProposal(w http.ResponseWriter, r *http.Request) {
-	proposalID := mux.Vars(r)["id"]
-
-	proposal, err := h.governanceService.GetProposal(r.Context(), proposalID)
+func (h *GovernanceHandler) GetProposal(c *gin.Context) {
+	proposal, err := h.governanceService.GetProposal(c, c.Param("id"))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		respondError(c, err)
 		return
 	}
 
-	json.NewEncoder(w).Encode(proposal)
+	c.JSON(http.StatusOK, proposal)
 }
 
 // CastVote handles vote casting on proposals
-func (h *GovernanceHandler) CastVote(w http.ResponseWriter, r *http.Request) {
-	proposalID := mux.Vars(r)["id"]
-
+func (h *GovernanceHandler) CastVote(c *gin.Context) {
 	var req models.VoteCastRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		respondBadRequest(c, err)
 		return
 	}
 
-	req.ProposalID = proposalID
+	req.ProposalID = c.Param("id")
+	if err := validation.Struct(req); err != nil {
+		problem.Respond(c, problem.FromBindingError(err))
+		return
+	}
 
 	// Get voter address from authenticated user
-	voterAddress := r.Context().Value("user_address").(string)
+	voterAddress := c.GetString("user_address")
+	if voterAddress == "" {
+		problem.Write(c, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
 
-	response, err := h.governanceService.CastVote(r.Context(), req, voterAddress)
+	response, err := h.governanceService.CastVote(c, req, voterAddress)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		respondGovernanceError(c, err, respondError)
 		return
 	}
 
-	json.NewEncoder(w).Encode(response)
+	c.JSON(http.StatusOK, response)
 }
 
-// ExecuteProposal handles proposal execution requests
-func (h *GovernanceHandler) ExecuteProposal(w http.ResponseWriter, r *http.Request) {
-	proposalID := mux.Vars(r)["id"]
+// CastSignedVote handles off-chain votes signed with the voter's Stellar key
+func (h *GovernanceHandler) CastSignedVote(c *gin.Context) {
+	var req models.SignedVoteRequest
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	req.ProposalID = c.Param("id")
+	if err := validation.Struct(req); err != nil {
+		problem.Respond(c, problem.FromBindingError(err))
+		return
+	}
+
+	// Get voter address from authenticated user
+	voterAddress := c.GetString("user_address")
+	if voterAddress == "" {
+		problem.Write(c, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	ballot, err := h.governanceService.CastSignedVote(c, req, voterAddress)
+	if err != nil {
+		respondGovernanceError(c, err, respondBadRequest)
+		return
+	}
+
+	c.JSON(http.StatusOK, ballot)
+}
+
+// GetBallotSet handles publishing the off-chain ballots of a proposal
+func (h *GovernanceHandler) GetBallotSet(c *gin.Context) {
+	ballotSet, err := h.governanceService.GetBallotSet(c, c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ballotSet)
+}
+
+// FinalizeProposal handles tallying a proposal whose voting period has ended
+func (h *GovernanceHandler) FinalizeProposal(c *gin.Context) {
+	response, err := h.governanceService.FinalizeProposal(c, c.Param("id"))
+	if err != nil {
+		respondGovernanceError(c, err, respondError)
+		return
+	}
 
+	c.JSON(http.StatusOK, response)
+}
+
+// ExecuteProposal handles proposal execution requests
+func (h *GovernanceHandler) ExecuteProposal(c *gin.Context) {
 	req := models.ProposalExecuteRequest{
-		ProposalID: proposalID,
+		ProposalID: c.Param("id"),
 	}
 
-	response, err := h.governanceService.ExecuteProposal(r.Context(), req)
+	response, err := h.governanceService.ExecuteProposal(c, req)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		respondGovernanceError(c, err, respondError)
 		return
 	}
 
-	json.NewEncoder(w).Encode(response)
+	c.JSON(http.StatusOK, response)
 }
 
 // GetVote handles getting a specific vote
-func (h *GovernanceHandler) GetVote(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	proposalID := vars["id"]
-	voterAddress := vars["voter"]
-
-	vote, err := h.governanceService.GetVote(r.Context(), proposalID, voterAddress)
+func (h *GovernanceHandler) GetVote(c *gin.Context) {
+	vote, err := h.governanceService.GetVote(c, c.Param("id"), c.Param("voter"))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		respondError(c, err)
 		return
 	}
 
-	json.NewEncoder(w).Encode(vote)
+	c.JSON(http.StatusOK, vote)
 }
 
 // GetParameter handles getting governance parameters
-func (h *GovernanceHandler) GetParameter(w http.ResponseWriter, r *http.Request) {
-	paramName := mux.Vars(r)["name"]
-
-	parameter, err := h.governanceService.GetParameter(r.Context(), paramName)
+func (h *GovernanceHandler) GetParameter(c *gin.Context) {
+	parameter, err := h.governanceService.GetParameter(c, c.Param("name"))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		respondError(c, err)
 		return
 	}
 
-	json.NewEncoder(w).Encode(parameter)
+	c.JSON(http.StatusOK, parameter)
+}
+
+// respondGovernanceError answers 409 when a proposal is not in a state that
+// allows the request or a newer ballot was already cast, and with respond otherwise
+func respondGovernanceError(c *gin.Context, err error, respond func(*gin.Context, error)) {
+	if errors.Is(err, services.ErrProposalNotActive) || errors.Is(err, services.ErrVotingNotEnded) ||
+		errors.Is(err, services.ErrStaleBallot) {
+		problem.Write(c, http.StatusConflict, err.Error())
+		return
+	}
+	respond(c, err)
 }
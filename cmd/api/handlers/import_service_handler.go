@@ -22,12 +22,12 @@ func NewImportServiceHandler(importService *services.ImportService) *ImportServi
 func (h *ImportServiceHandler) CreateSeaImportService(c *gin.Context) {
 	var service models.SeaImportService
 	if err := c.ShouldBindJSON(&service); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
 	if err := h.importService.CreateSeaImportService(&service); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -38,12 +38,12 @@ func (h *ImportServiceHandler) CreateSeaImportService(c *gin.Context) {
 func (h *ImportServiceHandler) CreateAirImportService(c *gin.Context) {
 	var service models.AirImportService
 	if err := c.ShouldBindJSON(&service); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
 	if err := h.importService.CreateAirImportService(&service); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -54,12 +54,12 @@ func (h *ImportServiceHandler) CreateAirImportService(c *gin.Context) {
 func (h *ImportServiceHandler) CreateRailImportService(c *gin.Context) {
 	var service models.RailImportService
 	if err := c.ShouldBindJSON(&service); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
 	if err := h.importService.CreateRailImportService(&service); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -70,12 +70,12 @@ func (h *ImportServiceHandler) CreateRailImportService(c *gin.Context) {
 func (h *ImportServiceHandler) CreateLandImportService(c *gin.Context) {
 	var service models.LandImportService
 	if err := c.ShouldBindJSON(&service); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
 	if err := h.importService.CreateLandImportService(&service); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -88,7 +88,7 @@ func (h *ImportServiceHandler) GetImportServiceSchedule(c *gin.Context) {
 
 	schedule, err := h.importService.GetImportServiceSchedule(serviceID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -101,7 +101,7 @@ func (h *ImportServiceHandler) GetImportServiceAvailability(c *gin.Context) {
 
 	availability, err := h.importService.GetImportServiceAvailability(serviceID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -114,7 +114,7 @@ func (h *ImportServiceHandler) GetImportServiceRate(c *gin.Context) {
 
 	rate, err := h.importService.GetImportServiceRate(serviceID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -127,7 +127,7 @@ func (h *ImportServiceHandler) GetImportServiceRequirements(c *gin.Context) {
 
 	requirements, err := h.importService.GetImportServiceRequirements(serviceType)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -138,12 +138,12 @@ func (h *ImportServiceHandler) GetImportServiceRequirements(c *gin.Context) {
 func (h *ImportServiceHandler) UpdateImportServiceSchedule(c *gin.Context) {
 	var schedule models.ImportServiceSchedule
 	if err := c.ShouldBindJSON(&schedule); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
 	if err := h.importService.UpdateImportServiceSchedule(&schedule); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -154,12 +154,12 @@ func (h *ImportServiceHandler) UpdateImportServiceSchedule(c *gin.Context) {
 func (h *ImportServiceHandler) UpdateImportServiceRate(c *gin.Context) {
 	var rate models.ImportServiceRate
 	if err := c.ShouldBindJSON(&rate); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
 	if err := h.importService.UpdateImportServiceRate(&rate); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -172,7 +172,7 @@ func (h *ImportServiceHandler) ListImportServices(c *gin.Context) {
 
 	services, err := h.importService.ListImportServices(mode)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -185,7 +185,7 @@ func (h *ImportServiceHandler) GetImportService(c *gin.Context) {
 
 	service, err := h.importService.GetImportService(serviceID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -196,7 +196,7 @@ func (h *ImportServiceHandler) GetImportService(c *gin.Context) {
 func (h *ImportServiceHandler) ListSeaImportServices(c *gin.Context) {
 	services, err := h.importService.ListImportServices(models.SeaTransport)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -207,7 +207,7 @@ func (h *ImportServiceHandler) ListSeaImportServices(c *gin.Context) {
 func (h *ImportServiceHandler) ListAirImportServices(c *gin.Context) {
 	services, err := h.importService.ListImportServices(models.AirTransport)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -218,7 +218,7 @@ func (h *ImportServiceHandler) ListAirImportServices(c *gin.Context) {
 func (h *ImportServiceHandler) ListRailImportServices(c *gin.Context) {
 	services, err := h.importService.ListImportServices(models.RailTransport)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -229,7 +229,7 @@ func (h *ImportServiceHandler) ListRailImportServices(c *gin.Context) {
 func (h *ImportServiceHandler) ListLandImportServices(c *gin.Context) {
 	services, err := h.importService.ListImportServices(models.RoadTransport)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -6,6 +6,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"logistics-marketplace/internal/models"
+	"logistics-marketplace/internal/problem"
 	"logistics-marketplace/internal/services"
 )
 
@@ -23,12 +24,12 @@ func NewInfrastructureHandler(infrastructureService *services.InfrastructureServ
 func (h *InfrastructureHandler) CreateAirport(c *gin.Context) {
 	var airport models.Airport
 	if err := c.ShouldBindJSON(&airport); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
 	if err := h.infrastructureService.CreateAirport(&airport); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -39,12 +40,12 @@ func (h *InfrastructureHandler) CreateAirport(c *gin.Context) {
 func (h *InfrastructureHandler) CreateSeaport(c *gin.Context) {
 	var seaport models.Seaport
 	if err := c.ShouldBindJSON(&seaport); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
 	if err := h.infrastructureService.CreateSeaport(&seaport); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -55,12 +56,12 @@ func (h *InfrastructureHandler) CreateSeaport(c *gin.Context) {
 func (h *InfrastructureHandler) CreateInlandDepot(c *gin.Context) {
 	var depot models.InlandDepot
 	if err := c.ShouldBindJSON(&depot); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
 	if err := h.infrastructureService.CreateInlandDepot(&depot); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -73,7 +74,7 @@ func (h *InfrastructureHandler) GetCountryInfrastructure(c *gin.Context) {
 	
 	infrastructure, err := h.infrastructureService.GetCountryInfrastructure(countryCode)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -87,7 +88,7 @@ func (h *InfrastructureHandler) GetInfrastructureServices(c *gin.Context) {
 
 	services, err := h.infrastructureService.GetInfrastructureServices(infraID, mode)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -99,13 +100,13 @@ func (h *InfrastructureHandler) UpdateInfrastructureCapacity(c *gin.Context) {
 	infraID := c.Param("id")
 	var capacity models.InfrastructureCapacity
 	if err := c.ShouldBindJSON(&capacity); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
 	capacity.InfrastructureID = infraID
 	if err := h.infrastructureService.UpdateInfrastructureCapacity(infraID, &capacity); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -117,13 +118,13 @@ func (h *InfrastructureHandler) UpdateInfrastructureSchedule(c *gin.Context) {
 	infraID := c.Param("id")
 	var schedule models.InfrastructureSchedule
 	if err := c.ShouldBindJSON(&schedule); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
 	schedule.InfrastructureID = infraID
 	if err := h.infrastructureService.UpdateInfrastructureSchedule(infraID, &schedule); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -136,7 +137,7 @@ func (h *InfrastructureHandler) GetServiceLocations(c *gin.Context) {
 	
 	locations, err := h.infrastructureService.GetServiceLocations(serviceID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -147,19 +148,19 @@ func (h *InfrastructureHandler) GetServiceLocations(c *gin.Context) {
 func (h *InfrastructureHandler) GetNearbyInfrastructure(c *gin.Context) {
 	lat, err := strconv.ParseFloat(c.Query("lat"), 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid latitude"})
+		problem.Write(c, http.StatusBadRequest, "invalid latitude")
 		return
 	}
 
 	lng, err := strconv.ParseFloat(c.Query("lng"), 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid longitude"})
+		problem.Write(c, http.StatusBadRequest, "invalid longitude")
 		return
 	}
 
 	radius, err := strconv.ParseFloat(c.Query("radius"), 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid radius"})
+		problem.Write(c, http.StatusBadRequest, "invalid radius")
 		return
 	}
 
@@ -167,7 +168,7 @@ func (h *InfrastructureHandler) GetNearbyInfrastructure(c *gin.Context) {
 
 	infrastructure, err := h.infrastructureService.GetNearbyInfrastructure(lat, lng, radius, infraType)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -180,7 +181,7 @@ func (h *InfrastructureHandler) GetInfrastructureCapacity(c *gin.Context) {
 	
 	capacity, err := h.infrastructureService.GetInfrastructureCapacity(infraID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -193,7 +194,7 @@ func (h *InfrastructureHandler) GetOperatingSchedule(c *gin.Context) {
 	
 	schedule, err := h.infrastructureService.GetOperatingSchedule(infraID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -5,6 +5,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"logistics-marketplace/internal/models"
+	"logistics-marketplace/internal/problem"
 	"logistics-marketplace/internal/services"
 )
 
@@ -32,13 +33,13 @@ func (h *MarketplaceHandler) ListServicesByCategory(c *gin.Context) {
 	case models.ImportService, models.ExportService, models.TransitService, models.TransshipService:
 		// Valid category
 	default:
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid service category"})
+		problem.Write(c, http.StatusBadRequest, "invalid service category")
 		return
 	}
 
 	services, err := h.marketplaceService.GetServicesByCategory(string(category))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -60,7 +61,7 @@ func (h *MarketplaceHandler) ListSubCategories(c *gin.Context) {
 	case models.TransshipService:
 		subcategories = models.TransshipSubCategories
 	default:
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid service category"})
+		problem.Write(c, http.StatusBadRequest, "invalid service category")
 		return
 	}
 
@@ -73,7 +74,7 @@ func (h *MarketplaceHandler) ListServiceItems(c *gin.Context) {
 	
 	items, err := h.marketplaceService.GetServiceItems(subcategoryID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -84,7 +85,7 @@ func (h *MarketplaceHandler) ListServiceItems(c *gin.Context) {
 func (h *MarketplaceHandler) CreateServiceListing(c *gin.Context) {
 	var service models.LogisticsService
 	if err := c.ShouldBindJSON(&service); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
@@ -92,8 +93,8 @@ func (h *MarketplaceHandler) CreateServiceListing(c *gin.Context) {
 	providerID := c.GetString("user_id")
 	service.Provider.ID = providerID
 
-	if err := h.marketplaceService.CreateServiceListing(&service); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.marketplaceService.CreateServiceListing(c, &service); err != nil {
+		respondError(c, err)
 		return
 	}
 
@@ -103,18 +104,18 @@ func (h *MarketplaceHandler) CreateServiceListing(c *gin.Context) {
 // GetQuotation handles rate quotation requests
 func (h *MarketplaceHandler) GetQuotation(c *gin.Context) {
 	var request struct {
-		ServiceID    string            `json:"service_id"`
+		ServiceID    string            `json:"service_id" validate:"required"`
 		CargoDetails models.CargoDetails `json:"cargo_details"`
 	}
 
 	if err := c.ShouldBindJSON(&request); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
 	rate, err := h.marketplaceService.GetQuotation(request.ServiceID, &request.CargoDetails)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -125,7 +126,7 @@ func (h *MarketplaceHandler) GetQuotation(c *gin.Context) {
 func (h *MarketplaceHandler) CreateBooking(c *gin.Context) {
 	var booking models.Booking
 	if err := c.ShouldBindJSON(&booking); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
@@ -133,8 +134,8 @@ func (h *MarketplaceHandler) CreateBooking(c *gin.Context) {
 	customerID := c.GetString("user_id")
 	booking.CustomerID = customerID
 
-	if err := h.marketplaceService.CreateBooking(&booking); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.marketplaceService.CreateBooking(c, &booking); err != nil {
+		respondError(c, err)
 		return
 	}
 
@@ -144,18 +145,18 @@ func (h *MarketplaceHandler) CreateBooking(c *gin.Context) {
 // ProcessPayment handles booking payment
 func (h *MarketplaceHandler) ProcessPayment(c *gin.Context) {
 	var request struct {
-		BookingID string  `json:"booking_id"`
-		Amount    float64 `json:"amount"`
+		BookingID string  `json:"booking_id" validate:"required"`
+		Amount    float64 `json:"amount" validate:"gt=0"`
 	}
 
 	if err := c.ShouldBindJSON(&request); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
 	customerID := c.GetString("user_id")
-	if err := h.marketplaceService.ProcessPayment(request.BookingID, customerID, request.Amount); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.marketplaceService.ProcessPayment(c, request.BookingID, customerID, request.Amount); err != nil {
+		respondError(c, err)
 		return
 	}
 
@@ -166,12 +167,12 @@ func (h *MarketplaceHandler) ProcessPayment(c *gin.Context) {
 func (h *MarketplaceHandler) UpdateShipmentStatus(c *gin.Context) {
 	var event models.TrackingEvent
 	if err := c.ShouldBindJSON(&event); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
-	if err := h.marketplaceService.UpdateShipmentStatus(&event); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.marketplaceService.UpdateShipmentStatus(c, &event); err != nil {
+		respondError(c, err)
 		return
 	}
 
@@ -182,12 +183,12 @@ func (h *MarketplaceHandler) UpdateShipmentStatus(c *gin.Context) {
 func (h *MarketplaceHandler) InitiateCustomsClearance(c *gin.Context) {
 	var clearance models.CustomsClearance
 	if err := c.ShouldBindJSON(&clearance); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
 	if err := h.customsService.InitiateCustomsClearance(&clearance); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -203,7 +204,7 @@ func (h *MarketplaceHandler) SubmitCustomsDeclaration(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&request); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
@@ -212,7 +213,7 @@ func (h *MarketplaceHandler) SubmitCustomsDeclaration(c *gin.Context) {
 		request.DeclarationType,
 		request.Documents,
 	); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -223,7 +224,7 @@ func (h *MarketplaceHandler) SubmitCustomsDeclaration(c *gin.Context) {
 func (h *MarketplaceHandler) GetRequiredDocuments(c *gin.Context) {
 	declarationType := c.Query("declaration_type")
 	if declarationType == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "declaration_type is required"})
+		problem.Write(c, http.StatusBadRequest, "declaration_type is required")
 		return
 	}
 
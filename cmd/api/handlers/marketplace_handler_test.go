@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
@@ -33,7 +34,7 @@ func (m *MockMarketplaceService) GetServiceItems(subcategoryID string) ([]models
 	return args.Get(0).([]models.ServiceItem), args.Error(1)
 }
 
-func (m *MockMarketplaceService) CreateServiceListing(service *models.LogisticsService) error {
+func (m *MockMarketplaceService) CreateServiceListing(ctx context.Context, service *models.LogisticsService) error {
 	args := m.Called(service)
 	return args.Error(0)
 }
@@ -43,17 +44,17 @@ func (m *MockMarketplaceService) GetQuotation(serviceID string, cargoDetails *mo
 	return args.Get(0).(*models.Rate), args.Error(1)
 }
 
-func (m *MockMarketplaceService) CreateBooking(booking *models.Booking) error {
+func (m *MockMarketplaceService) CreateBooking(ctx context.Context, booking *models.Booking) error {
 	args := m.Called(booking)
 	return args.Error(0)
 }
 
-func (m *MockMarketplaceService) ProcessPayment(bookingID string, customerID string, amount float64) error {
+func (m *MockMarketplaceService) ProcessPayment(ctx context.Context, bookingID string, customerID string, amount float64) error {
 	args := m.Called(bookingID, customerID, amount)
 	return args.Error(0)
 }
 
-func (m *MockMarketplaceService) UpdateShipmentStatus(event *models.TrackingEvent) error {
+func (m *MockMarketplaceService) UpdateShipmentStatus(ctx context.Context, event *models.TrackingEvent) error {
 	args := m.Called(event)
 	return args.Error(0)
 }
@@ -135,7 +136,7 @@ func TestListServicesByCategory(t *testing.T) {
 		var response gin.H
 		err := json.Unmarshal(w.Body.Bytes(), &response)
 		assert.NoError(t, err)
-		assert.Equal(t, "invalid service category", response["error"])
+		assert.Equal(t, "invalid service category", response["detail"])
 	})
 
 	t.Run("service error returns internal server error", func(t *testing.T) {
@@ -149,7 +150,7 @@ func TestListServicesByCategory(t *testing.T) {
 		var response gin.H
 		err := json.Unmarshal(w.Body.Bytes(), &response)
 		assert.NoError(t, err)
-		assert.Equal(t, "database error", response["error"])
+		assert.Equal(t, "INTERNAL_ERROR", response["code"])
 	})
 
 	t.Run("empty service list returns success", func(t *testing.T) {
@@ -256,7 +257,7 @@ func TestGetQuotation(t *testing.T) {
 		var response gin.H
 		err := json.Unmarshal(w.Body.Bytes(), &response)
 		assert.NoError(t, err)
-		assert.Contains(t, response["error"], "invalid")
+		assert.Contains(t, response["detail"], "invalid")
 	})
 
 	t.Run("missing required fields returns bad request", func(t *testing.T) {
@@ -288,7 +289,7 @@ func TestGetQuotation(t *testing.T) {
 		var response gin.H
 		err := json.Unmarshal(w.Body.Bytes(), &response)
 		assert.NoError(t, err)
-		assert.Equal(t, "rate calculation error", response["error"])
+		assert.Equal(t, "INTERNAL_ERROR", response["code"])
 	})
 }
 
@@ -338,7 +339,7 @@ func TestCreateBooking(t *testing.T) {
 		var response gin.H
 		err := json.Unmarshal(w.Body.Bytes(), &response)
 		assert.NoError(t, err)
-		assert.Contains(t, response["error"], "invalid")
+		assert.Contains(t, response["detail"], "invalid")
 	})
 
 	t.Run("missing user ID returns error", func(t *testing.T) {
@@ -352,7 +353,7 @@ func TestCreateBooking(t *testing.T) {
 		var response gin.H
 		err := json.Unmarshal(w.Body.Bytes(), &response)
 		assert.NoError(t, err)
-		assert.Contains(t, response["error"], "user_id")
+		assert.Contains(t, response["detail"], "user_id")
 	})
 
 	t.Run("booking creation error returns internal server error", func(t *testing.T) {
@@ -375,7 +376,7 @@ func TestCreateBooking(t *testing.T) {
 		var response gin.H
 		err := json.Unmarshal(w.Body.Bytes(), &response)
 		assert.NoError(t, err)
-		assert.Equal(t, "database error", response["error"])
+		assert.Equal(t, "INTERNAL_ERROR", response["code"])
 	})
 
 	t.Run("invalid booking fields return validation error", func(t *testing.T) {
@@ -404,7 +405,7 @@ func TestCreateBooking(t *testing.T) {
 		var response gin.H
 		err := json.Unmarshal(w.Body.Bytes(), &response)
 		assert.NoError(t, err)
-		assert.Contains(t, response["error"], "validation")
+		assert.Equal(t, "VALIDATION_FAILED", response["code"])
 	})
 }
 
@@ -457,7 +458,7 @@ func TestProcessPayment(t *testing.T) {
 		var response gin.H
 		err := json.Unmarshal(w.Body.Bytes(), &response)
 		assert.NoError(t, err)
-		assert.Contains(t, response["error"], "invalid")
+		assert.Contains(t, response["detail"], "invalid")
 	})
 
 	t.Run("missing user ID returns error", func(t *testing.T) {
@@ -468,7 +469,7 @@ func TestProcessPayment(t *testing.T) {
 		var response gin.H
 		err := json.Unmarshal(w.Body.Bytes(), &response)
 		assert.NoError(t, err)
-		assert.Contains(t, response["error"], "user_id")
+		assert.Contains(t, response["detail"], "user_id")
 	})
 
 	t.Run("payment processing error returns internal server error", func(t *testing.T) {
@@ -481,7 +482,7 @@ func TestProcessPayment(t *testing.T) {
 		var response gin.H
 		err := json.Unmarshal(w.Body.Bytes(), &response)
 		assert.NoError(t, err)
-		assert.Equal(t, "payment failed", response["error"])
+		assert.Equal(t, "INTERNAL_ERROR", response["code"])
 	})
 
 	t.Run("invalid payment fields return validation error", func(t *testing.T) {
@@ -530,7 +531,7 @@ func TestProcessPayment(t *testing.T) {
 				var response gin.H
 				err := json.Unmarshal(w.Body.Bytes(), &response)
 				assert.NoError(t, err)
-				assert.Contains(t, response["error"], "validation")
+				assert.Equal(t, "VALIDATION_FAILED", response["code"])
 			})
 		}
 	})
@@ -586,7 +587,7 @@ func TestInitiateCustomsClearance(t *testing.T) {
 		var response gin.H
 		err := json.Unmarshal(w.Body.Bytes(), &response)
 		assert.NoError(t, err)
-		assert.Contains(t, response["error"], "invalid")
+		assert.Contains(t, response["detail"], "invalid")
 	})
 
 	t.Run("service error returns internal server error", func(t *testing.T) {
@@ -599,7 +600,7 @@ func TestInitiateCustomsClearance(t *testing.T) {
 		var response gin.H
 		err := json.Unmarshal(w.Body.Bytes(), &response)
 		assert.NoError(t, err)
-		assert.Equal(t, "service error", response["error"])
+		assert.Equal(t, "INTERNAL_ERROR", response["code"])
 	})
 
 	t.Run("invalid clearance fields return validation error", func(t *testing.T) {
@@ -675,7 +676,7 @@ func TestInitiateCustomsClearance(t *testing.T) {
 				var response gin.H
 				err := json.Unmarshal(w.Body.Bytes(), &response)
 				assert.NoError(t, err)
-				assert.Contains(t, response["error"], tc.errorMsg)
+				assert.Contains(t, response["detail"], tc.errorMsg)
 			})
 		}
 	})
@@ -690,7 +691,7 @@ func TestInitiateCustomsClearance(t *testing.T) {
 		var response gin.H
 		err := json.Unmarshal(w.Body.Bytes(), &response)
 		assert.NoError(t, err)
-		assert.Contains(t, response["error"], "already exists")
+		assert.Contains(t, response["detail"], "already exists")
 	})
 }
 
@@ -774,7 +775,7 @@ func TestGetRequiredDocuments(t *testing.T) {
 		var response gin.H
 		err := json.Unmarshal(w.Body.Bytes(), &response)
 		assert.NoError(t, err)
-		assert.Equal(t, "declaration_type is required", response["error"])
+		assert.Equal(t, "declaration_type is required", response["detail"])
 	})
 
 	t.Run("invalid declaration type returns bad request", func(t *testing.T) {
@@ -785,7 +786,7 @@ func TestGetRequiredDocuments(t *testing.T) {
 		var response gin.H
 		err := json.Unmarshal(w.Body.Bytes(), &response)
 		assert.NoError(t, err)
-		assert.Contains(t, response["error"], "invalid declaration type")
+		assert.Contains(t, response["detail"], "invalid declaration type")
 	})
 
 	t.Run("empty document list returns success with empty array", func(t *testing.T) {
@@ -914,7 +915,7 @@ func TestSubmitCustomsDeclaration(t *testing.T) {
 		var response gin.H
 		err := json.Unmarshal(w.Body.Bytes(), &response)
 		assert.NoError(t, err)
-		assert.Contains(t, response["error"], "invalid")
+		assert.Contains(t, response["detail"], "invalid")
 	})
 
 	t.Run("missing required fields return validation error", func(t *testing.T) {
@@ -969,7 +970,7 @@ func TestSubmitCustomsDeclaration(t *testing.T) {
 				var response gin.H
 				err := json.Unmarshal(w.Body.Bytes(), &response)
 				assert.NoError(t, err)
-				assert.Contains(t, response["error"], tc.errMsg)
+				assert.Contains(t, response["detail"], tc.errMsg)
 			})
 		}
 	})
@@ -988,7 +989,7 @@ func TestSubmitCustomsDeclaration(t *testing.T) {
 		var response gin.H
 		err := json.Unmarshal(w.Body.Bytes(), &response)
 		assert.NoError(t, err)
-		assert.Equal(t, "service error", response["error"])
+		assert.Equal(t, "INTERNAL_ERROR", response["code"])
 	})
 
 	t.Run("invalid document formats return validation error", func(t *testing.T) {
@@ -1022,7 +1023,7 @@ func TestSubmitCustomsDeclaration(t *testing.T) {
 				var response gin.H
 				err := json.Unmarshal(w.Body.Bytes(), &response)
 				assert.NoError(t, err)
-				assert.Contains(t, response["error"], "invalid document format")
+				assert.Contains(t, response["detail"], "invalid document format")
 			})
 		}
 	})
@@ -1041,6 +1042,6 @@ func TestSubmitCustomsDeclaration(t *testing.T) {
 		var response gin.H
 		err := json.Unmarshal(w.Body.Bytes(), &response)
 		assert.NoError(t, err)
-		assert.Contains(t, response["error"], "not found")
+		assert.Contains(t, response["detail"], "not found")
 	})
 }
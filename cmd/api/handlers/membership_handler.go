@@ -5,6 +5,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"logistics-marketplace/internal/models"
+	"logistics-marketplace/internal/problem"
 	"logistics-marketplace/internal/services"
 )
 
@@ -22,25 +23,25 @@ func NewMembershipHandler(membershipService *services.MembershipService) *Member
 func (h *MembershipHandler) ActivateMembership(c *gin.Context) {
 	membershipID := c.Param("id")
 	var request struct {
-		PaymentMethod string  `json:"payment_method"`
-		Amount        float64 `json:"amount"`
+		PaymentMethod string  `json:"payment_method" validate:"required"`
+		Amount        float64 `json:"amount" validate:"gt=0"`
 	}
 
 	if err := c.ShouldBindJSON(&request); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
 	// Process payment
 	payment, err := h.membershipService.ProcessPayment(c, membershipID, request.Amount, request.PaymentMethod)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
 	// Activate membership
 	if err := h.membershipService.ActivateMembership(c, membershipID, payment); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -54,25 +55,25 @@ func (h *MembershipHandler) ActivateMembership(c *gin.Context) {
 func (h *MembershipHandler) RenewMembership(c *gin.Context) {
 	membershipID := c.Param("id")
 	var request struct {
-		PaymentMethod string  `json:"payment_method"`
-		Amount        float64 `json:"amount"`
+		PaymentMethod string  `json:"payment_method" validate:"required"`
+		Amount        float64 `json:"amount" validate:"gt=0"`
 	}
 
 	if err := c.ShouldBindJSON(&request); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
 	// Process payment
 	payment, err := h.membershipService.ProcessPayment(c, membershipID, request.Amount, request.PaymentMethod)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
 	// Renew membership
 	if err := h.membershipService.RenewMembership(c, membershipID, payment); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -87,7 +88,7 @@ func (h *MembershipHandler) CancelMembership(c *gin.Context) {
 	membershipID := c.Param("id")
 
 	if err := h.membershipService.CancelMembership(c, membershipID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -100,25 +101,22 @@ func (h *MembershipHandler) GetMembershipStatus(c *gin.Context) {
 	memberID := c.Query("member_id")
 
 	if memberType == "" || memberID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "member type and ID are required"})
+		problem.Write(c, http.StatusBadRequest, "member type and ID are required")
 		return
 	}
 
 	// Validate membership
 	err := h.membershipService.ValidateMembership(c, memberType, memberID)
 	if err != nil {
-		if _, ok := err.(*models.MembershipError); ok {
-			c.JSON(http.StatusPaymentRequired, gin.H{"error": err.Error()})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		// A MembershipError answers 402 Payment Required
+		respondError(c, err)
 		return
 	}
 
 	// Get membership details
 	membership, err := h.membershipService.GetMembershipByMember(memberType, memberID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
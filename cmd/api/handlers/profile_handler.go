@@ -5,6 +5,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"logistics-marketplace/internal/models"
+	"logistics-marketplace/internal/problem"
 	"logistics-marketplace/internal/services"
 )
 
@@ -22,12 +23,12 @@ func NewProfileHandler(profileService *services.ProfileService) *ProfileHandler
 func (h *ProfileHandler) CreateServiceProvider(c *gin.Context) {
 	var provider models.ServiceProvider
 	if err := c.ShouldBindJSON(&provider); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
 	if err := h.profileService.CreateServiceProvider(&provider); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -38,12 +39,12 @@ func (h *ProfileHandler) CreateServiceProvider(c *gin.Context) {
 func (h *ProfileHandler) CreateServiceBuyer(c *gin.Context) {
 	var buyer models.ServiceBuyer
 	if err := c.ShouldBindJSON(&buyer); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
 	if err := h.profileService.CreateServiceBuyer(&buyer); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -55,19 +56,19 @@ func (h *ProfileHandler) UpdateServiceProvider(c *gin.Context) {
 	id := c.Param("id")
 	var provider models.ServiceProvider
 	if err := c.ShouldBindJSON(&provider); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
 	// Verify provider ID matches authenticated user
 	if id != c.GetString("user_id") {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		problem.Write(c, http.StatusUnauthorized, "unauthorized")
 		return
 	}
 
 	provider.ID = id
 	if err := h.profileService.UpdateServiceProvider(&provider); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -79,19 +80,19 @@ func (h *ProfileHandler) UpdateServiceBuyer(c *gin.Context) {
 	id := c.Param("id")
 	var buyer models.ServiceBuyer
 	if err := c.ShouldBindJSON(&buyer); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
 	// Verify buyer ID matches authenticated user
 	if id != c.GetString("user_id") {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		problem.Write(c, http.StatusUnauthorized, "unauthorized")
 		return
 	}
 
 	buyer.ID = id
 	if err := h.profileService.UpdateServiceBuyer(&buyer); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -103,18 +104,18 @@ func (h *ProfileHandler) AddPort(c *gin.Context) {
 	providerID := c.Param("id")
 	var port models.Port
 	if err := c.ShouldBindJSON(&port); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
 	// Verify provider ID matches authenticated user
 	if providerID != c.GetString("user_id") {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		problem.Write(c, http.StatusUnauthorized, "unauthorized")
 		return
 	}
 
 	if err := h.profileService.AddPort(providerID, port); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -126,18 +127,18 @@ func (h *ProfileHandler) AddAirport(c *gin.Context) {
 	providerID := c.Param("id")
 	var airport models.Airport
 	if err := c.ShouldBindJSON(&airport); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
 	// Verify provider ID matches authenticated user
 	if providerID != c.GetString("user_id") {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		problem.Write(c, http.StatusUnauthorized, "unauthorized")
 		return
 	}
 
 	if err := h.profileService.AddAirport(providerID, airport); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -149,18 +150,18 @@ func (h *ProfileHandler) AddTerminal(c *gin.Context) {
 	providerID := c.Param("id")
 	var terminal models.Terminal
 	if err := c.ShouldBindJSON(&terminal); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
 	// Verify provider ID matches authenticated user
 	if providerID != c.GetString("user_id") {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		problem.Write(c, http.StatusUnauthorized, "unauthorized")
 		return
 	}
 
 	if err := h.profileService.AddTerminal(providerID, terminal); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
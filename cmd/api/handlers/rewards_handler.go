@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"logistics-marketplace/internal/models"
+	"logistics-marketplace/internal/problem"
+	"logistics-marketplace/internal/services"
+)
+
+type RewardsHandler struct {
+	rewardsService *services.RewardsService
+}
+
+func NewRewardsHandler(rewardsService *services.RewardsService) *RewardsHandler {
+	return &RewardsHandler{
+		rewardsService: rewardsService,
+	}
+}
+
+// EvaluateBooking handles evaluating a delivered booking for rewards
+func (h *RewardsHandler) EvaluateBooking(c *gin.Context) {
+	var req models.RewardEvaluationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	entries, err := h.rewardsService.EvaluateBooking(c, req.BookingID)
+	if errors.Is(err, services.ErrBookingEvaluated) || errors.Is(err, services.ErrBookingNotDelivered) {
+		problem.Write(c, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+// GetLedger handles retrieving the rewards ledger of an account
+func (h *RewardsHandler) GetLedger(c *gin.Context) {
+	ledger, err := h.rewardsService.GetLedger(c, c.Param("account"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ledger)
+}
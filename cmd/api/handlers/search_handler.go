@@ -5,6 +5,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"logistics-marketplace/internal/models"
+	"logistics-marketplace/internal/problem"
 	"logistics-marketplace/internal/services"
 )
 
@@ -22,7 +23,7 @@ func NewSearchHandler(searchService *services.SearchService) *SearchHandler {
 func (h *SearchHandler) SearchServices(c *gin.Context) {
 	var filter models.SearchFilter
 	if err := c.ShouldBindJSON(&filter); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
@@ -36,7 +37,7 @@ func (h *SearchHandler) SearchServices(c *gin.Context) {
 
 	result, err := h.searchService.SearchServices(&filter)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -47,13 +48,13 @@ func (h *SearchHandler) SearchServices(c *gin.Context) {
 func (h *SearchHandler) GetSearchSuggestions(c *gin.Context) {
 	query := c.Query("q")
 	if query == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "query parameter 'q' is required"})
+		problem.Write(c, http.StatusBadRequest, "query parameter 'q' is required")
 		return
 	}
 
 	suggestions, err := h.searchService.GetSearchSuggestions(query)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
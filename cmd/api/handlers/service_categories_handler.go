@@ -24,13 +24,13 @@ func NewServiceCategoriesHandler(categoriesService *services.ServiceCategoriesSe
 func (h *ServiceCategoriesHandler) CreateImportSeaService(c *gin.Context) {
 	var service models.SeaService
 	if err := c.ShouldBindJSON(&service); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
 	service.Category = models.ImportDirect
 	if err := h.categoriesService.CreateSeaService(&service); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -41,13 +41,13 @@ func (h *ServiceCategoriesHandler) CreateImportSeaService(c *gin.Context) {
 func (h *ServiceCategoriesHandler) CreateImportAirService(c *gin.Context) {
 	var service models.AirService
 	if err := c.ShouldBindJSON(&service); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
 	service.Category = models.ImportDirect
 	if err := h.categoriesService.CreateAirService(&service); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -58,13 +58,13 @@ func (h *ServiceCategoriesHandler) CreateImportAirService(c *gin.Context) {
 func (h *ServiceCategoriesHandler) CreateImportRailService(c *gin.Context) {
 	var service models.RailService
 	if err := c.ShouldBindJSON(&service); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
 	service.Category = models.ImportDirect
 	if err := h.categoriesService.CreateRailService(&service); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -75,13 +75,13 @@ func (h *ServiceCategoriesHandler) CreateImportRailService(c *gin.Context) {
 func (h *ServiceCategoriesHandler) CreateImportLandService(c *gin.Context) {
 	var service models.LandService
 	if err := c.ShouldBindJSON(&service); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
 	service.Category = models.ImportDirect
 	if err := h.categoriesService.CreateLandService(&service); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -94,13 +94,13 @@ func (h *ServiceCategoriesHandler) CreateImportLandService(c *gin.Context) {
 func (h *ServiceCategoriesHandler) CreateExportSeaService(c *gin.Context) {
 	var service models.SeaService
 	if err := c.ShouldBindJSON(&service); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
 	service.Category = models.ExportDirect
 	if err := h.categoriesService.CreateSeaService(&service); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -111,13 +111,13 @@ func (h *ServiceCategoriesHandler) CreateExportSeaService(c *gin.Context) {
 func (h *ServiceCategoriesHandler) CreateExportAirService(c *gin.Context) {
 	var service models.AirService
 	if err := c.ShouldBindJSON(&service); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
 	service.Category = models.ExportDirect
 	if err := h.categoriesService.CreateAirService(&service); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -128,13 +128,13 @@ func (h *ServiceCategoriesHandler) CreateExportAirService(c *gin.Context) {
 func (h *ServiceCategoriesHandler) CreateExportRailService(c *gin.Context) {
 	var service models.RailService
 	if err := c.ShouldBindJSON(&service); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
 	service.Category = models.ExportDirect
 	if err := h.categoriesService.CreateRailService(&service); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -145,13 +145,13 @@ func (h *ServiceCategoriesHandler) CreateExportRailService(c *gin.Context) {
 func (h *ServiceCategoriesHandler) CreateExportLandService(c *gin.Context) {
 	var service models.LandService
 	if err := c.ShouldBindJSON(&service); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
 	service.Category = models.ExportDirect
 	if err := h.categoriesService.CreateLandService(&service); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -165,12 +165,12 @@ func (h *ServiceCategoriesHandler) AddTransitDetails(c *gin.Context) {
 	serviceID := c.Param("id")
 	var details models.TransitDetails
 	if err := c.ShouldBindJSON(&details); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
 	if err := h.categoriesService.AddTransitDetails(serviceID, &details); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -184,12 +184,12 @@ func (h *ServiceCategoriesHandler) AddTransshipmentDetails(c *gin.Context) {
 	serviceID := c.Param("id")
 	var details models.TransshipmentDetails
 	if err := c.ShouldBindJSON(&details); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
 	if err := h.categoriesService.AddTransshipmentDetails(serviceID, &details); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -204,7 +204,7 @@ func (h *ServiceCategoriesHandler) GetServiceSchedule(c *gin.Context) {
 
 	schedule, err := h.categoriesService.GetServiceSchedule(serviceID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -217,7 +217,7 @@ func (h *ServiceCategoriesHandler) GetServiceAvailability(c *gin.Context) {
 
 	availability, err := h.categoriesService.GetServiceAvailability(serviceID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -230,7 +230,7 @@ func (h *ServiceCategoriesHandler) GetServiceRate(c *gin.Context) {
 
 	rate, err := h.categoriesService.GetServiceRate(serviceID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -244,7 +244,7 @@ func (h *ServiceCategoriesHandler) GetServiceRequirements(c *gin.Context) {
 
 	requirements, err := h.categoriesService.GetServiceRequirements(category, mode)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -258,7 +258,7 @@ func (h *ServiceCategoriesHandler) ListServices(c *gin.Context) {
 
 	services, err := h.categoriesService.ListServices(category, mode)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -271,7 +271,7 @@ func (h *ServiceCategoriesHandler) GetService(c *gin.Context) {
 
 	service, err := h.categoriesService.GetService(serviceID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -282,12 +282,12 @@ func (h *ServiceCategoriesHandler) GetService(c *gin.Context) {
 func (h *ServiceCategoriesHandler) UpdateServiceSchedule(c *gin.Context) {
 	var schedule models.ServiceSchedule
 	if err := c.ShouldBindJSON(&schedule); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
 	if err := h.categoriesService.UpdateServiceSchedule(&schedule); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -298,12 +298,12 @@ func (h *ServiceCategoriesHandler) UpdateServiceSchedule(c *gin.Context) {
 func (h *ServiceCategoriesHandler) UpdateServiceRate(c *gin.Context) {
 	var rate models.ServiceRate
 	if err := c.ShouldBindJSON(&rate); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
 	if err := h.categoriesService.UpdateServiceRate(&rate); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"logistics-marketplace/internal/models"
+	"logistics-marketplace/internal/problem"
+	"logistics-marketplace/internal/services"
+)
+
+type StakingHandler struct {
+	stakingService *services.StakingService
+}
+
+func NewStakingHandler(stakingService *services.StakingService) *StakingHandler {
+	return &StakingHandler{
+		stakingService: stakingService,
+	}
+}
+
+// GetStake handles retrieving a provider's stake
+func (h *StakingHandler) GetStake(c *gin.Context) {
+	stake, err := h.stakingService.GetStake(c, c.Param("provider"))
+	if err != nil {
+		problem.Write(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, stake)
+}
+
+// Stake handles providers bonding stake
+func (h *StakingHandler) Stake(c *gin.Context) {
+	var req models.StakeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	stake, err := h.stakingService.Stake(c, c.GetString("user_address"), req.Amount)
+	if err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, stake)
+}
+
+// Unstake handles providers starting to unbond stake
+func (h *StakingHandler) Unstake(c *gin.Context) {
+	var req models.StakeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	stake, err := h.stakingService.Unstake(c, c.GetString("user_address"), req.Amount)
+	if err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, stake)
+}
+
+// WithdrawUnbonded handles providers withdrawing unbonded stake
+func (h *StakingHandler) WithdrawUnbonded(c *gin.Context) {
+	stake, err := h.stakingService.WithdrawUnbonded(c, c.GetString("user_address"))
+	if err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, stake)
+}
+
+// ConfirmSchedule handles providers committing to a booking schedule
+func (h *StakingHandler) ConfirmSchedule(c *gin.Context) {
+	var req models.ScheduleConfirmationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	if err := h.stakingService.ConfirmSchedule(c, c.Param("id"), c.GetString("user_address"), &req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "confirmed"})
+}
+
+// RaiseDispute handles customers disputing a booking
+func (h *StakingHandler) RaiseDispute(c *gin.Context) {
+	var req models.DisputeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	dispute, err := h.stakingService.RaiseDispute(c, c.Param("id"), c.GetString("user_address"), req.Reason)
+	if err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dispute)
+}
+
+// GetDispute handles retrieving the dispute of a booking
+func (h *StakingHandler) GetDispute(c *gin.Context) {
+	dispute, err := h.stakingService.GetDispute(c, c.Param("id"))
+	if err != nil {
+		problem.Write(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, dispute)
+}
+
+// ResolveDispute handles the arbiter ruling on a dispute
+func (h *StakingHandler) ResolveDispute(c *gin.Context) {
+	var req models.DisputeResolutionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	dispute, err := h.stakingService.ResolveDispute(c, c.Param("id"), c.GetString("user_address"), req.Upheld)
+	if err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dispute)
+}
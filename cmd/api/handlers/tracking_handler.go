@@ -1,20 +1,25 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"logistics-marketplace/internal/auth"
 	"logistics-marketplace/internal/models"
+	"logistics-marketplace/internal/problem"
 	"logistics-marketplace/internal/services"
 )
 
 type TrackingHandler struct {
 	trackingService *services.TrackingService
+	bookings        auth.BookingLookup
 }
 
-func NewTrackingHandler(trackingService *services.TrackingService) *TrackingHandler {
+func NewTrackingHandler(trackingService *services.TrackingService, bookings auth.BookingLookup) *TrackingHandler {
 	return &TrackingHandler{
 		trackingService: trackingService,
+		bookings:        bookings,
 	}
 }
 
@@ -22,19 +27,17 @@ func NewTrackingHandler(trackingService *services.TrackingService) *TrackingHand
 func (h *TrackingHandler) AddTrackingEvent(c *gin.Context) {
 	var event models.TrackingEvent
 	if err := c.ShouldBindJSON(&event); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
 	// Verify provider authorization
-	providerID := c.GetString("user_id")
-	if !h.isAuthorizedProvider(providerID, event.BookingID) {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized provider"})
+	if !h.authorizeBooking(c, event.BookingID, auth.CanUpdateShipment) {
 		return
 	}
 
-	if err := h.trackingService.AddTrackingEvent(&event); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.trackingService.AddTrackingEvent(c, &event); err != nil {
+		respondError(c, err)
 		return
 	}
 
@@ -45,19 +48,17 @@ func (h *TrackingHandler) AddTrackingEvent(c *gin.Context) {
 func (h *TrackingHandler) AddTransshipmentPoint(c *gin.Context) {
 	var point models.TransshipmentPoint
 	if err := c.ShouldBindJSON(&point); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
 	// Verify provider authorization
-	providerID := c.GetString("user_id")
-	if !h.isAuthorizedProvider(providerID, point.BookingID) {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized provider"})
+	if !h.authorizeBooking(c, point.BookingID, auth.CanUpdateShipment) {
 		return
 	}
 
-	if err := h.trackingService.AddTransshipmentPoint(&point); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.trackingService.AddTransshipmentPoint(c, &point); err != nil {
+		respondError(c, err)
 		return
 	}
 
@@ -69,19 +70,17 @@ func (h *TrackingHandler) UpdateRouting(c *gin.Context) {
 	bookingID := c.Param("booking_id")
 	var points []models.TransshipmentPoint
 	if err := c.ShouldBindJSON(&points); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
 	// Verify provider authorization
-	providerID := c.GetString("user_id")
-	if !h.isAuthorizedProvider(providerID, bookingID) {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized provider"})
+	if !h.authorizeBooking(c, bookingID, auth.CanUpdateShipment) {
 		return
 	}
 
-	if err := h.trackingService.UpdateRouting(bookingID, points); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.trackingService.UpdateRouting(c, bookingID, points); err != nil {
+		respondError(c, err)
 		return
 	}
 
@@ -93,21 +92,40 @@ func (h *TrackingHandler) GetShipmentTracking(c *gin.Context) {
 	bookingID := c.Param("booking_id")
 
 	// Verify user authorization (both customer and provider should be able to track)
-	userID := c.GetString("user_id")
-	if !h.isAuthorizedUser(userID, bookingID) {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized user"})
+	if !h.authorizeBooking(c, bookingID, auth.CanViewBooking) {
 		return
 	}
 
-	events, err := h.trackingService.GetShipmentTracking(bookingID)
+	events, err := h.trackingService.GetShipmentTracking(c, bookingID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, events)
 }
 
+// GetPublicTracking handles retrieving the status history of a shipment
+// without authentication, leaving out the parties' descriptions
+func (h *TrackingHandler) GetPublicTracking(c *gin.Context) {
+	events, err := h.trackingService.GetShipmentTracking(c, c.Param("booking_id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	milestones := make([]gin.H, 0, len(events))
+	for _, event := range events {
+		milestones = append(milestones, gin.H{
+			"status":    event.Status,
+			"location":  event.Location,
+			"timestamp": event.Timestamp,
+		})
+	}
+
+	c.JSON(http.StatusOK, milestones)
+}
+
 // GetOptimalRoute handles calculating optimal route
 func (h *TrackingHandler) GetOptimalRoute(c *gin.Context) {
 	var request struct {
@@ -118,7 +136,7 @@ func (h *TrackingHandler) GetOptimalRoute(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&request); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
@@ -129,7 +147,7 @@ func (h *TrackingHandler) GetOptimalRoute(c *gin.Context) {
 		request.CargoDetails,
 	)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -146,14 +164,32 @@ func (h *TrackingHandler) GetOptimalRoute(c *gin.Context) {
 
 // Helper functions
 
-func (h *TrackingHandler) isAuthorizedProvider(providerID, bookingID string) bool {
-	// In a real implementation, this would check if the provider is associated with the booking
-	// This is a simplified example
-	return true
-}
+// authorizeBooking applies a booking access check, writing the error response when it fails
+func (h *TrackingHandler) authorizeBooking(
+	c *gin.Context,
+	bookingID string,
+	check func(*auth.Identity, *auth.BookingParties) error,
+) bool {
+	identity, ok := auth.FromContext(c)
+	if !ok {
+		problem.Write(c, http.StatusUnauthorized, "authentication required")
+		return false
+	}
+
+	parties, err := h.bookings.GetBookingParties(c, bookingID)
+	if errors.Is(err, auth.ErrBookingNotFound) {
+		problem.Write(c, http.StatusNotFound, err.Error())
+		return false
+	}
+	if err != nil {
+		problem.Error(c, err)
+		return false
+	}
+
+	if err := check(identity, parties); err != nil {
+		problem.Write(c, http.StatusForbidden, err.Error())
+		return false
+	}
 
-func (h *TrackingHandler) isAuthorizedUser(userID, bookingID string) bool {
-	// In a real implementation, this would check if the user is either the customer or provider
-	// This is a simplified example
 	return true
 }
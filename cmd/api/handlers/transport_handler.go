@@ -5,6 +5,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"logistics-marketplace/internal/models"
+	"logistics-marketplace/internal/problem"
 	"logistics-marketplace/internal/services"
 )
 
@@ -22,7 +23,7 @@ func NewTransportHandler(marketplaceService *services.MarketplaceService) *Trans
 func (h *TransportHandler) CreateSeaService(c *gin.Context) {
 	var service models.SeaService
 	if err := c.ShouldBindJSON(&service); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
@@ -34,7 +35,7 @@ func (h *TransportHandler) CreateSeaService(c *gin.Context) {
 	service.Provider.ID = providerID
 
 	if err := h.marketplaceService.CreateTransportService(&service.TransportService); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -45,7 +46,7 @@ func (h *TransportHandler) CreateSeaService(c *gin.Context) {
 func (h *TransportHandler) CreateAirService(c *gin.Context) {
 	var service models.AirService
 	if err := c.ShouldBindJSON(&service); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
@@ -57,7 +58,7 @@ func (h *TransportHandler) CreateAirService(c *gin.Context) {
 	service.Provider.ID = providerID
 
 	if err := h.marketplaceService.CreateTransportService(&service.TransportService); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -68,7 +69,7 @@ func (h *TransportHandler) CreateAirService(c *gin.Context) {
 func (h *TransportHandler) CreateRailService(c *gin.Context) {
 	var service models.RailService
 	if err := c.ShouldBindJSON(&service); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
@@ -80,7 +81,7 @@ func (h *TransportHandler) CreateRailService(c *gin.Context) {
 	service.Provider.ID = providerID
 
 	if err := h.marketplaceService.CreateTransportService(&service.TransportService); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -91,7 +92,7 @@ func (h *TransportHandler) CreateRailService(c *gin.Context) {
 func (h *TransportHandler) CreateRoadService(c *gin.Context) {
 	var service models.RoadService
 	if err := c.ShouldBindJSON(&service); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
@@ -103,7 +104,7 @@ func (h *TransportHandler) CreateRoadService(c *gin.Context) {
 	service.Provider.ID = providerID
 
 	if err := h.marketplaceService.CreateTransportService(&service.TransportService); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -117,7 +118,7 @@ func (h *TransportHandler) ListServicesByMode(c *gin.Context) {
 
 	services, err := h.marketplaceService.GetServicesByMode(mode, category)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -130,7 +131,7 @@ func (h *TransportHandler) GetServiceTemplates(c *gin.Context) {
 	
 	templates := models.GetServiceTemplatesByMode(mode)
 	if templates == nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid transport mode"})
+		problem.Write(c, http.StatusBadRequest, "invalid transport mode")
 		return
 	}
 
@@ -143,7 +144,7 @@ func (h *TransportHandler) GetEquipmentTypes(c *gin.Context) {
 	
 	equipment := models.GetEquipmentByMode(mode)
 	if equipment == nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid transport mode"})
+		problem.Write(c, http.StatusBadRequest, "invalid transport mode")
 		return
 	}
 
@@ -160,20 +161,20 @@ func (h *TransportHandler) UpdateService(c *gin.Context) {
 	case models.SeaTransport, models.AirTransport, models.RailTransport, models.RoadTransport:
 		// Valid mode
 	default:
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid transport mode"})
+		problem.Write(c, http.StatusBadRequest, "invalid transport mode")
 		return
 	}
 
 	var service models.TransportService
 	if err := c.ShouldBindJSON(&service); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
 	// Verify provider ID matches authenticated user
 	providerID := c.GetString("user_id")
 	if service.Provider.ID != providerID {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		problem.Write(c, http.StatusUnauthorized, "unauthorized")
 		return
 	}
 
@@ -181,7 +182,7 @@ func (h *TransportHandler) UpdateService(c *gin.Context) {
 	service.Mode = mode
 
 	if err := h.marketplaceService.UpdateTransportService(&service); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -197,7 +198,7 @@ func (h *TransportHandler) GetServiceQuotation(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&request); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
@@ -207,7 +208,7 @@ func (h *TransportHandler) GetServiceQuotation(c *gin.Context) {
 		&request.CargoDetails,
 	)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"logistics-marketplace/internal/models"
+	"logistics-marketplace/internal/problem"
+	"logistics-marketplace/internal/services"
+)
+
+type TreasuryHandler struct {
+	treasuryService *services.TreasuryService
+}
+
+func NewTreasuryHandler(treasuryService *services.TreasuryService) *TreasuryHandler {
+	return &TreasuryHandler{
+		treasuryService: treasuryService,
+	}
+}
+
+// GetSpendReport handles treasury spend report requests
+func (h *TreasuryHandler) GetSpendReport(c *gin.Context) {
+	// Default to the last 30 days
+	to := time.Now()
+	from := to.AddDate(0, 0, -30)
+
+	if val := c.Query("from"); val != "" {
+		parsed, err := time.Parse(time.RFC3339, val)
+		if err != nil {
+			problem.Write(c, http.StatusBadRequest, "invalid from date")
+			return
+		}
+		from = parsed
+	}
+	if val := c.Query("to"); val != "" {
+		parsed, err := time.Parse(time.RFC3339, val)
+		if err != nil {
+			problem.Write(c, http.StatusBadRequest, "invalid to date")
+			return
+		}
+		to = parsed
+	}
+
+	report, err := h.treasuryService.GetSpendReport(c, from, to)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// DepositToTreasury handles token holders depositing into the treasury
+func (h *TreasuryHandler) DepositToTreasury(c *gin.Context) {
+	var req models.TreasuryDepositRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	deposit, err := h.treasuryService.DepositToTreasury(c, c.GetString("user_address"), req.Amount)
+	if err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, deposit)
+}
+
+// ListBudgetCategories handles listing treasury budget categories
+func (h *TreasuryHandler) ListBudgetCategories(c *gin.Context) {
+	categories, err := h.treasuryService.ListBudgetCategories(c)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, categories)
+}
+
+// GetGrant handles retrieving a treasury grant
+func (h *TreasuryHandler) GetGrant(c *gin.Context) {
+	grant, err := h.treasuryService.GetGrant(c, c.Param("id"))
+	if err != nil {
+		problem.Write(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, grant)
+}
+
+// ClaimStreamedFunds handles grantees claiming vested streaming funds
+func (h *TreasuryHandler) ClaimStreamedFunds(c *gin.Context) {
+	granteeAddress := c.GetString("user_address")
+
+	grant, err := h.treasuryService.ClaimStreamedFunds(c, c.Param("id"), granteeAddress)
+	if err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, grant)
+}
+
+// ApproveMilestone handles reviewers releasing a grant milestone
+func (h *TreasuryHandler) ApproveMilestone(c *gin.Context) {
+	index, err := strconv.Atoi(c.Param("index"))
+	if err != nil {
+		problem.Write(c, http.StatusBadRequest, "invalid milestone index")
+		return
+	}
+
+	reviewerAddress := c.GetString("user_address")
+
+	grant, err := h.treasuryService.ApproveMilestone(c, c.Param("id"), index, reviewerAddress)
+	if err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, grant)
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"logistics-marketplace/internal/auth"
 	"logistics-marketplace/internal/models"
 	"logistics-marketplace/internal/services"
 )
@@ -22,16 +23,16 @@ func NewUserOperationsHandler(operationsService *services.UserOperationsService)
 func (h *UserOperationsHandler) CreateQuoteRequest(c *gin.Context) {
 	var request models.UserQuoteRequest
 	if err := c.ShouldBindJSON(&request); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
-	// Get user type from path parameter
-	userType := models.UserType(c.Param("user_type"))
+	// Act as the role the caller was authorized for on this route
+	userType := actingUserType(c)
 	request.RequestedBy = userType
 
 	if err := h.operationsService.CreateQuoteRequest(&request); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -42,13 +43,13 @@ func (h *UserOperationsHandler) CreateQuoteRequest(c *gin.Context) {
 func (h *UserOperationsHandler) GenerateQuoteResponse(c *gin.Context) {
 	var request models.UserQuoteRequest
 	if err := c.ShouldBindJSON(&request); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
 	response, err := h.operationsService.GenerateQuoteResponse(&request)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -59,14 +60,14 @@ func (h *UserOperationsHandler) GenerateQuoteResponse(c *gin.Context) {
 func (h *UserOperationsHandler) ConfirmQuote(c *gin.Context) {
 	var quote models.UserQuoteResponse
 	if err := c.ShouldBindJSON(&quote); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
-	userType := models.UserType(c.Param("user_type"))
+	userType := actingUserType(c)
 	confirmation, err := h.operationsService.ConfirmQuote(&quote, userType)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -80,11 +81,11 @@ func (h *UserOperationsHandler) CreateBooking(c *gin.Context) {
 		Booking        models.UserBookingRequest `json:"booking"`
 	}
 	if err := c.ShouldBindJSON(&request); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
-	userType := models.UserType(c.Param("user_type"))
+	userType := actingUserType(c)
 	request.Booking.RequestedBy = userType
 
 	// Get quote confirmation
@@ -95,7 +96,7 @@ func (h *UserOperationsHandler) CreateBooking(c *gin.Context) {
 
 	booking, err := h.operationsService.CreateBooking(confirmation, &request.Booking)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -106,13 +107,13 @@ func (h *UserOperationsHandler) CreateBooking(c *gin.Context) {
 func (h *UserOperationsHandler) ConfirmBooking(c *gin.Context) {
 	var request models.UserBookingRequest
 	if err := c.ShouldBindJSON(&request); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondBadRequest(c, err)
 		return
 	}
 
 	confirmation, err := h.operationsService.ConfirmBooking(&request)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -122,7 +123,7 @@ func (h *UserOperationsHandler) ConfirmBooking(c *gin.Context) {
 // GetQuoteRequest retrieves a quote request
 func (h *UserOperationsHandler) GetQuoteRequest(c *gin.Context) {
 	requestID := c.Param("id")
-	userType := models.UserType(c.Param("user_type"))
+	userType := actingUserType(c)
 
 	// In a real implementation, this would retrieve from storage
 	request := &models.UserQuoteRequest{
@@ -137,7 +138,7 @@ func (h *UserOperationsHandler) GetQuoteRequest(c *gin.Context) {
 // GetQuoteResponse retrieves a quote response
 func (h *UserOperationsHandler) GetQuoteResponse(c *gin.Context) {
 	quoteID := c.Param("id")
-	userType := models.UserType(c.Param("user_type"))
+	userType := actingUserType(c)
 
 	// In a real implementation, this would retrieve from storage
 	response := &models.UserQuoteResponse{
@@ -152,7 +153,7 @@ func (h *UserOperationsHandler) GetQuoteResponse(c *gin.Context) {
 // GetBooking retrieves a booking
 func (h *UserOperationsHandler) GetBooking(c *gin.Context) {
 	bookingID := c.Param("id")
-	userType := models.UserType(c.Param("user_type"))
+	userType := actingUserType(c)
 
 	// In a real implementation, this would retrieve from storage
 	booking := &models.UserBookingRequest{
@@ -166,7 +167,7 @@ func (h *UserOperationsHandler) GetBooking(c *gin.Context) {
 
 // ListQuoteRequests lists quote requests for a user
 func (h *UserOperationsHandler) ListQuoteRequests(c *gin.Context) {
-	userType := models.UserType(c.Param("user_type"))
+	userType := actingUserType(c)
 	status := c.Query("status") // Optional status filter
 
 	// In a real implementation, this would retrieve from storage
@@ -183,7 +184,7 @@ func (h *UserOperationsHandler) ListQuoteRequests(c *gin.Context) {
 
 // ListQuoteResponses lists quote responses for a user
 func (h *UserOperationsHandler) ListQuoteResponses(c *gin.Context) {
-	userType := models.UserType(c.Param("user_type"))
+	userType := actingUserType(c)
 	status := c.Query("status") // Optional status filter
 
 	// In a real implementation, this would retrieve from storage
@@ -200,7 +201,7 @@ func (h *UserOperationsHandler) ListQuoteResponses(c *gin.Context) {
 
 // ListBookings lists bookings for a user
 func (h *UserOperationsHandler) ListBookings(c *gin.Context) {
-	userType := models.UserType(c.Param("user_type"))
+	userType := actingUserType(c)
 	status := c.Query("status") // Optional status filter
 
 	// In a real implementation, this would retrieve from storage
@@ -214,3 +215,8 @@ func (h *UserOperationsHandler) ListBookings(c *gin.Context) {
 
 	c.JSON(http.StatusOK, bookings)
 }
+
+// actingUserType returns the user type the authenticated caller acts as on this route
+func actingUserType(c *gin.Context) models.UserType {
+	return models.UserType(auth.ActingRole(c))
+}
@@ -1,29 +1,87 @@
 package main
 
 import (
+	"context"
+	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"os"
 	"strings"
 	"time"
 
-	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
-	"golang.org/x/time/rate"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/golang-jwt/jwt/v4"
 
 	"logistics-marketplace/cmd/api/handlers"
+	"logistics-marketplace/internal/auth"
+	"logistics-marketplace/internal/fabric"
+	"logistics-marketplace/internal/idempotency"
+	"logistics-marketplace/internal/ledger"
+	"logistics-marketplace/internal/models"
+	"logistics-marketplace/internal/openapi"
+	"logistics-marketplace/internal/problem"
+	"logistics-marketplace/internal/ratelimit"
+	"logistics-marketplace/internal/requestid"
 	"logistics-marketplace/internal/services"
 	"logistics-marketplace/internal/stellar"
+	"logistics-marketplace/internal/telemetry"
+	"logistics-marketplace/internal/validation"
+	"logistics-marketplace/pkg/config"
 )
 
-var jwtSecret []byte
-
 func main() {
-	jwtSecretEnv := os.Getenv("JWT_SECRET")
-	if jwtSecretEnv == "" {
-		log.Fatal("JWT_SECRET environment variable is required")
+	// Logs are written as JSON and requests traced, with spans exported as
+	// OTEL_TRACES_EXPORTER selects
+	telemetryConfig, err := telemetry.ConfigFromEnv("logistics-marketplace")
+	if err != nil {
+		log.Fatalf("Failed to configure telemetry: %v", err)
+	}
+	shutdownTelemetry, err := telemetry.Setup(context.Background(), telemetryConfig)
+	if err != nil {
+		log.Fatalf("Failed to initialize telemetry: %v", err)
+	}
+	defer shutdownTelemetry(context.Background())
+
+	// Access tokens are signed with rotating asymmetric keys published as a JWKS
+	keyRing, err := newKeyRing()
+	if err != nil {
+		log.Fatalf("Failed to initialize signing keys: %v", err)
+	}
+
+	// Accounts allowed to log in
+	usersPath := os.Getenv("AUTH_USERS_FILE")
+	if usersPath == "" {
+		log.Fatal("AUTH_USERS_FILE environment variable is required")
+	}
+	users, err := auth.LoadUsers(usersPath)
+	if err != nil {
+		log.Fatalf("Failed to load users: %v", err)
+	}
+
+	// Sessions and revocations, kept in memory unless a file is given; they
+	// are not shared between instances, so run a single API instance
+	sessionManager, err := auth.NewSessionManager(users, keyRing, auth.DefaultAccessTokenTTL, auth.DefaultRefreshTokenTTL, os.Getenv("AUTH_SESSIONS_FILE"))
+	if err != nil {
+		log.Fatalf("Failed to load sessions: %v", err)
+	}
+
+	// API keys of server integrations, kept in memory unless a file is given
+	apiKeys, err := auth.NewAPIKeyStore(os.Getenv("AUTH_API_KEYS_FILE"))
+	if err != nil {
+		log.Fatalf("Failed to load API keys: %v", err)
+	}
+
+	// Load deployment configuration, falling back to the environment
+	cfg := config.FromEnv()
+	if configPath := os.Getenv("CONFIG_PATH"); configPath != "" {
+		loaded, err := config.LoadConfig(configPath)
+		if err != nil {
+			log.Fatalf("Failed to load config %s: %v", configPath, err)
+		}
+		cfg = loaded
 	}
-	jwtSecret = []byte(jwtSecretEnv)
 
 	// Initialize Stellar components
 	accountManager := stellar.NewAccountManager(true) // Use testnet for development
@@ -38,42 +96,162 @@ func main() {
 		os.Getenv("CONTRACT_ID"), // Get from environment
 	)
 
+	// Connect to the Fabric logistics chaincode when configured
+	var fabricClient *fabric.LogisticsClient
+	if cfg.Fabric.ConnectionProfile != "" {
+		fabricConn, err := fabric.Connect(fabric.Config{
+			ConnectionProfile: cfg.Fabric.ConnectionProfile,
+			CertPath:          cfg.Fabric.CertPath,
+			KeyPath:           cfg.Fabric.KeyPath,
+			Channel:           cfg.Fabric.Channel,
+			Chaincode:         cfg.Fabric.Chaincode,
+		})
+		if err != nil {
+			log.Fatalf("Failed to connect to Fabric: %v", err)
+		}
+		defer fabricConn.Close()
+
+		fabricClient = fabric.NewLogisticsClient(fabricConn.Contract)
+
+		go func() {
+			err := fabric.Listen(context.Background(), fabricConn.Events, func(event fabric.Event) {
+				log.Printf("Fabric event %s in transaction %s (block %d)", event.Name, event.TransactionID, event.BlockNumber)
+			})
+			if err != nil {
+				log.Printf("Fabric event listener stopped: %v", err)
+			}
+		}()
+	}
+
+	// Record listings, bookings, payments and tracking on the configured ledger
+	ledgerBackend, err := newLedgerBackend(cfg, txManager, fabricClient)
+	if err != nil {
+		log.Fatalf("Failed to initialize ledger backend: %v", err)
+	}
+	if closer, ok := ledgerBackend.(io.Closer); ok {
+		defer closer.Close()
+	}
+	log.Printf("Recording marketplace facts on the %s ledger", ledgerBackend.Name())
+
+	// Off-chain governance ballots are kept on disk before they are acknowledged
+	ballotsPath := os.Getenv("GOVERNANCE_BALLOTS_FILE")
+	if ballotsPath == "" {
+		ballotsPath = "data/governance_ballots.jsonl"
+	}
+	ballotLog, err := services.OpenBallotLog(ballotsPath)
+	if err != nil {
+		log.Fatalf("Failed to open governance ballot log: %v", err)
+	}
+	defer ballotLog.Close()
+
 	// Initialize services
 	governanceService := services.NewGovernanceService(
 		accountManager,
 		tokenManager,
 		os.Getenv("GOVERNANCE_CONTRACT_ID"),
+		ballotLog,
 	)
-	marketplaceService := services.NewMarketplaceService(txManager, tokenManager)
+	marketplaceService := services.NewMarketplaceService(ledgerBackend, tokenManager)
 	customsService := services.NewCustomsService(txManager, tokenManager)
-	trackingService := services.NewTrackingService(txManager, tokenManager)
+	trackingService := services.NewTrackingService(ledgerBackend, tokenManager)
 	profileService := services.NewProfileService(txManager, tokenManager)
 	customsRateService := services.NewCustomsRateService(txManager, tokenManager)
 	infrastructureService := services.NewInfrastructureService(txManager, tokenManager)
 	userOperationsService := services.NewUserOperationsService(txManager, tokenManager)
 	serviceCategoriesService := services.NewServiceCategoriesService(txManager, tokenManager)
+	treasuryService := services.NewTreasuryService(
+		stellar.NewContract(accountManager, os.Getenv("GOVERNANCE_CONTRACT_ID")),
+	)
+	rewardsPath := os.Getenv("REWARDS_LEDGER_FILE")
+	if rewardsPath == "" {
+		rewardsPath = "data/rewards.json"
+	}
+	rewardsService, err := services.NewRewardsService(
+		tokenManager,
+		stellar.NewContract(accountManager, os.Getenv("CONTRACT_ID")), // Bookings and tracking recorded on the marketplace contract
+		os.Getenv("REWARDS_POOL_ACCOUNT"),                             // Funded by governance treasury grants
+		services.DefaultRewardRules,
+		rewardsPath,
+	)
+	if err != nil {
+		log.Fatalf("Failed to load rewards ledger: %v", err)
+	}
+	stakingService := services.NewStakingService(
+		stellar.NewContract(accountManager, os.Getenv("CONTRACT_ID")),
+	)
+	bookingAccessService := services.NewBookingAccessService(
+		stellar.NewContract(accountManager, os.Getenv("CONTRACT_ID")),
+	)
+	// Private deployments record bookings on their ledger backend, not the contract
+	if records, ok := ledgerBackend.(ledger.RecordReader); ok {
+		bookingAccessService.UseLedger(records)
+	}
+
+	// Drive the chaincode shipment lifecycle when Fabric tracking is enabled
+	if cfg.Fabric.Tracking {
+		if fabricClient == nil {
+			log.Fatalf("Fabric tracking requires FABRIC_CONNECTION_PROFILE")
+		}
+		trackingService.UseFabric(fabricClient)
+	}
+
+	// Finalize proposals once their voting period ends
+	go governanceService.RunProposalFinalizer(context.Background(), time.Minute)
+
+	// Pay out earned loyalty rewards daily
+	go rewardsService.RunRewardDistribution(context.Background(), 24*time.Hour)
+
+	// Replace the access token signing key on schedule
+	keyRotation := auth.DefaultKeyRotation
+	if value := os.Getenv("JWT_KEY_ROTATION"); value != "" {
+		keyRotation, err = time.ParseDuration(value)
+		if err != nil {
+			log.Fatalf("Invalid JWT_KEY_ROTATION: %v", err)
+		}
+	}
+	go keyRing.RunRotation(context.Background(), keyRotation, time.Minute)
 
 	// Initialize handlers
 	governanceHandler := handlers.NewGovernanceHandler(governanceService)
 	marketplaceHandler := handlers.NewMarketplaceHandler(marketplaceService, customsService)
-	trackingHandler := handlers.NewTrackingHandler(trackingService)
+	trackingHandler := handlers.NewTrackingHandler(trackingService, bookingAccessService)
+	authHandler := handlers.NewAuthHandler(sessionManager)
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeys)
 	profileHandler := handlers.NewProfileHandler(profileService)
 	customsRateHandler := handlers.NewCustomsRateHandler(customsRateService)
 	transportHandler := handlers.NewTransportHandler(marketplaceService)
 	infrastructureHandler := handlers.NewInfrastructureHandler(infrastructureService)
 	userOperationsHandler := handlers.NewUserOperationsHandler(userOperationsService)
 	serviceCategoriesHandler := handlers.NewServiceCategoriesHandler(serviceCategoriesService)
+	treasuryHandler := handlers.NewTreasuryHandler(treasuryService)
+	stakingHandler := handlers.NewStakingHandler(stakingService)
+	rewardsHandler := handlers.NewRewardsHandler(rewardsService)
 
-	// Initialize Gin router
+	// Initialize Gin router, validating bound requests with their validate tags
+	binding.Validator = validation.Binding()
 	router := gin.New()
+	router.ContextWithFallback = true // Services given the gin context see the request's span
 
 	// Middleware
-	router.Use(gin.Recovery())
-	router.Use(loggingMiddleware())
+	router.Use(requestid.Middleware())
+	router.Use(telemetry.Middleware()) // Traces the request and writes its access log
+	router.Use(gin.CustomRecovery(func(c *gin.Context, recovered interface{}) {
+		problem.Error(c, fmt.Errorf("panic: %v", recovered))
+		c.Abort()
+	}))
 	router.Use(securityHeadersMiddleware())
 	router.Use(corsMiddleware())
-	router.Use(authMiddleware())
-	router.Use(rateLimitMiddleware(100, time.Minute)) // 100 requests per minute
+	router.Use(authMiddleware(keyRing, sessionManager, apiKeys))
+	router.Use(accessPolicy().Middleware())
+	router.Use(rateLimits().Middleware())
+
+	// Requests and responses are checked against the OpenAPI document
+	// outside production when OPENAPI_VALIDATE is set
+	spec := apiSpec(router)
+	if os.Getenv("OPENAPI_VALIDATE") == "true" && gin.Mode() != gin.ReleaseMode {
+		router.Use(spec.Middleware())
+	}
+	router.Use(idempotency.NewStore(idempotency.DefaultTTL).Middleware()) // Replays retried POST and PUT requests
 
 	// API Routes
 	api := router.Group("/api/v1")
@@ -86,11 +264,25 @@ func main() {
 			governance.GET("/proposals", governanceHandler.ListProposals)
 			governance.GET("/proposals/:id", governanceHandler.GetProposal)
 			governance.POST("/proposals/:id/vote", governanceHandler.CastVote)
+			governance.POST("/proposals/:id/signed-votes", governanceHandler.CastSignedVote)
+			governance.GET("/proposals/:id/ballots", governanceHandler.GetBallotSet)
+			governance.POST("/proposals/:id/finalize", governanceHandler.FinalizeProposal)
 			governance.POST("/proposals/:id/execute", governanceHandler.ExecuteProposal)
 			governance.GET("/proposals/:id/votes/:voter", governanceHandler.GetVote)
 
 			// Parameters
 			governance.GET("/parameters/:name", governanceHandler.GetParameter)
+
+			// Treasury
+			treasury := governance.Group("/treasury")
+			{
+				treasury.GET("/report", treasuryHandler.GetSpendReport)
+				treasury.POST("/deposits", treasuryHandler.DepositToTreasury)
+				treasury.GET("/categories", treasuryHandler.ListBudgetCategories)
+				treasury.GET("/grants/:id", treasuryHandler.GetGrant)
+				treasury.POST("/grants/:id/claim", treasuryHandler.ClaimStreamedFunds)
+				treasury.POST("/grants/:id/milestones/:index/approve", treasuryHandler.ApproveMilestone)
+			}
 		}
 
 		// Service Categories
@@ -307,94 +499,43 @@ func main() {
 			consignee := users.Group("/consignee")
 			{
 				// Quote Management
-				consignee.POST("/quotes/request", func(c *gin.Context) {
-					c.Param("user_type", "CONSIGNEE")
-					userOperationsHandler.CreateQuoteRequest(c)
-				})
-				consignee.GET("/quotes/request/:id", func(c *gin.Context) {
-					c.Param("user_type", "CONSIGNEE")
-					userOperationsHandler.GetQuoteRequest(c)
-				})
-				consignee.GET("/quotes/requests", func(c *gin.Context) {
-					c.Param("user_type", "CONSIGNEE")
-					userOperationsHandler.ListQuoteRequests(c)
-				})
-				consignee.POST("/quotes/confirm", func(c *gin.Context) {
-					c.Param("user_type", "CONSIGNEE")
-					userOperationsHandler.ConfirmQuote(c)
-				})
+				consignee.POST("/quotes/request", userOperationsHandler.CreateQuoteRequest)
+				consignee.GET("/quotes/request/:id", userOperationsHandler.GetQuoteRequest)
+				consignee.GET("/quotes/requests", userOperationsHandler.ListQuoteRequests)
+				consignee.POST("/quotes/confirm", userOperationsHandler.ConfirmQuote)
 
 				// Booking Management
-				consignee.POST("/bookings", func(c *gin.Context) {
-					c.Param("user_type", "CONSIGNEE")
-					userOperationsHandler.CreateBooking(c)
-				})
-				consignee.GET("/bookings/:id", func(c *gin.Context) {
-					c.Param("user_type", "CONSIGNEE")
-					userOperationsHandler.GetBooking(c)
-				})
-				consignee.GET("/bookings", func(c *gin.Context) {
-					c.Param("user_type", "CONSIGNEE")
-					userOperationsHandler.ListBookings(c)
-				})
+				consignee.POST("/bookings", userOperationsHandler.CreateBooking)
+				consignee.GET("/bookings/:id", userOperationsHandler.GetBooking)
+				consignee.GET("/bookings", userOperationsHandler.ListBookings)
 			}
 
 			// Shipper Operations
 			shipper := users.Group("/shipper")
 			{
 				// Quote Management
-				shipper.POST("/quotes/request", func(c *gin.Context) {
-					c.Param("user_type", "SHIPPER")
-					userOperationsHandler.CreateQuoteRequest(c)
-				})
-				shipper.GET("/quotes/request/:id", func(c *gin.Context) {
-					c.Param("user_type", "SHIPPER")
-					userOperationsHandler.GetQuoteRequest(c)
-				})
-				shipper.GET("/quotes/requests", func(c *gin.Context) {
-					c.Param("user_type", "SHIPPER")
-					userOperationsHandler.ListQuoteRequests(c)
-				})
-				shipper.POST("/quotes/confirm", func(c *gin.Context) {
-					c.Param("user_type", "SHIPPER")
-					userOperationsHandler.ConfirmQuote(c)
-				})
+				shipper.POST("/quotes/request", userOperationsHandler.CreateQuoteRequest)
+				shipper.GET("/quotes/request/:id", userOperationsHandler.GetQuoteRequest)
+				shipper.GET("/quotes/requests", userOperationsHandler.ListQuoteRequests)
+				shipper.POST("/quotes/confirm", userOperationsHandler.ConfirmQuote)
 
 				// Booking Management
-				shipper.POST("/bookings", func(c *gin.Context) {
-					c.Param("user_type", "SHIPPER")
-					userOperationsHandler.CreateBooking(c)
-				})
-				shipper.GET("/bookings/:id", func(c *gin.Context) {
-					c.Param("user_type", "SHIPPER")
-					userOperationsHandler.GetBooking(c)
-				})
-				shipper.GET("/bookings", func(c *gin.Context) {
-					c.Param("user_type", "SHIPPER")
-					userOperationsHandler.ListBookings(c)
-				})
+				shipper.POST("/bookings", userOperationsHandler.CreateBooking)
+				shipper.GET("/bookings/:id", userOperationsHandler.GetBooking)
+				shipper.GET("/bookings", userOperationsHandler.ListBookings)
 			}
 
 			// Freight Forwarder Operations
 			forwarder := users.Group("/forwarder")
 			{
 				// Quote Management
-				forwarder.GET("/quotes/requests", func(c *gin.Context) {
-					c.Param("user_type", "FREIGHT_FORWARDER")
-					userOperationsHandler.ListQuoteRequests(c)
-				})
+				forwarder.GET("/quotes/requests", userOperationsHandler.ListQuoteRequests)
 				forwarder.POST("/quotes/response", userOperationsHandler.GenerateQuoteResponse)
-				forwarder.GET("/quotes/responses", func(c *gin.Context) {
-					c.Param("user_type", "FREIGHT_FORWARDER")
-					userOperationsHandler.ListQuoteResponses(c)
-				})
+				forwarder.GET("/quotes/responses", userOperationsHandler.ListQuoteResponses)
 
 				// Booking Management
 				forwarder.POST("/bookings/confirm", userOperationsHandler.ConfirmBooking)
-				forwarder.GET("/bookings", func(c *gin.Context) {
-					c.Param("user_type", "FREIGHT_FORWARDER")
-					userOperationsHandler.ListBookings(c)
-				})
+				forwarder.GET("/bookings", userOperationsHandler.ListBookings)
 			}
 		}
 
@@ -525,8 +666,56 @@ func main() {
 			tracking.PUT("/routing/:booking_id", trackingHandler.UpdateRouting)
 			tracking.POST("/route/optimal", trackingHandler.GetOptimalRoute)
 		}
+
+		// Provider Staking and SLA Disputes
+		staking := api.Group("/staking")
+		{
+			staking.GET("/providers/:provider", stakingHandler.GetStake)
+			staking.POST("/stake", stakingHandler.Stake)
+			staking.POST("/unstake", stakingHandler.Unstake)
+			staking.POST("/withdraw", stakingHandler.WithdrawUnbonded)
+			staking.POST("/bookings/:id/schedule", stakingHandler.ConfirmSchedule)
+			staking.POST("/bookings/:id/dispute", stakingHandler.RaiseDispute)
+			staking.GET("/bookings/:id/dispute", stakingHandler.GetDispute)
+			staking.POST("/bookings/:id/dispute/resolve", stakingHandler.ResolveDispute)
+		}
+
+		// Loyalty Rewards
+		rewards := api.Group("/rewards")
+		{
+			rewards.POST("/evaluations", rewardsHandler.EvaluateBooking)
+			rewards.GET("/ledger/:account", rewardsHandler.GetLedger)
+		}
+
+		// API Keys for server integrations
+		apiKeyRoutes := api.Group("/api-keys")
+		{
+			apiKeyRoutes.POST("", apiKeyHandler.CreateAPIKey)
+			apiKeyRoutes.GET("", apiKeyHandler.ListAPIKeys)
+			apiKeyRoutes.DELETE("/:id", apiKeyHandler.RevokeAPIKey)
+		}
+	}
+
+	// OpenAPI document of every route above
+	router.GET("/api/v1/openapi.json", spec.Handler())
+
+	// Public shipment tracking
+	router.GET("/api/v1/public/tracking/:booking_id", trackingHandler.GetPublicTracking)
+
+	// Sessions
+	authRoutes := router.Group("/auth")
+	{
+		authRoutes.POST("/login", authHandler.Login)
+		authRoutes.POST("/refresh", authHandler.Refresh)
+		authRoutes.POST("/logout", authHandler.Logout)
 	}
 
+	// Public keys verifying our access tokens
+	router.GET("/.well-known/jwks.json", func(c *gin.Context) {
+		c.Header("Cache-Control", "public, max-age=300")
+		c.JSON(http.StatusOK, keyRing.JWKS())
+	})
+
 	// Health check endpoint
 	router.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{
@@ -547,62 +736,348 @@ func main() {
 	}
 }
 
-func loggingMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		start := time.Now()
-		c.Next()
-		latency := time.Since(start)
-		status := c.Writer.Status()
-		log.Printf("%s %s %d %s", c.Request.Method, c.Request.URL.Path, status, latency)
+// newKeyRing creates the access token signing keys from the environment.
+// HS256 tokens signed with JWT_SECRET are still accepted until
+// JWT_HS256_ACCEPT_UNTIL, by default until the last of them has expired.
+func newKeyRing() (*auth.KeyRing, error) {
+	algorithm := os.Getenv("JWT_SIGNING_ALG")
+	if algorithm == "" {
+		algorithm = auth.AlgorithmEdDSA
+	}
+
+	keyRing, err := auth.NewKeyRing(algorithm, auth.DefaultAccessTokenTTL, os.Getenv("JWT_KEYS_DIR"))
+	if err != nil {
+		return nil, err
+	}
+
+	if secret := os.Getenv("JWT_SECRET"); secret != "" {
+		until := time.Now().Add(auth.DefaultAccessTokenTTL)
+		if value := os.Getenv("JWT_HS256_ACCEPT_UNTIL"); value != "" {
+			until, err = time.Parse(time.RFC3339, value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid JWT_HS256_ACCEPT_UNTIL: %w", err)
+			}
+		}
+		keyRing.AcceptHS256([]byte(secret), until)
+		log.Printf("Accepting HS256 access tokens until %s", until.Format(time.RFC3339))
+	}
+
+	return keyRing, nil
+}
+
+// newLedgerBackend creates the ledger backend selected in the configuration
+func newLedgerBackend(cfg *config.Config, txManager *stellar.TransactionManager, fabricClient *fabric.LogisticsClient) (ledger.LedgerBackend, error) {
+	switch cfg.Ledger.Backend {
+	case "", ledger.BackendStellar:
+		return ledger.NewStellarBackend(txManager), nil
+	case ledger.BackendFabric:
+		if fabricClient == nil {
+			return nil, fmt.Errorf("fabric ledger requires a Fabric connection profile")
+		}
+		return ledger.NewFabricBackend(fabricClient), nil
+	case ledger.BackendHashChain:
+		if cfg.Ledger.HashChainPath == "" {
+			return nil, fmt.Errorf("hashchain ledger requires a log path")
+		}
+		return ledger.OpenHashChain(cfg.Ledger.HashChainPath)
+	default:
+		return nil, fmt.Errorf("unknown ledger backend: %s", cfg.Ledger.Backend)
+	}
+}
+
+// accessPolicy maps routes to the roles allowed to call them. Every
+// state-changing route needs a rule here, or only administrators may call it.
+func accessPolicy() *auth.Policy {
+	providers := []auth.Role{
+		auth.RoleFreightForwarder,
+		auth.RoleCustomsBroker,
+		auth.RoleInfrastructureOperator,
+	}
+	customers := []auth.Role{
+		auth.RoleShipper,
+		auth.RoleConsignee,
+	}
+
+	return auth.NewPolicy().
+		// Quotes and bookings act as the role of the route group
+		Allow("/api/v1/users/consignee", auth.RoleConsignee).
+		Allow("/api/v1/users/shipper", auth.RoleShipper).
+		Allow("/api/v1/users/forwarder", auth.RoleFreightForwarder).
+
+		// Listings are published by providers
+		AllowWrite("/api/v1/services", auth.RoleFreightForwarder, auth.RoleCustomsBroker).
+		AllowWrite("/api/v1/transport", auth.RoleFreightForwarder).
+		AllowWrite("/api/v1/infrastructure", auth.RoleInfrastructureOperator).
+
+		// Shipment updates come from providers; the booking check narrows it to the booking's provider
+		AllowWrite("/api/v1/tracking/events", providers...).
+		AllowWrite("/api/v1/tracking/transshipment", providers...).
+		AllowWrite("/api/v1/tracking/routing", providers...).
+		OpenWrite("/api/v1/tracking/route/optimal"). // A query, posted for its request body
+
+		// Only providers bond stake; disputes are settled by administrators
+		AllowWrite("/api/v1/staking/stake", providers...).
+		AllowWrite("/api/v1/staking/unstake", providers...).
+		AllowWrite("/api/v1/staking/withdraw", providers...).
+		AllowWrite("/api/v1/staking/bookings/:id/schedule", providers...).
+		AllowWrite("/api/v1/staking/bookings/:id/dispute", customers...).
+		AllowWrite("/api/v1/staking/bookings/:id/dispute/resolve").
+
+		// Token holders propose and vote, their voting power is checked on chain;
+		// tallying and executing proposals is left to administrators
+		OpenWrite("/api/v1/governance/proposals").
+		AllowWrite("/api/v1/governance/proposals/:id/finalize").
+		AllowWrite("/api/v1/governance/proposals/:id/execute").
+
+		// Anyone may fund the treasury; grants check the grantee and reviewer themselves
+		OpenWrite("/api/v1/governance/treasury/deposits").
+		OpenWrite("/api/v1/governance/treasury/grants/:id/claim").
+		OpenWrite("/api/v1/governance/treasury/grants/:id/milestones/:index/approve").
+
+		// Members manage their own company's API keys
+		OpenWrite("/api/v1/api-keys").
+		OpenWrite("/auth/logout").
+
+		// Rewards are evaluated by operations staff
+		AllowWrite("/api/v1/rewards/evaluations").
+
+		// Areas of the API that API keys can be scoped to; other routes reject API keys
+		Scope("/api/v1/users/consignee/quotes", "quotes").
+		Scope("/api/v1/users/shipper/quotes", "quotes").
+		Scope("/api/v1/users/forwarder/quotes", "quotes").
+		Scope("/api/v1/users/consignee/bookings", "bookings").
+		Scope("/api/v1/users/shipper/bookings", "bookings").
+		Scope("/api/v1/users/forwarder/bookings", "bookings").
+		Scope("/api/v1/tracking", "tracking").
+		Scope("/api/v1/services", "listings").
+		Scope("/api/v1/transport", "listings").
+		Scope("/api/v1/infrastructure", "infrastructure")
+}
+
+// rateLimits sets the request budgets of each caller per route group
+func rateLimits() *ratelimit.Limiter {
+	return ratelimit.NewLimiter(ratelimit.PerMinute(100)).
+		// Logins and refreshes are counted per IP address
+		Group("auth", ratelimit.PerMinute(10), "/auth/login", "/auth/refresh").
+
+		// Quotes fan out to every provider and are the most expensive to serve
+		Group("quotes", ratelimit.PerMinute(30),
+			"/api/v1/users/consignee/quotes",
+			"/api/v1/users/shipper/quotes",
+			"/api/v1/users/forwarder/quotes",
+		).
+
+		// Tracking writes are recorded on the ledger
+		GroupWrite("tracking-writes", ratelimit.PerMinute(60), "/api/v1/tracking").
+
+		// Higher membership tiers get larger budgets
+		Tier(models.TierPremium, 2).
+		Tier(models.TierBusiness, 5).
+		Tier(models.TierEnterprise, 10)
+}
+
+// apiSpec describes the routes of the router in an OpenAPI document. Routes
+// without a description are listed with their path parameters only.
+func apiSpec(router *gin.Engine) *openapi.Spec {
+	const (
+		get  = http.MethodGet
+		post = http.MethodPost
+		put  = http.MethodPut
+		del  = http.MethodDelete
+	)
+
+	spec := openapi.NewSpec(router, "Logistics Marketplace API", "1.0.0").
+		Enum(models.SeaTransport, models.AirTransport, models.RailTransport, models.RoadTransport, models.LandTransport).
+		Enum(models.FCL, models.LCL, models.BulkAir, models.ULD, models.Palletized, models.ContainerRail,
+			models.BulkRail, models.CarLoad, models.WagonLoad, models.FTL, models.LTL, models.Parcel, models.Consolidated).
+		Enum(models.ImportService, models.ExportService, models.TransitService, models.TransshipService,
+			models.ImportDirect, models.ImportTransit, models.ImportTransshipment, models.ExportDirect, models.ExportTransit).
+		Enum(models.OriginCustoms, models.TransshipmentCustoms, models.TransitCustoms, models.DestinationCustoms).
+		Enum(models.Consignee, models.Shipper, models.FreightForwarder).
+		Enum(models.ParameterChange, models.ContractUpgrade, models.FundsAllocation, models.ServiceUpdate).
+		Enum(models.ProposalStatusActive, models.ProposalStatusPassed, models.ProposalStatusExecuted,
+			models.ProposalStatusRejected, models.ProposalStatusFailedQuorum).
+		Enum(models.VoteTypeFor, models.VoteTypeAgainst, models.VoteTypeAbstain).
+		Enum(models.VotingModeOnChain, models.VotingModeOffChain).
+		Enum(models.DisputeStatusOpen, models.DisputeStatusUpheld, models.DisputeStatusRejected)
+
+	for path := range publicRoutes {
+		spec.Public(path)
+	}
+
+	// Governance
+	spec.
+		Describe(post, "/api/v1/governance/proposals", openapi.Route{Summary: "Create a proposal", Request: models.ProposalCreateRequest{}, Response: models.ProposalResponse{}}).
+		Describe(get, "/api/v1/governance/proposals", openapi.Route{Summary: "List proposals", Response: []models.ProposalResponse{}}).
+		Describe(get, "/api/v1/governance/proposals/:id", openapi.Route{Summary: "Get a proposal", Response: models.ProposalResponse{}}).
+		Describe(post, "/api/v1/governance/proposals/:id/vote", openapi.Route{Summary: "Vote on chain", Request: models.VoteCastRequest{}, Response: models.VoteResponse{}}).
+		Describe(post, "/api/v1/governance/proposals/:id/signed-votes", openapi.Route{Summary: "Cast a signed off-chain vote", Request: models.SignedVoteRequest{}, Response: models.SignedBallot{}}).
+		Describe(get, "/api/v1/governance/proposals/:id/ballots", openapi.Route{Summary: "Get the off-chain ballots", Response: models.BallotSetResponse{}}).
+		Describe(post, "/api/v1/governance/proposals/:id/finalize", openapi.Route{Summary: "Tally an ended proposal", Response: models.ProposalResponse{}}).
+		Describe(post, "/api/v1/governance/proposals/:id/execute", openapi.Route{Summary: "Execute a passed proposal", Response: models.ProposalResponse{}}).
+		Describe(get, "/api/v1/governance/proposals/:id/votes/:voter", openapi.Route{Summary: "Get a vote", Response: models.VoteResponse{}}).
+		Describe(get, "/api/v1/governance/parameters/:name", openapi.Route{Summary: "Get a governance parameter", Response: models.ParameterResponse{}}).
+		Describe(get, "/api/v1/governance/treasury/report", openapi.Route{Summary: "Report treasury spending", Response: models.TreasurySpendReport{}}).
+		Describe(post, "/api/v1/governance/treasury/deposits", openapi.Route{Summary: "Deposit tokens into the treasury", Request: models.TreasuryDepositRequest{}, Response: models.TreasuryDeposit{}}).
+		Describe(get, "/api/v1/governance/treasury/categories", openapi.Route{Summary: "List budget categories", Response: []models.TreasuryBudgetCategory{}}).
+		Describe(get, "/api/v1/governance/treasury/grants/:id", openapi.Route{Summary: "Get a grant", Response: models.TreasuryGrant{}}).
+		Describe(post, "/api/v1/governance/treasury/grants/:id/claim", openapi.Route{Summary: "Claim streamed grant funds", Response: models.TreasuryGrant{}}).
+		Describe(post, "/api/v1/governance/treasury/grants/:id/milestones/:index/approve", openapi.Route{Summary: "Approve a grant milestone", Response: models.TreasuryGrant{}})
+
+	// Import and export services
+	for _, direction := range []string{"imports", "exports"} {
+		base := "/api/v1/services/" + direction
+		spec.
+			Describe(post, base+"/sea/", openapi.Route{Summary: "Create a sea service", Request: models.SeaService{}, Response: models.SeaService{}, Status: http.StatusCreated}).
+			Describe(post, base+"/air/", openapi.Route{Summary: "Create an air service", Request: models.AirService{}, Response: models.AirService{}, Status: http.StatusCreated}).
+			Describe(post, base+"/rail/", openapi.Route{Summary: "Create a rail service", Request: models.RailService{}, Response: models.RailService{}, Status: http.StatusCreated}).
+			Describe(post, base+"/land/", openapi.Route{Summary: "Create a land service", Request: models.LandService{}, Response: models.LandService{}, Status: http.StatusCreated})
+	}
+	spec.
+		Describe(put, "/api/v1/services/:id/schedule", openapi.Route{Summary: "Update a service schedule", Request: models.ServiceSchedule{}, Response: models.ServiceSchedule{}}).
+		Describe(put, "/api/v1/services/:id/rates", openapi.Route{Summary: "Update service rates", Request: models.ServiceRate{}, Response: models.ServiceRate{}})
+
+	// Quotes and bookings of consignees, shippers and forwarders
+	for _, party := range []string{"consignee", "shipper"} {
+		base := "/api/v1/users/" + party
+		spec.
+			Describe(post, base+"/quotes/request", openapi.Route{Summary: "Request a quote", Request: models.UserQuoteRequest{}, Response: models.UserQuoteRequest{}, Status: http.StatusCreated}).
+			Describe(get, base+"/quotes/request/:id", openapi.Route{Summary: "Get a quote request", Response: models.UserQuoteRequest{}}).
+			Describe(get, base+"/quotes/requests", openapi.Route{Summary: "List quote requests", Response: []models.UserQuoteRequest{}}).
+			Describe(post, base+"/quotes/confirm", openapi.Route{Summary: "Confirm a quote", Request: models.UserQuoteResponse{}, Response: models.UserQuoteConfirmation{}}).
+			Describe(post, base+"/bookings", openapi.Route{Summary: "Book a confirmed quote", Response: models.UserBookingRequest{}, Status: http.StatusCreated}).
+			Describe(get, base+"/bookings/:id", openapi.Route{Summary: "Get a booking", Response: models.UserBookingRequest{}}).
+			Describe(get, base+"/bookings", openapi.Route{Summary: "List bookings", Response: []models.UserBookingRequest{}})
 	}
+	spec.
+		Describe(get, "/api/v1/users/forwarder/quotes/requests", openapi.Route{Summary: "List quote requests", Response: []models.UserQuoteRequest{}}).
+		Describe(post, "/api/v1/users/forwarder/quotes/response", openapi.Route{Summary: "Answer a quote request", Request: models.UserQuoteRequest{}, Response: models.UserQuoteResponse{}}).
+		Describe(get, "/api/v1/users/forwarder/quotes/responses", openapi.Route{Summary: "List quote responses", Response: []models.UserQuoteResponse{}}).
+		Describe(post, "/api/v1/users/forwarder/bookings/confirm", openapi.Route{Summary: "Confirm a booking", Request: models.UserBookingRequest{}, Response: models.UserBookingConfirmation{}}).
+		Describe(get, "/api/v1/users/forwarder/bookings", openapi.Route{Summary: "List bookings", Response: []models.UserBookingRequest{}})
+
+	// Infrastructure
+	spec.
+		Describe(get, "/api/v1/infrastructure/country/:country_code", openapi.Route{Summary: "Get the infrastructure of a country", Response: models.CountryInfrastructure{}}).
+		Describe(post, "/api/v1/infrastructure/airports/", openapi.Route{Summary: "Register an airport", Request: models.Airport{}, Response: models.Airport{}, Status: http.StatusCreated}).
+		Describe(post, "/api/v1/infrastructure/seaports/", openapi.Route{Summary: "Register a seaport", Request: models.Seaport{}, Response: models.Seaport{}, Status: http.StatusCreated}).
+		Describe(post, "/api/v1/infrastructure/depots/", openapi.Route{Summary: "Register an inland depot", Request: models.InlandDepot{}, Response: models.InlandDepot{}, Status: http.StatusCreated})
+	for _, kind := range []string{"airports", "seaports", "depots"} {
+		base := "/api/v1/infrastructure/" + kind + "/:id"
+		spec.
+			Describe(put, base+"/capacity", openapi.Route{Summary: "Update capacity", Request: models.InfrastructureCapacity{}, Response: models.InfrastructureCapacity{}}).
+			Describe(put, base+"/schedule", openapi.Route{Summary: "Update the operating schedule", Request: models.InfrastructureSchedule{}, Response: models.InfrastructureSchedule{}}).
+			Describe(get, base+"/capacity", openapi.Route{Summary: "Get capacity", Response: models.InfrastructureCapacity{}}).
+			Describe(get, base+"/schedule", openapi.Route{Summary: "Get the operating schedule", Response: models.InfrastructureSchedule{}})
+	}
+
+	// Transport services
+	spec.
+		Describe(post, "/api/v1/transport/sea/services", openapi.Route{Summary: "Create a sea transport service", Request: models.SeaService{}, Response: models.SeaService{}, Status: http.StatusCreated}).
+		Describe(post, "/api/v1/transport/air/services", openapi.Route{Summary: "Create an air transport service", Request: models.AirService{}, Response: models.AirService{}, Status: http.StatusCreated}).
+		Describe(post, "/api/v1/transport/rail/services", openapi.Route{Summary: "Create a rail transport service", Request: models.RailService{}, Response: models.RailService{}, Status: http.StatusCreated}).
+		Describe(post, "/api/v1/transport/road/services", openapi.Route{Summary: "Create a road transport service", Request: models.RoadService{}, Response: models.RoadService{}, Status: http.StatusCreated})
+
+	// Staking, disputes and rewards
+	spec.
+		Describe(get, "/api/v1/staking/providers/:provider", openapi.Route{Summary: "Get a provider's stake", Response: models.ProviderStake{}}).
+		Describe(post, "/api/v1/staking/stake", openapi.Route{Summary: "Bond stake", Request: models.StakeRequest{}, Response: models.ProviderStake{}}).
+		Describe(post, "/api/v1/staking/unstake", openapi.Route{Summary: "Unbond stake", Request: models.StakeRequest{}, Response: models.ProviderStake{}}).
+		Describe(post, "/api/v1/staking/withdraw", openapi.Route{Summary: "Withdraw unbonded stake", Response: models.ProviderStake{}}).
+		Describe(post, "/api/v1/staking/bookings/:id/schedule", openapi.Route{Summary: "Commit to a booking schedule", Request: models.ScheduleConfirmationRequest{}}).
+		Describe(post, "/api/v1/staking/bookings/:id/dispute", openapi.Route{Summary: "Raise a dispute", Request: models.DisputeRequest{}, Response: models.MarketplaceDispute{}, Status: http.StatusCreated}).
+		Describe(get, "/api/v1/staking/bookings/:id/dispute", openapi.Route{Summary: "Get a dispute", Response: models.MarketplaceDispute{}}).
+		Describe(post, "/api/v1/staking/bookings/:id/dispute/resolve", openapi.Route{Summary: "Resolve a dispute", Request: models.DisputeResolutionRequest{}, Response: models.MarketplaceDispute{}}).
+		Describe(post, "/api/v1/rewards/evaluations", openapi.Route{Summary: "Evaluate a delivered booking", Request: models.RewardEvaluationRequest{}, Response: []models.RewardEntry{}}).
+		Describe(get, "/api/v1/rewards/ledger/:account", openapi.Route{Summary: "Get an account's rewards", Response: models.RewardsLedger{}})
+
+	// API keys and sessions
+	spec.
+		Describe(post, "/api/v1/api-keys", openapi.Route{Summary: "Issue an API key", Request: handlers.CreateAPIKeyRequest{}, Response: handlers.CreateAPIKeyResponse{}, Status: http.StatusCreated}).
+		Describe(get, "/api/v1/api-keys", openapi.Route{Summary: "List the company's API keys", Response: []auth.APIKey{}}).
+		Describe(del, "/api/v1/api-keys/:id", openapi.Route{Summary: "Revoke an API key", Status: http.StatusNoContent}).
+		Describe(post, "/auth/login", openapi.Route{Summary: "Log in", Request: handlers.LoginRequest{}, Response: auth.TokenPair{}}).
+		Describe(post, "/auth/refresh", openapi.Route{Summary: "Rotate a refresh token", Request: handlers.RefreshRequest{}, Response: auth.TokenPair{}}).
+		Describe(post, "/auth/logout", openapi.Route{Summary: "Log out", Status: http.StatusNoContent}).
+		Describe(get, "/.well-known/jwks.json", openapi.Route{Summary: "Keys verifying access tokens", Response: auth.JWKSet{}}).
+		Describe(get, "/api/v1/openapi.json", openapi.Route{Summary: "This document"})
+
+	return spec
+}
+
+// publicRoutes are served without authentication
+var publicRoutes = map[string]bool{
+	"/health":                             true,
+	"/.well-known/jwks.json":              true,
+	"/auth/login":                         true,
+	"/auth/refresh":                       true,
+	"/api/v1/public/tracking/:booking_id": true,
+	"/api/v1/openapi.json":                true,
 }
 
-func authMiddleware() gin.HandlerFunc {
+func authMiddleware(keys *auth.KeyRing, sessions *auth.SessionManager, apiKeys *auth.APIKeyStore) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if publicRoutes[c.FullPath()] {
+			c.Next()
+			return
+		}
+
+		// Server integrations authenticate with an API key instead of a token
+		if apiKey := c.GetHeader("X-API-Key"); apiKey != "" {
+			identity, err := apiKeys.Authenticate(apiKey)
+			if err != nil {
+				problem.Abort(c, http.StatusUnauthorized, err.Error())
+				return
+			}
+			if !apiKeys.Allow(identity.APIKeyID) {
+				problem.Abort(c, http.StatusTooManyRequests, "API key rate limit exceeded")
+				return
+			}
+
+			auth.SetIdentity(c, identity)
+			c.Next()
+			return
+		}
+
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "authorization header required"})
-			c.Abort()
+			problem.Abort(c, http.StatusUnauthorized, "authorization header required")
 			return
 		}
 
 		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			// Validate signing method
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.ErrSignatureInvalid
-			}
-			return jwtSecret, nil
-		})
+		token, err := jwt.Parse(tokenString, keys.Keyfunc)
 
 		if err != nil || !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
-			c.Abort()
+			problem.Abort(c, http.StatusUnauthorized, "invalid token")
 			return
 		}
 
 		claims, ok := token.Claims.(jwt.MapClaims)
 		if !ok {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token claims"})
-			c.Abort()
+			problem.Abort(c, http.StatusUnauthorized, "invalid token claims")
 			return
 		}
 
 		// Check token expiration
 		if exp, ok := claims["exp"].(float64); !ok || int64(exp) < time.Now().Unix() {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "token expired"})
-			c.Abort()
+			problem.Abort(c, http.StatusUnauthorized, "token expired")
 			return
 		}
 
-		userAddress, ok := claims["user_address"].(string)
-		if !ok || userAddress == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "user_address claim missing"})
-			c.Abort()
+		// Roles and company membership come from the token, never from the route
+		identity, err := auth.IdentityFromClaims(claims)
+		if err != nil {
+			problem.Abort(c, http.StatusUnauthorized, err.Error())
 			return
 		}
 
-		c.Set("user_address", userAddress)
+		if sessions.IsRevoked(identity) {
+			problem.Abort(c, http.StatusUnauthorized, "token revoked")
+			return
+		}
+
+		auth.SetIdentity(c, identity)
 		c.Next()
 	}
 }
@@ -636,7 +1111,8 @@ func corsMiddleware() gin.HandlerFunc {
 		if allowed {
 			c.Writer.Header().Set("Access-Control-Allow-Origin", origin)
 			c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-			c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+			c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, Idempotency-Key, X-Request-ID")
+			c.Writer.Header().Set("Access-Control-Expose-Headers", "X-RateLimit-Limit, X-RateLimit-Remaining, X-RateLimit-Reset, Retry-After, Idempotent-Replayed, X-Request-ID")
 		}
 
 		if c.Request.Method == "OPTIONS" {
@@ -648,14 +1124,3 @@ func corsMiddleware() gin.HandlerFunc {
 	}
 }
 
-func rateLimitMiddleware(rps int, burstDuration time.Duration) gin.HandlerFunc {
-	limiter := rate.NewLimiter(rate.Every(burstDuration/time.Duration(rps)), rps)
-	return func(c *gin.Context) {
-		if !limiter.Allow() {
-			c.JSON(http.StatusTooManyRequests, gin.H{"error": "rate limit exceeded"})
-			c.Abort()
-			return
-		}
-		c.Next()
-	}
-}
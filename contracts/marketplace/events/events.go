@@ -0,0 +1,197 @@
+// Package events defines the versioned schema of the events published by
+// MarketplaceContract. It has no Soroban dependencies, so an off-chain
+// consumer of the contract's events can decode them with the same types the
+// contract publishes. The API does not ingest contract events yet.
+package events
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// SchemaVersion is the version stamped on every marketplace event
+const SchemaVersion uint32 = 1
+
+// Event topics
+const (
+	TopicServiceListed    = "service_listed"
+	TopicServiceDelisted  = "service_delisted"
+	TopicBookingCreated   = "booking_created"
+	TopicPaymentProcessed = "payment_processed"
+	TopicTrackingUpdated  = "tracking_updated"
+	TopicPaymentReleased  = "payment_released"
+	TopicBookingCancelled = "booking_cancelled"
+	TopicStakeBonded      = "stake_bonded"
+	TopicStakeUnbonding   = "stake_unbonding"
+	TopicStakeWithdrawn   = "stake_withdrawn"
+	TopicStakeSlashed     = "stake_slashed"
+	TopicDisputeRaised    = "dispute_raised"
+)
+
+// ServiceListed is published when a provider lists a service
+type ServiceListed struct {
+	Version      uint32 `json:"version"`
+	ListingID    string `json:"listing_id"`
+	Provider     string `json:"provider"`
+	ServiceType  uint8  `json:"service_type"`
+	ShipmentMode uint8  `json:"shipment_mode"`
+	Origin       string `json:"origin"`
+	Destination  string `json:"destination"`
+	Rate         uint64 `json:"rate"`
+}
+
+// ServiceDelisted is published when a provider withdraws a listing
+type ServiceDelisted struct {
+	Version   uint32 `json:"version"`
+	ListingID string `json:"listing_id"`
+	Provider  string `json:"provider"`
+}
+
+// BookingCreated is published when a customer books a service
+type BookingCreated struct {
+	Version   uint32 `json:"version"`
+	BookingID string `json:"booking_id"`
+	ServiceID string `json:"service_id"`
+	Customer  string `json:"customer"`
+	Provider  string `json:"provider"`
+}
+
+// PaymentProcessed is published when a booking is paid
+type PaymentProcessed struct {
+	Version   uint32 `json:"version"`
+	BookingID string `json:"booking_id"`
+	Customer  string `json:"customer"`
+	Provider  string `json:"provider"`
+	Amount    uint64 `json:"amount"`
+}
+
+// TrackingUpdated is published when a provider records a tracking event
+type TrackingUpdated struct {
+	Version   uint32 `json:"version"`
+	BookingID string `json:"booking_id"`
+	Status    string `json:"status"`
+	Location  string `json:"location"`
+	Timestamp uint64 `json:"timestamp"`
+}
+
+// PaymentReleased is published when an escrowed tranche is paid to the provider
+type PaymentReleased struct {
+	Version   uint32 `json:"version"`
+	BookingID string `json:"booking_id"`
+	Provider  string `json:"provider"`
+	Milestone string `json:"milestone"`
+	Amount    uint64 `json:"amount"`
+}
+
+// BookingCancelled is published when a booking is cancelled and its
+// unreleased escrow refunded
+type BookingCancelled struct {
+	Version        uint32 `json:"version"`
+	BookingID      string `json:"booking_id"`
+	CancelledBy    string `json:"cancelled_by"`
+	RefundedAmount uint64 `json:"refunded_amount"`
+}
+
+// StakeBonded is published when a provider bonds stake
+type StakeBonded struct {
+	Version  uint32 `json:"version"`
+	Provider string `json:"provider"`
+	Amount   uint64 `json:"amount"`
+	Bonded   uint64 `json:"bonded"`
+}
+
+// StakeUnbonding is published when a provider starts unbonding stake
+type StakeUnbonding struct {
+	Version   uint32 `json:"version"`
+	Provider  string `json:"provider"`
+	Amount    uint64 `json:"amount"`
+	ReleaseAt int64  `json:"release_at"`
+}
+
+// StakeWithdrawn is published when unbonded stake is paid back to a provider
+type StakeWithdrawn struct {
+	Version  uint32 `json:"version"`
+	Provider string `json:"provider"`
+	Amount   uint64 `json:"amount"`
+}
+
+// StakeSlashed is published when an SLA breach moves provider stake to a customer
+type StakeSlashed struct {
+	Version   uint32 `json:"version"`
+	BookingID string `json:"booking_id"`
+	Provider  string `json:"provider"`
+	Customer  string `json:"customer"`
+	Reason    string `json:"reason"`
+	Amount    uint64 `json:"amount"`
+}
+
+// DisputeRaised is published when a customer disputes a booking
+type DisputeRaised struct {
+	Version   uint32 `json:"version"`
+	BookingID string `json:"booking_id"`
+	Customer  string `json:"customer"`
+	Provider  string `json:"provider"`
+	Reason    string `json:"reason"`
+}
+
+// Decode decodes the payload of a marketplace event published under topic
+// into its typed schema, rejecting unknown topics, unknown fields and
+// unsupported versions
+func Decode(topic string, payload []byte) (interface{}, error) {
+	var event interface{}
+	var version *uint32
+
+	switch topic {
+	case TopicServiceListed:
+		e := &ServiceListed{}
+		event, version = e, &e.Version
+	case TopicServiceDelisted:
+		e := &ServiceDelisted{}
+		event, version = e, &e.Version
+	case TopicBookingCreated:
+		e := &BookingCreated{}
+		event, version = e, &e.Version
+	case TopicPaymentProcessed:
+		e := &PaymentProcessed{}
+		event, version = e, &e.Version
+	case TopicTrackingUpdated:
+		e := &TrackingUpdated{}
+		event, version = e, &e.Version
+	case TopicPaymentReleased:
+		e := &PaymentReleased{}
+		event, version = e, &e.Version
+	case TopicBookingCancelled:
+		e := &BookingCancelled{}
+		event, version = e, &e.Version
+	case TopicStakeBonded:
+		e := &StakeBonded{}
+		event, version = e, &e.Version
+	case TopicStakeUnbonding:
+		e := &StakeUnbonding{}
+		event, version = e, &e.Version
+	case TopicStakeWithdrawn:
+		e := &StakeWithdrawn{}
+		event, version = e, &e.Version
+	case TopicStakeSlashed:
+		e := &StakeSlashed{}
+		event, version = e, &e.Version
+	case TopicDisputeRaised:
+		e := &DisputeRaised{}
+		event, version = e, &e.Version
+	default:
+		return nil, fmt.Errorf("unknown marketplace event topic: %s", topic)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(event); err != nil {
+		return nil, fmt.Errorf("failed to decode %s event: %w", topic, err)
+	}
+
+	if *version != SchemaVersion {
+		return nil, fmt.Errorf("unsupported %s event version: %d", topic, *version)
+	}
+
+	return event, nil
+}
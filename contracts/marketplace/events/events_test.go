@@ -0,0 +1,46 @@
+package events
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeRoundTrip(t *testing.T) {
+	published := PaymentProcessed{
+		Version:   SchemaVersion,
+		BookingID: "booking-1",
+		Customer:  "GCUSTOMER",
+		Provider:  "GPROVIDER",
+		Amount:    50,
+	}
+
+	payload, err := json.Marshal(published)
+	require.NoError(t, err)
+
+	decoded, err := Decode(TopicPaymentProcessed, payload)
+	require.NoError(t, err)
+	assert.Equal(t, &published, decoded)
+}
+
+func TestDecodeRejectsInvalidEvents(t *testing.T) {
+	tests := []struct {
+		name    string
+		topic   string
+		payload string
+	}{
+		{"unknown topic", "service_deleted", `{"version":1}`},
+		{"unknown field", TopicBookingCreated, `{"version":1,"booking_id":"b","extra":true}`},
+		{"unsupported version", TopicTrackingUpdated, `{"version":2,"booking_id":"b"}`},
+		{"malformed payload", TopicServiceListed, `{"version":`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Decode(tt.topic, []byte(tt.payload))
+			assert.Error(t, err)
+		})
+	}
+}
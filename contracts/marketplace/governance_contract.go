@@ -44,6 +44,9 @@ type Proposal struct {
 	AbstainVotes uint64
 	Executed    bool
 	Data        []byte // Encoded proposal-specific data
+	OffChain    bool   // Votes are collected off-chain and attested on close
+	Attestor    string // Address allowed to attest off-chain results
+	BallotRoot  string // Merkle root of the attested off-chain ballots
 }
 
 type Vote struct {
@@ -59,6 +62,7 @@ type GovernanceContract struct {
 	proposals      map[string]Proposal
 	votes         map[string]map[string]Vote // proposalID -> voter -> Vote
 	parameters    map[string]interface{}
+	treasury      Treasury
 }
 
 func (c *GovernanceContract) Initialize(env soroban.Env, tokenAddress string) {
@@ -67,10 +71,14 @@ func (c *GovernanceContract) Initialize(env soroban.Env, tokenAddress string) {
 	}
 
 	// Initialize the governance contract with token contract
-	c.token = &TokenContract{}
+	c.token = TokenClient(tokenAddress)
 	c.proposals = make(map[string]Proposal)
 	c.votes = make(map[string]map[string]Vote)
 	c.parameters = make(map[string]interface{})
+	c.treasury = Treasury{
+		categories: make(map[string]BudgetCategory),
+		grants:     make(map[string]Grant),
+	}
 
 	// Set initial governance parameters
 	c.parameters["minProposalThreshold"] = MinProposalThreshold
@@ -118,12 +126,29 @@ func (c *GovernanceContract) CreateProposal(env soroban.Env, creator string, tit
 	return proposalID
 }
 
+// CreateOffChainProposal creates a proposal whose votes are signed and
+// collected off-chain, with only the final result attested on-chain
+func (c *GovernanceContract) CreateOffChainProposal(env soroban.Env, creator string, title string, description string, proposalType ProposalType, data []byte, attestor string) string {
+	proposalID := c.CreateProposal(env, creator, title, description, proposalType, data)
+
+	proposal := c.proposals[proposalID]
+	proposal.OffChain = true
+	proposal.Attestor = attestor
+	c.proposals[proposalID] = proposal
+
+	return proposalID
+}
+
 func (c *GovernanceContract) CastVote(env soroban.Env, voter string, proposalID string, voteType VoteType) bool {
 	proposal, exists := c.proposals[proposalID]
 	if !exists {
 		panic("Proposal does not exist")
 	}
 
+	if proposal.OffChain {
+		panic("Proposal uses off-chain voting")
+	}
+
 	// Check if proposal is active
 	currentTime := env.Ledger().Timestamp()
 	if currentTime > proposal.EndTime || currentTime < proposal.StartTime {
@@ -181,6 +206,108 @@ func (c *GovernanceContract) CastVote(env soroban.Env, voter string, proposalID
 	return true
 }
 
+// FinalizeProposal tallies an active proposal once its voting period has
+// ended and moves it to PASSED, REJECTED or FAILED_QUORUM
+func (c *GovernanceContract) FinalizeProposal(env soroban.Env, proposalID string) string {
+	proposal, exists := c.proposals[proposalID]
+	if !exists {
+		panic("Proposal does not exist")
+	}
+
+	if proposal.Status != "ACTIVE" {
+		panic("Proposal is not active")
+	}
+
+	if proposal.OffChain {
+		panic("Off-chain proposals are finalized by attestation")
+	}
+
+	currentTime := env.Ledger().Timestamp()
+	if currentTime <= proposal.EndTime {
+		panic("Voting period has not ended")
+	}
+
+	proposal.Status = c.tallyProposal(proposal)
+	c.proposals[proposalID] = proposal
+
+	// Emit proposal finalization event
+	env.Events().Publish("proposal_finalized", map[string]interface{}{
+		"proposal_id":   proposalID,
+		"status":        proposal.Status,
+		"for_votes":     proposal.ForVotes,
+		"against_votes": proposal.AgainstVotes,
+		"abstain_votes": proposal.AbstainVotes,
+	})
+
+	return proposal.Status
+}
+
+// AttestOffChainResult anchors the Merkle root of an off-chain ballot set
+// together with its final tally and finalizes the proposal
+func (c *GovernanceContract) AttestOffChainResult(env soroban.Env, proposalID string, ballotRoot string, forVotes uint64, againstVotes uint64, abstainVotes uint64) string {
+	proposal, exists := c.proposals[proposalID]
+	if !exists {
+		panic("Proposal does not exist")
+	}
+
+	if !proposal.OffChain {
+		panic("Proposal uses on-chain voting")
+	}
+
+	if proposal.Status != "ACTIVE" {
+		panic("Proposal is not active")
+	}
+
+	// Verify attestor authorization
+	attestor := env.Current().Auth().Address()
+	if attestor.String() != proposal.Attestor {
+		panic("Unauthorized attestor")
+	}
+
+	currentTime := env.Ledger().Timestamp()
+	if currentTime <= proposal.EndTime {
+		panic("Voting period has not ended")
+	}
+
+	proposal.BallotRoot = ballotRoot
+	proposal.ForVotes = forVotes
+	proposal.AgainstVotes = againstVotes
+	proposal.AbstainVotes = abstainVotes
+	proposal.Status = c.tallyProposal(proposal)
+	c.proposals[proposalID] = proposal
+
+	// Emit attestation event
+	env.Events().Publish("offchain_result_attested", map[string]interface{}{
+		"proposal_id":   proposalID,
+		"ballot_root":   ballotRoot,
+		"status":        proposal.Status,
+		"for_votes":     forVotes,
+		"against_votes": againstVotes,
+		"abstain_votes": abstainVotes,
+	})
+
+	return proposal.Status
+}
+
+// tallyProposal applies the quorum and majority rules to a proposal's votes
+func (c *GovernanceContract) tallyProposal(proposal Proposal) string {
+	totalVotes := proposal.ForVotes + proposal.AgainstVotes + proposal.AbstainVotes
+
+	// Check quorum
+	totalSupply := c.token.TotalSupply()
+	if totalSupply == 0 || (totalVotes*100)/totalSupply < QuorumPercentage {
+		return "FAILED_QUORUM"
+	}
+
+	// Check majority
+	forPercentage := (proposal.ForVotes * 100) / totalVotes
+	if forPercentage < MajorityPercentage {
+		return "REJECTED"
+	}
+
+	return "PASSED"
+}
+
 func (c *GovernanceContract) ExecuteProposal(env soroban.Env, proposalID string) bool {
 	proposal, exists := c.proposals[proposalID]
 	if !exists {
@@ -197,25 +324,22 @@ func (c *GovernanceContract) ExecuteProposal(env soroban.Env, proposalID string)
 		panic("Proposal already executed")
 	}
 
-	// Calculate total votes
-	totalVotes := proposal.ForVotes + proposal.AgainstVotes + proposal.AbstainVotes
-	
-	// Check quorum
-	quorum := (totalVotes * 100) / c.token.TotalSupply()
-	if quorum < QuorumPercentage {
-		proposal.Status = "FAILED_QUORUM"
-		c.proposals[proposalID] = proposal
-		return false
+	// Finalize proposals nobody has finalized yet
+	if proposal.Status == "ACTIVE" {
+		c.FinalizeProposal(env, proposalID)
+		proposal = c.proposals[proposalID]
 	}
 
-	// Check majority
-	forPercentage := (proposal.ForVotes * 100) / totalVotes
-	if forPercentage < MajorityPercentage {
-		proposal.Status = "REJECTED"
-		c.proposals[proposalID] = proposal
+	if proposal.Status != "PASSED" {
 		return false
 	}
 
+	// Mark proposal as executed before applying it, so treasury
+	// disbursements can verify the proposal that authorized them
+	proposal.Executed = true
+	proposal.Status = "EXECUTED"
+	c.proposals[proposalID] = proposal
+
 	// Execute proposal based on type
 	switch proposal.Type {
 	case ParameterChange:
@@ -228,11 +352,6 @@ func (c *GovernanceContract) ExecuteProposal(env soroban.Env, proposalID string)
 		c.executeServiceUpdate(env, proposal)
 	}
 
-	// Mark proposal as executed
-	proposal.Executed = true
-	proposal.Status = "EXECUTED"
-	c.proposals[proposalID] = proposal
-
 	// Emit proposal execution event
 	env.Events().Publish("proposal_executed", map[string]interface{}{
 		"proposal_id": proposalID,
@@ -251,7 +370,7 @@ func (c *GovernanceContract) executeContractUpgrade(env soroban.Env, proposal Pr
 }
 
 func (c *GovernanceContract) executeFundsAllocation(env soroban.Env, proposal Proposal) {
-	// Implementation for funds allocation execution
+	c.allocateFunds(env, proposal)
 }
 
 func (c *GovernanceContract) executeServiceUpdate(env soroban.Env, proposal Proposal) {
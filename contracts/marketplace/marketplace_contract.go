@@ -2,8 +2,32 @@ package marketplace
 
 import (
 	"github.com/stellar/soroban-sdk/go/soroban"
+
+	"logistics-marketplace/contracts/marketplace/events"
+)
+
+// Storage TTLs, in ledgers
+const (
+	StorageTTLThreshold = 17_280  // Extend entries with less than ~1 day left
+	StorageTTLExtendTo  = 518_400 // to ~30 days
+)
+
+// DataKeyKind identifies the type of a persistent storage entry
+type DataKeyKind uint8
+
+const (
+	ListingKey DataKeyKind = iota
+	BookingKey
+	StakeKey
+	DisputeKey
 )
 
+// DataKey is the persistent storage key of a marketplace entry
+type DataKey struct {
+	Kind DataKeyKind
+	ID   string
+}
+
 // ServiceType represents different types of logistics services
 type ServiceType uint8
 
@@ -37,16 +61,50 @@ type ServiceListing struct {
 	IsActive      bool
 }
 
+// PaymentTranche is the share of a booking's payment released when the
+// shipment reaches a milestone status
+type PaymentTranche struct {
+	Milestone  string // Tracking status that releases the tranche
+	Percentage uint32
+	Amount     uint64
+	Released   bool
+}
+
+// DefaultPaymentSchedule releases 20% at pickup, 50% at departure and 30% at proof of delivery
+var DefaultPaymentSchedule = []PaymentTranche{
+	{Milestone: "PICKED_UP", Percentage: 20},
+	{Milestone: "DEPARTED", Percentage: 50},
+	{Milestone: "DELIVERED", Percentage: 30},
+}
+
+// TrackingStatuses are the statuses a provider may report for a shipment.
+// Booking states such as PENDING and CANCELLED are set by their own flows.
+var TrackingStatuses = map[string]bool{
+	"BOOKED":           true,
+	"PICKED_UP":        true,
+	"DEPARTED":         true,
+	"IN_TRANSIT":       true,
+	"ARRIVED":          true,
+	"CUSTOMS_CLEARED":  true,
+	"OUT_FOR_DELIVERY": true,
+	"DELIVERED":        true,
+}
+
 // Booking represents a cargo booking
 type Booking struct {
-	ID            string
-	ServiceID     string
-	Customer      string
-	Provider      string
-	Status        string
-	CargoDetails  CargoDetails
-	PaymentStatus string
-	TrackingInfo  []TrackingEvent
+	ID                string
+	ServiceID         string
+	Customer          string
+	Provider          string
+	Status            string
+	CargoDetails      CargoDetails
+	PaymentStatus     string
+	PaymentSchedule   []PaymentTranche
+	EscrowedAmount    uint64
+	ReleasedAmount    uint64
+	ConfirmedSchedule ConfirmedSchedule
+	DeliveredAt       int64
+	TrackingInfo      []TrackingEvent
 }
 
 // CargoDetails contains information about the cargo
@@ -69,8 +127,7 @@ type TrackingEvent struct {
 type MarketplaceContract struct {
 	soroban.Contract
 	tokenContract *TokenContract
-	listings      map[string]ServiceListing
-	bookings     map[string]Booking
+	admin         string // Arbiter of disputes
 }
 
 func (c *MarketplaceContract) Initialize(env soroban.Env, tokenContractAddress string) {
@@ -78,9 +135,8 @@ func (c *MarketplaceContract) Initialize(env soroban.Env, tokenContractAddress s
 		panic("Contract already initialized")
 	}
 
-	// Initialize storage
-	c.listings = make(map[string]ServiceListing)
-	c.bookings = make(map[string]Booking)
+	c.tokenContract = TokenClient(tokenContractAddress)
+	c.admin = env.Current().Auth().Address().String()
 }
 
 // CreateServiceListing creates a new service listing
@@ -90,27 +146,72 @@ func (c *MarketplaceContract) CreateServiceListing(env soroban.Env, listing Serv
 	listing.Provider = provider.String()
 	listing.IsActive = true
 
+	// Require the provider to have staked for the service type
+	stake := c.getStake(env, listing.Provider)
+	if stake.Bonded < MinimumStake[listing.ServiceType] {
+		panic("Insufficient stake for service type")
+	}
+	stake.Listings[listing.ServiceType]++
+	c.putStake(env, stake)
+
 	// Generate unique ID
 	listing.ID = env.GenerateUUID()
 	
 	// Store listing
-	c.listings[listing.ID] = listing
+	c.putListing(env, listing)
 
 	// Emit event
-	env.Events().Publish("service_listed", map[string]interface{}{
-		"id":       listing.ID,
-		"provider": listing.Provider,
+	env.Events().Publish(events.TopicServiceListed, events.ServiceListed{
+		Version:      events.SchemaVersion,
+		ListingID:    listing.ID,
+		Provider:     listing.Provider,
+		ServiceType:  uint8(listing.ServiceType),
+		ShipmentMode: uint8(listing.ShipmentMode),
+		Origin:       listing.Origin,
+		Destination:  listing.Destination,
+		Rate:         listing.Rate,
 	})
 
 	return listing.ID
 }
 
+// DelistService deactivates a listing of the calling provider, releasing the
+// stake it required
+func (c *MarketplaceContract) DelistService(env soroban.Env, listingID string) bool {
+	listing, exists := c.getListing(env, listingID)
+	if !exists || !listing.IsActive {
+		return false
+	}
+
+	if env.Current().Auth().Address().String() != listing.Provider {
+		return false
+	}
+
+	listing.IsActive = false
+	c.putListing(env, listing)
+
+	stake := c.getStake(env, listing.Provider)
+	if stake.Listings[listing.ServiceType] > 0 {
+		stake.Listings[listing.ServiceType]--
+	}
+	c.putStake(env, stake)
+
+	env.Events().Publish(events.TopicServiceDelisted, events.ServiceDelisted{
+		Version:   events.SchemaVersion,
+		ListingID: listing.ID,
+		Provider:  listing.Provider,
+	})
+
+	return true
+}
+
 // GetQuotation calculates shipping rate for given parameters
 func (c *MarketplaceContract) GetQuotation(
+	env soroban.Env,
 	serviceID string,
 	cargoDetails CargoDetails,
 ) uint64 {
-	listing, exists := c.listings[serviceID]
+	listing, exists := c.getListing(env, serviceID)
 	if !exists {
 		return 0
 	}
@@ -123,17 +224,48 @@ func (c *MarketplaceContract) GetQuotation(
 	return baseRate * (volumeMultiplier + weightMultiplier) / 100
 }
 
-// CreateBooking creates a new cargo booking
+// CreateBooking creates a new cargo booking paid out on the default schedule
 func (c *MarketplaceContract) CreateBooking(
 	env soroban.Env,
 	serviceID string,
 	cargoDetails CargoDetails,
 ) string {
-	listing, exists := c.listings[serviceID]
+	return c.CreateBookingWithSchedule(env, serviceID, cargoDetails, DefaultPaymentSchedule)
+}
+
+// CreateBookingWithSchedule creates a new cargo booking whose payment is
+// released to the provider in tranches as the shipment reaches milestones
+func (c *MarketplaceContract) CreateBookingWithSchedule(
+	env soroban.Env,
+	serviceID string,
+	cargoDetails CargoDetails,
+	schedule []PaymentTranche,
+) string {
+	listing, exists := c.getListing(env, serviceID)
 	if !exists || !listing.IsActive {
 		panic("Invalid or inactive service")
 	}
 
+	var total uint64
+	milestones := make(map[string]bool)
+	for i, tranche := range schedule {
+		if !TrackingStatuses[tranche.Milestone] || milestones[tranche.Milestone] {
+			panic("Invalid payment schedule milestone")
+		}
+		// Delivery ends tracking, so no tranche can follow it
+		if tranche.Milestone == "DELIVERED" && i != len(schedule)-1 {
+			panic("Delivery must be the last payment milestone")
+		}
+		if tranche.Percentage == 0 || tranche.Percentage > 100 {
+			panic("Payment tranche must be between 1% and 100%")
+		}
+		milestones[tranche.Milestone] = true
+		total += uint64(tranche.Percentage)
+	}
+	if total != 100 {
+		panic("Payment schedule must add up to 100%")
+	}
+
 	customer := env.Current().Auth().Address()
 	
 	booking := Booking{
@@ -141,27 +273,30 @@ func (c *MarketplaceContract) CreateBooking(
 		ServiceID:     serviceID,
 		Customer:      customer.String(),
 		Provider:      listing.Provider,
-		Status:        "PENDING",
-		CargoDetails:  cargoDetails,
-		PaymentStatus: "UNPAID",
-		TrackingInfo:  make([]TrackingEvent, 0),
+		Status:          "PENDING",
+		CargoDetails:    cargoDetails,
+		PaymentStatus:   "UNPAID",
+		PaymentSchedule: append([]PaymentTranche(nil), schedule...),
+		TrackingInfo:    make([]TrackingEvent, 0),
 	}
 
-	c.bookings[booking.ID] = booking
+	c.putBooking(env, booking)
 
 	// Emit event
-	env.Events().Publish("booking_created", map[string]interface{}{
-		"id":       booking.ID,
-		"customer": booking.Customer,
-		"provider": booking.Provider,
+	env.Events().Publish(events.TopicBookingCreated, events.BookingCreated{
+		Version:   events.SchemaVersion,
+		BookingID: booking.ID,
+		ServiceID: booking.ServiceID,
+		Customer:  booking.Customer,
+		Provider:  booking.Provider,
 	})
 
 	return booking.ID
 }
 
-// ProcessPayment handles payment for a booking
+// ProcessPayment moves the payment for a booking into contract escrow
 func (c *MarketplaceContract) ProcessPayment(env soroban.Env, bookingID string) bool {
-	booking, exists := c.bookings[bookingID]
+	booking, exists := c.getBooking(env, bookingID)
 	if !exists {
 		return false
 	}
@@ -171,32 +306,98 @@ func (c *MarketplaceContract) ProcessPayment(env soroban.Env, bookingID string)
 		return false
 	}
 
+	if booking.PaymentStatus != "UNPAID" {
+		return false
+	}
+
 	// Calculate payment amount
-	amount := c.GetQuotation(booking.ServiceID, booking.CargoDetails)
+	amount := c.GetQuotation(env, booking.ServiceID, booking.CargoDetails)
 
-	// Process payment using token contract
-	success := c.tokenContract.Transfer(
+	// Escrow the payment using the allowance the customer granted the marketplace
+	marketplace := env.Current().Contract().Address()
+	success := c.tokenContract.TransferFrom(
 		env,
+		marketplace.String(),
 		booking.Customer,
-		booking.Provider,
+		marketplace.String(),
 		amount,
 	)
 
 	if success {
-		booking.PaymentStatus = "PAID"
+		booking.PaymentStatus = "ESCROWED"
 		booking.Status = "CONFIRMED"
-		c.bookings[bookingID] = booking
+		booking.EscrowedAmount = amount
+
+		// Split the escrow into tranches, the last one absorbing rounding
+		var allocated uint64
+		for i := range booking.PaymentSchedule {
+			if i == len(booking.PaymentSchedule)-1 {
+				booking.PaymentSchedule[i].Amount = amount - allocated
+				break
+			}
+			booking.PaymentSchedule[i].Amount = amount * uint64(booking.PaymentSchedule[i].Percentage) / 100
+			allocated += booking.PaymentSchedule[i].Amount
+		}
+
+		c.putBooking(env, booking)
 
 		// Emit payment event
-		env.Events().Publish("payment_processed", map[string]interface{}{
-			"booking_id": bookingID,
-			"amount":     amount,
+		env.Events().Publish(events.TopicPaymentProcessed, events.PaymentProcessed{
+			Version:   events.SchemaVersion,
+			BookingID: bookingID,
+			Customer:  booking.Customer,
+			Provider:  booking.Provider,
+			Amount:    amount,
 		})
 	}
 
 	return success
 }
 
+// CancelBooking cancels a booking and refunds the unreleased escrow to the customer
+func (c *MarketplaceContract) CancelBooking(env soroban.Env, bookingID string) bool {
+	booking, exists := c.getBooking(env, bookingID)
+	if !exists {
+		return false
+	}
+
+	// Either party may cancel
+	caller := env.Current().Auth().Address()
+	if caller.String() != booking.Customer && caller.String() != booking.Provider {
+		return false
+	}
+
+	if booking.Status == "CANCELLED" || booking.Status == "DELIVERED" {
+		return false
+	}
+
+	refund := booking.EscrowedAmount - booking.ReleasedAmount
+	if refund > 0 {
+		marketplace := env.Current().Contract().Address()
+		if !c.tokenContract.Transfer(env, marketplace.String(), booking.Customer, refund) {
+			panic("Insufficient escrow balance")
+		}
+
+		booking.PaymentStatus = "REFUNDED"
+		if booking.ReleasedAmount > 0 {
+			booking.PaymentStatus = "PARTIALLY_REFUNDED"
+		}
+	}
+
+	booking.Status = "CANCELLED"
+	c.putBooking(env, booking)
+
+	// Emit cancellation event
+	env.Events().Publish(events.TopicBookingCancelled, events.BookingCancelled{
+		Version:        events.SchemaVersion,
+		BookingID:      bookingID,
+		CancelledBy:    caller.String(),
+		RefundedAmount: refund,
+	})
+
+	return true
+}
+
 // UpdateShipmentStatus updates tracking information for a booking
 func (c *MarketplaceContract) UpdateShipmentStatus(
 	env soroban.Env,
@@ -205,7 +406,7 @@ func (c *MarketplaceContract) UpdateShipmentStatus(
 	status string,
 	description string,
 ) bool {
-	booking, exists := c.bookings[bookingID]
+	booking, exists := c.getBooking(env, bookingID)
 	if !exists {
 		return false
 	}
@@ -216,6 +417,15 @@ func (c *MarketplaceContract) UpdateShipmentStatus(
 		return false
 	}
 
+	// Tracking starts once the payment is escrowed and ends with delivery
+	if booking.PaymentStatus == "UNPAID" || booking.Status == "CANCELLED" || booking.Status == "DELIVERED" {
+		return false
+	}
+
+	if !TrackingStatuses[status] || !milestoneAllowed(booking, status) {
+		return false
+	}
+
 	event := TrackingEvent{
 		Timestamp:   uint64(env.Current().Ledger().Timestamp()),
 		Location:    location,
@@ -225,22 +435,102 @@ func (c *MarketplaceContract) UpdateShipmentStatus(
 
 	booking.TrackingInfo = append(booking.TrackingInfo, event)
 	booking.Status = status
-	c.bookings[bookingID] = booking
+
+	delivered := status == "DELIVERED" && booking.DeliveredAt == 0
+	if delivered {
+		booking.DeliveredAt = env.Ledger().Timestamp()
+	}
+
+	// Release the tranche tied to this milestone
+	released := c.releaseTranche(env, &booking, status)
+
+	c.putBooking(env, booking)
 
 	// Emit tracking update event
-	env.Events().Publish("tracking_updated", map[string]interface{}{
-		"booking_id":  bookingID,
-		"status":      status,
-		"location":    location,
-		"timestamp":   event.Timestamp,
+	env.Events().Publish(events.TopicTrackingUpdated, events.TrackingUpdated{
+		Version:   events.SchemaVersion,
+		BookingID: bookingID,
+		Status:    status,
+		Location:  location,
+		Timestamp: event.Timestamp,
 	})
 
+	if released != nil {
+		env.Events().Publish(events.TopicPaymentReleased, events.PaymentReleased{
+			Version:   events.SchemaVersion,
+			BookingID: bookingID,
+			Provider:  booking.Provider,
+			Milestone: released.Milestone,
+			Amount:    released.Amount,
+		})
+	}
+
+	// Late deliveries breach the provider's SLA
+	if delivered {
+		c.checkDeliverySLA(env, booking)
+	}
+
 	return true
 }
 
+// milestoneAllowed reports whether a shipment may report status. Milestones
+// of the payment schedule are reached in order: a status may be the next
+// milestone or repeat the latest one, and delivery requires every milestone
+// before it. Other statuses report progress between milestones.
+func milestoneAllowed(booking Booking, status string) bool {
+	reached := 0
+	for _, tranche := range booking.PaymentSchedule {
+		if !tranche.Released {
+			break
+		}
+		reached++
+	}
+
+	for i, tranche := range booking.PaymentSchedule {
+		if tranche.Milestone == status {
+			return i == reached || i == reached-1
+		}
+	}
+
+	if status == "DELIVERED" {
+		return reached == len(booking.PaymentSchedule)
+	}
+	return true
+}
+
+// releaseTranche pays out the escrowed tranche matching a milestone status
+func (c *MarketplaceContract) releaseTranche(env soroban.Env, booking *Booking, status string) *PaymentTranche {
+	if booking.PaymentStatus != "ESCROWED" && booking.PaymentStatus != "PARTIALLY_RELEASED" {
+		return nil
+	}
+
+	for i := range booking.PaymentSchedule {
+		tranche := &booking.PaymentSchedule[i]
+		if tranche.Milestone != status || tranche.Released {
+			continue
+		}
+
+		marketplace := env.Current().Contract().Address()
+		if !c.tokenContract.Transfer(env, marketplace.String(), booking.Provider, tranche.Amount) {
+			panic("Insufficient escrow balance")
+		}
+
+		tranche.Released = true
+		booking.ReleasedAmount += tranche.Amount
+		booking.PaymentStatus = "PARTIALLY_RELEASED"
+		if booking.ReleasedAmount == booking.EscrowedAmount {
+			booking.PaymentStatus = "RELEASED"
+		}
+
+		return tranche
+	}
+
+	return nil
+}
+
 // GetBooking retrieves booking information
-func (c *MarketplaceContract) GetBooking(bookingID string) *Booking {
-	booking, exists := c.bookings[bookingID]
+func (c *MarketplaceContract) GetBooking(env soroban.Env, bookingID string) *Booking {
+	booking, exists := c.getBooking(env, bookingID)
 	if !exists {
 		return nil
 	}
@@ -248,10 +538,44 @@ func (c *MarketplaceContract) GetBooking(bookingID string) *Booking {
 }
 
 // GetServiceListing retrieves service listing information
-func (c *MarketplaceContract) GetServiceListing(listingID string) *ServiceListing {
-	listing, exists := c.listings[listingID]
+func (c *MarketplaceContract) GetServiceListing(env soroban.Env, listingID string) *ServiceListing {
+	listing, exists := c.getListing(env, listingID)
 	if !exists {
 		return nil
 	}
 	return &listing
 }
+
+// Storage helpers
+
+func (c *MarketplaceContract) getListing(env soroban.Env, listingID string) (ServiceListing, bool) {
+	key := DataKey{Kind: ListingKey, ID: listingID}
+	value, exists := env.Storage().Persistent().Get(key)
+	if !exists {
+		return ServiceListing{}, false
+	}
+	env.Storage().Persistent().ExtendTTL(key, StorageTTLThreshold, StorageTTLExtendTo)
+	return value.(ServiceListing), true
+}
+
+func (c *MarketplaceContract) putListing(env soroban.Env, listing ServiceListing) {
+	key := DataKey{Kind: ListingKey, ID: listing.ID}
+	env.Storage().Persistent().Set(key, listing)
+	env.Storage().Persistent().ExtendTTL(key, StorageTTLThreshold, StorageTTLExtendTo)
+}
+
+func (c *MarketplaceContract) getBooking(env soroban.Env, bookingID string) (Booking, bool) {
+	key := DataKey{Kind: BookingKey, ID: bookingID}
+	value, exists := env.Storage().Persistent().Get(key)
+	if !exists {
+		return Booking{}, false
+	}
+	env.Storage().Persistent().ExtendTTL(key, StorageTTLThreshold, StorageTTLExtendTo)
+	return value.(Booking), true
+}
+
+func (c *MarketplaceContract) putBooking(env soroban.Env, booking Booking) {
+	key := DataKey{Kind: BookingKey, ID: booking.ID}
+	env.Storage().Persistent().Set(key, booking)
+	env.Storage().Persistent().ExtendTTL(key, StorageTTLThreshold, StorageTTLExtendTo)
+}
@@ -0,0 +1,781 @@
+package marketplace
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"logistics-marketplace/contracts/marketplace/events"
+	"logistics-marketplace/contracts/sorobantest"
+)
+
+const (
+	adminAddress       = "GADMIN"
+	providerAddress    = "GPROVIDER"
+	customerAddress    = "GCUSTOMER"
+	voterAddress       = "GVOTER"
+	tokenAddress       = "CTOKEN"
+	marketplaceAddress = "CMARKETPLACE"
+	governanceAddress  = "CGOVERNANCE"
+
+	genesisTime = int64(1_700_000_000)
+
+	providerStake = uint64(100_000)
+)
+
+type testContracts struct {
+	env         *sorobantest.Env
+	token       *TokenContract
+	marketplace *MarketplaceContract
+	governance  *GovernanceContract
+}
+
+func setupContracts(t *testing.T) *testContracts {
+	t.Helper()
+
+	env := sorobantest.NewEnv(genesisTime)
+
+	token := &TokenContract{}
+	env.Invoke(tokenAddress, adminAddress)
+	token.Initialize(env, adminAddress, 100_000_000)
+
+	marketplace := &MarketplaceContract{}
+	env.Invoke(marketplaceAddress, adminAddress)
+	marketplace.Initialize(env, tokenAddress)
+
+	governance := &GovernanceContract{}
+	env.Invoke(governanceAddress, adminAddress)
+	governance.Initialize(env, tokenAddress)
+
+	// Fund the participants
+	env.Invoke(tokenAddress, adminAddress)
+	require.True(t, token.Transfer(env, adminAddress, customerAddress, 1_000_000))
+	require.True(t, token.Transfer(env, adminAddress, voterAddress, 10_000_000))
+	require.True(t, token.Transfer(env, adminAddress, providerAddress, providerStake))
+
+	// The provider bonds its whole balance to be able to list services
+	env.Invoke(marketplaceAddress, providerAddress)
+	require.True(t, marketplace.Stake(env, providerStake))
+
+	env.Events().Reset()
+
+	return &testContracts{
+		env:         env,
+		token:       token,
+		marketplace: marketplace,
+		governance:  governance,
+	}
+}
+
+func TestMarketplaceBookingScenario(t *testing.T) {
+	c := setupContracts(t)
+	env := c.env
+
+	// Provider lists a service
+	env.Invoke(marketplaceAddress, providerAddress)
+	serviceID := c.marketplace.CreateServiceListing(env, ServiceListing{
+		ServiceType:  FreightForwarding,
+		ShipmentMode: Sea,
+		Origin:       "CNSHA",
+		Destination:  "NLRTM",
+		Rate:         10,
+	})
+	require.NotEmpty(t, serviceID)
+
+	cargo := CargoDetails{Weight: 3, Volume: 2, Type: "GENERAL"}
+	amount := c.marketplace.GetQuotation(env, serviceID, cargo)
+	assert.Equal(t, uint64(50), amount)
+
+	// Customer books the service
+	env.Invoke(marketplaceAddress, customerAddress)
+	bookingID := c.marketplace.CreateBooking(env, serviceID, cargo)
+	require.NotEmpty(t, bookingID)
+
+	// Payment without an allowance fails
+	assert.False(t, c.marketplace.ProcessPayment(env, bookingID))
+
+	// Customer approves the marketplace and pays
+	env.Invoke(tokenAddress, customerAddress)
+	require.True(t, c.token.Approve(env, customerAddress, marketplaceAddress, amount, genesisTime+3600))
+
+	env.Invoke(marketplaceAddress, customerAddress)
+	require.True(t, c.marketplace.ProcessPayment(env, bookingID))
+
+	// The payment is held in escrow by the marketplace next to the provider's stake
+	assert.Equal(t, uint64(1_000_000)-amount, c.token.BalanceOf(customerAddress))
+	assert.Equal(t, uint64(0), c.token.BalanceOf(providerAddress))
+	assert.Equal(t, providerStake+amount, c.token.BalanceOf(marketplaceAddress))
+	assert.Equal(t, uint64(0), c.token.Allowance(env, customerAddress, marketplaceAddress))
+
+	booking := c.marketplace.GetBooking(env, bookingID)
+	require.NotNil(t, booking)
+	assert.Equal(t, "ESCROWED", booking.PaymentStatus)
+	assert.Equal(t, "CONFIRMED", booking.Status)
+	assert.Equal(t, amount, booking.EscrowedAmount)
+
+	// Only the provider can update tracking
+	env.AdvanceTime(3600)
+	assert.False(t, c.marketplace.UpdateShipmentStatus(env, bookingID, "CNSHA", "DEPARTED", "Vessel departed"))
+
+	env.Invoke(marketplaceAddress, providerAddress)
+	require.True(t, c.marketplace.UpdateShipmentStatus(env, bookingID, "CNSHA", "PICKED_UP", "Cargo collected"))
+	require.True(t, c.marketplace.UpdateShipmentStatus(env, bookingID, "CNSHA", "DEPARTED", "Vessel departed"))
+
+	booking = c.marketplace.GetBooking(env, bookingID)
+	require.Len(t, booking.TrackingInfo, 2)
+	assert.Equal(t, "DEPARTED", booking.Status)
+	assert.Equal(t, "PARTIALLY_RELEASED", booking.PaymentStatus)
+	assert.Equal(t, uint64(35), c.token.BalanceOf(providerAddress))
+
+	var names []string
+	for _, event := range env.Events().All() {
+		names = append(names, event.Name)
+	}
+	assert.Equal(t, []string{
+		"service_listed",
+		"booking_created",
+		"approve",
+		"transfer",
+		"payment_processed",
+		"transfer",
+		"tracking_updated",
+		"payment_released",
+		"transfer",
+		"tracking_updated",
+		"payment_released",
+	}, names)
+
+	tracking, ok := env.Events().Last(events.TopicTrackingUpdated)
+	require.True(t, ok)
+	assert.Equal(t, marketplaceAddress, tracking.Contract)
+
+	payment, ok := env.Events().Last(events.TopicPaymentProcessed)
+	require.True(t, ok)
+	assert.Equal(t, events.PaymentProcessed{
+		Version:   events.SchemaVersion,
+		BookingID: bookingID,
+		Customer:  customerAddress,
+		Provider:  providerAddress,
+		Amount:    amount,
+	}, payment.Data)
+}
+
+func setupPaidBooking(t *testing.T, c *testContracts) (string, uint64) {
+	t.Helper()
+	env := c.env
+
+	env.Invoke(marketplaceAddress, providerAddress)
+	serviceID := c.marketplace.CreateServiceListing(env, ServiceListing{Rate: 100})
+
+	cargo := CargoDetails{Weight: 7, Volume: 3}
+	amount := c.marketplace.GetQuotation(env, serviceID, cargo)
+
+	env.Invoke(marketplaceAddress, customerAddress)
+	bookingID := c.marketplace.CreateBooking(env, serviceID, cargo)
+
+	env.Invoke(tokenAddress, customerAddress)
+	require.True(t, c.token.Approve(env, customerAddress, marketplaceAddress, amount, genesisTime+3600))
+
+	env.Invoke(marketplaceAddress, customerAddress)
+	require.True(t, c.marketplace.ProcessPayment(env, bookingID))
+
+	return bookingID, amount
+}
+
+func TestMarketplaceMilestoneReleases(t *testing.T) {
+	c := setupContracts(t)
+	env := c.env
+
+	bookingID, amount := setupPaidBooking(t, c)
+	assert.Equal(t, uint64(1_000), amount)
+
+	env.Invoke(marketplaceAddress, providerAddress)
+	for _, step := range []struct {
+		status   string
+		released uint64
+	}{
+		{"PICKED_UP", 200},
+		{"IN_TRANSIT", 200}, // Not a milestone
+		{"DEPARTED", 700},
+		{"DEPARTED", 700}, // Already released
+		{"DELIVERED", 1_000},
+	} {
+		require.True(t, c.marketplace.UpdateShipmentStatus(env, bookingID, "", step.status, ""))
+		assert.Equal(t, step.released, c.token.BalanceOf(providerAddress), step.status)
+	}
+
+	booking := c.marketplace.GetBooking(env, bookingID)
+	assert.Equal(t, "RELEASED", booking.PaymentStatus)
+	assert.Equal(t, amount, booking.ReleasedAmount)
+	assert.Equal(t, providerStake, c.token.BalanceOf(marketplaceAddress))
+	assert.Len(t, env.Events().Named(events.TopicPaymentReleased), 3)
+
+	// Delivered bookings cannot be cancelled
+	assert.False(t, c.marketplace.CancelBooking(env, bookingID))
+}
+
+func TestMarketplaceShipmentStatusOrder(t *testing.T) {
+	tests := []struct {
+		name     string
+		reported []string // Accepted before the update under test
+		status   string
+		accepted bool
+		released uint64
+	}{
+		{name: "first milestone", status: "PICKED_UP", accepted: true, released: 200},
+		{name: "skips a milestone", status: "DEPARTED", accepted: false},
+		{name: "delivers immediately", status: "DELIVERED", accepted: false},
+		{name: "progress before pickup", status: "BOOKED", accepted: true},
+		{name: "empty status", status: "", accepted: false},
+		{name: "unknown status", status: "LOST_AT_SEA", accepted: false},
+		{name: "cancels", reported: []string{"PICKED_UP"}, status: "CANCELLED", accepted: false, released: 200},
+		{name: "reopens", reported: []string{"PICKED_UP"}, status: "PENDING", accepted: false, released: 200},
+		{name: "delivers before departure", reported: []string{"PICKED_UP", "IN_TRANSIT"}, status: "DELIVERED", accepted: false, released: 200},
+		{name: "next milestone", reported: []string{"PICKED_UP"}, status: "DEPARTED", accepted: true, released: 700},
+		{name: "repeats latest milestone", reported: []string{"PICKED_UP", "DEPARTED"}, status: "DEPARTED", accepted: true, released: 700},
+		{name: "moves back", reported: []string{"PICKED_UP", "DEPARTED"}, status: "PICKED_UP", accepted: false, released: 700},
+		{name: "delivers in order", reported: []string{"PICKED_UP", "IN_TRANSIT", "DEPARTED"}, status: "DELIVERED", accepted: true, released: 1_000},
+		{name: "update after delivery", reported: []string{"PICKED_UP", "DEPARTED", "DELIVERED"}, status: "IN_TRANSIT", accepted: false, released: 1_000},
+		{name: "milestone after delivery", reported: []string{"PICKED_UP", "DEPARTED", "DELIVERED"}, status: "PICKED_UP", accepted: false, released: 1_000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := setupContracts(t)
+			env := c.env
+			bookingID, _ := setupPaidBooking(t, c)
+
+			env.Invoke(marketplaceAddress, providerAddress)
+			for _, status := range tt.reported {
+				require.True(t, c.marketplace.UpdateShipmentStatus(env, bookingID, "", status, ""), status)
+			}
+
+			assert.Equal(t, tt.accepted, c.marketplace.UpdateShipmentStatus(env, bookingID, "", tt.status, ""))
+			assert.Equal(t, tt.released, c.token.BalanceOf(providerAddress))
+		})
+	}
+}
+
+func TestMarketplaceTrackingRequiresPayment(t *testing.T) {
+	c := setupContracts(t)
+	env := c.env
+
+	env.Invoke(marketplaceAddress, providerAddress)
+	serviceID := c.marketplace.CreateServiceListing(env, ServiceListing{Rate: 100})
+
+	env.Invoke(marketplaceAddress, customerAddress)
+	bookingID := c.marketplace.CreateBooking(env, serviceID, CargoDetails{Weight: 1})
+
+	env.Invoke(marketplaceAddress, providerAddress)
+	assert.False(t, c.marketplace.UpdateShipmentStatus(env, bookingID, "", "PICKED_UP", ""))
+}
+
+func TestMarketplaceCancellationRefund(t *testing.T) {
+	c := setupContracts(t)
+	env := c.env
+
+	bookingID, amount := setupPaidBooking(t, c)
+
+	env.Invoke(marketplaceAddress, providerAddress)
+	require.True(t, c.marketplace.UpdateShipmentStatus(env, bookingID, "", "PICKED_UP", ""))
+
+	// Strangers cannot cancel
+	env.Invoke(marketplaceAddress, voterAddress)
+	assert.False(t, c.marketplace.CancelBooking(env, bookingID))
+
+	env.Invoke(marketplaceAddress, customerAddress)
+	require.True(t, c.marketplace.CancelBooking(env, bookingID))
+
+	booking := c.marketplace.GetBooking(env, bookingID)
+	assert.Equal(t, "CANCELLED", booking.Status)
+	assert.Equal(t, "PARTIALLY_REFUNDED", booking.PaymentStatus)
+	assert.Equal(t, uint64(200), c.token.BalanceOf(providerAddress))
+	assert.Equal(t, uint64(1_000_000)-200, c.token.BalanceOf(customerAddress))
+	assert.Equal(t, providerStake, c.token.BalanceOf(marketplaceAddress))
+
+	cancelled, ok := env.Events().Last(events.TopicBookingCancelled)
+	require.True(t, ok)
+	assert.Equal(t, events.BookingCancelled{
+		Version:        events.SchemaVersion,
+		BookingID:      bookingID,
+		CancelledBy:    customerAddress,
+		RefundedAmount: amount - 200,
+	}, cancelled.Data)
+
+	// Cancelled bookings no longer accept tracking updates
+	env.Invoke(marketplaceAddress, providerAddress)
+	assert.False(t, c.marketplace.UpdateShipmentStatus(env, bookingID, "", "DEPARTED", ""))
+}
+
+func TestMarketplaceCancellationIsNotATrackingUpdate(t *testing.T) {
+	c := setupContracts(t)
+	env := c.env
+
+	bookingID, _ := setupPaidBooking(t, c)
+
+	env.Invoke(marketplaceAddress, providerAddress)
+	assert.False(t, c.marketplace.UpdateShipmentStatus(env, bookingID, "", "CANCELLED", ""))
+	assert.Equal(t, "CONFIRMED", c.marketplace.GetBooking(env, bookingID).Status)
+
+	// The customer can still cancel and recover the escrow
+	env.Invoke(marketplaceAddress, customerAddress)
+	require.True(t, c.marketplace.CancelBooking(env, bookingID))
+	assert.Equal(t, "REFUNDED", c.marketplace.GetBooking(env, bookingID).PaymentStatus)
+	assert.Equal(t, uint64(1_000_000), c.token.BalanceOf(customerAddress))
+}
+
+func TestMarketplaceInvalidPaymentSchedule(t *testing.T) {
+	c := setupContracts(t)
+	env := c.env
+
+	env.Invoke(marketplaceAddress, providerAddress)
+	serviceID := c.marketplace.CreateServiceListing(env, ServiceListing{Rate: 10})
+
+	env.Invoke(marketplaceAddress, customerAddress)
+	assert.Panics(t, func() {
+		c.marketplace.CreateBookingWithSchedule(env, serviceID, CargoDetails{}, []PaymentTranche{
+			{Milestone: "PICKED_UP", Percentage: 50},
+			{Milestone: "DELIVERED", Percentage: 40},
+		})
+	})
+	assert.Panics(t, func() {
+		c.marketplace.CreateBookingWithSchedule(env, serviceID, CargoDetails{}, []PaymentTranche{
+			{Milestone: "DELIVERED", Percentage: 50},
+			{Milestone: "DELIVERED", Percentage: 50},
+		})
+	})
+
+	for name, schedule := range map[string][]PaymentTranche{
+		"overflowing percentages": {
+			{Milestone: "PICKED_UP", Percentage: 4_294_967_295},
+			{Milestone: "DELIVERED", Percentage: 101},
+		},
+		"empty tranche": {
+			{Milestone: "PICKED_UP", Percentage: 0},
+			{Milestone: "DELIVERED", Percentage: 100},
+		},
+		"booking state milestone": {
+			{Milestone: "CANCELLED", Percentage: 50},
+			{Milestone: "DELIVERED", Percentage: 50},
+		},
+		"tranche after delivery": {
+			{Milestone: "DELIVERED", Percentage: 50},
+			{Milestone: "ARRIVED", Percentage: 50},
+		},
+	} {
+		assert.Panics(t, func() {
+			c.marketplace.CreateBookingWithSchedule(env, serviceID, CargoDetails{}, schedule)
+		}, name)
+	}
+}
+
+func TestProviderStakeRequiredForListing(t *testing.T) {
+	c := setupContracts(t)
+	env := c.env
+
+	// Customers have not staked
+	env.Invoke(marketplaceAddress, customerAddress)
+	assert.Panics(t, func() { c.marketplace.CreateServiceListing(env, ServiceListing{ServiceType: CustomsBrokerage}) })
+
+	env.Invoke(marketplaceAddress, providerAddress)
+	c.marketplace.CreateServiceListing(env, ServiceListing{ServiceType: CustomsBrokerage})
+
+	// Stake backing active listings cannot be unbonded
+	assert.Panics(t, func() { c.marketplace.Unstake(env, providerStake) })
+	require.True(t, c.marketplace.Unstake(env, providerStake-MinimumStake[CustomsBrokerage]))
+
+	stake := c.marketplace.GetStake(env, providerAddress)
+	assert.Equal(t, MinimumStake[CustomsBrokerage], stake.Bonded)
+	assert.Equal(t, MinimumStake[CustomsBrokerage], stake.RequiredStake())
+
+	// Shipping needs more than what is left bonded
+	assert.Panics(t, func() { c.marketplace.CreateServiceListing(env, ServiceListing{ServiceType: Shipping}) })
+}
+
+func TestDelistingReleasesRequiredStake(t *testing.T) {
+	c := setupContracts(t)
+	env := c.env
+
+	env.Invoke(marketplaceAddress, providerAddress)
+	listingID := c.marketplace.CreateServiceListing(env, ServiceListing{ServiceType: Shipping, Rate: 10})
+	assert.Equal(t, MinimumStake[Shipping], c.marketplace.GetStake(env, providerAddress).RequiredStake())
+
+	// Only the provider can delist
+	env.Invoke(marketplaceAddress, customerAddress)
+	assert.False(t, c.marketplace.DelistService(env, listingID))
+
+	env.Invoke(marketplaceAddress, providerAddress)
+	require.True(t, c.marketplace.DelistService(env, listingID))
+	assert.False(t, c.marketplace.DelistService(env, listingID))
+	assert.False(t, c.marketplace.GetServiceListing(env, listingID).IsActive)
+
+	stake := c.marketplace.GetStake(env, providerAddress)
+	assert.Equal(t, uint32(0), stake.Listings[Shipping])
+	assert.Equal(t, uint64(0), stake.RequiredStake())
+
+	delisted, ok := env.Events().Last(events.TopicServiceDelisted)
+	require.True(t, ok)
+	assert.Equal(t, events.ServiceDelisted{
+		Version:   events.SchemaVersion,
+		ListingID: listingID,
+		Provider:  providerAddress,
+	}, delisted.Data)
+
+	// Delisted services cannot be booked and their stake can be unbonded
+	env.Invoke(marketplaceAddress, customerAddress)
+	assert.Panics(t, func() { c.marketplace.CreateBooking(env, listingID, CargoDetails{Weight: 1}) })
+
+	env.Invoke(marketplaceAddress, providerAddress)
+	require.True(t, c.marketplace.Unstake(env, providerStake))
+	env.AdvanceTime(UnbondingPeriod)
+	assert.Equal(t, providerStake, c.marketplace.WithdrawUnbonded(env))
+}
+
+func TestProviderUnbonding(t *testing.T) {
+	c := setupContracts(t)
+	env := c.env
+
+	env.Invoke(marketplaceAddress, providerAddress)
+	require.True(t, c.marketplace.Unstake(env, 40_000))
+
+	// Unbonding stake is only released after the unbonding period
+	env.AdvanceTime(UnbondingPeriod - 1)
+	assert.Equal(t, uint64(0), c.marketplace.WithdrawUnbonded(env))
+
+	env.AdvanceTime(1)
+	assert.Equal(t, uint64(40_000), c.marketplace.WithdrawUnbonded(env))
+	assert.Equal(t, uint64(40_000), c.token.BalanceOf(providerAddress))
+	assert.Empty(t, c.marketplace.GetStake(env, providerAddress).Unbonding)
+}
+
+func TestLateDeliverySlashesStake(t *testing.T) {
+	c := setupContracts(t)
+	env := c.env
+
+	bookingID, amount := setupPaidBooking(t, c)
+
+	env.Invoke(marketplaceAddress, providerAddress)
+	require.True(t, c.marketplace.ConfirmBookingSchedule(env, bookingID, ConfirmedSchedule{
+		Pickup:    genesisTime + 3600,
+		Departure: genesisTime + 2*3600,
+		Arrival:   genesisTime + 3*3600,
+		Delivery:  genesisTime + 4*3600,
+	}))
+
+	// The confirmed delivery date cannot be pushed back
+	assert.False(t, c.marketplace.ConfirmBookingSchedule(env, bookingID, ConfirmedSchedule{
+		Pickup:    genesisTime + 3600,
+		Departure: genesisTime + 2*3600,
+		Arrival:   genesisTime + 3*3600,
+		Delivery:  genesisTime + 4*3600 + 3*DeliveryGracePeriod,
+	}))
+
+	// Delivered two days late
+	require.True(t, c.marketplace.UpdateShipmentStatus(env, bookingID, "", "PICKED_UP", ""))
+	require.True(t, c.marketplace.UpdateShipmentStatus(env, bookingID, "", "DEPARTED", ""))
+	env.SetTime(genesisTime + 4*3600 + DeliveryGracePeriod + 1)
+	require.True(t, c.marketplace.UpdateShipmentStatus(env, bookingID, "", "DELIVERED", ""))
+
+	slashed := amount * LateDeliverySlashPercent / 100
+	stake := c.marketplace.GetStake(env, providerAddress)
+	assert.Equal(t, providerStake-slashed, stake.Bonded)
+	assert.Equal(t, slashed, stake.Slashed)
+	assert.Equal(t, uint64(1_000_000)-amount+slashed, c.token.BalanceOf(customerAddress))
+
+	slash, ok := env.Events().Last(events.TopicStakeSlashed)
+	require.True(t, ok)
+	assert.Equal(t, "LATE_DELIVERY", slash.Data.(events.StakeSlashed).Reason)
+
+	// Delivery is final, so it cannot be reported and slashed twice
+	assert.False(t, c.marketplace.UpdateShipmentStatus(env, bookingID, "", "DELIVERED", ""))
+	assert.Len(t, env.Events().Named(events.TopicStakeSlashed), 1)
+}
+
+func TestConfirmedScheduleStartsInFuture(t *testing.T) {
+	c := setupContracts(t)
+	env := c.env
+
+	bookingID, _ := setupPaidBooking(t, c)
+
+	env.Invoke(marketplaceAddress, providerAddress)
+	assert.PanicsWithValue(t, "Confirmed schedule is in the past", func() {
+		c.marketplace.ConfirmBookingSchedule(env, bookingID, ConfirmedSchedule{
+			Pickup:    genesisTime - 3600,
+			Departure: genesisTime,
+			Arrival:   genesisTime + 3600,
+			Delivery:  genesisTime + 2*3600,
+		})
+	})
+	assert.Zero(t, c.marketplace.GetBooking(env, bookingID).ConfirmedSchedule.Delivery)
+}
+
+func TestDisputeRequiresFundedEscrow(t *testing.T) {
+	c := setupContracts(t)
+	env := c.env
+
+	env.Invoke(marketplaceAddress, providerAddress)
+	serviceID := c.marketplace.CreateServiceListing(env, ServiceListing{Rate: 100})
+
+	env.Invoke(marketplaceAddress, customerAddress)
+	bookingID := c.marketplace.CreateBooking(env, serviceID, CargoDetails{Weight: 1})
+	assert.False(t, c.marketplace.RaiseDispute(env, bookingID, "Cargo lost"))
+	assert.Nil(t, c.marketplace.GetDispute(env, bookingID))
+
+	stake := c.marketplace.GetStake(env, providerAddress)
+	assert.Zero(t, stake.OpenDisputes)
+	assert.Zero(t, stake.LockedUntil)
+}
+
+func TestDisputeSlashesUnbondingStake(t *testing.T) {
+	c := setupContracts(t)
+	env := c.env
+
+	bookingID, amount := setupPaidBooking(t, c)
+
+	// The provider tries to leave with all stake not backing its listing
+	env.Invoke(marketplaceAddress, providerAddress)
+	unbonded := providerStake - MinimumStake[FreightForwarding]
+	require.True(t, c.marketplace.Unstake(env, unbonded))
+
+	env.Invoke(marketplaceAddress, customerAddress)
+	require.True(t, c.marketplace.RaiseDispute(env, bookingID, "Cargo damaged"))
+
+	// Open disputes lock unbonded stake
+	env.AdvanceTime(UnbondingPeriod)
+	env.Invoke(marketplaceAddress, providerAddress)
+	assert.Panics(t, func() { c.marketplace.WithdrawUnbonded(env) })
+
+	// Only the arbiter can rule
+	assert.Panics(t, func() { c.marketplace.ResolveDispute(env, bookingID, true) })
+
+	env.Invoke(marketplaceAddress, adminAddress)
+	require.True(t, c.marketplace.ResolveDispute(env, bookingID, true))
+	assert.Equal(t, "UPHELD", c.marketplace.GetDispute(env, bookingID).Status)
+
+	slashed := amount * DisputeSlashPercent / 100
+	assert.Equal(t, uint64(1_000_000)-amount+slashed, c.token.BalanceOf(customerAddress))
+	assert.Equal(t, MinimumStake[FreightForwarding]-slashed, c.marketplace.GetStake(env, providerAddress).Bonded)
+
+	env.Invoke(marketplaceAddress, providerAddress)
+	assert.Equal(t, unbonded, c.marketplace.WithdrawUnbonded(env))
+}
+
+func TestMarketplaceStorageTTL(t *testing.T) {
+	c := setupContracts(t)
+	env := c.env
+
+	env.Invoke(marketplaceAddress, providerAddress)
+	serviceID := c.marketplace.CreateServiceListing(env, ServiceListing{Rate: 10})
+
+	key := DataKey{Kind: ListingKey, ID: serviceID}
+	assert.Equal(t, uint32(StorageTTLExtendTo), env.Storage().Persistent().TTL(key))
+
+	// Reading a listing close to expiry extends it again
+	env.AdvanceTime(int64(StorageTTLExtendTo-StorageTTLThreshold+1) * sorobantest.LedgerCloseTime)
+	require.NotNil(t, c.marketplace.GetServiceListing(env, serviceID))
+	assert.Equal(t, uint32(StorageTTLExtendTo), env.Storage().Persistent().TTL(key))
+
+	// Listings nobody touches are archived
+	env.AdvanceTime(int64(StorageTTLExtendTo+1) * sorobantest.LedgerCloseTime)
+	assert.Panics(t, func() { c.marketplace.GetServiceListing(env, serviceID) })
+}
+
+func TestAllowanceExpiry(t *testing.T) {
+	c := setupContracts(t)
+	env := c.env
+
+	env.Invoke(tokenAddress, customerAddress)
+	require.True(t, c.token.Approve(env, customerAddress, marketplaceAddress, 100, genesisTime+60))
+	assert.Equal(t, uint64(100), c.token.Allowance(env, customerAddress, marketplaceAddress))
+
+	env.AdvanceTime(120)
+	assert.Equal(t, uint64(0), c.token.Allowance(env, customerAddress, marketplaceAddress))
+
+	env.Invoke(marketplaceAddress, customerAddress)
+	assert.False(t, c.token.TransferFrom(env, marketplaceAddress, customerAddress, providerAddress, 100))
+}
+
+func TestMintAndBurn(t *testing.T) {
+	c := setupContracts(t)
+	env := c.env
+
+	env.Invoke(tokenAddress, customerAddress)
+	assert.Panics(t, func() { c.token.Mint(env, customerAddress, 1) })
+
+	env.Invoke(tokenAddress, adminAddress)
+	require.True(t, c.token.Mint(env, customerAddress, 500))
+	assert.Equal(t, uint64(100_000_500), c.token.TotalSupply())
+	assert.Panics(t, func() { c.token.Mint(env, customerAddress, MaxSupply) })
+
+	env.Invoke(tokenAddress, customerAddress)
+	require.True(t, c.token.Burn(env, customerAddress, 1_000_500))
+	assert.Equal(t, uint64(0), c.token.BalanceOf(customerAddress))
+	assert.Equal(t, uint64(99_000_000), c.token.TotalSupply())
+
+	assert.Len(t, env.Events().Named("mint"), 1)
+	assert.Len(t, env.Events().Named("burn"), 1)
+}
+
+func TestGovernanceProposalScenario(t *testing.T) {
+	c := setupContracts(t)
+	env := c.env
+
+	data, err := json.Marshal(FundsAllocationData{
+		Kind:     SetBudget,
+		Category: "DEVELOPMENT",
+		Budget:   5_000_000,
+	})
+	require.NoError(t, err)
+
+	// Voter proposes and votes
+	env.Invoke(governanceAddress, voterAddress)
+	proposalID := c.governance.CreateProposal(env, voterAddress, "Fund development", "Development budget", FundsAllocation, data)
+	require.NotEmpty(t, proposalID)
+
+	env.AdvanceTime(3600)
+	require.True(t, c.governance.CastVote(env, voterAddress, proposalID, For))
+
+	// Voting is still open
+	assert.Panics(t, func() { c.governance.FinalizeProposal(env, proposalID) })
+
+	env.AdvanceTime(VotingPeriod)
+	assert.Equal(t, "PASSED", c.governance.FinalizeProposal(env, proposalID))
+
+	// Execution waits for the delay
+	assert.Panics(t, func() { c.governance.ExecuteProposal(env, proposalID) })
+
+	env.AdvanceTime(ExecutionDelay)
+	require.True(t, c.governance.ExecuteProposal(env, proposalID))
+
+	proposal := c.governance.GetProposal(proposalID)
+	assert.True(t, proposal.Executed)
+	assert.Equal(t, "EXECUTED", proposal.Status)
+	assert.Equal(t, uint64(5_000_000), c.governance.GetBudgetCategory("DEVELOPMENT").Budget)
+
+	var names []string
+	for _, event := range env.Events().All() {
+		names = append(names, event.Name)
+	}
+	assert.Equal(t, []string{
+		"proposal_created",
+		"vote_cast",
+		"proposal_finalized",
+		"treasury_budget_set",
+		"proposal_executed",
+	}, names)
+}
+
+func TestGovernanceProposalFailsQuorum(t *testing.T) {
+	c := setupContracts(t)
+	env := c.env
+
+	// The customer holds 1% of the supply, below the 4% quorum
+	env.Invoke(governanceAddress, voterAddress)
+	proposalID := c.governance.CreateProposal(env, voterAddress, "Update services", "", ServiceUpdate, nil)
+
+	env.Invoke(governanceAddress, customerAddress)
+	require.True(t, c.governance.CastVote(env, customerAddress, proposalID, For))
+
+	env.AdvanceTime(VotingPeriod + 1)
+	assert.Equal(t, "FAILED_QUORUM", c.governance.FinalizeProposal(env, proposalID))
+
+	env.AdvanceTime(ExecutionDelay)
+	assert.False(t, c.governance.ExecuteProposal(env, proposalID))
+}
+
+func TestTreasuryDeposit(t *testing.T) {
+	c := setupContracts(t)
+	env := c.env
+
+	env.Invoke(governanceAddress, voterAddress)
+	require.True(t, c.governance.DepositToTreasury(env, voterAddress, 250_000))
+
+	assert.Equal(t, uint64(250_000), c.governance.TreasuryBalance(env))
+	assert.Equal(t, uint64(9_750_000), c.token.BalanceOf(voterAddress))
+	deposits := env.Events().Named("treasury_deposit")
+	require.Len(t, deposits, 1)
+	assert.Equal(t, map[string]interface{}{
+		"from":   voterAddress,
+		"amount": uint64(250_000),
+	}, deposits[0].Data)
+
+	// Only the holder can deposit its tokens
+	env.Invoke(governanceAddress, customerAddress)
+	assert.PanicsWithValue(t, "Unauthorized depositor", func() {
+		c.governance.DepositToTreasury(env, voterAddress, 1)
+	})
+
+	// A deposit above the balance moves nothing
+	assert.False(t, c.governance.DepositToTreasury(env, customerAddress, 2_000_000))
+	assert.Equal(t, uint64(250_000), c.governance.TreasuryBalance(env))
+	assert.Equal(t, uint64(1_000_000), c.token.BalanceOf(customerAddress))
+}
+
+func TestContractsResolveTokenByAddress(t *testing.T) {
+	c := setupContracts(t)
+	env := c.env
+
+	// The contracts use the token deployed at the configured address
+	assert.Same(t, c.token, c.marketplace.tokenContract)
+	assert.Same(t, c.token, c.governance.token)
+
+	env.Invoke("CUNKNOWN", adminAddress)
+	assert.PanicsWithValue(t, "Token contract not deployed", func() {
+		(&GovernanceContract{}).Initialize(env, "CMISSING")
+	})
+}
+
+func TestTokenContractBalanceIsNotSpendable(t *testing.T) {
+	c := setupContracts(t)
+	env := c.env
+
+	env.Invoke(tokenAddress, voterAddress)
+	require.True(t, c.token.Transfer(env, voterAddress, tokenAddress, 100))
+
+	// Nobody authorizes for the token contract's own address
+	env.Invoke(tokenAddress, customerAddress)
+	assert.PanicsWithValue(t, "Unauthorized", func() {
+		c.token.Transfer(env, tokenAddress, customerAddress, 100)
+	})
+
+	// A contract moves its own funds only while its code runs
+	env.Invoke(marketplaceAddress, customerAddress)
+	require.True(t, c.token.Transfer(env, customerAddress, marketplaceAddress, 10))
+	env.Invoke(tokenAddress, customerAddress)
+	assert.PanicsWithValue(t, "Unauthorized", func() {
+		c.token.Transfer(env, marketplaceAddress, customerAddress, 10)
+	})
+}
+
+func TestUnresolvedDisputeLockLapses(t *testing.T) {
+	c := setupContracts(t)
+	env := c.env
+
+	bookingID, _ := setupPaidBooking(t, c)
+
+	env.Invoke(marketplaceAddress, providerAddress)
+	unbonded := providerStake - MinimumStake[FreightForwarding]
+	require.True(t, c.marketplace.Unstake(env, unbonded))
+
+	env.Invoke(marketplaceAddress, customerAddress)
+	require.True(t, c.marketplace.RaiseDispute(env, bookingID, "Cargo damaged"))
+
+	// The arbiter rejects the claim, and the booking cannot be disputed again
+	env.Invoke(marketplaceAddress, adminAddress)
+	require.True(t, c.marketplace.ResolveDispute(env, bookingID, false))
+	env.Invoke(marketplaceAddress, customerAddress)
+	assert.False(t, c.marketplace.RaiseDispute(env, bookingID, "Cargo damaged"))
+
+	// A second booking is disputed and never resolved
+	secondID, _ := setupPaidBooking(t, c)
+	env.Invoke(marketplaceAddress, customerAddress)
+	require.True(t, c.marketplace.RaiseDispute(env, secondID, "Cargo lost"))
+
+	env.Invoke(marketplaceAddress, providerAddress)
+	env.AdvanceTime(DisputeLockPeriod - 1)
+	assert.Panics(t, func() { c.marketplace.WithdrawUnbonded(env) })
+
+	env.AdvanceTime(1)
+	assert.Equal(t, unbonded, c.marketplace.WithdrawUnbonded(env))
+	assert.Equal(t, uint32(1), c.marketplace.GetStake(env, providerAddress).OpenDisputes)
+}
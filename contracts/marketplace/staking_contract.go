@@ -0,0 +1,399 @@
+package marketplace
+
+import (
+	"github.com/stellar/soroban-sdk/go/soroban"
+
+	"logistics-marketplace/contracts/marketplace/events"
+)
+
+// Staking and SLA parameters
+const (
+	UnbondingPeriod          = 14 * 24 * 3600 // 14 days in seconds
+	DeliveryGracePeriod      = 24 * 3600      // 24 hours in seconds
+	LateDeliverySlashPercent = 10             // Of the booking value
+	DisputeSlashPercent      = 25             // Of the booking value
+	DisputeLockPeriod        = 30 * 24 * 3600 // 30 days in seconds
+)
+
+// MinimumStake is the stake, in LMT, a provider must bond to list a service of each type
+var MinimumStake = map[ServiceType]uint64{
+	FreightForwarding: 50_000,
+	CustomsBrokerage:  25_000,
+	Shipping:          100_000,
+	Transshipment:     75_000,
+}
+
+// ConfirmedSchedule is the schedule a provider commits to when confirming a booking
+type ConfirmedSchedule struct {
+	Pickup    int64
+	Departure int64
+	Arrival   int64
+	Delivery  int64
+}
+
+// UnbondingEntry is stake waiting out the unbonding period before withdrawal
+type UnbondingEntry struct {
+	Amount    uint64
+	ReleaseAt int64
+}
+
+// ProviderStake tracks the LMT a provider has bonded to back its listings
+type ProviderStake struct {
+	Provider     string
+	Bonded       uint64
+	Unbonding    []UnbondingEntry
+	Slashed      uint64
+	Listings     map[ServiceType]uint32 // Active listings per service type
+	OpenDisputes uint32
+	LockedUntil  int64 // Open disputes block withdrawal until this time
+}
+
+// Dispute is a customer claim against a provider for a booking
+type Dispute struct {
+	BookingID  string
+	Customer   string
+	Provider   string
+	Reason     string
+	Status     string // OPEN, UPHELD, REJECTED
+	RaisedAt   int64
+	ResolvedAt int64
+}
+
+// RequiredStake returns the stake a provider needs for its active listings
+func (s ProviderStake) RequiredStake() uint64 {
+	var required uint64
+	for serviceType, count := range s.Listings {
+		if count > 0 && MinimumStake[serviceType] > required {
+			required = MinimumStake[serviceType]
+		}
+	}
+	return required
+}
+
+// Stake bonds LMT from the calling provider
+func (c *MarketplaceContract) Stake(env soroban.Env, amount uint64) bool {
+	provider := env.Current().Auth().Address().String()
+	marketplace := env.Current().Contract().Address().String()
+
+	if !c.tokenContract.Transfer(env, provider, marketplace, amount) {
+		return false
+	}
+
+	stake := c.getStake(env, provider)
+	stake.Bonded += amount
+	c.putStake(env, stake)
+
+	env.Events().Publish(events.TopicStakeBonded, events.StakeBonded{
+		Version:  events.SchemaVersion,
+		Provider: provider,
+		Amount:   amount,
+		Bonded:   stake.Bonded,
+	})
+
+	return true
+}
+
+// Unstake starts unbonding stake; it stays slashable until withdrawn
+func (c *MarketplaceContract) Unstake(env soroban.Env, amount uint64) bool {
+	provider := env.Current().Auth().Address().String()
+
+	stake := c.getStake(env, provider)
+	if amount == 0 || amount > stake.Bonded {
+		return false
+	}
+
+	if stake.Bonded-amount < stake.RequiredStake() {
+		panic("Stake would fall below minimum for active listings")
+	}
+
+	releaseAt := env.Ledger().Timestamp() + UnbondingPeriod
+	stake.Bonded -= amount
+	stake.Unbonding = append(stake.Unbonding, UnbondingEntry{
+		Amount:    amount,
+		ReleaseAt: releaseAt,
+	})
+	c.putStake(env, stake)
+
+	env.Events().Publish(events.TopicStakeUnbonding, events.StakeUnbonding{
+		Version:   events.SchemaVersion,
+		Provider:  provider,
+		Amount:    amount,
+		ReleaseAt: releaseAt,
+	})
+
+	return true
+}
+
+// WithdrawUnbonded pays out unbonded stake whose unbonding period has ended.
+// Open disputes hold it back for at most DisputeLockPeriod after the latest
+// was raised; stake withdrawn after that can no longer be slashed
+func (c *MarketplaceContract) WithdrawUnbonded(env soroban.Env) uint64 {
+	provider := env.Current().Auth().Address().String()
+
+	stake := c.getStake(env, provider)
+	now := env.Ledger().Timestamp()
+	if stake.OpenDisputes > 0 && now < stake.LockedUntil {
+		panic("Stake is locked by open disputes")
+	}
+
+	var amount uint64
+	pending := make([]UnbondingEntry, 0, len(stake.Unbonding))
+	for _, entry := range stake.Unbonding {
+		if entry.ReleaseAt <= now {
+			amount += entry.Amount
+		} else {
+			pending = append(pending, entry)
+		}
+	}
+
+	if amount == 0 {
+		return 0
+	}
+
+	marketplace := env.Current().Contract().Address().String()
+	if !c.tokenContract.Transfer(env, marketplace, provider, amount) {
+		panic("Insufficient stake balance")
+	}
+
+	stake.Unbonding = pending
+	c.putStake(env, stake)
+
+	env.Events().Publish(events.TopicStakeWithdrawn, events.StakeWithdrawn{
+		Version:  events.SchemaVersion,
+		Provider: provider,
+		Amount:   amount,
+	})
+
+	return amount
+}
+
+// GetStake retrieves the stake of a provider
+func (c *MarketplaceContract) GetStake(env soroban.Env, provider string) ProviderStake {
+	return c.getStake(env, provider)
+}
+
+// ConfirmBookingSchedule records the schedule the provider commits to for a
+// booking. The schedule is the SLA deliveries are measured against, so it can
+// be confirmed only once.
+func (c *MarketplaceContract) ConfirmBookingSchedule(env soroban.Env, bookingID string, schedule ConfirmedSchedule) bool {
+	booking, exists := c.getBooking(env, bookingID)
+	if !exists {
+		return false
+	}
+
+	if env.Current().Auth().Address().String() != booking.Provider {
+		return false
+	}
+
+	if booking.DeliveredAt != 0 || booking.Status == "CANCELLED" || booking.ConfirmedSchedule.Delivery != 0 {
+		return false
+	}
+
+	if schedule.Delivery < schedule.Arrival || schedule.Arrival < schedule.Departure || schedule.Departure < schedule.Pickup {
+		panic("Confirmed schedule is out of order")
+	}
+	if schedule.Pickup < env.Ledger().Timestamp() {
+		panic("Confirmed schedule is in the past")
+	}
+
+	booking.ConfirmedSchedule = schedule
+	c.putBooking(env, booking)
+
+	return true
+}
+
+// RaiseDispute opens a dispute against the provider of a booking
+func (c *MarketplaceContract) RaiseDispute(env soroban.Env, bookingID string, reason string) bool {
+	booking, exists := c.getBooking(env, bookingID)
+	if !exists {
+		return false
+	}
+
+	customer := env.Current().Auth().Address().String()
+	if customer != booking.Customer {
+		return false
+	}
+
+	// Only funded bookings can be disputed, so the provider's stake cannot be
+	// locked with free bookings
+	if booking.EscrowedAmount == 0 {
+		return false
+	}
+
+	// A booking can be disputed once
+	if _, exists := c.getDispute(env, bookingID); exists {
+		return false
+	}
+
+	now := env.Ledger().Timestamp()
+	c.putDispute(env, Dispute{
+		BookingID: bookingID,
+		Customer:  booking.Customer,
+		Provider:  booking.Provider,
+		Reason:    reason,
+		Status:    "OPEN",
+		RaisedAt:  now,
+	})
+
+	stake := c.getStake(env, booking.Provider)
+	stake.OpenDisputes++
+	if lockedUntil := now + DisputeLockPeriod; lockedUntil > stake.LockedUntil {
+		stake.LockedUntil = lockedUntil
+	}
+	c.putStake(env, stake)
+
+	env.Events().Publish(events.TopicDisputeRaised, events.DisputeRaised{
+		Version:   events.SchemaVersion,
+		BookingID: bookingID,
+		Customer:  booking.Customer,
+		Provider:  booking.Provider,
+		Reason:    reason,
+	})
+
+	return true
+}
+
+// ResolveDispute rules on an open dispute, slashing the provider if it is upheld
+func (c *MarketplaceContract) ResolveDispute(env soroban.Env, bookingID string, upheld bool) bool {
+	if env.Current().Auth().Address().String() != c.admin {
+		panic("Only the arbiter can resolve disputes")
+	}
+
+	dispute, exists := c.getDispute(env, bookingID)
+	if !exists || dispute.Status != "OPEN" {
+		return false
+	}
+
+	dispute.Status = "REJECTED"
+	if upheld {
+		dispute.Status = "UPHELD"
+	}
+	dispute.ResolvedAt = env.Ledger().Timestamp()
+	c.putDispute(env, dispute)
+
+	stake := c.getStake(env, dispute.Provider)
+	stake.OpenDisputes--
+	c.putStake(env, stake)
+
+	if upheld {
+		booking, _ := c.getBooking(env, bookingID)
+		c.slash(env, booking, DisputeSlashPercent, "DISPUTE")
+	}
+
+	return true
+}
+
+// GetDispute retrieves the dispute raised for a booking
+func (c *MarketplaceContract) GetDispute(env soroban.Env, bookingID string) *Dispute {
+	dispute, exists := c.getDispute(env, bookingID)
+	if !exists {
+		return nil
+	}
+	return &dispute
+}
+
+// checkDeliverySLA slashes the provider when a booking is delivered after the
+// confirmed delivery date plus the grace period
+func (c *MarketplaceContract) checkDeliverySLA(env soroban.Env, booking Booking) {
+	if booking.ConfirmedSchedule.Delivery == 0 {
+		return
+	}
+
+	if booking.DeliveredAt <= booking.ConfirmedSchedule.Delivery+DeliveryGracePeriod {
+		return
+	}
+
+	c.slash(env, booking, LateDeliverySlashPercent, "LATE_DELIVERY")
+}
+
+// slash moves a percentage of the booking value out of the provider's stake to
+// the customer, taking bonded stake first and then unbonding stake
+func (c *MarketplaceContract) slash(env soroban.Env, booking Booking, percent uint64, reason string) uint64 {
+	stake := c.getStake(env, booking.Provider)
+
+	amount := booking.EscrowedAmount * percent / 100
+	if amount == 0 {
+		return 0
+	}
+
+	remaining := amount
+	if remaining <= stake.Bonded {
+		stake.Bonded -= remaining
+		remaining = 0
+	} else {
+		remaining -= stake.Bonded
+		stake.Bonded = 0
+	}
+
+	for i := len(stake.Unbonding) - 1; i >= 0 && remaining > 0; i-- {
+		entry := &stake.Unbonding[i]
+		if remaining <= entry.Amount {
+			entry.Amount -= remaining
+			remaining = 0
+		} else {
+			remaining -= entry.Amount
+			entry.Amount = 0
+		}
+	}
+
+	amount -= remaining
+	if amount == 0 {
+		return 0
+	}
+
+	marketplace := env.Current().Contract().Address().String()
+	if !c.tokenContract.Transfer(env, marketplace, booking.Customer, amount) {
+		panic("Insufficient stake balance")
+	}
+
+	stake.Slashed += amount
+	c.putStake(env, stake)
+
+	env.Events().Publish(events.TopicStakeSlashed, events.StakeSlashed{
+		Version:   events.SchemaVersion,
+		BookingID: booking.ID,
+		Provider:  booking.Provider,
+		Customer:  booking.Customer,
+		Reason:    reason,
+		Amount:    amount,
+	})
+
+	return amount
+}
+
+// Storage helpers
+
+func (c *MarketplaceContract) getStake(env soroban.Env, provider string) ProviderStake {
+	key := DataKey{Kind: StakeKey, ID: provider}
+	value, exists := env.Storage().Persistent().Get(key)
+	if !exists {
+		return ProviderStake{
+			Provider: provider,
+			Listings: make(map[ServiceType]uint32),
+		}
+	}
+	env.Storage().Persistent().ExtendTTL(key, StorageTTLThreshold, StorageTTLExtendTo)
+	return value.(ProviderStake)
+}
+
+func (c *MarketplaceContract) putStake(env soroban.Env, stake ProviderStake) {
+	key := DataKey{Kind: StakeKey, ID: stake.Provider}
+	env.Storage().Persistent().Set(key, stake)
+	env.Storage().Persistent().ExtendTTL(key, StorageTTLThreshold, StorageTTLExtendTo)
+}
+
+func (c *MarketplaceContract) getDispute(env soroban.Env, bookingID string) (Dispute, bool) {
+	key := DataKey{Kind: DisputeKey, ID: bookingID}
+	value, exists := env.Storage().Persistent().Get(key)
+	if !exists {
+		return Dispute{}, false
+	}
+	env.Storage().Persistent().ExtendTTL(key, StorageTTLThreshold, StorageTTLExtendTo)
+	return value.(Dispute), true
+}
+
+func (c *MarketplaceContract) putDispute(env soroban.Env, dispute Dispute) {
+	key := DataKey{Kind: DisputeKey, ID: dispute.BookingID}
+	env.Storage().Persistent().Set(key, dispute)
+	env.Storage().Persistent().ExtendTTL(key, StorageTTLThreshold, StorageTTLExtendTo)
+}
@@ -1,6 +1,8 @@
 package marketplace
 
 import (
+	"sync"
+
 	"github.com/stellar/soroban-sdk/go/soroban"
 )
 
@@ -11,24 +13,61 @@ const (
 	TokenDecimals = 7
 )
 
+// Allowance represents an amount a spender may transfer on behalf of an owner
+type Allowance struct {
+	Amount    uint64
+	ExpiresAt int64 // Ledger timestamp after which the allowance is void
+}
+
+// deployedTokens maps contract addresses to the token contracts initialized
+// at them, so contracts can call the token they were configured with
+var deployedTokens sync.Map
+
+// TokenClient returns the token contract deployed at address
+func TokenClient(address string) *TokenContract {
+	token, ok := deployedTokens.Load(address)
+	if !ok {
+		panic("Token contract not deployed")
+	}
+	return token.(*TokenContract)
+}
+
 type TokenContract struct {
 	soroban.Contract
-	token    soroban.Token
-	balances map[string]uint64
+	token       soroban.Token
+	address     string
+	admin       string
+	totalSupply uint64
+	balances    map[string]uint64
+	allowances  map[string]map[string]Allowance // owner -> spender -> Allowance
 }
 
-func (c *TokenContract) Initialize(env soroban.Env) {
-	if c.token != nil {
+func (c *TokenContract) Initialize(env soroban.Env, admin string, initialSupply uint64) {
+	if c.balances != nil {
 		panic("Contract already initialized")
 	}
 
+	if initialSupply > MaxSupply {
+		panic("Initial supply exceeds max supply")
+	}
+
 	// Create the token with initial supply
 	c.token = env.Token()
+	c.address = env.Current().Contract().Address().String()
+	c.admin = admin
 	c.balances = make(map[string]uint64)
-	
-	// Mint initial supply to contract creator
-	admin := env.Current().Contract().Address()
-	c.balances[admin.String()] = MaxSupply
+	c.allowances = make(map[string]map[string]Allowance)
+
+	// Mint initial supply to the admin
+	c.balances[admin] = initialSupply
+	c.totalSupply = initialSupply
+
+	env.Events().Publish("mint", map[string]interface{}{
+		"admin":  admin,
+		"to":     admin,
+		"amount": initialSupply,
+	})
+	deployedTokens.Store(c.address, c)
 }
 
 func (c *TokenContract) Name() string {
@@ -44,7 +83,11 @@ func (c *TokenContract) Decimals() uint32 {
 }
 
 func (c *TokenContract) TotalSupply() uint64 {
-	return MaxSupply
+	return c.totalSupply
+}
+
+func (c *TokenContract) Admin() string {
+	return c.admin
 }
 
 func (c *TokenContract) BalanceOf(owner string) uint64 {
@@ -56,21 +99,114 @@ func (c *TokenContract) BalanceOf(owner string) uint64 {
 }
 
 func (c *TokenContract) Transfer(env soroban.Env, from string, to string, amount uint64) bool {
+	c.requireAuth(env, from)
+
+	if !c.move(from, to, amount) {
+		return false
+	}
+
+	// Emit transfer event
+	env.Events().Publish("transfer", map[string]interface{}{
+		"from":   from,
+		"to":     to,
+		"amount": amount,
+	})
+
+	return true
+}
+
+// Approve lets a spender transfer up to amount of the owner's tokens until expiresAt
+func (c *TokenContract) Approve(env soroban.Env, owner string, spender string, amount uint64, expiresAt int64) bool {
+	c.requireAuth(env, owner)
+
+	if amount > 0 && expiresAt <= env.Ledger().Timestamp() {
+		panic("Allowance expiry must be in the future")
+	}
+
+	if c.allowances[owner] == nil {
+		c.allowances[owner] = make(map[string]Allowance)
+	}
+
+	// A zero amount revokes the allowance
 	if amount == 0 {
+		delete(c.allowances[owner], spender)
+	} else {
+		c.allowances[owner][spender] = Allowance{
+			Amount:    amount,
+			ExpiresAt: expiresAt,
+		}
+	}
+
+	// Emit approval event
+	env.Events().Publish("approve", map[string]interface{}{
+		"from":       owner,
+		"spender":    spender,
+		"amount":     amount,
+		"expires_at": expiresAt,
+	})
+
+	return true
+}
+
+// Allowance returns how much a spender may still transfer on behalf of an owner
+func (c *TokenContract) Allowance(env soroban.Env, owner string, spender string) uint64 {
+	allowance, exists := c.allowances[owner][spender]
+	if !exists || allowance.ExpiresAt < env.Ledger().Timestamp() {
+		return 0
+	}
+	return allowance.Amount
+}
+
+// TransferFrom moves tokens on behalf of an owner using the spender's allowance
+func (c *TokenContract) TransferFrom(env soroban.Env, spender string, from string, to string, amount uint64) bool {
+	c.requireAuth(env, spender)
+
+	available := c.Allowance(env, from, spender)
+	if available < amount {
 		return false
 	}
 
-	fromBalance := c.BalanceOf(from)
-	if fromBalance < amount {
+	if !c.move(from, to, amount) {
 		return false
 	}
 
-	c.balances[from] = fromBalance - amount
-	c.balances[to] += amount
+	if available == amount {
+		delete(c.allowances[from], spender)
+	} else {
+		allowance := c.allowances[from][spender]
+		allowance.Amount = available - amount
+		c.allowances[from][spender] = allowance
+	}
 
 	// Emit transfer event
 	env.Events().Publish("transfer", map[string]interface{}{
-		"from":   from,
+		"spender": spender,
+		"from":    from,
+		"to":      to,
+		"amount":  amount,
+	})
+
+	return true
+}
+
+// Mint creates new tokens, only callable by the admin and capped by MaxSupply
+func (c *TokenContract) Mint(env soroban.Env, to string, amount uint64) bool {
+	c.requireAuth(env, c.admin)
+
+	if amount == 0 {
+		return false
+	}
+
+	if amount > MaxSupply-c.totalSupply {
+		panic("Mint exceeds max supply")
+	}
+
+	c.balances[to] += amount
+	c.totalSupply += amount
+
+	// Emit mint event
+	env.Events().Publish("mint", map[string]interface{}{
+		"admin":  c.admin,
 		"to":     to,
 		"amount": amount,
 	})
@@ -78,8 +214,59 @@ func (c *TokenContract) Transfer(env soroban.Env, from string, to string, amount
 	return true
 }
 
-func (c *TokenContract) Approve(env soroban.Env, owner string, spender string, amount uint64) bool {
-	// Implementation for token approval
-	// This would allow marketplace contract to transfer tokens on behalf of users
+// Burn destroys tokens held by the caller
+func (c *TokenContract) Burn(env soroban.Env, from string, amount uint64) bool {
+	c.requireAuth(env, from)
+
+	if amount == 0 {
+		return false
+	}
+
+	balance := c.BalanceOf(from)
+	if balance < amount {
+		return false
+	}
+
+	c.balances[from] = balance - amount
+	c.totalSupply -= amount
+
+	// Emit burn event
+	env.Events().Publish("burn", map[string]interface{}{
+		"from":   from,
+		"amount": amount,
+	})
+
+	return true
+}
+
+// requireAuth panics unless the invoker is the given address or the
+// calling contract moves its own funds. The marketplace escrow and the
+// governance treasury are held at contract addresses, which have no key to
+// authorize an invocation with; only the code of the contract owning them
+// runs with its address as the current contract. Tokens held at the token
+// contract's own address are never spendable.
+func (c *TokenContract) requireAuth(env soroban.Env, address string) {
+	if env.Current().Auth().Address().String() == address {
+		return
+	}
+	if caller := env.Current().Contract().Address().String(); caller == address && caller != c.address {
+		return
+	}
+	panic("Unauthorized")
+}
+
+func (c *TokenContract) move(from string, to string, amount uint64) bool {
+	if amount == 0 {
+		return false
+	}
+
+	fromBalance := c.BalanceOf(from)
+	if fromBalance < amount {
+		return false
+	}
+
+	c.balances[from] = fromBalance - amount
+	c.balances[to] += amount
+
 	return true
 }
@@ -0,0 +1,335 @@
+package marketplace
+
+import (
+	"encoding/json"
+
+	"github.com/stellar/soroban-sdk/go/soroban"
+)
+
+// AllocationKind represents what a FundsAllocation proposal does
+type AllocationKind string
+
+const (
+	SetBudget  AllocationKind = "SET_BUDGET"
+	AwardGrant AllocationKind = "AWARD_GRANT"
+)
+
+// DisbursementType represents how a grant is paid out
+type DisbursementType string
+
+const (
+	Streaming DisbursementType = "STREAMING"
+	Milestone DisbursementType = "MILESTONE"
+)
+
+// FundsAllocationData is the proposal data of a FundsAllocation proposal
+type FundsAllocationData struct {
+	Kind         AllocationKind   `json:"kind"`
+	Category     string           `json:"category"`
+	Budget       uint64           `json:"budget,omitempty"`
+	Grantee      string           `json:"grantee,omitempty"`
+	Amount       uint64           `json:"amount,omitempty"`
+	Disbursement DisbursementType `json:"disbursement,omitempty"`
+	StreamStart  int64            `json:"stream_start,omitempty"`
+	StreamEnd    int64            `json:"stream_end,omitempty"`
+	Reviewer     string           `json:"reviewer,omitempty"`
+	Milestones   []GrantMilestone `json:"milestones,omitempty"`
+}
+
+// BudgetCategory tracks the treasury budget of a spending category
+type BudgetCategory struct {
+	Name      string
+	Budget    uint64
+	Allocated uint64
+	Spent     uint64
+}
+
+// GrantMilestone is a tranche of a milestone-based grant
+type GrantMilestone struct {
+	Description string `json:"description"`
+	Amount      uint64 `json:"amount"`
+	Released    bool   `json:"released"`
+}
+
+// Grant represents treasury funds awarded to a grantee by an executed proposal
+type Grant struct {
+	ID           string
+	ProposalID   string
+	Category     string
+	Grantee      string
+	Amount       uint64
+	Released     uint64
+	Disbursement DisbursementType
+	StreamStart  int64
+	StreamEnd    int64
+	Reviewer     string
+	Milestones   []GrantMilestone
+}
+
+// Disbursement records a payment out of the treasury
+type Disbursement struct {
+	GrantID   string
+	Category  string
+	Grantee   string
+	Amount    uint64
+	Timestamp int64
+}
+
+// Treasury holds the budget categories, grants and spend history managed by
+// the governance contract
+type Treasury struct {
+	categories    map[string]BudgetCategory
+	grants        map[string]Grant
+	disbursements []Disbursement
+}
+
+// TreasuryAddress returns the account holding treasury funds
+func (c *GovernanceContract) TreasuryAddress(env soroban.Env) string {
+	return env.Current().Contract().Address().String()
+}
+
+// TreasuryBalance returns the token balance held by the treasury
+func (c *GovernanceContract) TreasuryBalance(env soroban.Env) uint64 {
+	return c.token.BalanceOf(c.TreasuryAddress(env))
+}
+
+// DepositToTreasury moves tokens from a holder into the treasury
+func (c *GovernanceContract) DepositToTreasury(env soroban.Env, from string, amount uint64) bool {
+	depositor := env.Current().Auth().Address()
+	if depositor.String() != from {
+		panic("Unauthorized depositor")
+	}
+
+	if !c.token.Transfer(env, from, c.TreasuryAddress(env), amount) {
+		return false
+	}
+
+	env.Events().Publish("treasury_deposit", map[string]interface{}{
+		"from":   from,
+		"amount": amount,
+	})
+
+	return true
+}
+
+// ClaimStreamedFunds releases the vested part of a streaming grant to its grantee
+func (c *GovernanceContract) ClaimStreamedFunds(env soroban.Env, grantID string) uint64 {
+	grant := c.getExecutedGrant(grantID)
+	if grant.Disbursement != Streaming {
+		panic("Grant is not streamed")
+	}
+
+	grantee := env.Current().Auth().Address()
+	if grantee.String() != grant.Grantee {
+		panic("Unauthorized grantee")
+	}
+
+	currentTime := env.Ledger().Timestamp()
+	vested := grant.Amount
+	if currentTime < grant.StreamEnd {
+		if currentTime <= grant.StreamStart {
+			vested = 0
+		} else {
+			elapsed := uint64(currentTime - grant.StreamStart)
+			duration := uint64(grant.StreamEnd - grant.StreamStart)
+			vested = grant.Amount * elapsed / duration
+		}
+	}
+
+	claimable := vested - grant.Released
+	if claimable == 0 {
+		return 0
+	}
+
+	c.disburse(env, &grant, claimable)
+	return claimable
+}
+
+// ApproveMilestone releases a milestone tranche of a grant once its reviewer approves it
+func (c *GovernanceContract) ApproveMilestone(env soroban.Env, grantID string, index uint32) uint64 {
+	grant := c.getExecutedGrant(grantID)
+	if grant.Disbursement != Milestone {
+		panic("Grant is not milestone based")
+	}
+
+	reviewer := env.Current().Auth().Address()
+	if reviewer.String() != grant.Reviewer {
+		panic("Unauthorized reviewer")
+	}
+
+	if int(index) >= len(grant.Milestones) {
+		panic("Milestone does not exist")
+	}
+
+	milestone := grant.Milestones[index]
+	if milestone.Released {
+		panic("Milestone already released")
+	}
+
+	grant.Milestones[index].Released = true
+	c.disburse(env, &grant, milestone.Amount)
+	return milestone.Amount
+}
+
+// GetBudgetCategory retrieves a treasury budget category
+func (c *GovernanceContract) GetBudgetCategory(name string) BudgetCategory {
+	category, exists := c.treasury.categories[name]
+	if !exists {
+		panic("Budget category does not exist")
+	}
+	return category
+}
+
+// ListBudgetCategories retrieves all treasury budget categories
+func (c *GovernanceContract) ListBudgetCategories() []BudgetCategory {
+	categories := make([]BudgetCategory, 0, len(c.treasury.categories))
+	for _, category := range c.treasury.categories {
+		categories = append(categories, category)
+	}
+	return categories
+}
+
+// GetGrant retrieves a treasury grant
+func (c *GovernanceContract) GetGrant(grantID string) Grant {
+	grant, exists := c.treasury.grants[grantID]
+	if !exists {
+		panic("Grant does not exist")
+	}
+	return grant
+}
+
+// GetDisbursements retrieves treasury disbursements made between two timestamps
+func (c *GovernanceContract) GetDisbursements(from int64, to int64) []Disbursement {
+	disbursements := make([]Disbursement, 0)
+	for _, disbursement := range c.treasury.disbursements {
+		if disbursement.Timestamp >= from && disbursement.Timestamp <= to {
+			disbursements = append(disbursements, disbursement)
+		}
+	}
+	return disbursements
+}
+
+// allocateFunds applies the data of a FundsAllocation proposal being
+// executed. It only records budgets and grants; funds leave the treasury in
+// ClaimStreamedFunds and ApproveMilestone, which check the awarding proposal.
+func (c *GovernanceContract) allocateFunds(env soroban.Env, proposal Proposal) {
+	var allocation FundsAllocationData
+	if err := json.Unmarshal(proposal.Data, &allocation); err != nil {
+		panic("Invalid funds allocation data")
+	}
+
+	switch allocation.Kind {
+	case SetBudget:
+		category := c.treasury.categories[allocation.Category]
+		if allocation.Budget < category.Allocated {
+			panic("Budget is below already allocated funds")
+		}
+		category.Name = allocation.Category
+		category.Budget = allocation.Budget
+		c.treasury.categories[allocation.Category] = category
+
+		env.Events().Publish("treasury_budget_set", map[string]interface{}{
+			"proposal_id": proposal.ID,
+			"category":    allocation.Category,
+			"budget":      allocation.Budget,
+		})
+	case AwardGrant:
+		c.awardGrant(env, proposal, allocation)
+	default:
+		panic("Unknown funds allocation kind")
+	}
+}
+
+func (c *GovernanceContract) awardGrant(env soroban.Env, proposal Proposal, allocation FundsAllocationData) {
+	category, exists := c.treasury.categories[allocation.Category]
+	if !exists {
+		panic("Budget category does not exist")
+	}
+
+	if category.Allocated+allocation.Amount > category.Budget {
+		panic("Grant exceeds category budget")
+	}
+
+	switch allocation.Disbursement {
+	case Streaming:
+		if allocation.StreamEnd <= allocation.StreamStart {
+			panic("Invalid stream period")
+		}
+	case Milestone:
+		var total uint64
+		for _, milestone := range allocation.Milestones {
+			total += milestone.Amount
+		}
+		if total != allocation.Amount || allocation.Reviewer == "" {
+			panic("Invalid milestone schedule")
+		}
+	default:
+		panic("Unknown disbursement type")
+	}
+
+	grant := Grant{
+		ID:           env.GenerateUUID(),
+		ProposalID:   proposal.ID,
+		Category:     allocation.Category,
+		Grantee:      allocation.Grantee,
+		Amount:       allocation.Amount,
+		Disbursement: allocation.Disbursement,
+		StreamStart:  allocation.StreamStart,
+		StreamEnd:    allocation.StreamEnd,
+		Reviewer:     allocation.Reviewer,
+		Milestones:   allocation.Milestones,
+	}
+
+	category.Allocated += allocation.Amount
+	c.treasury.categories[allocation.Category] = category
+	c.treasury.grants[grant.ID] = grant
+
+	env.Events().Publish("treasury_grant_awarded", map[string]interface{}{
+		"grant_id":     grant.ID,
+		"proposal_id":  proposal.ID,
+		"category":     grant.Category,
+		"grantee":      grant.Grantee,
+		"amount":       grant.Amount,
+		"disbursement": grant.Disbursement,
+	})
+}
+
+// getExecutedGrant retrieves a grant and verifies it was awarded by an executed proposal
+func (c *GovernanceContract) getExecutedGrant(grantID string) Grant {
+	grant := c.GetGrant(grantID)
+
+	proposal, exists := c.proposals[grant.ProposalID]
+	if !exists || !proposal.Executed || proposal.Type != FundsAllocation {
+		panic("Grant was not awarded by an executed proposal")
+	}
+
+	return grant
+}
+
+func (c *GovernanceContract) disburse(env soroban.Env, grant *Grant, amount uint64) {
+	if !c.token.Transfer(env, c.TreasuryAddress(env), grant.Grantee, amount) {
+		panic("Insufficient treasury funds")
+	}
+
+	grant.Released += amount
+	c.treasury.grants[grant.ID] = *grant
+
+	category := c.treasury.categories[grant.Category]
+	category.Spent += amount
+	c.treasury.categories[grant.Category] = category
+
+	disbursement := Disbursement{
+		GrantID:   grant.ID,
+		Category:  grant.Category,
+		Grantee:   grant.Grantee,
+		Amount:    amount,
+		Timestamp: env.Ledger().Timestamp(),
+	}
+	c.treasury.disbursements = append(c.treasury.disbursements, disbursement)
+
+	env.Events().Publish("treasury_disbursement", map[string]interface{}{
+		"grant_id": grant.ID,
+		"grantee":  grant.Grantee,
+		"amount":   amount,
+	})
+}
@@ -0,0 +1,180 @@
+// Package sorobantest provides an in-memory implementation of the soroban.Env
+// surface used by the marketplace, governance and token contracts, so that
+// full contract scenarios can run under go test without a Soroban network.
+package sorobantest
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/stellar/soroban-sdk/go/soroban"
+)
+
+// LedgerCloseTime is the simulated number of seconds between ledgers
+const LedgerCloseTime = 5
+
+var _ soroban.Env = (*Env)(nil)
+
+// Env is a local contract environment with a controllable ledger clock,
+// invoker and current contract
+type Env struct {
+	timestamp int64
+	sequence  uint32
+	invoker   string
+	contract  string
+	uuids     uint64
+	events    *Events
+	storage   *Storage
+}
+
+// NewEnv creates an environment whose ledger clock starts at timestamp
+func NewEnv(timestamp int64) *Env {
+	env := &Env{
+		timestamp: timestamp,
+		sequence:  1,
+	}
+	env.events = &Events{env: env}
+	env.storage = newStorage(env)
+	return env
+}
+
+// Invoke sets the contract being called and the address authorizing the call
+func (e *Env) Invoke(contract string, invoker string) *Env {
+	e.contract = contract
+	e.invoker = invoker
+	return e
+}
+
+// SetTime moves the ledger clock to timestamp, closing one ledger per
+// LedgerCloseTime seconds elapsed
+func (e *Env) SetTime(timestamp int64) {
+	ledgers := (timestamp - e.timestamp) / LedgerCloseTime
+	if ledgers < 1 {
+		ledgers = 1
+	}
+	e.timestamp = timestamp
+	e.sequence += uint32(ledgers)
+}
+
+// AdvanceTime moves the ledger clock forward by seconds
+func (e *Env) AdvanceTime(seconds int64) {
+	e.SetTime(e.timestamp + seconds)
+}
+
+// Ledger returns the ledger information of the environment
+func (e *Env) Ledger() Ledger {
+	return Ledger{env: e}
+}
+
+// Current returns the execution context of the current invocation
+func (e *Env) Current() Context {
+	return Context{env: e}
+}
+
+// Events returns the event recorder of the environment
+func (e *Env) Events() *Events {
+	return e.events
+}
+
+// Storage returns the contract storage of the environment
+func (e *Env) Storage() *Storage {
+	return e.storage
+}
+
+// Crypto returns the cryptographic helpers of the environment
+func (e *Env) Crypto() Crypto {
+	return Crypto{}
+}
+
+// Token returns the host token interface, which the local environment does not provide
+func (e *Env) Token() soroban.Token {
+	return nil
+}
+
+// GenerateUUID returns a deterministic, unique identifier
+func (e *Env) GenerateUUID() string {
+	e.uuids++
+	return fmt.Sprintf("00000000-0000-4000-8000-%012x", e.uuids)
+}
+
+// Address identifies an account or contract
+type Address string
+
+func (a Address) String() string {
+	return string(a)
+}
+
+// Ledger exposes the ledger clock
+type Ledger struct {
+	env *Env
+}
+
+// Timestamp returns the current ledger close time in Unix seconds
+func (l Ledger) Timestamp() int64 {
+	return l.env.timestamp
+}
+
+// Sequence returns the current ledger sequence number
+func (l Ledger) Sequence() uint32 {
+	return l.env.sequence
+}
+
+// Context exposes the current invocation
+type Context struct {
+	env *Env
+}
+
+// Auth returns the authorization of the current invocation
+func (c Context) Auth() Auth {
+	return Auth{address: Address(c.env.invoker)}
+}
+
+// Contract returns the contract being executed
+func (c Context) Contract() ContractInfo {
+	return ContractInfo{address: Address(c.env.contract)}
+}
+
+// Ledger returns the ledger information of the current invocation
+func (c Context) Ledger() Ledger {
+	return c.env.Ledger()
+}
+
+// Auth holds the address that authorized the current invocation
+type Auth struct {
+	address Address
+}
+
+// Address returns the authorizing address
+func (a Auth) Address() Address {
+	return a.address
+}
+
+// ContractInfo describes the contract being executed
+type ContractInfo struct {
+	address Address
+}
+
+// Address returns the contract address
+func (c ContractInfo) Address() Address {
+	return c.address
+}
+
+// Crypto provides random bytes to contracts
+type Crypto struct{}
+
+// RandomBytes returns n cryptographically random bytes
+func (Crypto) RandomBytes(n int) Bytes {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("failed to read random bytes: %v", err))
+	}
+	return Bytes(b)
+}
+
+// Bytes is a byte string returned by the environment
+type Bytes []byte
+
+func (b Bytes) String() string {
+	return hex.EncodeToString(b)
+}
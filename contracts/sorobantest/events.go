@@ -0,0 +1,56 @@
+package sorobantest
+
+// Event is a contract event published in the local environment
+type Event struct {
+	Contract  string
+	Name      string
+	Data      interface{}
+	Timestamp int64
+}
+
+// Events records published contract events in order
+type Events struct {
+	env    *Env
+	events []Event
+}
+
+// Publish records an event
+func (e *Events) Publish(name string, data interface{}) {
+	event := Event{Name: name, Data: data}
+	if e.env != nil {
+		event.Contract = e.env.contract
+		event.Timestamp = e.env.timestamp
+	}
+	e.events = append(e.events, event)
+}
+
+// All returns every recorded event
+func (e *Events) All() []Event {
+	return append([]Event(nil), e.events...)
+}
+
+// Named returns the recorded events with the given name
+func (e *Events) Named(name string) []Event {
+	var events []Event
+	for _, event := range e.events {
+		if event.Name == name {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// Last returns the most recent event with the given name
+func (e *Events) Last(name string) (Event, bool) {
+	for i := len(e.events) - 1; i >= 0; i-- {
+		if e.events[i].Name == name {
+			return e.events[i], true
+		}
+	}
+	return Event{}, false
+}
+
+// Reset discards every recorded event
+func (e *Events) Reset() {
+	e.events = nil
+}
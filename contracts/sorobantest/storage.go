@@ -0,0 +1,135 @@
+package sorobantest
+
+import (
+	"fmt"
+)
+
+// MinTTL is the number of ledgers a new storage entry lives for
+const MinTTL = 4096
+
+// Storage holds the persistent, instance and temporary storage of the environment
+type Storage struct {
+	persistent *Entries
+	instance   *Entries
+	temporary  *Entries
+}
+
+func newStorage(env *Env) *Storage {
+	return &Storage{
+		persistent: newEntries(env, true),
+		instance:   newEntries(env, true),
+		temporary:  newEntries(env, false),
+	}
+}
+
+// Persistent returns storage for entries that are archived when their TTL expires
+func (s *Storage) Persistent() *Entries {
+	return s.persistent
+}
+
+// Instance returns storage tied to the lifetime of the contract instance
+func (s *Storage) Instance() *Entries {
+	return s.instance
+}
+
+// Temporary returns storage for entries that are deleted when their TTL expires
+func (s *Storage) Temporary() *Entries {
+	return s.temporary
+}
+
+type entry struct {
+	value     interface{}
+	liveUntil uint32
+}
+
+// Entries is a keyed storage area with per-entry TTLs, namespaced by contract
+type Entries struct {
+	env     *Env
+	archive bool
+	entries map[string]*entry
+}
+
+func newEntries(env *Env, archive bool) *Entries {
+	return &Entries{
+		env:     env,
+		archive: archive,
+		entries: make(map[string]*entry),
+	}
+}
+
+// Set stores a value under key
+func (s *Entries) Set(key interface{}, value interface{}) {
+	k := s.key(key)
+	if existing, ok := s.entries[k]; ok {
+		existing.value = value
+		return
+	}
+	s.entries[k] = &entry{
+		value:     value,
+		liveUntil: s.env.sequence + MinTTL,
+	}
+}
+
+// Get retrieves the value stored under key
+func (s *Entries) Get(key interface{}) (interface{}, bool) {
+	e, ok := s.live(key)
+	if !ok {
+		return nil, false
+	}
+	return e.value, true
+}
+
+// Has reports whether a live value is stored under key
+func (s *Entries) Has(key interface{}) bool {
+	_, ok := s.live(key)
+	return ok
+}
+
+// Remove deletes the value stored under key
+func (s *Entries) Remove(key interface{}) {
+	delete(s.entries, s.key(key))
+}
+
+// ExtendTTL extends the entry's TTL to extendTo ledgers when fewer than
+// threshold ledgers remain
+func (s *Entries) ExtendTTL(key interface{}, threshold uint32, extendTo uint32) {
+	e, ok := s.live(key)
+	if !ok {
+		panic(fmt.Sprintf("storage entry %v does not exist", key))
+	}
+	if e.liveUntil-s.env.sequence < threshold {
+		e.liveUntil = s.env.sequence + extendTo
+	}
+}
+
+// TTL returns the number of ledgers the entry under key has left to live
+func (s *Entries) TTL(key interface{}) uint32 {
+	e, ok := s.live(key)
+	if !ok {
+		return 0
+	}
+	return e.liveUntil - s.env.sequence
+}
+
+// live returns an unexpired entry, panicking on archived persistent entries
+// and dropping expired temporary ones
+func (s *Entries) live(key interface{}) (*entry, bool) {
+	k := s.key(key)
+	e, ok := s.entries[k]
+	if !ok {
+		return nil, false
+	}
+	if e.liveUntil < s.env.sequence {
+		if s.archive {
+			panic(fmt.Sprintf("storage entry %v is archived", key))
+		}
+		delete(s.entries, k)
+		return nil, false
+	}
+	return e, true
+}
+
+// key namespaces storage keys by the current contract
+func (s *Entries) key(key interface{}) string {
+	return fmt.Sprintf("%s/%T/%v", s.env.contract, key, key)
+}
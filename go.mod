@@ -4,7 +4,20 @@ go 1.21
 
 require (
     github.com/gin-gonic/gin v1.9.1
+    github.com/go-playground/validator/v10 v10.14.0
+    github.com/golang-jwt/jwt/v4 v4.5.0
+    github.com/hyperledger/fabric-chaincode-go v0.0.0-20230731094759-d626e9ab09b9
+    github.com/hyperledger/fabric-contract-api-go v1.2.2
+    github.com/hyperledger/fabric-gateway v1.4.0
     github.com/stellar/go v0.0.0-20231122203702-b641e7025e73
     github.com/stellar/soroban-sdk v0.9.2
     github.com/stretchr/testify v1.8.4
+    go.opentelemetry.io/otel v1.21.0
+    go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp v1.21.0
+    go.opentelemetry.io/otel/exporters/stdout/stdouttrace v1.21.0
+    go.opentelemetry.io/otel/sdk v1.21.0
+    go.opentelemetry.io/otel/trace v1.21.0
+    golang.org/x/crypto v0.17.0
+    golang.org/x/time v0.5.0
+    google.golang.org/grpc v1.59.0
 )
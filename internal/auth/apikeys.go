@@ -0,0 +1,331 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// APIKeyPrefix starts every API key so leaked keys are easy to recognize
+const APIKeyPrefix = "lmk_"
+
+// DefaultAPIKeyRateLimit is the requests per minute allowed to a key without its own limit
+const DefaultAPIKeyRateLimit = 600
+
+// lastUsedResolution limits how often a key's last use is written to disk
+const lastUsedResolution = time.Minute
+
+var (
+	// ErrInvalidAPIKey is returned for an unknown, malformed or revoked API key
+	ErrInvalidAPIKey = errors.New("invalid API key")
+	// ErrAPIKeyNotFound is returned when managing a key that does not exist
+	ErrAPIKeyNotFound = errors.New("API key not found")
+)
+
+// Scope grants an API key read or write access to one area of the API
+type Scope string
+
+// Scope access levels; GET and HEAD need read, every other method write
+const (
+	ScopeRead  = "read"
+	ScopeWrite = "write"
+)
+
+// scopeResources are the areas of the API that API keys can be granted
+var scopeResources = []string{"quotes", "bookings", "tracking", "listings", "infrastructure"}
+
+// ValidScope reports whether a scope names a known resource and access level
+func ValidScope(scope Scope) bool {
+	resource, access, ok := strings.Cut(string(scope), ":")
+	if !ok || (access != ScopeRead && access != ScopeWrite) {
+		return false
+	}
+	for _, known := range scopeResources {
+		if resource == known {
+			return true
+		}
+	}
+	return false
+}
+
+// APIKey lets a server integration act for a company without a user present.
+// The key acts as the user who created it, limited to its scopes.
+type APIKey struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	CompanyID  string     `json:"company_id"`
+	UserID     string     `json:"user_id"`
+	Address    string     `json:"user_address"`
+	Roles      []Role     `json:"roles"`
+	Scopes     []Scope    `json:"scopes"`
+	Tier       string     `json:"tier,omitempty"`
+	RateLimit  int        `json:"rate_limit"` // Requests per minute
+	SecretHash string     `json:"secret_hash,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// Redacted returns a copy of the key without its secret hash
+func (k *APIKey) Redacted() *APIKey {
+	redacted := *k
+	redacted.SecretHash = ""
+	return &redacted
+}
+
+// Identity returns the identity requests made with the key authenticate as
+func (k *APIKey) Identity() *Identity {
+	return &Identity{
+		UserID:    k.UserID,
+		Address:   k.Address,
+		CompanyID: k.CompanyID,
+		Roles:     append([]Role(nil), k.Roles...),
+		Tier:      k.Tier,
+		APIKeyID:  k.ID,
+		Scopes:    append([]Scope(nil), k.Scopes...),
+	}
+}
+
+// APIKeyStore holds API keys by ID, storing only a hash of their secret.
+// Keys are persisted to a JSON file when the store has a path.
+type APIKeyStore struct {
+	path string
+	now  func() time.Time
+
+	mu       sync.Mutex
+	keys     map[string]*APIKey
+	limiters map[string]*rate.Limiter
+}
+
+// NewAPIKeyStore creates a new APIKeyStore instance, loading the keys in path if it exists
+func NewAPIKeyStore(path string) (*APIKeyStore, error) {
+	s := &APIKeyStore{
+		path:     path,
+		now:      time.Now,
+		keys:     make(map[string]*APIKey),
+		limiters: make(map[string]*rate.Limiter),
+	}
+	if path == "" {
+		return s, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read API keys: %w", err)
+	}
+
+	var keys []*APIKey
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, fmt.Errorf("failed to parse API keys: %w", err)
+	}
+	for _, key := range keys {
+		s.keys[key.ID] = key
+	}
+	return s, nil
+}
+
+// Create issues a key acting as the caller for its company. The returned
+// secret is only available now; the store keeps its hash.
+func (s *APIKeyStore) Create(creator *Identity, name string, scopes []Scope, rateLimit int) (*APIKey, string, error) {
+	if creator.APIKeyID != "" {
+		return nil, "", forbidden("API keys cannot create API keys")
+	}
+	if creator.CompanyID == "" {
+		return nil, "", forbidden("API keys belong to a company and the caller has none")
+	}
+	if len(scopes) == 0 {
+		return nil, "", fmt.Errorf("at least one scope is required")
+	}
+	for _, scope := range scopes {
+		if !ValidScope(scope) {
+			return nil, "", fmt.Errorf("unknown scope %q", scope)
+		}
+	}
+	if rateLimit < 0 {
+		return nil, "", fmt.Errorf("rate limit must be positive")
+	}
+	if rateLimit == 0 {
+		rateLimit = DefaultAPIKeyRateLimit
+	}
+
+	// Keys never carry administrator rights
+	var roles []Role
+	for _, role := range creator.Roles {
+		if role != RoleAdmin {
+			roles = append(roles, role)
+		}
+	}
+	if len(roles) == 0 {
+		return nil, "", forbidden("API keys need a marketplace role to act as")
+	}
+
+	id, err := randomToken(9)
+	if err != nil {
+		return nil, "", err
+	}
+	secret, err := randomToken(32)
+	if err != nil {
+		return nil, "", err
+	}
+
+	key := &APIKey{
+		ID:         id,
+		Name:       name,
+		CompanyID:  creator.CompanyID,
+		UserID:     creator.UserID,
+		Address:    creator.Address,
+		Roles:      roles,
+		Scopes:     append([]Scope(nil), scopes...),
+		Tier:       creator.Tier,
+		RateLimit:  rateLimit,
+		SecretHash: hashToken(secret),
+		CreatedAt:  s.now().UTC(),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.keys[id] = key
+	if err := s.save(); err != nil {
+		delete(s.keys, id)
+		return nil, "", err
+	}
+
+	return key.Redacted(), APIKeyPrefix + id + "." + secret, nil
+}
+
+// List returns the keys of a company, newest first
+func (s *APIKeyStore) List(companyID string) []*APIKey {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys := []*APIKey{}
+	for _, key := range s.keys {
+		if key.CompanyID == companyID {
+			keys = append(keys, key.Redacted())
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.After(keys[j].CreatedAt)
+	})
+	return keys
+}
+
+// Revoke disables a key of the caller's company
+func (s *APIKeyStore) Revoke(caller *Identity, keyID string) error {
+	if caller.APIKeyID != "" {
+		return forbidden("API keys cannot revoke API keys")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, ok := s.keys[keyID]
+	if !ok || (key.CompanyID != caller.CompanyID && !caller.IsAdmin()) {
+		return ErrAPIKeyNotFound
+	}
+	if key.RevokedAt != nil {
+		return nil
+	}
+
+	now := s.now().UTC()
+	key.RevokedAt = &now
+	delete(s.limiters, keyID)
+	return s.save()
+}
+
+// Authenticate resolves the identity of a presented key and records its use
+func (s *APIKeyStore) Authenticate(token string) (*Identity, error) {
+	id, secret, ok := strings.Cut(strings.TrimPrefix(token, APIKeyPrefix), ".")
+	if !ok || !strings.HasPrefix(token, APIKeyPrefix) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, ok := s.keys[id]
+	if !ok || key.RevokedAt != nil {
+		return nil, ErrInvalidAPIKey
+	}
+	if subtle.ConstantTimeCompare([]byte(hashToken(secret)), []byte(key.SecretHash)) != 1 {
+		return nil, ErrInvalidAPIKey
+	}
+
+	now := s.now().UTC()
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= lastUsedResolution {
+		key.LastUsedAt = &now
+		if err := s.save(); err != nil {
+			return nil, err
+		}
+	}
+
+	return key.Identity(), nil
+}
+
+// Allow takes one request from the key's rate limit
+func (s *APIKeyStore) Allow(keyID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, ok := s.keys[keyID]
+	if !ok {
+		return false
+	}
+	limiter, ok := s.limiters[keyID]
+	if !ok {
+		limiter = rate.NewLimiter(rate.Every(time.Minute/time.Duration(key.RateLimit)), key.RateLimit)
+		s.limiters[keyID] = limiter
+	}
+	return limiter.Allow()
+}
+
+// save writes every key to the store's file, replacing it atomically
+func (s *APIKeyStore) save() error {
+	if s.path == "" {
+		return nil
+	}
+
+	keys := make([]*APIKey, 0, len(s.keys))
+	for _, key := range s.keys {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+	})
+
+	data, err := json.MarshalIndent(keys, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode API keys: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".apikeys-*")
+	if err != nil {
+		return fmt.Errorf("failed to save API keys: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save API keys: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to save API keys: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to save API keys: %w", err)
+	}
+	return nil
+}
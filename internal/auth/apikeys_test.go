@@ -0,0 +1,109 @@
+package auth
+
+import (
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testIntegrator = &Identity{
+	UserID:    "user-1",
+	Address:   "GALICE",
+	CompanyID: "acme",
+	Roles:     []Role{RoleFreightForwarder, RoleAdmin},
+}
+
+func TestAPIKeyLifecycle(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "apikeys.json")
+	keys, err := NewAPIKeyStore(path)
+	require.NoError(t, err)
+
+	key, secret, err := keys.Create(testIntegrator, "TMS", []Scope{"tracking:write"}, 0)
+	require.NoError(t, err)
+	assert.Empty(t, key.SecretHash)
+	assert.Equal(t, DefaultAPIKeyRateLimit, key.RateLimit)
+	assert.Equal(t, []Role{RoleFreightForwarder}, key.Roles, "keys never carry admin rights")
+
+	// Keys survive a restart and authenticate as their creator's company
+	keys, err = NewAPIKeyStore(path)
+	require.NoError(t, err)
+	id, err := keys.Authenticate(secret)
+	require.NoError(t, err)
+	assert.Equal(t, key.ID, id.APIKeyID)
+	assert.True(t, id.Is("acme"))
+	assert.True(t, id.HasScope("tracking:write"))
+
+	listed := keys.List("acme")
+	require.Len(t, listed, 1)
+	assert.NotNil(t, listed[0].LastUsedAt)
+	assert.Empty(t, listed[0].SecretHash)
+	assert.Empty(t, keys.List("globex"))
+
+	_, err = keys.Authenticate(secret + "x")
+	assert.ErrorIs(t, err, ErrInvalidAPIKey)
+
+	// Another company cannot see or revoke the key
+	other := &Identity{UserID: "user-2", CompanyID: "globex", Roles: []Role{RoleShipper}}
+	assert.ErrorIs(t, keys.Revoke(other, key.ID), ErrAPIKeyNotFound)
+
+	require.NoError(t, keys.Revoke(testIntegrator, key.ID))
+	_, err = keys.Authenticate(secret)
+	assert.ErrorIs(t, err, ErrInvalidAPIKey)
+}
+
+func TestAPIKeyCreateValidation(t *testing.T) {
+	keys, err := NewAPIKeyStore("")
+	require.NoError(t, err)
+
+	_, _, err = keys.Create(testIntegrator, "ERP", []Scope{"ledger:write"}, 0)
+	assert.Error(t, err)
+
+	_, _, err = keys.Create(&Identity{UserID: "user-1", Roles: []Role{RoleShipper}}, "ERP", []Scope{"quotes:read"}, 0)
+	var forbiddenErr *ForbiddenError
+	assert.ErrorAs(t, err, &forbiddenErr)
+
+	_, secret, err := keys.Create(testIntegrator, "ERP", []Scope{"quotes:read"}, 0)
+	require.NoError(t, err)
+	id, err := keys.Authenticate(secret)
+	require.NoError(t, err)
+	_, _, err = keys.Create(id, "nested", []Scope{"quotes:read"}, 0)
+	assert.ErrorAs(t, err, &forbiddenErr)
+}
+
+func TestAPIKeyRateLimit(t *testing.T) {
+	keys, err := NewAPIKeyStore("")
+	require.NoError(t, err)
+
+	key, _, err := keys.Create(testIntegrator, "TMS", []Scope{"tracking:read"}, 2)
+	require.NoError(t, err)
+
+	assert.True(t, keys.Allow(key.ID))
+	assert.True(t, keys.Allow(key.ID))
+	assert.False(t, keys.Allow(key.ID))
+}
+
+func TestPolicyAuthorizeAPIKeyScopes(t *testing.T) {
+	policy := testPolicy().
+		Scope("/api/v1/tracking", "tracking").
+		Scope("/api/v1/users/shipper/quotes", "quotes")
+	key := &Identity{UserID: "u1", Roles: []Role{RoleShipper}, APIKeyID: "k1", Scopes: []Scope{"tracking:read", "quotes:write"}}
+
+	_, err := policy.Authorize(key, http.MethodGet, "/api/v1/tracking/:booking_id")
+	assert.NoError(t, err)
+	_, err = policy.Authorize(key, http.MethodPost, "/api/v1/users/shipper/quotes/request")
+	assert.NoError(t, err)
+
+	var forbiddenErr *ForbiddenError
+	_, err = policy.Authorize(key, http.MethodPost, "/api/v1/tracking/events")
+	require.ErrorAs(t, err, &forbiddenErr)
+	assert.Contains(t, forbiddenErr.Reason, "tracking:write")
+
+	// Routes outside every scope are closed to API keys
+	_, err = policy.Authorize(key, http.MethodGet, "/api/v1/users/shipper/bookings")
+	assert.ErrorAs(t, err, &forbiddenErr)
+	_, err = policy.Authorize(key, http.MethodGet, "/api/v1/api-keys")
+	assert.ErrorAs(t, err, &forbiddenErr)
+}
@@ -0,0 +1,152 @@
+// Package auth resolves the authenticated identity of a request and decides
+// what it may access.
+package auth
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Role is a marketplace role granted in the access token
+type Role string
+
+const (
+	RoleShipper                Role = "SHIPPER"
+	RoleConsignee              Role = "CONSIGNEE"
+	RoleFreightForwarder       Role = "FREIGHT_FORWARDER"
+	RoleCustomsBroker          Role = "CUSTOMS_BROKER"
+	RoleInfrastructureOperator Role = "INFRASTRUCTURE_OPERATOR"
+	RoleAdmin                  Role = "ADMIN"
+)
+
+var validRoles = map[Role]bool{
+	RoleShipper:                true,
+	RoleConsignee:              true,
+	RoleFreightForwarder:       true,
+	RoleCustomsBroker:          true,
+	RoleInfrastructureOperator: true,
+	RoleAdmin:                  true,
+}
+
+// Context keys set for an authenticated request
+const (
+	identityKey   = "identity"
+	actingRoleKey = "acting_role"
+)
+
+// Identity is the authenticated caller of a request
+type Identity struct {
+	UserID    string `json:"user_id"`
+	Address   string `json:"user_address"`
+	CompanyID string `json:"company_id,omitempty"`
+	Roles     []Role `json:"roles"`
+	Tier      string `json:"tier,omitempty"` // Membership tier, scaling rate limits
+
+	SessionID string `json:"-"` // Login session the token was issued in
+	TokenID   string `json:"-"` // ID of the access token
+
+	APIKeyID string  `json:"api_key_id,omitempty"` // Set when authenticated with an API key
+	Scopes   []Scope `json:"scopes,omitempty"`     // What the API key may access
+}
+
+// HasRole reports whether the identity holds any of the given roles
+func (id *Identity) HasRole(roles ...Role) bool {
+	for _, held := range id.Roles {
+		for _, role := range roles {
+			if held == role {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// HasScope reports whether an API key identity was granted the scope
+func (id *Identity) HasScope(scope Scope) bool {
+	for _, held := range id.Scopes {
+		if held == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// IsAdmin reports whether the identity is a marketplace administrator
+func (id *Identity) IsAdmin() bool {
+	return id.HasRole(RoleAdmin)
+}
+
+// Is reports whether a party ID refers to the identity: its user, its
+// Stellar address or the company it belongs to
+func (id *Identity) Is(partyID string) bool {
+	if partyID == "" {
+		return false
+	}
+	return partyID == id.UserID || partyID == id.Address || (id.CompanyID != "" && partyID == id.CompanyID)
+}
+
+// IdentityFromClaims builds an identity from verified token claims
+func IdentityFromClaims(claims map[string]interface{}) (*Identity, error) {
+	address, _ := claims["user_address"].(string)
+	if address == "" {
+		return nil, fmt.Errorf("user_address claim missing")
+	}
+
+	id := &Identity{
+		UserID:  address,
+		Address: address,
+	}
+	if sub, ok := claims["sub"].(string); ok && sub != "" {
+		id.UserID = sub
+	}
+	if companyID, ok := claims["company_id"].(string); ok {
+		id.CompanyID = companyID
+	}
+	id.Tier, _ = claims["tier"].(string)
+	id.SessionID, _ = claims["sid"].(string)
+	id.TokenID, _ = claims["jti"].(string)
+
+	rawRoles, ok := claims["roles"].([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("roles claim missing")
+	}
+	for _, raw := range rawRoles {
+		name, _ := raw.(string)
+		role := Role(name)
+		if !validRoles[role] {
+			return nil, fmt.Errorf("unknown role %q in token", name)
+		}
+		id.Roles = append(id.Roles, role)
+	}
+	if len(id.Roles) == 0 {
+		return nil, fmt.Errorf("token grants no roles")
+	}
+
+	return id, nil
+}
+
+// SetIdentity stores the authenticated identity on the request
+func SetIdentity(c *gin.Context, id *Identity) {
+	c.Set(identityKey, id)
+	c.Set("user_id", id.UserID)
+	c.Set("user_address", id.Address)
+	c.Set("company_id", id.CompanyID)
+}
+
+// FromContext returns the authenticated identity of the request
+func FromContext(c *gin.Context) (*Identity, bool) {
+	value, ok := c.Get(identityKey)
+	if !ok {
+		return nil, false
+	}
+	id, ok := value.(*Identity)
+	return id, ok
+}
+
+// ActingRole returns the role the caller was authorized to act as on this route
+func ActingRole(c *gin.Context) Role {
+	role, _ := c.Get(actingRoleKey)
+	acting, _ := role.(Role)
+	return acting
+}
@@ -0,0 +1,387 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// Signing algorithms for access tokens
+const (
+	AlgorithmEdDSA = "EdDSA"
+	AlgorithmRS256 = "RS256"
+)
+
+// DefaultKeyRotation is how long a signing key signs before it is replaced
+const DefaultKeyRotation = 30 * 24 * time.Hour
+
+// SigningKey is an asymmetric key that signs access tokens
+type SigningKey struct {
+	ID        string
+	Algorithm string
+	CreatedAt time.Time
+	RetiredAt time.Time // Zero while the key signs
+
+	private crypto.Signer
+}
+
+// Public returns the verification key
+func (k *SigningKey) Public() crypto.PublicKey {
+	return k.private.Public()
+}
+
+func (k *SigningKey) method() jwt.SigningMethod {
+	if k.Algorithm == AlgorithmRS256 {
+		return jwt.SigningMethodRS256
+	}
+	return jwt.SigningMethodEdDSA
+}
+
+// KeyRing signs access tokens with its newest key and verifies them with
+// every key that may still have unexpired tokens out. It can also accept
+// HS256 tokens signed with the legacy shared secret until a deadline, so
+// tokens minted before the switch keep working while they expire.
+type KeyRing struct {
+	algorithm string
+	retention time.Duration // How long a retired key keeps verifying, at least the access token TTL
+	dir       string        // Optional directory the keys are persisted in and shared through
+	now       func() time.Time
+
+	mu         sync.RWMutex
+	keys       []*SigningKey // Newest first
+	hmacSecret []byte
+	hmacUntil  time.Time
+}
+
+// NewKeyRing creates a key ring for the algorithm. Keys are loaded from dir
+// when given, and a first key is generated if there is none.
+func NewKeyRing(algorithm string, retention time.Duration, dir string) (*KeyRing, error) {
+	if algorithm != AlgorithmEdDSA && algorithm != AlgorithmRS256 {
+		return nil, fmt.Errorf("unsupported signing algorithm: %s", algorithm)
+	}
+
+	r := &KeyRing{
+		algorithm: algorithm,
+		retention: retention,
+		dir:       dir,
+		now:       time.Now,
+	}
+
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	if r.activeKey() == nil {
+		if _, err := r.Rotate(); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+// AcceptHS256 accepts HS256 tokens signed with secret until the given time
+func (r *KeyRing) AcceptHS256(secret []byte, until time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hmacSecret = secret
+	r.hmacUntil = until
+}
+
+// Rotate generates a new signing key; the previous key keeps verifying for the retention period
+func (r *KeyRing) Rotate() (*SigningKey, error) {
+	var private crypto.Signer
+	switch r.algorithm {
+	case AlgorithmRS256:
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate RSA key: %w", err)
+		}
+		private = key
+	default:
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate Ed25519 key: %w", err)
+		}
+		private = key
+	}
+
+	now := r.now().UTC()
+	suffix, err := randomToken(4)
+	if err != nil {
+		return nil, err
+	}
+	key := &SigningKey{
+		ID:        now.Format("20060102T150405Z") + "-" + suffix,
+		Algorithm: r.algorithm,
+		CreatedAt: now,
+		private:   private,
+	}
+
+	if r.dir != "" {
+		if err := writeKeyFile(r.dir, key); err != nil {
+			return nil, err
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.addKey(key)
+	r.prune()
+
+	return key, nil
+}
+
+// Reload reads the keys persisted in the key directory, picking up keys
+// rotated by other instances sharing it
+func (r *KeyRing) Reload() error {
+	if r.dir == "" {
+		return nil
+	}
+
+	paths, err := filepath.Glob(filepath.Join(r.dir, "*.pem"))
+	if err != nil {
+		return fmt.Errorf("failed to list signing keys: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, path := range paths {
+		id := strings.TrimSuffix(filepath.Base(path), ".pem")
+		if r.findKey(id) != nil {
+			continue
+		}
+
+		key, err := readKeyFile(path, id)
+		if err != nil {
+			return err
+		}
+		r.addKey(key)
+	}
+	r.prune()
+
+	return nil
+}
+
+// RunRotation rotates the signing key once it is older than interval,
+// checking every tick until the context is cancelled
+func (r *KeyRing) RunRotation(ctx context.Context, interval time.Duration, tick time.Duration) {
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.Reload(); err != nil {
+				log.Printf("Failed to reload signing keys: %v", err)
+				continue
+			}
+
+			r.mu.RLock()
+			active := r.activeKey()
+			r.mu.RUnlock()
+			if active != nil && r.now().Sub(active.CreatedAt) < interval {
+				continue
+			}
+
+			key, err := r.Rotate()
+			if err != nil {
+				log.Printf("Failed to rotate signing key: %v", err)
+				continue
+			}
+			log.Printf("Rotated access token signing key to %s", key.ID)
+		}
+	}
+}
+
+// Sign signs claims with the active key, naming it in the kid header
+func (r *KeyRing) Sign(claims jwt.Claims) (string, error) {
+	r.mu.RLock()
+	key := r.activeKey()
+	r.mu.RUnlock()
+	if key == nil {
+		return "", fmt.Errorf("no active signing key")
+	}
+
+	token := jwt.NewWithClaims(key.method(), claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.private)
+}
+
+// Keyfunc resolves the verification key of a token for jwt.Parse
+func (r *KeyRing) Keyfunc(token *jwt.Token) (interface{}, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); ok {
+		if token.Method.Alg() != jwt.SigningMethodHS256.Alg() || len(r.hmacSecret) == 0 || r.now().After(r.hmacUntil) {
+			return nil, fmt.Errorf("HS256 tokens are no longer accepted")
+		}
+		return r.hmacSecret, nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	key := r.findKey(kid)
+	if key == nil {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	if token.Method.Alg() != key.method().Alg() {
+		return nil, fmt.Errorf("token algorithm %s does not match key %s", token.Method.Alg(), kid)
+	}
+	return key.Public(), nil
+}
+
+// JWK is a public key in JSON Web Key format
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+}
+
+// JWKSet is the document served at /.well-known/jwks.json
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns every key that may verify an unexpired token
+func (r *KeyRing) JWKS() JWKSet {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	set := JWKSet{Keys: []JWK{}}
+	for _, key := range r.keys {
+		jwk := JWK{KeyID: key.ID, Use: "sig", Algorithm: key.Algorithm}
+		switch public := key.Public().(type) {
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
+
+// activeKey returns the newest key of the ring's algorithm
+func (r *KeyRing) activeKey() *SigningKey {
+	for _, key := range r.keys {
+		if key.Algorithm == r.algorithm {
+			return key
+		}
+	}
+	return nil
+}
+
+func (r *KeyRing) findKey(id string) *SigningKey {
+	for _, key := range r.keys {
+		if key.ID == id {
+			return key
+		}
+	}
+	return nil
+}
+
+// addKey inserts a key and recomputes when each key was retired by its successor
+func (r *KeyRing) addKey(key *SigningKey) {
+	r.keys = append(r.keys, key)
+	sort.Slice(r.keys, func(i, j int) bool {
+		return r.keys[i].CreatedAt.After(r.keys[j].CreatedAt)
+	})
+
+	for i, k := range r.keys {
+		k.RetiredAt = time.Time{}
+		if i > 0 {
+			k.RetiredAt = r.keys[i-1].CreatedAt
+		}
+	}
+}
+
+// prune drops keys retired longer ago than the retention period
+func (r *KeyRing) prune() {
+	now := r.now()
+	kept := r.keys[:0]
+	for _, key := range r.keys {
+		if !key.RetiredAt.IsZero() && now.Sub(key.RetiredAt) > r.retention {
+			if r.dir != "" {
+				os.Remove(filepath.Join(r.dir, key.ID+".pem"))
+			}
+			continue
+		}
+		kept = append(kept, key)
+	}
+	r.keys = kept
+}
+
+func writeKeyFile(dir string, key *SigningKey) error {
+	der, err := x509.MarshalPKCS8PrivateKey(key.private)
+	if err != nil {
+		return fmt.Errorf("failed to encode signing key: %w", err)
+	}
+
+	path := filepath.Join(dir, key.ID+".pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write signing key: %w", err)
+	}
+	return os.Chtimes(path, key.CreatedAt, key.CreatedAt)
+}
+
+func readKeyFile(path string, id string) (*SigningKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key %s: %w", id, err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key %s: %w", id, err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("signing key %s is not PEM encoded", id)
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signing key %s: %w", id, err)
+	}
+
+	key := &SigningKey{ID: id, CreatedAt: info.ModTime().UTC()}
+	switch private := parsed.(type) {
+	case ed25519.PrivateKey:
+		key.Algorithm = AlgorithmEdDSA
+		key.private = private
+	case *rsa.PrivateKey:
+		key.Algorithm = AlgorithmRS256
+		key.private = private
+	default:
+		return nil, fmt.Errorf("signing key %s has unsupported type %T", id, parsed)
+	}
+	return key, nil
+}
@@ -0,0 +1,89 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyRingSignsWithKeyID(t *testing.T) {
+	for _, algorithm := range []string{AlgorithmEdDSA, AlgorithmRS256} {
+		keys, err := NewKeyRing(algorithm, time.Hour, "")
+		require.NoError(t, err)
+
+		signed, err := keys.Sign(jwt.MapClaims{"sub": "user-1"})
+		require.NoError(t, err)
+
+		token, err := jwt.Parse(signed, keys.Keyfunc)
+		require.NoError(t, err, algorithm)
+		assert.Equal(t, algorithm, token.Method.Alg())
+		assert.Equal(t, keys.JWKS().Keys[0].KeyID, token.Header["kid"])
+	}
+}
+
+func TestKeyRingRotation(t *testing.T) {
+	keys, err := NewKeyRing(AlgorithmEdDSA, time.Hour, "")
+	require.NoError(t, err)
+	now := time.Now()
+	keys.now = func() time.Time { return now }
+
+	old, err := keys.Sign(jwt.MapClaims{"sub": "user-1"})
+	require.NoError(t, err)
+
+	now = now.Add(time.Minute)
+	_, err = keys.Rotate()
+	require.NoError(t, err)
+	require.Len(t, keys.JWKS().Keys, 2)
+
+	// Tokens of the retired key verify until the retention period is over
+	_, err = jwt.Parse(old, keys.Keyfunc)
+	assert.NoError(t, err)
+
+	now = now.Add(2 * time.Hour)
+	_, err = keys.Rotate()
+	require.NoError(t, err)
+	assert.Len(t, keys.JWKS().Keys, 2)
+	_, err = jwt.Parse(old, keys.Keyfunc)
+	assert.Error(t, err)
+}
+
+func TestKeyRingSharesKeysThroughDirectory(t *testing.T) {
+	dir := t.TempDir()
+	first, err := NewKeyRing(AlgorithmEdDSA, time.Hour, dir)
+	require.NoError(t, err)
+	second, err := NewKeyRing(AlgorithmEdDSA, time.Hour, dir)
+	require.NoError(t, err)
+
+	_, err = first.Rotate()
+	require.NoError(t, err)
+	require.NoError(t, second.Reload())
+
+	signed, err := first.Sign(jwt.MapClaims{"sub": "user-1"})
+	require.NoError(t, err)
+	_, err = jwt.Parse(signed, second.Keyfunc)
+	assert.NoError(t, err)
+}
+
+func TestKeyRingHS256MigrationWindow(t *testing.T) {
+	keys, err := NewKeyRing(AlgorithmEdDSA, time.Hour, "")
+	require.NoError(t, err)
+	now := time.Now()
+	keys.now = func() time.Time { return now }
+
+	legacy, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "user-1"}).SignedString([]byte("legacy"))
+	require.NoError(t, err)
+
+	_, err = jwt.Parse(legacy, keys.Keyfunc)
+	assert.Error(t, err)
+
+	keys.AcceptHS256([]byte("legacy"), now.Add(time.Minute))
+	_, err = jwt.Parse(legacy, keys.Keyfunc)
+	assert.NoError(t, err)
+
+	now = now.Add(2 * time.Minute)
+	_, err = jwt.Parse(legacy, keys.Keyfunc)
+	assert.Error(t, err)
+}
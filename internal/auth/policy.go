@@ -0,0 +1,254 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"logistics-marketplace/internal/problem"
+)
+
+// ForbiddenError is returned when an identity may not perform an action
+type ForbiddenError struct {
+	Reason string
+}
+
+func (e *ForbiddenError) Error() string {
+	return e.Reason
+}
+
+// StatusCode is the HTTP status answering the error
+func (e *ForbiddenError) StatusCode() int {
+	return http.StatusForbidden
+}
+
+func forbidden(format string, args ...interface{}) error {
+	return &ForbiddenError{Reason: fmt.Sprintf(format, args...)}
+}
+
+// writeMethods are the HTTP methods that change state
+var writeMethods = []string{http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+
+// rule grants the routes under a path prefix to a set of roles
+type rule struct {
+	prefix  string
+	methods []string // Empty applies to every method
+	roles   []Role   // Empty leaves the routes to administrators
+	open    bool     // Grants the routes to every authenticated identity
+}
+
+func (r rule) matches(method string, route string) bool {
+	if route != r.prefix && !strings.HasPrefix(route, strings.TrimSuffix(r.prefix, "/")+"/") {
+		return false
+	}
+	if len(r.methods) == 0 {
+		return true
+	}
+	for _, m := range r.methods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// scopeRule names the API key scope resource of the routes under a path prefix
+type scopeRule struct {
+	prefix   string
+	resource string
+}
+
+// Policy decides which roles may call which routes. Routes match rules by
+// path prefix on the registered route pattern; every matching rule must be
+// satisfied and administrators may call every route. Reads without a rule
+// are open to any authenticated identity, while state-changing calls
+// without a rule are reserved to administrators. API keys may additionally
+// only call routes with a scope rule whose scope they were granted.
+type Policy struct {
+	rules  []rule
+	scopes []scopeRule
+}
+
+// NewPolicy creates a new Policy instance
+func NewPolicy() *Policy {
+	return &Policy{}
+}
+
+// Allow grants every method of the routes under prefix to the given roles
+func (p *Policy) Allow(prefix string, roles ...Role) *Policy {
+	p.rules = append(p.rules, rule{prefix: prefix, roles: roles})
+	return p
+}
+
+// AllowWrite grants the state-changing methods of the routes under prefix to the given roles
+func (p *Policy) AllowWrite(prefix string, roles ...Role) *Policy {
+	p.rules = append(p.rules, rule{prefix: prefix, methods: writeMethods, roles: roles})
+	return p
+}
+
+// OpenWrite grants the state-changing methods of the routes under prefix to
+// every authenticated identity, leaving the checks to the handlers
+func (p *Policy) OpenWrite(prefix string) *Policy {
+	p.rules = append(p.rules, rule{prefix: prefix, methods: writeMethods, open: true})
+	return p
+}
+
+// Scope puts the routes under prefix in a scope resource: API keys need
+// resource:read to read them and resource:write for every other method
+func (p *Policy) Scope(prefix string, resource string) *Policy {
+	p.scopes = append(p.scopes, scopeRule{prefix: prefix, resource: resource})
+	return p
+}
+
+// Authorize checks that the identity may call the route and returns the role
+// it acts as, taken from the most specific matching rule
+func (p *Policy) Authorize(id *Identity, method string, route string) (Role, error) {
+	if id.APIKeyID != "" {
+		if err := p.authorizeScope(id, method, route); err != nil {
+			return "", err
+		}
+	}
+
+	var acting Role
+	actingPrefix := -1
+	covered := false
+
+	for _, r := range p.rules {
+		if !r.matches(method, route) {
+			continue
+		}
+		covered = true
+		if r.open {
+			continue
+		}
+
+		var granted Role
+		for _, role := range r.roles {
+			if id.HasRole(role) {
+				granted = role
+				break
+			}
+		}
+		if granted == "" {
+			if !id.IsAdmin() {
+				return "", forbidden("%s %s requires one of the roles %s", method, route, formatRoles(r.roles))
+			}
+			granted = RoleAdmin
+		}
+
+		if len(r.prefix) > actingPrefix {
+			acting = granted
+			actingPrefix = len(r.prefix)
+		}
+	}
+
+	if !covered && isWrite(method) {
+		if !id.IsAdmin() {
+			return "", forbidden("%s %s has no access rule and is reserved to administrators", method, route)
+		}
+		acting = RoleAdmin
+	}
+
+	return acting, nil
+}
+
+// authorizeScope checks an API key against the most specific scope rule of the route
+func (p *Policy) authorizeScope(id *Identity, method string, route string) error {
+	var resource string
+	resourcePrefix := -1
+	for _, s := range p.scopes {
+		if (rule{prefix: s.prefix}).matches(method, route) && len(s.prefix) > resourcePrefix {
+			resource = s.resource
+			resourcePrefix = len(s.prefix)
+		}
+	}
+	if resource == "" {
+		return forbidden("%s %s cannot be called with an API key", method, route)
+	}
+
+	access := ScopeWrite
+	if method == http.MethodGet || method == http.MethodHead {
+		access = ScopeRead
+	}
+	required := Scope(resource + ":" + access)
+	if !id.HasScope(required) {
+		return forbidden("%s %s requires the scope %s", method, route, required)
+	}
+	return nil
+}
+
+// Middleware enforces the policy on every route, answering 403 with the reason
+func (p *Policy) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := FromContext(c)
+		if !ok {
+			// Unauthenticated routes are exempted by the authentication middleware
+			c.Next()
+			return
+		}
+
+		role, err := p.Authorize(id, c.Request.Method, c.FullPath())
+		if err != nil {
+			problem.Abort(c, http.StatusForbidden, err.Error())
+			return
+		}
+
+		c.Set(actingRoleKey, role)
+		c.Next()
+	}
+}
+
+func isWrite(method string) bool {
+	for _, m := range writeMethods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+func formatRoles(roles []Role) string {
+	if len(roles) == 0 {
+		return "[ADMIN]"
+	}
+	names := make([]string, len(roles))
+	for i, role := range roles {
+		names[i] = string(role)
+	}
+	return "[" + strings.Join(names, ", ") + "]"
+}
+
+// BookingParties are the parties of a booking that may access it
+type BookingParties struct {
+	BookingID  string
+	CustomerID string
+	ProviderID string
+}
+
+// ErrBookingNotFound is returned when looking up a booking that does not exist
+var ErrBookingNotFound = errors.New("booking not found")
+
+// BookingLookup resolves the parties of a booking, failing with
+// ErrBookingNotFound when there is no such booking
+type BookingLookup interface {
+	GetBookingParties(ctx context.Context, bookingID string) (*BookingParties, error)
+}
+
+// CanViewBooking allows the customer, the provider and administrators
+func CanViewBooking(id *Identity, booking *BookingParties) error {
+	if id.IsAdmin() || id.Is(booking.CustomerID) || id.Is(booking.ProviderID) {
+		return nil
+	}
+	return forbidden("only the customer or provider of booking %s may view it", booking.BookingID)
+}
+
+// CanUpdateShipment allows the booking's provider and administrators
+func CanUpdateShipment(id *Identity, booking *BookingParties) error {
+	if id.IsAdmin() || id.Is(booking.ProviderID) {
+		return nil
+	}
+	return forbidden("only the provider of booking %s may update its shipment", booking.BookingID)
+}
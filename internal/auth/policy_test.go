@@ -0,0 +1,154 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testPolicy() *Policy {
+	return NewPolicy().
+		Allow("/api/v1/users/shipper", RoleShipper).
+		Allow("/api/v1/users/consignee", RoleConsignee).
+		AllowWrite("/api/v1/infrastructure", RoleInfrastructureOperator).
+		AllowWrite("/api/v1/staking/bookings/:id/dispute/resolve").
+		OpenWrite("/api/v1/governance/proposals").
+		AllowWrite("/api/v1/governance/proposals/:id/execute")
+}
+
+func TestIdentityFromClaims(t *testing.T) {
+	id, err := IdentityFromClaims(map[string]interface{}{
+		"sub":          "user-1",
+		"user_address": "GADDR",
+		"company_id":   "acme",
+		"roles":        []interface{}{"SHIPPER", "CONSIGNEE"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", id.UserID)
+	assert.Equal(t, "acme", id.CompanyID)
+	assert.True(t, id.HasRole(RoleConsignee))
+	assert.False(t, id.IsAdmin())
+	assert.True(t, id.Is("acme"))
+
+	_, err = IdentityFromClaims(map[string]interface{}{
+		"user_address": "GADDR",
+		"roles":        []interface{}{"SUPERUSER"},
+	})
+	assert.Error(t, err)
+
+	_, err = IdentityFromClaims(map[string]interface{}{"user_address": "GADDR"})
+	assert.Error(t, err)
+}
+
+func TestPolicyAuthorize(t *testing.T) {
+	policy := testPolicy()
+	shipper := &Identity{UserID: "u1", Roles: []Role{RoleShipper}}
+	admin := &Identity{UserID: "a1", Roles: []Role{RoleAdmin}}
+
+	role, err := policy.Authorize(shipper, http.MethodPost, "/api/v1/users/shipper/bookings")
+	require.NoError(t, err)
+	assert.Equal(t, RoleShipper, role)
+
+	// A shipper token cannot act as a consignee
+	_, err = policy.Authorize(shipper, http.MethodGet, "/api/v1/users/consignee/bookings")
+	var forbiddenErr *ForbiddenError
+	require.ErrorAs(t, err, &forbiddenErr)
+	assert.Contains(t, forbiddenErr.Reason, "CONSIGNEE")
+
+	// Write rules leave reads open
+	_, err = policy.Authorize(shipper, http.MethodGet, "/api/v1/infrastructure/airports/:id/capacity")
+	assert.NoError(t, err)
+	_, err = policy.Authorize(shipper, http.MethodPut, "/api/v1/infrastructure/airports/:id/capacity")
+	assert.Error(t, err)
+
+	// Rules without roles are reserved to administrators
+	_, err = policy.Authorize(shipper, http.MethodPost, "/api/v1/staking/bookings/:id/dispute/resolve")
+	assert.Error(t, err)
+	role, err = policy.Authorize(admin, http.MethodPost, "/api/v1/staking/bookings/:id/dispute/resolve")
+	require.NoError(t, err)
+	assert.Equal(t, RoleAdmin, role)
+
+	// Prefixes match whole path segments only
+	_, err = policy.Authorize(shipper, http.MethodGet, "/api/v1/users/shippers")
+	assert.NoError(t, err)
+}
+
+func TestPolicyDeniesUnruledWrites(t *testing.T) {
+	policy := testPolicy()
+	shipper := &Identity{UserID: "u1", Roles: []Role{RoleShipper}}
+	admin := &Identity{UserID: "a1", Roles: []Role{RoleAdmin}}
+
+	tests := []struct {
+		name     string
+		id       *Identity
+		method   string
+		route    string
+		wantRole Role
+		wantErr  string
+	}{
+		{name: "unruled read", id: shipper, method: http.MethodGet, route: "/api/v1/governance/treasury/report"},
+		{name: "unruled write", id: shipper, method: http.MethodPost, route: "/api/v1/governance/treasury/deposits", wantErr: "POST /api/v1/governance/treasury/deposits has no access rule and is reserved to administrators"},
+		{name: "unruled delete", id: shipper, method: http.MethodDelete, route: "/api/v1/api-keys/:id", wantErr: "DELETE /api/v1/api-keys/:id has no access rule and is reserved to administrators"},
+		{name: "unruled write by administrator", id: admin, method: http.MethodPost, route: "/api/v1/governance/treasury/deposits", wantRole: RoleAdmin},
+		{name: "open write", id: shipper, method: http.MethodPost, route: "/api/v1/governance/proposals/:id/vote"},
+		{name: "open write narrowed", id: shipper, method: http.MethodPost, route: "/api/v1/governance/proposals/:id/execute", wantErr: "POST /api/v1/governance/proposals/:id/execute requires one of the roles [ADMIN]"},
+		{name: "open write narrowed for administrator", id: admin, method: http.MethodPost, route: "/api/v1/governance/proposals/:id/execute", wantRole: RoleAdmin},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			role, err := policy.Authorize(tt.id, tt.method, tt.route)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantRole, role)
+		})
+	}
+}
+
+func TestPolicyMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		SetIdentity(c, &Identity{UserID: "u1", Roles: []Role{RoleConsignee}})
+		c.Next()
+	})
+	router.Use(testPolicy().Middleware())
+	router.GET("/api/v1/users/consignee/bookings", func(c *gin.Context) {
+		c.String(http.StatusOK, string(ActingRole(c)))
+	})
+	router.GET("/api/v1/users/shipper/bookings", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/users/consignee/bookings", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "CONSIGNEE", w.Body.String())
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/users/shipper/bookings", nil))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "requires one of the roles [SHIPPER]")
+}
+
+func TestBookingAccess(t *testing.T) {
+	booking := &BookingParties{BookingID: "BK-1", CustomerID: "acme", ProviderID: "fwd-1"}
+
+	customerColleague := &Identity{UserID: "u2", CompanyID: "acme", Roles: []Role{RoleShipper}}
+	provider := &Identity{UserID: "fwd-1", Roles: []Role{RoleFreightForwarder}}
+	otherForwarder := &Identity{UserID: "fwd-2", Roles: []Role{RoleFreightForwarder}}
+
+	assert.NoError(t, CanViewBooking(customerColleague, booking))
+	assert.NoError(t, CanViewBooking(provider, booking))
+	assert.Error(t, CanViewBooking(otherForwarder, booking))
+
+	assert.NoError(t, CanUpdateShipment(provider, booking))
+	assert.Error(t, CanUpdateShipment(customerColleague, booking))
+	assert.Error(t, CanUpdateShipment(otherForwarder, booking))
+}
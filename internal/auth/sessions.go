@@ -0,0 +1,383 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// Default token lifetimes
+const (
+	DefaultAccessTokenTTL  = 15 * time.Minute
+	DefaultRefreshTokenTTL = 30 * 24 * time.Hour
+)
+
+var (
+	// ErrInvalidRefreshToken is returned for an unknown or expired refresh token
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	// ErrRefreshTokenReused is returned when a rotated refresh token is presented
+	// again; the whole session is revoked since the token may have been stolen
+	ErrRefreshTokenReused = errors.New("refresh token reused, session revoked")
+)
+
+// TokenPair is returned by login and refresh
+type TokenPair struct {
+	AccessToken      string    `json:"access_token"`
+	TokenType        string    `json:"token_type"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+}
+
+// session is a login, kept server-side and extended by each refresh
+type session struct {
+	id           string
+	username     string
+	refreshHash  string // Hash of the only refresh token currently valid
+	previousHash string // Hash of the token it replaced, kept to detect reuse
+	expiresAt    time.Time
+}
+
+// sessionState is the saved form of the sessions and revocations
+type sessionState struct {
+	Sessions        []sessionRecord      `json:"sessions"`
+	RevokedTokens   map[string]time.Time `json:"revoked_tokens"`
+	RevokedSessions map[string]time.Time `json:"revoked_sessions"`
+}
+
+type sessionRecord struct {
+	ID           string    `json:"id"`
+	Username     string    `json:"username"`
+	RefreshHash  string    `json:"refresh_hash"`
+	PreviousHash string    `json:"previous_hash,omitempty"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// SessionManager issues access tokens and rotating refresh tokens, and
+// tracks revoked sessions and tokens until they would have expired anyway.
+// State is persisted to a JSON file when the manager has a path, so logins
+// and revocations survive restarts; it is not shared between instances, so
+// the API must run as a single instance.
+type SessionManager struct {
+	path       string
+	users      *UserStore
+	keys       *KeyRing
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+	now        func() time.Time
+
+	mu             sync.Mutex
+	sessions       map[string]*session  // By session ID
+	refreshTokens  map[string]string    // Current and previous refresh token hashes to session ID
+	revokedTokens  map[string]time.Time // Access token ID to expiry
+	revokedSession map[string]time.Time // Session ID to the expiry of its last access token
+}
+
+// NewSessionManager creates a new SessionManager instance, loading the sessions in path if it exists
+func NewSessionManager(users *UserStore, keys *KeyRing, accessTTL time.Duration, refreshTTL time.Duration, path string) (*SessionManager, error) {
+	m := &SessionManager{
+		path:           path,
+		users:          users,
+		keys:           keys,
+		accessTTL:      accessTTL,
+		refreshTTL:     refreshTTL,
+		now:            time.Now,
+		sessions:       make(map[string]*session),
+		refreshTokens:  make(map[string]string),
+		revokedTokens:  make(map[string]time.Time),
+		revokedSession: make(map[string]time.Time),
+	}
+	if path == "" {
+		return m, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sessions: %w", err)
+	}
+
+	var state sessionState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse sessions: %w", err)
+	}
+	for _, record := range state.Sessions {
+		s := &session{
+			id:           record.ID,
+			username:     record.Username,
+			refreshHash:  record.RefreshHash,
+			previousHash: record.PreviousHash,
+			expiresAt:    record.ExpiresAt,
+		}
+		m.sessions[s.id] = s
+		m.refreshTokens[s.refreshHash] = s.id
+		if s.previousHash != "" {
+			m.refreshTokens[s.previousHash] = s.id
+		}
+	}
+	for tokenID, expiresAt := range state.RevokedTokens {
+		m.revokedTokens[tokenID] = expiresAt
+	}
+	for sessionID, expiresAt := range state.RevokedSessions {
+		m.revokedSession[sessionID] = expiresAt
+	}
+	return m, nil
+}
+
+// Login authenticates a user and starts a session
+func (m *SessionManager) Login(username string, password string) (*TokenPair, error) {
+	user, err := m.users.Authenticate(username, password)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionID, err := randomToken(16)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.pruneSessions()
+	s := &session{id: sessionID, username: user.Username}
+	m.sessions[sessionID] = s
+	return m.issueAndSave(s, user)
+}
+
+// Refresh exchanges a refresh token for a new token pair; the presented
+// refresh token stops being valid
+func (m *SessionManager) Refresh(refreshToken string) (*TokenPair, error) {
+	hash := hashToken(refreshToken)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	sessionID, ok := m.refreshTokens[hash]
+	if !ok {
+		return nil, ErrInvalidRefreshToken
+	}
+	s, ok := m.sessions[sessionID]
+	if !ok {
+		return nil, ErrInvalidRefreshToken
+	}
+	if s.refreshHash != hash {
+		m.revokeSession(s)
+		if err := m.save(); err != nil {
+			return nil, err
+		}
+		return nil, ErrRefreshTokenReused
+	}
+
+	// Pick up role changes and disabled accounts
+	user, ok := m.users.Get(s.username)
+	if m.now().After(s.expiresAt) || !ok || user.Disabled {
+		m.revokeSession(s)
+		if err := m.save(); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidRefreshToken
+	}
+
+	return m.issueAndSave(s, user)
+}
+
+// Logout ends the session of the caller's access token, revoking its
+// refresh token and every access token issued in it
+func (m *SessionManager) Logout(id *Identity) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if id.TokenID != "" {
+		m.revokedTokens[id.TokenID] = m.now().Add(m.accessTTL)
+	}
+	if s, ok := m.sessions[id.SessionID]; ok {
+		m.revokeSession(s)
+	}
+	return m.save()
+}
+
+// IsRevoked reports whether the caller's access token was revoked by logout
+// or by a revoked session
+func (m *SessionManager) IsRevoked(id *Identity) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.pruneRevocations()
+	if _, ok := m.revokedTokens[id.TokenID]; ok && id.TokenID != "" {
+		return true
+	}
+	_, ok := m.revokedSession[id.SessionID]
+	return ok && id.SessionID != ""
+}
+
+// issue mints an access token and rotates the session's refresh token
+func (m *SessionManager) issue(s *session, user *User) (*TokenPair, error) {
+	now := m.now()
+
+	tokenID, err := randomToken(16)
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, err := randomToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	roles := make([]string, len(user.Roles))
+	for i, role := range user.Roles {
+		roles[i] = string(role)
+	}
+
+	accessExpiresAt := now.Add(m.accessTTL)
+	accessToken, err := m.keys.Sign(jwt.MapClaims{
+		"sub":          user.UserID,
+		"user_address": user.Address,
+		"company_id":   user.CompanyID,
+		"roles":        roles,
+		"tier":         user.Tier,
+		"sid":          s.id,
+		"jti":          tokenID,
+		"iat":          now.Unix(),
+		"exp":          accessExpiresAt.Unix(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign access token: %w", err)
+	}
+
+	// The previous refresh token stays known so its reuse can be detected;
+	// the one before it is forgotten
+	if s.previousHash != "" {
+		delete(m.refreshTokens, s.previousHash)
+	}
+	s.previousHash = s.refreshHash
+	s.refreshHash = hashToken(refreshToken)
+	s.expiresAt = now.Add(m.refreshTTL)
+	m.refreshTokens[s.refreshHash] = s.id
+
+	return &TokenPair{
+		AccessToken:      accessToken,
+		TokenType:        "Bearer",
+		ExpiresAt:        accessExpiresAt,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: s.expiresAt,
+	}, nil
+}
+
+// issueAndSave issues a token pair and saves the rotated refresh token
+func (m *SessionManager) issueAndSave(s *session, user *User) (*TokenPair, error) {
+	tokens, err := m.issue(s, user)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.save(); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// revokeSession ends a session; its access tokens stay revoked until the
+// last of them would have expired
+func (m *SessionManager) revokeSession(s *session) {
+	delete(m.sessions, s.id)
+	delete(m.refreshTokens, s.refreshHash)
+	delete(m.refreshTokens, s.previousHash)
+	m.revokedSession[s.id] = m.now().Add(m.accessTTL)
+}
+
+// pruneSessions forgets sessions whose refresh token expired unused
+func (m *SessionManager) pruneSessions() {
+	now := m.now()
+	for _, s := range m.sessions {
+		if now.After(s.expiresAt) {
+			m.revokeSession(s)
+		}
+	}
+}
+
+func (m *SessionManager) pruneRevocations() {
+	now := m.now()
+	for jti, expiresAt := range m.revokedTokens {
+		if now.After(expiresAt) {
+			delete(m.revokedTokens, jti)
+		}
+	}
+	for sessionID, expiresAt := range m.revokedSession {
+		if now.After(expiresAt) {
+			delete(m.revokedSession, sessionID)
+		}
+	}
+}
+
+// save writes the sessions and revocations to the manager's file, replacing it atomically
+func (m *SessionManager) save() error {
+	if m.path == "" {
+		return nil
+	}
+
+	m.pruneSessions()
+	m.pruneRevocations()
+	state := sessionState{
+		Sessions:        make([]sessionRecord, 0, len(m.sessions)),
+		RevokedTokens:   m.revokedTokens,
+		RevokedSessions: m.revokedSession,
+	}
+	for _, s := range m.sessions {
+		state.Sessions = append(state.Sessions, sessionRecord{
+			ID:           s.id,
+			Username:     s.username,
+			RefreshHash:  s.refreshHash,
+			PreviousHash: s.previousHash,
+			ExpiresAt:    s.expiresAt,
+		})
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to encode sessions: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(m.path), ".sessions-*")
+	if err != nil {
+		return fmt.Errorf("failed to save sessions: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save sessions: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to save sessions: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), m.path); err != nil {
+		return fmt.Errorf("failed to save sessions: %w", err)
+	}
+	return nil
+}
+
+func randomToken(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// hashToken hashes a refresh token for storage
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
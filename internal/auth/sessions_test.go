@@ -0,0 +1,184 @@
+package auth
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func newTestSessions(t *testing.T) (*SessionManager, *User) {
+	return newTestSessionsAt(t, "")
+}
+
+func newTestSessionsAt(t *testing.T, path string) (*SessionManager, *User) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("s3cret"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	user := &User{
+		Username:     "alice",
+		PasswordHash: string(hash),
+		UserID:       "user-1",
+		Address:      "GALICE",
+		CompanyID:    "acme",
+		Roles:        []Role{RoleShipper},
+	}
+	users, err := NewUserStore([]*User{user})
+	require.NoError(t, err)
+
+	keys, err := NewKeyRing(AlgorithmEdDSA, time.Minute, "")
+	require.NoError(t, err)
+
+	sessions, err := NewSessionManager(users, keys, time.Minute, time.Hour, path)
+	require.NoError(t, err)
+	return sessions, user
+}
+
+// parseIdentity verifies an access token the way the auth middleware does
+func parseIdentity(t *testing.T, sessions *SessionManager, accessToken string) *Identity {
+	token, err := jwt.Parse(accessToken, sessions.keys.Keyfunc)
+	require.NoError(t, err)
+
+	id, err := IdentityFromClaims(token.Claims.(jwt.MapClaims))
+	require.NoError(t, err)
+	return id
+}
+
+func TestLoginIssuesTokens(t *testing.T) {
+	sessions, _ := newTestSessions(t)
+
+	_, err := sessions.Login("alice", "wrong")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+	_, err = sessions.Login("bob", "s3cret")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+
+	tokens, err := sessions.Login("alice", "s3cret")
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer", tokens.TokenType)
+	assert.NotEmpty(t, tokens.RefreshToken)
+
+	id := parseIdentity(t, sessions, tokens.AccessToken)
+	assert.Equal(t, "user-1", id.UserID)
+	assert.Equal(t, "acme", id.CompanyID)
+	assert.Equal(t, []Role{RoleShipper}, id.Roles)
+	assert.NotEmpty(t, id.SessionID)
+	assert.False(t, sessions.IsRevoked(id))
+}
+
+func TestRefreshRotatesAndDetectsReuse(t *testing.T) {
+	sessions, _ := newTestSessions(t)
+
+	first, err := sessions.Login("alice", "s3cret")
+	require.NoError(t, err)
+
+	second, err := sessions.Refresh(first.RefreshToken)
+	require.NoError(t, err)
+	assert.NotEqual(t, first.RefreshToken, second.RefreshToken)
+
+	// Presenting the rotated token again revokes the whole session
+	_, err = sessions.Refresh(first.RefreshToken)
+	assert.ErrorIs(t, err, ErrRefreshTokenReused)
+	_, err = sessions.Refresh(second.RefreshToken)
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+	assert.True(t, sessions.IsRevoked(parseIdentity(t, sessions, second.AccessToken)))
+}
+
+func TestRefreshKeepsOnlyThePreviousToken(t *testing.T) {
+	sessions, _ := newTestSessions(t)
+	now := time.Now()
+	sessions.now = func() time.Time { return now }
+
+	first, err := sessions.Login("alice", "s3cret")
+	require.NoError(t, err)
+	tokens := first
+	for i := 0; i < 3; i++ {
+		tokens, err = sessions.Refresh(tokens.RefreshToken)
+		require.NoError(t, err)
+	}
+	assert.Len(t, sessions.refreshTokens, 2)
+
+	// Tokens older than the previous one are forgotten rather than reused
+	_, err = sessions.Refresh(first.RefreshToken)
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+
+	// Hashes of expired sessions are pruned with them
+	now = now.Add(2 * time.Hour)
+	_, err = sessions.Login("alice", "s3cret")
+	require.NoError(t, err)
+	assert.Len(t, sessions.sessions, 1)
+	assert.Len(t, sessions.refreshTokens, 1)
+}
+
+func TestRefreshPicksUpDisabledAccounts(t *testing.T) {
+	sessions, user := newTestSessions(t)
+
+	tokens, err := sessions.Login("alice", "s3cret")
+	require.NoError(t, err)
+
+	user.Disabled = true
+	_, err = sessions.Refresh(tokens.RefreshToken)
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+}
+
+func TestRefreshTokenExpires(t *testing.T) {
+	sessions, _ := newTestSessions(t)
+	now := time.Now()
+	sessions.now = func() time.Time { return now }
+
+	tokens, err := sessions.Login("alice", "s3cret")
+	require.NoError(t, err)
+
+	now = now.Add(2 * time.Hour)
+	_, err = sessions.Refresh(tokens.RefreshToken)
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+}
+
+func TestLogoutRevokesSession(t *testing.T) {
+	sessions, _ := newTestSessions(t)
+
+	tokens, err := sessions.Login("alice", "s3cret")
+	require.NoError(t, err)
+	refreshed, err := sessions.Refresh(tokens.RefreshToken)
+	require.NoError(t, err)
+
+	require.NoError(t, sessions.Logout(parseIdentity(t, sessions, refreshed.AccessToken)))
+
+	// Every access token of the session is revoked, and it cannot be refreshed
+	assert.True(t, sessions.IsRevoked(parseIdentity(t, sessions, tokens.AccessToken)))
+	assert.True(t, sessions.IsRevoked(parseIdentity(t, sessions, refreshed.AccessToken)))
+	_, err = sessions.Refresh(refreshed.RefreshToken)
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+
+	// Other sessions are unaffected
+	other, err := sessions.Login("alice", "s3cret")
+	require.NoError(t, err)
+	assert.False(t, sessions.IsRevoked(parseIdentity(t, sessions, other.AccessToken)))
+}
+
+func TestSessionsSurviveRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sessions.json")
+	sessions, _ := newTestSessionsAt(t, path)
+
+	kept, err := sessions.Login("alice", "s3cret")
+	require.NoError(t, err)
+	rotated, err := sessions.Refresh(kept.RefreshToken)
+	require.NoError(t, err)
+	ended, err := sessions.Login("alice", "s3cret")
+	require.NoError(t, err)
+	require.NoError(t, sessions.Logout(parseIdentity(t, sessions, ended.AccessToken)))
+
+	reopened, _ := newTestSessionsAt(t, path)
+	reopened.keys = sessions.keys
+
+	// Logouts stay revoked and rotated refresh tokens are still recognised
+	assert.True(t, reopened.IsRevoked(parseIdentity(t, reopened, ended.AccessToken)))
+	_, err = reopened.Refresh(ended.RefreshToken)
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+	assert.False(t, reopened.IsRevoked(parseIdentity(t, reopened, rotated.AccessToken)))
+	_, err = reopened.Refresh(kept.RefreshToken)
+	assert.ErrorIs(t, err, ErrRefreshTokenReused)
+}
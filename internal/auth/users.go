@@ -0,0 +1,101 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ErrInvalidCredentials is returned for an unknown user, a wrong password or a disabled account
+var ErrInvalidCredentials = errors.New("invalid username or password")
+
+// dummyHash is compared against for unknown users so they take as long as wrong passwords
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("unknown user"), bcrypt.DefaultCost)
+
+// User is an account allowed to log in
+type User struct {
+	Username     string `json:"username"`
+	PasswordHash string `json:"password_hash"` // bcrypt
+	UserID       string `json:"user_id"`
+	Address      string `json:"user_address"`
+	CompanyID    string `json:"company_id,omitempty"`
+	Roles        []Role `json:"roles"`
+	Tier         string `json:"tier,omitempty"`
+	Disabled     bool   `json:"disabled,omitempty"`
+}
+
+// Identity returns the identity the user authenticates as
+func (u *User) Identity() *Identity {
+	return &Identity{
+		UserID:    u.UserID,
+		Address:   u.Address,
+		CompanyID: u.CompanyID,
+		Roles:     append([]Role(nil), u.Roles...),
+		Tier:      u.Tier,
+	}
+}
+
+// UserStore holds the accounts allowed to log in
+type UserStore struct {
+	users map[string]*User
+}
+
+// NewUserStore creates a new UserStore instance
+func NewUserStore(users []*User) (*UserStore, error) {
+	store := &UserStore{users: make(map[string]*User)}
+	for _, user := range users {
+		if user.Username == "" || user.UserID == "" || user.Address == "" {
+			return nil, fmt.Errorf("user %q needs a username, user_id and user_address", user.Username)
+		}
+		for _, role := range user.Roles {
+			if !validRoles[role] {
+				return nil, fmt.Errorf("user %s has unknown role %q", user.Username, role)
+			}
+		}
+		if _, exists := store.users[user.Username]; exists {
+			return nil, fmt.Errorf("duplicate user %s", user.Username)
+		}
+		store.users[user.Username] = user
+	}
+	return store, nil
+}
+
+// LoadUsers reads accounts from a JSON file
+func LoadUsers(path string) (*UserStore, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read users: %w", err)
+	}
+
+	var users []*User
+	if err := json.Unmarshal(data, &users); err != nil {
+		return nil, fmt.Errorf("failed to parse users: %w", err)
+	}
+
+	return NewUserStore(users)
+}
+
+// Authenticate checks a username and password
+func (s *UserStore) Authenticate(username string, password string) (*User, error) {
+	user, ok := s.users[username]
+	if !ok {
+		bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+		return nil, ErrInvalidCredentials
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+	if user.Disabled {
+		return nil, ErrInvalidCredentials
+	}
+	return user, nil
+}
+
+// Get returns an account by username
+func (s *UserStore) Get(username string) (*User, bool) {
+	user, ok := s.users[username]
+	return user, ok
+}
@@ -0,0 +1,152 @@
+// Package fabric connects the API to the logistics chaincode through the
+// Hyperledger Fabric Gateway.
+package fabric
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// Config holds what is needed to connect to the logistics chaincode
+type Config struct {
+	ConnectionProfile string
+	CertPath          string
+	KeyPath           string
+	Channel           string
+	Chaincode         string
+}
+
+// Contract invokes chaincode transactions
+type Contract interface {
+	// Submit endorses and commits a transaction
+	Submit(name string, transient map[string][]byte, args ...string) ([]byte, error)
+	// Evaluate runs a transaction on a peer without committing it
+	Evaluate(name string, transient map[string][]byte, args ...string) ([]byte, error)
+}
+
+// EventSource delivers chaincode events
+type EventSource interface {
+	ChaincodeEvents(ctx context.Context) (<-chan Event, error)
+}
+
+// Connection is an open gateway connection to the logistics chaincode
+type Connection struct {
+	conn    *grpc.ClientConn
+	gateway *client.Gateway
+	network *client.Network
+
+	Contract Contract
+	Events   EventSource
+}
+
+// Connect opens a gateway connection using the connection profile and client identity
+func Connect(cfg Config) (*Connection, error) {
+	profile, err := LoadConnectionProfile(cfg.ConnectionProfile)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint, err := profile.PeerEndpoint()
+	if err != nil {
+		return nil, err
+	}
+
+	id, sign, err := LoadIdentity(profile.MSPID(), cfg.CertPath, cfg.KeyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := insecure.NewCredentials()
+	if endpoint.TLS {
+		pool := x509.NewCertPool()
+		pool.AddCert(endpoint.TLSCACert)
+		transport = credentials.NewClientTLSFromCert(pool, endpoint.ServerNameOverride)
+	}
+
+	conn, err := grpc.Dial(endpoint.Address, grpc.WithTransportCredentials(transport))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to peer %s: %w", endpoint.Name, err)
+	}
+
+	gateway, err := client.Connect(
+		id,
+		client.WithSign(sign),
+		client.WithClientConnection(conn),
+		client.WithEvaluateTimeout(5*time.Second),
+		client.WithEndorseTimeout(15*time.Second),
+		client.WithSubmitTimeout(5*time.Second),
+		client.WithCommitStatusTimeout(time.Minute),
+	)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to connect to gateway: %w", err)
+	}
+
+	network := gateway.GetNetwork(cfg.Channel)
+
+	return &Connection{
+		conn:     conn,
+		gateway:  gateway,
+		network:  network,
+		Contract: &gatewayContract{contract: network.GetContract(cfg.Chaincode)},
+		Events:   &gatewayEvents{network: network, chaincode: cfg.Chaincode},
+	}, nil
+}
+
+// Close closes the gateway and its gRPC connection
+func (c *Connection) Close() error {
+	c.gateway.Close()
+	return c.conn.Close()
+}
+
+// gatewayContract adapts a Fabric Gateway contract to Contract
+type gatewayContract struct {
+	contract *client.Contract
+}
+
+func (c *gatewayContract) Submit(name string, transient map[string][]byte, args ...string) ([]byte, error) {
+	return c.contract.Submit(name, client.WithArguments(args...), client.WithTransient(transient))
+}
+
+func (c *gatewayContract) Evaluate(name string, transient map[string][]byte, args ...string) ([]byte, error) {
+	return c.contract.Evaluate(name, client.WithArguments(args...), client.WithTransient(transient))
+}
+
+// gatewayEvents adapts Fabric Gateway chaincode events to EventSource
+type gatewayEvents struct {
+	network   *client.Network
+	chaincode string
+}
+
+func (e *gatewayEvents) ChaincodeEvents(ctx context.Context) (<-chan Event, error) {
+	source, err := e.network.ChaincodeEvents(ctx, e.chaincode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for chaincode events: %w", err)
+	}
+
+	events := make(chan Event)
+	go func() {
+		defer close(events)
+		for event := range source {
+			select {
+			case events <- Event{
+				Name:          event.EventName,
+				Payload:       event.Payload,
+				TransactionID: event.TransactionID,
+				BlockNumber:   event.BlockNumber,
+			}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return events, nil
+}
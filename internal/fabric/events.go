@@ -0,0 +1,66 @@
+package fabric
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Chaincode event names
+const (
+	EventShipmentUpdated      = "ShipmentUpdated"
+	EventQuotationConfirmed   = "QuotationConfirmed"
+	EventCustomsStatusUpdated = "CustomsStatusUpdated"
+	EventLedgerRecorded       = "LedgerRecorded"
+)
+
+// Event is a chaincode event emitted by a committed transaction
+type Event struct {
+	Name          string
+	Payload       []byte
+	TransactionID string
+	BlockNumber   uint64
+}
+
+// Decode decodes the event payload into the asset the event carries
+func (e Event) Decode() (interface{}, error) {
+	var asset interface{}
+	switch e.Name {
+	case EventShipmentUpdated:
+		asset = &Shipment{}
+	case EventQuotationConfirmed:
+		asset = &FreightQuotation{}
+	case EventCustomsStatusUpdated:
+		asset = &CustomsDeclaration{}
+	case EventLedgerRecorded:
+		asset = &LedgerRecord{}
+	default:
+		return nil, fmt.Errorf("unknown chaincode event: %s", e.Name)
+	}
+
+	if err := json.Unmarshal(e.Payload, asset); err != nil {
+		return nil, fmt.Errorf("failed to decode %s event: %w", e.Name, err)
+	}
+	return asset, nil
+}
+
+// Listen calls handler for every chaincode event until the context is
+// cancelled or the event stream ends
+func Listen(ctx context.Context, source EventSource, handler func(Event)) error {
+	events, err := source.ChaincodeEvents(ctx)
+	if err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+			handler(event)
+		}
+	}
+}
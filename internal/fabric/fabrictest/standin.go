@@ -0,0 +1,94 @@
+// Package fabrictest provides an in-memory stand-in for the logistics
+// chaincode so Fabric clients can be tested without a running network.
+package fabrictest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"logistics-marketplace/internal/fabric"
+)
+
+// Handler answers a chaincode transaction
+type Handler func(transient map[string][]byte, args []string) ([]byte, error)
+
+// Call records a transaction invoked on the stand-in
+type Call struct {
+	Name      string
+	Submitted bool
+	Transient map[string][]byte
+	Args      []string
+}
+
+// StandIn implements fabric.Contract and fabric.EventSource in memory
+type StandIn struct {
+	mu       sync.Mutex
+	handlers map[string]Handler
+	calls    []Call
+	events   chan fabric.Event
+}
+
+// NewStandIn creates a new StandIn instance
+func NewStandIn() *StandIn {
+	return &StandIn{
+		handlers: make(map[string]Handler),
+		events:   make(chan fabric.Event, 16),
+	}
+}
+
+// On registers the handler for a transaction name
+func (s *StandIn) On(name string, handler Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[name] = handler
+}
+
+// Calls returns the transactions invoked so far
+func (s *StandIn) Calls() []Call {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Call(nil), s.calls...)
+}
+
+// Emit delivers a chaincode event to listeners
+func (s *StandIn) Emit(event fabric.Event) {
+	s.events <- event
+}
+
+// Close ends the event stream
+func (s *StandIn) Close() {
+	close(s.events)
+}
+
+// Submit records and answers a submitted transaction
+func (s *StandIn) Submit(name string, transient map[string][]byte, args ...string) ([]byte, error) {
+	return s.invoke(name, true, transient, args)
+}
+
+// Evaluate records and answers an evaluated transaction
+func (s *StandIn) Evaluate(name string, transient map[string][]byte, args ...string) ([]byte, error) {
+	return s.invoke(name, false, transient, args)
+}
+
+// ChaincodeEvents returns the stream of emitted events
+func (s *StandIn) ChaincodeEvents(ctx context.Context) (<-chan fabric.Event, error) {
+	return s.events, nil
+}
+
+func (s *StandIn) invoke(name string, submitted bool, transient map[string][]byte, args []string) ([]byte, error) {
+	s.mu.Lock()
+	s.calls = append(s.calls, Call{
+		Name:      name,
+		Submitted: submitted,
+		Transient: transient,
+		Args:      args,
+	})
+	handler, ok := s.handlers[name]
+	s.mu.Unlock()
+
+	if !ok {
+		return nil, fmt.Errorf("no handler for transaction %s", name)
+	}
+	return handler(transient, args)
+}
@@ -0,0 +1,239 @@
+package fabric
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+// LogisticsClient provides typed access to the LogisticsContract chaincode
+type LogisticsClient struct {
+	contract Contract
+}
+
+// NewLogisticsClient creates a new LogisticsClient instance
+func NewLogisticsClient(contract Contract) *LogisticsClient {
+	return &LogisticsClient{
+		contract: contract,
+	}
+}
+
+// CreateShipper registers a shipper owned by the client's organization
+func (c *LogisticsClient) CreateShipper(id string, name string) error {
+	return c.submit("CreateShipper", nil, id, name)
+}
+
+// CreateConsignee registers a consignee owned by the client's organization
+func (c *LogisticsClient) CreateConsignee(id string, name string) error {
+	return c.submit("CreateConsignee", nil, id, name)
+}
+
+// CreateFreightQuotation issues a quotation to a shipper, keeping its terms
+// private. Terms without a salt are given a random one.
+func (c *LogisticsClient) CreateFreightQuotation(id string, carrier string, shipperID string, terms QuotationTerms) error {
+	if terms.Salt == "" {
+		salt := make([]byte, 32)
+		if _, err := rand.Read(salt); err != nil {
+			return fmt.Errorf("failed to generate quotation salt: %w", err)
+		}
+		terms.Salt = hex.EncodeToString(salt)
+	}
+	transient, err := quotationTransient(terms)
+	if err != nil {
+		return err
+	}
+	return c.submit("CreateFreightQuotation", transient, id, carrier, shipperID)
+}
+
+// ConfirmFreightQuotation confirms a quotation against its private terms,
+// salt included, as read with ReadFreightQuotationTerms
+func (c *LogisticsClient) ConfirmFreightQuotation(id string, terms QuotationTerms) error {
+	transient, err := quotationTransient(terms)
+	if err != nil {
+		return err
+	}
+	return c.submit("ConfirmFreightQuotation", transient, id)
+}
+
+// ReadFreightQuotationTerms reads the private terms of a quotation
+func (c *LogisticsClient) ReadFreightQuotationTerms(id string) (*QuotationTerms, error) {
+	terms := &QuotationTerms{}
+	if err := c.evaluate("ReadFreightQuotationTerms", terms, id); err != nil {
+		return nil, err
+	}
+	return terms, nil
+}
+
+// BookShipment books a shipment for a shipper and consignee
+func (c *LogisticsClient) BookShipment(id string, shipperID string, consigneeID string) error {
+	return c.submit("BookShipment", nil, id, shipperID, consigneeID)
+}
+
+// TrackShipment reads the current state of a shipment
+func (c *LogisticsClient) TrackShipment(id string) (*Shipment, error) {
+	// TrackShipment returns a string, which the chaincode passes through unquoted
+	shipment := &Shipment{}
+	if err := c.evaluate("TrackShipment", shipment, id); err != nil {
+		return nil, err
+	}
+	return shipment, nil
+}
+
+// UpdateShipmentStatus moves a shipment to a new status
+func (c *LogisticsClient) UpdateShipmentStatus(id string, status string) error {
+	return c.submit("UpdateShipmentStatus", nil, id, status)
+}
+
+// SetRoutingInfo replaces the route of a shipment
+func (c *LogisticsClient) SetRoutingInfo(id string, legs []RouteLeg) error {
+	legsJSON, err := json.Marshal(legs)
+	if err != nil {
+		return fmt.Errorf("failed to encode route: %w", err)
+	}
+	return c.submit("SetRoutingInfo", nil, id, string(legsJSON))
+}
+
+// GetShipmentHistory reads every committed version of a shipment
+func (c *LogisticsClient) GetShipmentHistory(id string) ([]ShipmentHistoryEntry, error) {
+	var history []ShipmentHistoryEntry
+	if err := c.evaluate("GetShipmentHistory", &history, id); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// AddThirdPartyLogistics registers the client's organization as a 3PL provider
+func (c *LogisticsClient) AddThirdPartyLogistics(id string, name string, roles []string) error {
+	rolesJSON, err := json.Marshal(roles)
+	if err != nil {
+		return fmt.Errorf("failed to encode roles: %w", err)
+	}
+	return c.submit("AddThirdPartyLogistics", nil, id, name, string(rolesJSON))
+}
+
+// AssignThirdPartyLogistics assigns a 3PL provider to a role on a shipment
+func (c *LogisticsClient) AssignThirdPartyLogistics(shipmentID string, providerID string, role string) error {
+	return c.submit("AssignThirdPartyLogistics", nil, shipmentID, providerID, role)
+}
+
+// GetThirdPartyLogistics reads a 3PL provider
+func (c *LogisticsClient) GetThirdPartyLogistics(id string) (*ThirdPartyLogistics, error) {
+	provider := &ThirdPartyLogistics{}
+	if err := c.evaluate("GetThirdPartyLogistics", provider, id); err != nil {
+		return nil, err
+	}
+	return provider, nil
+}
+
+// FileCustomsDeclaration files a customs declaration for a shipment as its
+// assigned customs broker
+func (c *LogisticsClient) FileCustomsDeclaration(shipmentID string, declaration CustomsDeclaration) error {
+	declarationJSON, err := json.Marshal(declaration)
+	if err != nil {
+		return fmt.Errorf("failed to encode customs declaration: %w", err)
+	}
+	return c.submit("HandleCustomsOperations", nil, shipmentID, string(declarationJSON))
+}
+
+// UpdateCustomsStatus records a customs decision on a declaration
+func (c *LogisticsClient) UpdateCustomsStatus(declarationNumber string, status string, reason string) error {
+	return c.submit("UpdateCustomsStatus", nil, declarationNumber, status, reason)
+}
+
+// GetCustomsDeclaration reads a customs declaration
+func (c *LogisticsClient) GetCustomsDeclaration(declarationNumber string) (*CustomsDeclaration, error) {
+	declaration := &CustomsDeclaration{}
+	if err := c.evaluate("GetCustomsDeclaration", declaration, declarationNumber); err != nil {
+		return nil, err
+	}
+	return declaration, nil
+}
+
+// QueryShipmentsByShipper lists the shipments of a shipper
+func (c *LogisticsClient) QueryShipmentsByShipper(shipperID string) ([]Shipment, error) {
+	var shipments []Shipment
+	if err := c.evaluate("QueryShipmentsByShipper", &shipments, shipperID); err != nil {
+		return nil, err
+	}
+	return shipments, nil
+}
+
+// QueryQuotationsByCarrier lists the quotations of a carrier
+func (c *LogisticsClient) QueryQuotationsByCarrier(carrier string) ([]FreightQuotation, error) {
+	var quotations []FreightQuotation
+	if err := c.evaluate("QueryQuotationsByCarrier", &quotations, carrier); err != nil {
+		return nil, err
+	}
+	return quotations, nil
+}
+
+// QueryUnconfirmedQuotations lists the quotations not yet confirmed
+func (c *LogisticsClient) QueryUnconfirmedQuotations() ([]FreightQuotation, error) {
+	var quotations []FreightQuotation
+	if err := c.evaluate("QueryUnconfirmedQuotations", &quotations); err != nil {
+		return nil, err
+	}
+	return quotations, nil
+}
+
+// RecordEntry appends a marketplace fact about a subject to the ledger
+func (c *LogisticsClient) RecordEntry(kind string, subjectID string, payload []byte) (*LedgerRecord, error) {
+	result, err := c.contract.Submit("RecordEntry", nil, kind, subjectID, string(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to submit RecordEntry: %w", err)
+	}
+
+	record := &LedgerRecord{}
+	if err := json.Unmarshal(result, record); err != nil {
+		return nil, fmt.Errorf("failed to decode RecordEntry result: %w", err)
+	}
+	return record, nil
+}
+
+// GetLedgerRecords lists the records of a subject
+func (c *LogisticsClient) GetLedgerRecords(kind string, subjectID string) ([]LedgerRecord, error) {
+	var records []LedgerRecord
+	if err := c.evaluate("GetLedgerRecords", &records, kind, subjectID); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// FindLedgerRecord reads the record of a kind written by a transaction, or nil when there is none
+func (c *LogisticsClient) FindLedgerRecord(kind string, txID string) (*LedgerRecord, error) {
+	var records []LedgerRecord
+	if err := c.evaluate("FindLedgerRecord", &records, kind, txID); err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+	return &records[0], nil
+}
+
+func (c *LogisticsClient) submit(name string, transient map[string][]byte, args ...string) error {
+	if _, err := c.contract.Submit(name, transient, args...); err != nil {
+		return fmt.Errorf("failed to submit %s: %w", name, err)
+	}
+	return nil
+}
+
+func (c *LogisticsClient) evaluate(name string, result interface{}, args ...string) error {
+	payload, err := c.contract.Evaluate(name, nil, args...)
+	if err != nil {
+		return fmt.Errorf("failed to evaluate %s: %w", name, err)
+	}
+	if err := json.Unmarshal(payload, result); err != nil {
+		return fmt.Errorf("failed to decode %s result: %w", name, err)
+	}
+	return nil
+}
+
+func quotationTransient(terms QuotationTerms) (map[string][]byte, error) {
+	termsJSON, err := json.Marshal(terms)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode quotation terms: %w", err)
+	}
+	return map[string][]byte{"quotation": termsJSON}, nil
+}
@@ -0,0 +1,146 @@
+package fabric_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"logistics-marketplace/internal/fabric"
+	"logistics-marketplace/internal/fabric/fabrictest"
+)
+
+func TestLogisticsClientQuotationTerms(t *testing.T) {
+	standIn := fabrictest.NewStandIn()
+	standIn.On("CreateFreightQuotation", func(transient map[string][]byte, args []string) ([]byte, error) {
+		return nil, nil
+	})
+	client := fabric.NewLogisticsClient(standIn)
+
+	terms := fabric.QuotationTerms{
+		QuotationID: "Q1",
+		Amount:      "1500.00",
+		Currency:    "USD",
+		ValidUntil:  "2026-12-31",
+		Terms:       []string{"FOB"},
+	}
+	require.NoError(t, client.CreateFreightQuotation("Q1", "carrier-1", "S1", terms))
+
+	calls := standIn.Calls()
+	require.Len(t, calls, 1)
+	assert.True(t, calls[0].Submitted)
+	assert.Equal(t, []string{"Q1", "carrier-1", "S1"}, calls[0].Args)
+
+	// Terms travel only in the transient map, never as arguments
+	var sent fabric.QuotationTerms
+	require.NoError(t, json.Unmarshal(calls[0].Transient["quotation"], &sent))
+	assert.Len(t, sent.Salt, 64)
+	terms.Salt = sent.Salt
+	assert.Equal(t, terms, sent)
+}
+
+func TestLogisticsClientTrackShipment(t *testing.T) {
+	standIn := fabrictest.NewStandIn()
+	standIn.On("TrackShipment", func(transient map[string][]byte, args []string) ([]byte, error) {
+		return json.Marshal(fabric.Shipment{
+			ID:     args[0],
+			Status: "InTransit",
+			Route:  []fabric.RouteLeg{{Sequence: 1, Origin: "CNSHA", Destination: "NLRTM", Mode: "Sea"}},
+		})
+	})
+	client := fabric.NewLogisticsClient(standIn)
+
+	shipment, err := client.TrackShipment("SH1")
+	require.NoError(t, err)
+	assert.Equal(t, "SH1", shipment.ID)
+	assert.Equal(t, "InTransit", shipment.Status)
+	require.Len(t, shipment.Route, 1)
+	assert.Equal(t, "NLRTM", shipment.Route[0].Destination)
+	assert.False(t, standIn.Calls()[0].Submitted)
+}
+
+func TestLogisticsClientSetRoutingInfo(t *testing.T) {
+	standIn := fabrictest.NewStandIn()
+	standIn.On("SetRoutingInfo", func(transient map[string][]byte, args []string) ([]byte, error) {
+		return nil, nil
+	})
+	client := fabric.NewLogisticsClient(standIn)
+
+	legs := []fabric.RouteLeg{
+		{Sequence: 1, Origin: "CNSHA", Destination: "SGSIN", Mode: "Sea", Carrier: "C1"},
+		{Sequence: 2, Origin: "SGSIN", Destination: "NLRTM", Mode: "Sea", Carrier: "C1"},
+	}
+	require.NoError(t, client.SetRoutingInfo("SH1", legs))
+
+	args := standIn.Calls()[0].Args
+	require.Len(t, args, 2)
+	var sent []fabric.RouteLeg
+	require.NoError(t, json.Unmarshal([]byte(args[1]), &sent))
+	assert.Equal(t, legs, sent)
+}
+
+func TestLogisticsClientWrapsErrors(t *testing.T) {
+	standIn := fabrictest.NewStandIn()
+	endorsementErr := errors.New("client Org2MSP is not allowed to update shipment SH1")
+	standIn.On("UpdateShipmentStatus", func(transient map[string][]byte, args []string) ([]byte, error) {
+		return nil, endorsementErr
+	})
+	client := fabric.NewLogisticsClient(standIn)
+
+	err := client.UpdateShipmentStatus("SH1", "Delivered")
+	assert.ErrorIs(t, err, endorsementErr)
+	assert.Contains(t, err.Error(), "failed to submit UpdateShipmentStatus")
+
+	_, err = client.GetShipmentHistory("SH1")
+	assert.Error(t, err)
+}
+
+func TestListenDecodesEvents(t *testing.T) {
+	standIn := fabrictest.NewStandIn()
+
+	payload, err := json.Marshal(fabric.CustomsDeclaration{DeclarationNumber: "D1", Status: "Released"})
+	require.NoError(t, err)
+	standIn.Emit(fabric.Event{Name: fabric.EventCustomsStatusUpdated, Payload: payload, TransactionID: "tx1"})
+	standIn.Emit(fabric.Event{Name: "Unknown"})
+	standIn.Close()
+
+	var decoded []interface{}
+	var decodeErrs []error
+	err = fabric.Listen(context.Background(), standIn, func(event fabric.Event) {
+		asset, err := event.Decode()
+		if err != nil {
+			decodeErrs = append(decodeErrs, err)
+			return
+		}
+		decoded = append(decoded, asset)
+	})
+	require.NoError(t, err)
+
+	require.Len(t, decoded, 1)
+	declaration, ok := decoded[0].(*fabric.CustomsDeclaration)
+	require.True(t, ok)
+	assert.Equal(t, "Released", declaration.Status)
+	assert.Len(t, decodeErrs, 1)
+}
+
+func TestLogisticsClientFindLedgerRecord(t *testing.T) {
+	standIn := fabrictest.NewStandIn()
+	standIn.On("FindLedgerRecord", func(transient map[string][]byte, args []string) ([]byte, error) {
+		if args[1] != "tx1" {
+			return []byte("[]"), nil
+		}
+		return json.Marshal([]fabric.LedgerRecord{{Kind: args[0], SubjectID: "SVC-1", TxID: args[1], Payload: `{"customerId":"C1"}`}})
+	})
+	client := fabric.NewLogisticsClient(standIn)
+
+	record, err := client.FindLedgerRecord("booking", "tx1")
+	require.NoError(t, err)
+	assert.Equal(t, `{"customerId":"C1"}`, record.Payload)
+
+	record, err = client.FindLedgerRecord("booking", "tx2")
+	require.NoError(t, err)
+	assert.Nil(t, record)
+}
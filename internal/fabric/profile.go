@@ -0,0 +1,172 @@
+package fabric
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hyperledger/fabric-gateway/pkg/identity"
+)
+
+// ConnectionProfile is the subset of a Fabric common connection profile the
+// gateway client needs to reach a peer of the client's organization
+type ConnectionProfile struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Client  struct {
+		Organization string `json:"organization"`
+	} `json:"client"`
+	Organizations map[string]OrganizationConfig `json:"organizations"`
+	Peers         map[string]PeerConfig         `json:"peers"`
+
+	dir string // Directory of the profile, relative paths resolve against it
+}
+
+// OrganizationConfig describes an organization in the connection profile
+type OrganizationConfig struct {
+	MSPID string   `json:"mspid"`
+	Peers []string `json:"peers"`
+}
+
+// PeerConfig describes how to connect to a peer
+type PeerConfig struct {
+	URL        string `json:"url"`
+	TLSCACerts struct {
+		PEM  string `json:"pem"`
+		Path string `json:"path"`
+	} `json:"tlsCACerts"`
+	GRPCOptions struct {
+		SSLTargetNameOverride string `json:"ssl-target-name-override"`
+	} `json:"grpcOptions"`
+}
+
+// PeerEndpoint is a resolved peer address
+type PeerEndpoint struct {
+	Name               string
+	Address            string
+	TLS                bool
+	TLSCACert          *x509.Certificate
+	ServerNameOverride string
+}
+
+// LoadConnectionProfile reads a connection profile from a JSON file
+func LoadConnectionProfile(path string) (*ConnectionProfile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read connection profile: %w", err)
+	}
+
+	profile := &ConnectionProfile{}
+	if err := json.Unmarshal(data, profile); err != nil {
+		return nil, fmt.Errorf("failed to parse connection profile: %w", err)
+	}
+	profile.dir = filepath.Dir(path)
+
+	if profile.Client.Organization == "" {
+		return nil, fmt.Errorf("connection profile has no client organization")
+	}
+	if _, ok := profile.Organizations[profile.Client.Organization]; !ok {
+		return nil, fmt.Errorf("client organization %s is not defined in the connection profile", profile.Client.Organization)
+	}
+
+	return profile, nil
+}
+
+// MSPID returns the MSP ID of the client's organization
+func (p *ConnectionProfile) MSPID() string {
+	return p.Organizations[p.Client.Organization].MSPID
+}
+
+// PeerEndpoint resolves the first peer of the client's organization
+func (p *ConnectionProfile) PeerEndpoint() (*PeerEndpoint, error) {
+	org := p.Organizations[p.Client.Organization]
+	if len(org.Peers) == 0 {
+		return nil, fmt.Errorf("organization %s has no peers", p.Client.Organization)
+	}
+
+	name := org.Peers[0]
+	peer, ok := p.Peers[name]
+	if !ok {
+		return nil, fmt.Errorf("peer %s is not defined in the connection profile", name)
+	}
+
+	endpoint := &PeerEndpoint{
+		Name:               name,
+		ServerNameOverride: peer.GRPCOptions.SSLTargetNameOverride,
+	}
+
+	switch {
+	case strings.HasPrefix(peer.URL, "grpcs://"):
+		endpoint.Address = strings.TrimPrefix(peer.URL, "grpcs://")
+		endpoint.TLS = true
+	case strings.HasPrefix(peer.URL, "grpc://"):
+		endpoint.Address = strings.TrimPrefix(peer.URL, "grpc://")
+	default:
+		return nil, fmt.Errorf("peer %s has unsupported URL %q", name, peer.URL)
+	}
+
+	if endpoint.TLS {
+		certPEM := []byte(peer.TLSCACerts.PEM)
+		if len(certPEM) == 0 {
+			if peer.TLSCACerts.Path == "" {
+				return nil, fmt.Errorf("peer %s uses TLS but has no CA certificate", name)
+			}
+			data, err := os.ReadFile(p.resolve(peer.TLSCACerts.Path))
+			if err != nil {
+				return nil, fmt.Errorf("failed to read TLS CA certificate of peer %s: %w", name, err)
+			}
+			certPEM = data
+		}
+
+		cert, err := identity.CertificateFromPEM(certPEM)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse TLS CA certificate of peer %s: %w", name, err)
+		}
+		endpoint.TLSCACert = cert
+	}
+
+	return endpoint, nil
+}
+
+func (p *ConnectionProfile) resolve(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(p.dir, path)
+}
+
+// LoadIdentity loads the client certificate and private key used to sign transactions
+func LoadIdentity(mspID string, certPath string, keyPath string) (*identity.X509Identity, identity.Sign, error) {
+	certPEM, err := os.ReadFile(certPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read client certificate: %w", err)
+	}
+	cert, err := identity.CertificateFromPEM(certPEM)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse client certificate: %w", err)
+	}
+
+	id, err := identity.NewX509Identity(mspID, cert)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create client identity: %w", err)
+	}
+
+	keyPEM, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read client private key: %w", err)
+	}
+	key, err := identity.PrivateKeyFromPEM(keyPEM)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse client private key: %w", err)
+	}
+
+	sign, err := identity.NewPrivateKeySign(key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create signer: %w", err)
+	}
+
+	return id, sign, nil
+}
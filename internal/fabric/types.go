@@ -0,0 +1,106 @@
+package fabric
+
+// The types below mirror the JSON documents of the logistics chaincode
+
+// Shipment is a shipment asset
+type Shipment struct {
+	ID           string                `json:"id"`
+	ShipperID    string                `json:"shipperId"`
+	ConsigneeID  string                `json:"consigneeId"`
+	Status       string                `json:"status"`
+	Route        []RouteLeg            `json:"route"`
+	Assignments  []LogisticsAssignment `json:"assignments"`
+	Declarations []string              `json:"declarations"`
+	UpdatedAt    string                `json:"updatedAt"`
+}
+
+// RouteLeg is one leg of a shipment's route
+type RouteLeg struct {
+	Sequence    int    `json:"sequence"`
+	Origin      string `json:"origin"`
+	Destination string `json:"destination"`
+	Mode        string `json:"mode"`
+	Carrier     string `json:"carrier"`
+	ETD         string `json:"etd,omitempty"`
+	ETA         string `json:"eta,omitempty"`
+}
+
+// ShipmentHistoryEntry is a committed version of a shipment
+type ShipmentHistoryEntry struct {
+	TxID      string    `json:"txId"`
+	Timestamp string    `json:"timestamp"`
+	IsDelete  bool      `json:"isDelete"`
+	Shipment  *Shipment `json:"shipment,omitempty"`
+}
+
+// LogisticsAssignment assigns a 3PL provider to a role on a shipment
+type LogisticsAssignment struct {
+	ProviderID string `json:"providerId"`
+	Role       string `json:"role"`
+	AssignedAt string `json:"assignedAt"`
+}
+
+// ThirdPartyLogistics is a 3PL provider asset
+type ThirdPartyLogistics struct {
+	ID     string   `json:"id"`
+	Name   string   `json:"name"`
+	MSPID  string   `json:"mspId"`
+	Roles  []string `json:"roles"`
+	Active bool     `json:"active"`
+}
+
+// FreightQuotation is the public part of a freight quotation
+type FreightQuotation struct {
+	ID           string `json:"id"`
+	Carrier      string `json:"carrier"`
+	CarrierMSPID string `json:"carrierMspId"`
+	ShipperID    string `json:"shipperId"`
+	ShipperMSPID string `json:"shipperMspId"`
+	Collection   string `json:"collection"`
+	TermsHash    string `json:"termsHash"`
+	Confirmed    bool   `json:"confirmed"`
+}
+
+// QuotationTerms are the private amount and terms of a freight quotation.
+// The salt is stored with them so the public terms hash cannot be guessed.
+type QuotationTerms struct {
+	QuotationID string   `json:"quotationId"`
+	Amount      string   `json:"amount"`
+	Currency    string   `json:"currency"`
+	ValidUntil  string   `json:"validUntil"`
+	Terms       []string `json:"terms"`
+	Salt        string   `json:"salt"`
+}
+
+// CustomsDeclaration is a customs declaration asset
+type CustomsDeclaration struct {
+	DeclarationNumber string   `json:"declarationNumber"`
+	ShipmentID        string   `json:"shipmentId"`
+	Type              string   `json:"type"`
+	Lines             []HSLine `json:"lines"`
+	Status            string   `json:"status"`
+	BrokerMSPID       string   `json:"brokerMspId"`
+	HoldReason        string   `json:"holdReason,omitempty"`
+	FiledAt           string   `json:"filedAt"`
+	UpdatedAt         string   `json:"updatedAt"`
+}
+
+// HSLine is a tariff line of a customs declaration
+type HSLine struct {
+	HSCode        string  `json:"hsCode"`
+	Description   string  `json:"description"`
+	Quantity      float64 `json:"quantity"`
+	Value         float64 `json:"value"`
+	OriginCountry string  `json:"originCountry"`
+}
+
+// LedgerRecord is a write-once marketplace fact recorded by the API
+type LedgerRecord struct {
+	Kind        string `json:"kind"`
+	SubjectID   string `json:"subjectId"`
+	TxID        string `json:"txId"`
+	MSPID       string `json:"mspId"`
+	Payload     string `json:"payload"`
+	PayloadHash string `json:"payloadHash"`
+	RecordedAt  string `json:"recordedAt"`
+}
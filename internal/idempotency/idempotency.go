@@ -0,0 +1,217 @@
+// Package idempotency replays the stored response of a POST or PUT retried
+// with the same Idempotency-Key, so retries do not repeat bookings, payments
+// or ledger transactions.
+package idempotency
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"logistics-marketplace/internal/auth"
+	"logistics-marketplace/internal/problem"
+)
+
+// Header is the request header carrying the client's idempotency key
+const Header = "Idempotency-Key"
+
+// ReplayedHeader marks a response replayed from the store
+const ReplayedHeader = "Idempotent-Replayed"
+
+// DefaultTTL is how long a response is replayed for
+const DefaultTTL = 24 * time.Hour
+
+// maxKeyLength bounds the keys clients may send
+const maxKeyLength = 255
+
+// replayedHeaders are the response headers stored and replayed with the body
+var replayedHeaders = []string{"Content-Type", "Location"}
+
+// entry is the first request made with a key and, once finished, its response
+type entry struct {
+	fingerprint string
+	done        bool
+	status      int
+	header      http.Header
+	body        []byte
+	expiresAt   time.Time
+}
+
+// Store keeps the responses of idempotent requests per caller and key
+type Store struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu        sync.Mutex
+	entries   map[string]*entry
+	nextPrune time.Time
+}
+
+// NewStore creates a store replaying responses for ttl
+func NewStore(ttl time.Duration) *Store {
+	return &Store{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]*entry),
+	}
+}
+
+// begin claims a key for a request. It returns the entry to replay when the
+// request was already answered, or a non-zero status when the key cannot be used.
+func (s *Store) begin(key string, fingerprint string) (*entry, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.prune(now)
+
+	if e, ok := s.entries[key]; ok {
+		switch {
+		case e.fingerprint != fingerprint:
+			return nil, http.StatusUnprocessableEntity
+		case !e.done:
+			return nil, http.StatusConflict
+		default:
+			return e, 0
+		}
+	}
+
+	s.entries[key] = &entry{fingerprint: fingerprint, expiresAt: now.Add(s.ttl)}
+	return nil, 0
+}
+
+// complete stores the response of a claimed key
+func (s *Store) complete(key string, status int, header http.Header, body []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if e, ok := s.entries[key]; ok {
+		e.done = true
+		e.status = status
+		e.header = header
+		e.body = body
+	}
+}
+
+// release forgets a claimed key whose request did not finish
+func (s *Store) release(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, key)
+}
+
+// prune forgets expired responses, at most once a minute
+func (s *Store) prune(now time.Time) {
+	if now.Before(s.nextPrune) {
+		return
+	}
+	for key, e := range s.entries {
+		if now.After(e.expiresAt) {
+			delete(s.entries, key)
+		}
+	}
+	s.nextPrune = now.Add(time.Minute)
+}
+
+// recorder keeps a copy of the response body as it is written
+type recorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *recorder) Write(data []byte) (int, error) {
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
+
+func (r *recorder) WriteString(data string) (int, error) {
+	r.body.WriteString(data)
+	return r.ResponseWriter.WriteString(data)
+}
+
+// Middleware honours the Idempotency-Key header on authenticated POST and PUT
+// requests. The first response per caller and key is replayed to retries; a
+// retry with a different request answers 422 and one made while the first is
+// still running answers 409.
+func (s *Store) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(Header)
+		if key == "" || (c.Request.Method != http.MethodPost && c.Request.Method != http.MethodPut) {
+			c.Next()
+			return
+		}
+		if len(key) > maxKeyLength {
+			problem.Abort(c, http.StatusBadRequest, "Idempotency-Key is too long")
+			return
+		}
+
+		id, ok := auth.FromContext(c)
+		if !ok {
+			c.Next()
+			return
+		}
+		caller := "user:" + id.Address
+		if id.APIKeyID != "" {
+			caller = "key:" + id.APIKeyID
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			problem.Abort(c, http.StatusBadRequest, "failed to read request body")
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		sum := sha256.Sum256(append([]byte(c.Request.Method+" "+c.Request.URL.Path+"\n"), body...))
+		storeKey := caller + "|" + key
+
+		replay, status := s.begin(storeKey, hex.EncodeToString(sum[:]))
+		switch status {
+		case http.StatusUnprocessableEntity:
+			problem.Abort(c, status, "Idempotency-Key was already used with a different request")
+			return
+		case http.StatusConflict:
+			problem.Abort(c, status, "a request with this Idempotency-Key is still being processed")
+			return
+		}
+
+		if replay != nil {
+			for name, values := range replay.header {
+				for _, value := range values {
+					c.Writer.Header().Add(name, value)
+				}
+			}
+			c.Header(ReplayedHeader, "true")
+			c.Writer.WriteHeader(replay.status)
+			c.Writer.Write(replay.body)
+			c.Abort()
+			return
+		}
+
+		// A handler that panics releases the key so the request can be retried
+		completed := false
+		defer func() {
+			if !completed {
+				s.release(storeKey)
+			}
+		}()
+
+		rec := &recorder{ResponseWriter: c.Writer}
+		c.Writer = rec
+		c.Next()
+
+		header := http.Header{}
+		for _, name := range replayedHeaders {
+			if value := rec.Header().Get(name); value != "" {
+				header.Set(name, value)
+			}
+		}
+		s.complete(storeKey, rec.Status(), header, rec.body.Bytes())
+		completed = true
+	}
+}
@@ -0,0 +1,101 @@
+package idempotency
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"logistics-marketplace/internal/auth"
+)
+
+func newTestRouter(store *Store, address string, handler gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(gin.Recovery())
+	router.Use(func(c *gin.Context) {
+		auth.SetIdentity(c, &auth.Identity{UserID: address, Address: address, Roles: []auth.Role{auth.RoleShipper}})
+	})
+	router.Use(store.Middleware())
+	router.POST("/bookings", handler)
+	return router
+}
+
+func post(router *gin.Engine, key string, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/bookings", strings.NewReader(body))
+	req.Header.Set(Header, key)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestMiddlewareReplaysFirstResponse(t *testing.T) {
+	store := NewStore(DefaultTTL)
+	calls := 0
+	router := newTestRouter(store, "GALICE", func(c *gin.Context) {
+		calls++
+		c.JSON(http.StatusCreated, gin.H{"booking_id": calls})
+	})
+
+	first := post(router, "k1", `{"listing":"L1"}`)
+	require.Equal(t, http.StatusCreated, first.Code)
+
+	retry := post(router, "k1", `{"listing":"L1"}`)
+	assert.Equal(t, http.StatusCreated, retry.Code)
+	assert.Equal(t, first.Body.String(), retry.Body.String())
+	assert.Equal(t, "true", retry.Header().Get(ReplayedHeader))
+	assert.Equal(t, "application/json; charset=utf-8", retry.Header().Get("Content-Type"))
+	assert.Equal(t, 1, calls)
+
+	// A different body under the same key is rejected
+	assert.Equal(t, http.StatusUnprocessableEntity, post(router, "k1", `{"listing":"L2"}`).Code)
+
+	// Other keys, and the same key of another caller, run the handler
+	assert.Equal(t, http.StatusCreated, post(router, "k2", `{"listing":"L1"}`).Code)
+	other := newTestRouter(store, "GBOB", func(c *gin.Context) {
+		calls++
+		c.JSON(http.StatusCreated, gin.H{"booking_id": calls})
+	})
+	assert.Equal(t, http.StatusCreated, post(other, "k1", `{"listing":"L1"}`).Code)
+	assert.Equal(t, 3, calls)
+}
+
+func TestMiddlewareExpiresAndReleasesKeys(t *testing.T) {
+	store := NewStore(time.Hour)
+	now := time.Now()
+	store.now = func() time.Time { return now }
+
+	calls := 0
+	router := newTestRouter(store, "GALICE", func(c *gin.Context) {
+		calls++
+		if calls == 1 {
+			panic("ledger unavailable")
+		}
+		c.Status(http.StatusNoContent)
+	})
+
+	// A panicking request does not hold on to its key
+	assert.Equal(t, http.StatusInternalServerError, post(router, "k1", "{}").Code)
+	assert.Equal(t, http.StatusNoContent, post(router, "k1", "{}").Code)
+	assert.Equal(t, http.StatusNoContent, post(router, "k1", "{}").Code)
+	assert.Equal(t, 2, calls)
+
+	now = now.Add(2 * time.Hour)
+	post(router, "k1", "{}")
+	assert.Equal(t, 3, calls)
+}
+
+func TestStoreRejectsConcurrentRetry(t *testing.T) {
+	store := NewStore(DefaultTTL)
+
+	replay, status := store.begin("user:GALICE|k1", "f1")
+	assert.Nil(t, replay)
+	assert.Zero(t, status)
+
+	_, status = store.begin("user:GALICE|k1", "f1")
+	assert.Equal(t, http.StatusConflict, status)
+}
@@ -0,0 +1,115 @@
+package ledger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"logistics-marketplace/internal/fabric"
+)
+
+// FabricBackend records facts as ledger records of the logistics chaincode
+type FabricBackend struct {
+	client *fabric.LogisticsClient
+}
+
+// NewFabricBackend creates a new FabricBackend instance
+func NewFabricBackend(client *fabric.LogisticsClient) *FabricBackend {
+	return &FabricBackend{
+		client: client,
+	}
+}
+
+// Name returns the backend name
+func (b *FabricBackend) Name() string {
+	return BackendFabric
+}
+
+// GetListing reads back the service listing recorded by a transaction
+func (b *FabricBackend) GetListing(ctx context.Context, txID string) (*ListingRecord, error) {
+	var listing ListingRecord
+	if err := b.lookup(ctx, KindListing, txID, &listing); err != nil {
+		return nil, err
+	}
+	return &listing, nil
+}
+
+// GetBooking reads back the booking recorded by a transaction
+func (b *FabricBackend) GetBooking(ctx context.Context, txID string) (*BookingRecord, error) {
+	var booking BookingRecord
+	if err := b.lookup(ctx, KindBooking, txID, &booking); err != nil {
+		return nil, err
+	}
+	return &booking, nil
+}
+
+func (b *FabricBackend) lookup(ctx context.Context, kind string, txID string, record interface{}) error {
+	_, span := startLookup(ctx, BackendFabric, kind)
+	defer span.End()
+
+	ledgerRecord, err := b.client.FindLedgerRecord(kind, txID)
+	if err != nil {
+		return err
+	}
+	if ledgerRecord == nil {
+		return fmt.Errorf("%w: %s %s", ErrNotFound, kind, txID)
+	}
+	return decodeRecord(kind, []byte(ledgerRecord.Payload), record)
+}
+
+// RecordListing records a service listing
+func (b *FabricBackend) RecordListing(ctx context.Context, listing *ListingRecord) (*Receipt, error) {
+	return b.record(ctx, KindListing, listing)
+}
+
+// RecordBooking records a booking
+func (b *FabricBackend) RecordBooking(ctx context.Context, booking *BookingRecord) (*Receipt, error) {
+	return b.record(ctx, KindBooking, booking)
+}
+
+// RecordPayment records a payment
+func (b *FabricBackend) RecordPayment(ctx context.Context, payment *PaymentRecord) (*Receipt, error) {
+	return b.record(ctx, KindPayment, payment)
+}
+
+// RecordTrackingEvent records a shipment status update
+func (b *FabricBackend) RecordTrackingEvent(ctx context.Context, event *TrackingEventRecord) (*Receipt, error) {
+	return b.record(ctx, KindTrackingEvent, event)
+}
+
+// RecordDocumentHash anchors a document hash
+func (b *FabricBackend) RecordDocumentHash(ctx context.Context, document *DocumentHashRecord) (*Receipt, error) {
+	return b.record(ctx, KindDocumentHash, document)
+}
+
+func (b *FabricBackend) record(ctx context.Context, kind string, record interface{}) (*Receipt, error) {
+	_, span := startRecord(ctx, BackendFabric, kind)
+	defer span.End()
+
+	id, err := subjectID(kind, record)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := json.Marshal(record)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s record: %w", kind, err)
+	}
+
+	ledgerRecord, err := b.client.RecordEntry(kind, id, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	recordedAt, err := time.Parse(time.RFC3339, ledgerRecord.RecordedAt)
+	if err != nil {
+		recordedAt = time.Now()
+	}
+
+	return &Receipt{
+		Backend:    BackendFabric,
+		TxID:       ledgerRecord.TxID,
+		RecordedAt: recordedAt,
+	}, nil
+}
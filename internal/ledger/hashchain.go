@@ -0,0 +1,251 @@
+package ledger
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// genesisHash is the previous hash of the first entry of a chain
+var genesisHash = strings.Repeat("0", sha256.Size*2)
+
+// ChainEntry is one line of the hash-chained log
+type ChainEntry struct {
+	Sequence   uint64          `json:"sequence"`
+	Kind       string          `json:"kind"`
+	SubjectID  string          `json:"subjectId"`
+	Payload    json.RawMessage `json:"payload"`
+	RecordedAt time.Time       `json:"recordedAt"`
+	PrevHash   string          `json:"prevHash"`
+	Hash       string          `json:"hash"`
+}
+
+// computeHash hashes the entry's content together with the previous hash,
+// so changing any entry breaks every hash after it
+func (e *ChainEntry) computeHash() string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%d\n%s\n%s\n%s\n%s\n",
+		e.Sequence, e.Kind, e.SubjectID, e.RecordedAt.UTC().Format(time.RFC3339Nano), e.PrevHash)
+	h.Write(e.Payload)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// HashChainBackend records facts in an append-only local log where every
+// entry carries the hash of the one before it, for private deployments
+// without a blockchain
+type HashChainBackend struct {
+	mu       sync.Mutex
+	file     *os.File
+	sequence uint64
+	head     string
+	records  map[string]json.RawMessage // Listing and booking payloads by kind and entry hash
+}
+
+// OpenHashChain opens or creates the log at path, verifying existing entries
+func OpenHashChain(path string) (*HashChainBackend, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open hash chain: %w", err)
+	}
+
+	b := &HashChainBackend{file: file, records: make(map[string]json.RawMessage)}
+	entries, err := readChain(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	for i := range entries {
+		b.index(&entries[i])
+	}
+
+	b.head = genesisHash
+	if len(entries) > 0 {
+		last := entries[len(entries)-1]
+		b.sequence = last.Sequence
+		b.head = last.Hash
+	}
+
+	return b, nil
+}
+
+// Close closes the log
+func (b *HashChainBackend) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.file.Close()
+}
+
+// Name returns the backend name
+func (b *HashChainBackend) Name() string {
+	return BackendHashChain
+}
+
+// Head returns the hash of the latest entry
+func (b *HashChainBackend) Head() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.head
+}
+
+// Entries reads and verifies every entry of the log
+func (b *HashChainBackend) Entries() ([]ChainEntry, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return readChain(b.file)
+}
+
+// RecordListing records a service listing
+func (b *HashChainBackend) RecordListing(ctx context.Context, listing *ListingRecord) (*Receipt, error) {
+	return b.append(ctx, KindListing, listing)
+}
+
+// RecordBooking records a booking
+func (b *HashChainBackend) RecordBooking(ctx context.Context, booking *BookingRecord) (*Receipt, error) {
+	return b.append(ctx, KindBooking, booking)
+}
+
+// RecordPayment records a payment
+func (b *HashChainBackend) RecordPayment(ctx context.Context, payment *PaymentRecord) (*Receipt, error) {
+	return b.append(ctx, KindPayment, payment)
+}
+
+// RecordTrackingEvent records a shipment status update
+func (b *HashChainBackend) RecordTrackingEvent(ctx context.Context, event *TrackingEventRecord) (*Receipt, error) {
+	return b.append(ctx, KindTrackingEvent, event)
+}
+
+// RecordDocumentHash anchors a document hash
+func (b *HashChainBackend) RecordDocumentHash(ctx context.Context, document *DocumentHashRecord) (*Receipt, error) {
+	return b.append(ctx, KindDocumentHash, document)
+}
+
+func (b *HashChainBackend) append(ctx context.Context, kind string, record interface{}) (*Receipt, error) {
+	_, span := startRecord(ctx, BackendHashChain, kind)
+	defer span.End()
+
+	id, err := subjectID(kind, record)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := json.Marshal(record)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s record: %w", kind, err)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	entry := &ChainEntry{
+		Sequence:   b.sequence + 1,
+		Kind:       kind,
+		SubjectID:  id,
+		Payload:    payload,
+		RecordedAt: time.Now().UTC(),
+		PrevHash:   b.head,
+	}
+	entry.Hash = entry.computeHash()
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode chain entry: %w", err)
+	}
+	if _, err := b.file.Write(append(line, '\n')); err != nil {
+		return nil, fmt.Errorf("failed to append to hash chain: %w", err)
+	}
+	if err := b.file.Sync(); err != nil {
+		return nil, fmt.Errorf("failed to sync hash chain: %w", err)
+	}
+
+	b.sequence = entry.Sequence
+	b.head = entry.Hash
+	b.index(entry)
+
+	return &Receipt{
+		Backend:    BackendHashChain,
+		TxID:       entry.Hash,
+		RecordedAt: entry.RecordedAt,
+	}, nil
+}
+
+// GetListing reads back the service listing recorded by an entry
+func (b *HashChainBackend) GetListing(ctx context.Context, txID string) (*ListingRecord, error) {
+	var listing ListingRecord
+	if err := b.lookup(ctx, KindListing, txID, &listing); err != nil {
+		return nil, err
+	}
+	return &listing, nil
+}
+
+// GetBooking reads back the booking recorded by an entry
+func (b *HashChainBackend) GetBooking(ctx context.Context, txID string) (*BookingRecord, error) {
+	var booking BookingRecord
+	if err := b.lookup(ctx, KindBooking, txID, &booking); err != nil {
+		return nil, err
+	}
+	return &booking, nil
+}
+
+func (b *HashChainBackend) lookup(ctx context.Context, kind string, txID string, record interface{}) error {
+	_, span := startLookup(ctx, BackendHashChain, kind)
+	defer span.End()
+
+	b.mu.Lock()
+	payload, ok := b.records[kind+"/"+txID]
+	b.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("%w: %s %s", ErrNotFound, kind, txID)
+	}
+	return decodeRecord(kind, payload, record)
+}
+
+// index keeps the payloads of the entries that can be read back
+func (b *HashChainBackend) index(entry *ChainEntry) {
+	if entry.Kind == KindListing || entry.Kind == KindBooking {
+		b.records[entry.Kind+"/"+entry.Hash] = entry.Payload
+	}
+}
+
+// readChain reads every entry from the start of the log and verifies the links
+func readChain(file *os.File) ([]ChainEntry, error) {
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to read hash chain: %w", err)
+	}
+
+	var entries []ChainEntry
+	prevHash := genesisHash
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var entry ChainEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("failed to decode hash chain entry %d: %w", len(entries)+1, err)
+		}
+
+		if entry.Sequence != uint64(len(entries)+1) {
+			return nil, fmt.Errorf("hash chain entry %d has sequence %d", len(entries)+1, entry.Sequence)
+		}
+		if entry.PrevHash != prevHash {
+			return nil, fmt.Errorf("hash chain broken at entry %d: previous hash mismatch", entry.Sequence)
+		}
+		if entry.computeHash() != entry.Hash {
+			return nil, fmt.Errorf("hash chain broken at entry %d: content does not match hash", entry.Sequence)
+		}
+
+		prevHash = entry.Hash
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read hash chain: %w", err)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,105 @@
+package ledger
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashChainAppendAndReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ledger.log")
+
+	chain, err := OpenHashChain(path)
+	require.NoError(t, err)
+	assert.Equal(t, genesisHash, chain.Head())
+
+	booking, err := chain.RecordBooking(context.Background(), &BookingRecord{CustomerID: "C1", ServiceID: "SVC-1"})
+	require.NoError(t, err)
+	assert.Equal(t, BackendHashChain, booking.Backend)
+
+	payment, err := chain.RecordPayment(context.Background(), &PaymentRecord{CustomerID: "C1", BookingID: "BK-1", Amount: "1000"})
+	require.NoError(t, err)
+	assert.Equal(t, payment.TxID, chain.Head())
+	require.NoError(t, chain.Close())
+
+	// Reopening continues the chain from the last entry
+	chain, err = OpenHashChain(path)
+	require.NoError(t, err)
+	defer chain.Close()
+	assert.Equal(t, payment.TxID, chain.Head())
+
+	_, err = chain.RecordDocumentHash(context.Background(), &DocumentHashRecord{DocumentID: "BL-1", BookingID: "BK-1", Algorithm: "sha256", Hash: "ab12"})
+	require.NoError(t, err)
+
+	entries, err := chain.Entries()
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	assert.Equal(t, genesisHash, entries[0].PrevHash)
+	assert.Equal(t, booking.TxID, entries[1].PrevHash)
+	assert.Equal(t, payment.TxID, entries[2].PrevHash)
+	assert.Equal(t, KindDocumentHash, entries[2].Kind)
+	assert.Equal(t, "BL-1", entries[2].SubjectID)
+}
+
+func TestHashChainDetectsTampering(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ledger.log")
+
+	chain, err := OpenHashChain(path)
+	require.NoError(t, err)
+	_, err = chain.RecordPayment(context.Background(), &PaymentRecord{CustomerID: "C1", BookingID: "BK-1", Amount: "1000"})
+	require.NoError(t, err)
+	_, err = chain.RecordTrackingEvent(context.Background(), &TrackingEventRecord{BookingID: "BK-1", Location: "Rotterdam", Status: "DELIVERED"})
+	require.NoError(t, err)
+	require.NoError(t, chain.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, []byte(strings.Replace(string(data), `"amount":"1000"`, `"amount":"1"`, 1)), 0o600))
+
+	_, err = OpenHashChain(path)
+	assert.ErrorContains(t, err, "hash chain broken at entry 1")
+}
+
+func TestHashChainRequiresSubject(t *testing.T) {
+	chain, err := OpenHashChain(filepath.Join(t.TempDir(), "ledger.log"))
+	require.NoError(t, err)
+	defer chain.Close()
+
+	_, err = chain.RecordTrackingEvent(context.Background(), &TrackingEventRecord{Status: "DELIVERED"})
+	assert.Error(t, err)
+}
+
+func TestHashChainReadsBackBookings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ledger.log")
+	ctx := context.Background()
+
+	chain, err := OpenHashChain(path)
+	require.NoError(t, err)
+	listing, err := chain.RecordListing(ctx, &ListingRecord{ProviderID: "P1", Origin: "CNSHA", Destination: "NLRTM", Price: 1000})
+	require.NoError(t, err)
+	booking, err := chain.RecordBooking(ctx, &BookingRecord{CustomerID: "C1", ServiceID: listing.TxID})
+	require.NoError(t, err)
+	require.NoError(t, chain.Close())
+
+	// Records are read back by the transaction ID of their receipt after reopening
+	chain, err = OpenHashChain(path)
+	require.NoError(t, err)
+	defer chain.Close()
+
+	bookingRecord, err := chain.GetBooking(ctx, booking.TxID)
+	require.NoError(t, err)
+	assert.Equal(t, "C1", bookingRecord.CustomerID)
+
+	listingRecord, err := chain.GetListing(ctx, bookingRecord.ServiceID)
+	require.NoError(t, err)
+	assert.Equal(t, "P1", listingRecord.ProviderID)
+
+	// A listing is not a booking
+	_, err = chain.GetBooking(ctx, listing.TxID)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
@@ -0,0 +1,147 @@
+// Package ledger records marketplace facts on the ledger chosen for a
+// deployment: the public Stellar network, a private Fabric channel or a
+// local hash-chained log.
+package ledger
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"logistics-marketplace/internal/telemetry"
+)
+
+// Backend names, as configured in pkg/config
+const (
+	BackendStellar   = "stellar"
+	BackendFabric    = "fabric"
+	BackendHashChain = "hashchain"
+)
+
+// Record kinds
+const (
+	KindListing       = "listing"
+	KindBooking       = "booking"
+	KindPayment       = "payment"
+	KindTrackingEvent = "trackingEvent"
+	KindDocumentHash  = "documentHash"
+)
+
+// LedgerBackend records marketplace facts on a ledger, tracing each record
+// under the span in ctx
+type LedgerBackend interface {
+	// Name returns the backend name
+	Name() string
+	RecordListing(ctx context.Context, listing *ListingRecord) (*Receipt, error)
+	RecordBooking(ctx context.Context, booking *BookingRecord) (*Receipt, error)
+	RecordPayment(ctx context.Context, payment *PaymentRecord) (*Receipt, error)
+	RecordTrackingEvent(ctx context.Context, event *TrackingEventRecord) (*Receipt, error)
+	RecordDocumentHash(ctx context.Context, document *DocumentHashRecord) (*Receipt, error)
+}
+
+// ErrNotFound is returned when no record of a kind was written by a transaction
+var ErrNotFound = errors.New("ledger record not found")
+
+// RecordReader reads records back by the transaction ID of their receipt, which
+// is the ID the marketplace gives listings and bookings
+type RecordReader interface {
+	GetListing(ctx context.Context, txID string) (*ListingRecord, error)
+	GetBooking(ctx context.Context, txID string) (*BookingRecord, error)
+}
+
+// Receipt identifies where a fact was recorded
+type Receipt struct {
+	Backend    string    `json:"backend"`
+	TxID       string    `json:"txId"`
+	RecordedAt time.Time `json:"recordedAt"`
+}
+
+// ListingRecord is a service listing published by a provider
+type ListingRecord struct {
+	ProviderID   string `json:"providerId"`
+	Category     uint8  `json:"category"`
+	ShipmentMode uint8  `json:"shipmentMode"`
+	Origin       string `json:"origin"`
+	Destination  string `json:"destination"`
+	Price        uint64 `json:"price"` // Smallest token unit
+	Description  string `json:"description"`
+}
+
+// BookingRecord is a booking of a service listing
+type BookingRecord struct {
+	CustomerID string                 `json:"customerId"`
+	ServiceID  string                 `json:"serviceId"`
+	Cargo      map[string]interface{} `json:"cargo"`
+}
+
+// PaymentRecord is a payment for a booking
+type PaymentRecord struct {
+	CustomerID string `json:"customerId"`
+	BookingID  string `json:"bookingId"`
+	Amount     string `json:"amount"` // Smallest token unit
+}
+
+// TrackingEventRecord is a shipment status update
+type TrackingEventRecord struct {
+	BookingID   string `json:"bookingId"`
+	Location    string `json:"location"`
+	Status      string `json:"status"`
+	Description string `json:"description"`
+}
+
+// DocumentHashRecord anchors the hash of a shipping document
+type DocumentHashRecord struct {
+	DocumentID string `json:"documentId"`
+	BookingID  string `json:"bookingId"`
+	Algorithm  string `json:"algorithm"` // e.g. sha256
+	Hash       string `json:"hash"`      // Hex encoded
+}
+
+// startRecord starts the span of recording a fact of a kind on a backend
+func startRecord(ctx context.Context, backend string, kind string) (context.Context, trace.Span) {
+	return telemetry.Start(ctx, "ledger.Record",
+		attribute.String("ledger.backend", backend),
+		attribute.String("ledger.kind", kind),
+	)
+}
+
+// startLookup starts the span of reading a record of a kind back from a backend
+func startLookup(ctx context.Context, backend string, kind string) (context.Context, trace.Span) {
+	return telemetry.Start(ctx, "ledger.Get",
+		attribute.String("ledger.backend", backend),
+		attribute.String("ledger.kind", kind),
+	)
+}
+
+// decodeRecord decodes the payload of a record of a kind
+func decodeRecord(kind string, payload []byte, record interface{}) error {
+	if err := json.Unmarshal(payload, record); err != nil {
+		return fmt.Errorf("failed to decode %s record: %w", kind, err)
+	}
+	return nil
+}
+
+// subjectID returns the ID a record is filed under
+func subjectID(kind string, record interface{}) (string, error) {
+	var id string
+	switch r := record.(type) {
+	case *ListingRecord:
+		id = r.ProviderID
+	case *BookingRecord:
+		id = r.ServiceID
+	case *PaymentRecord:
+		id = r.BookingID
+	case *TrackingEventRecord:
+		id = r.BookingID
+	case *DocumentHashRecord:
+		id = r.DocumentID
+	}
+	if id == "" {
+		return "", fmt.Errorf("%s record has no subject ID", kind)
+	}
+	return id, nil
+}
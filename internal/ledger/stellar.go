@@ -0,0 +1,106 @@
+package ledger
+
+import (
+	"context"
+	"time"
+
+	"logistics-marketplace/internal/stellar"
+)
+
+// StellarBackend records facts through the marketplace contract on Stellar
+type StellarBackend struct {
+	txManager *stellar.TransactionManager
+}
+
+// NewStellarBackend creates a new StellarBackend instance
+func NewStellarBackend(txManager *stellar.TransactionManager) *StellarBackend {
+	return &StellarBackend{
+		txManager: txManager,
+	}
+}
+
+// Name returns the backend name
+func (b *StellarBackend) Name() string {
+	return BackendStellar
+}
+
+// RecordListing records a service listing
+func (b *StellarBackend) RecordListing(ctx context.Context, listing *ListingRecord) (*Receipt, error) {
+	_, span := startRecord(ctx, BackendStellar, KindListing)
+	defer span.End()
+
+	result, err := b.txManager.CreateServiceListing(
+		listing.ProviderID,
+		listing.Category,
+		listing.ShipmentMode,
+		listing.Origin,
+		listing.Destination,
+		listing.Price,
+		listing.Description,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return b.receipt(result.TxID), nil
+}
+
+// RecordBooking records a booking
+func (b *StellarBackend) RecordBooking(ctx context.Context, booking *BookingRecord) (*Receipt, error) {
+	_, span := startRecord(ctx, BackendStellar, KindBooking)
+	defer span.End()
+
+	result, err := b.txManager.CreateBooking(booking.CustomerID, booking.ServiceID, booking.Cargo)
+	if err != nil {
+		return nil, err
+	}
+	return b.receipt(result.TxID), nil
+}
+
+// RecordPayment records a payment
+func (b *StellarBackend) RecordPayment(ctx context.Context, payment *PaymentRecord) (*Receipt, error) {
+	_, span := startRecord(ctx, BackendStellar, KindPayment)
+	defer span.End()
+
+	result, err := b.txManager.ProcessPayment(payment.CustomerID, payment.BookingID, payment.Amount)
+	if err != nil {
+		return nil, err
+	}
+	return b.receipt(result.TxID), nil
+}
+
+// RecordTrackingEvent records a shipment status update
+func (b *StellarBackend) RecordTrackingEvent(ctx context.Context, event *TrackingEventRecord) (*Receipt, error) {
+	_, span := startRecord(ctx, BackendStellar, KindTrackingEvent)
+	defer span.End()
+
+	result, err := b.txManager.UpdateShipmentStatus(
+		event.BookingID,
+		event.Location,
+		event.Status,
+		event.Description,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return b.receipt(result.TxID), nil
+}
+
+// RecordDocumentHash anchors a document hash
+func (b *StellarBackend) RecordDocumentHash(ctx context.Context, document *DocumentHashRecord) (*Receipt, error) {
+	_, span := startRecord(ctx, BackendStellar, KindDocumentHash)
+	defer span.End()
+
+	result, err := b.txManager.RecordDocumentHash(document.DocumentID, document.BookingID, document.Hash)
+	if err != nil {
+		return nil, err
+	}
+	return b.receipt(result.TxID), nil
+}
+
+func (b *StellarBackend) receipt(txID string) *Receipt {
+	return &Receipt{
+		Backend:    BackendStellar,
+		TxID:       txID,
+		RecordedAt: time.Now(),
+	}
+}
@@ -44,7 +44,7 @@ type Quote struct {
 type Booking struct {
     BaseModel
     QuoteID         string     `json:"quote_id"`
-    ServiceID       string     `json:"service_id"`
+    ServiceID       string     `json:"service_id" validate:"required"`
     CustomerID      string     `json:"customer_id"`
     ProviderID      string     `json:"provider_id"`
     Status          string     `json:"status"`
@@ -95,6 +95,17 @@ type BookingPayment struct {
     PaidAt         time.Time `json:"paid_at,omitempty"`
     RefundedAt     time.Time `json:"refunded_at,omitempty"`
     EscrowID       string    `json:"escrow_id,omitempty"`
+    Schedule       []PaymentTranche `json:"schedule,omitempty"`
+    ReleasedAmount Currency  `json:"released_amount"`
+}
+
+// PaymentTranche represents the share of an escrowed payment released at a shipment milestone
+type PaymentTranche struct {
+    Milestone  string    `json:"milestone"`
+    Percentage int       `json:"percentage"`
+    Amount     Currency  `json:"amount"`
+    Released   bool      `json:"released"`
+    ReleasedAt time.Time `json:"released_at,omitempty"`
 }
 
 // BookingDispute represents a dispute raised for a booking
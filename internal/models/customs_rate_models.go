@@ -95,6 +95,18 @@ func (e *LicenseVerificationError) Error() string {
 		e.BrokerID, e.LicenseNumber, e.AuthorityID, e.Reason)
 }
 
+// CustomsBrokerRestrictionError represents an error when a broker acts outside their licensed country
+type CustomsBrokerRestrictionError struct {
+	BrokerID         string
+	BrokerCountry    string
+	RequestedCountry string
+}
+
+func (e *CustomsBrokerRestrictionError) Error() string {
+	return fmt.Sprintf("customs broker %s licensed in %s cannot clear customs in %s",
+		e.BrokerID, e.BrokerCountry, e.RequestedCountry)
+}
+
 // CustomsRateType represents different types of customs clearance rates
 type CustomsRateType string
 
@@ -108,8 +120,8 @@ const (
 // CustomsRate represents the rate structure for customs clearance
 type CustomsRate struct {
 	ID          string         `json:"id"`
-	Type        CustomsRateType `json:"type"`
-	Country     string         `json:"country"`
+	Type        CustomsRateType `json:"type" validate:"required,oneof=ORIGIN TRANSSHIPMENT TRANSIT DESTINATION"`
+	Country     string         `json:"country" validate:"required,country"`
 	Port        string         `json:"port,omitempty"`
 	Airport     string         `json:"airport,omitempty"`
 	
@@ -117,8 +129,8 @@ type CustomsRate struct {
 	TransportModeRates map[TransportMode]TransportModeRate `json:"transport_mode_rates"`
 	
 	// Base Charges
-	BasicHandling float64 `json:"basic_handling"`
-	Documentation float64 `json:"documentation"`
+	BasicHandling float64 `json:"basic_handling" validate:"gt=0"`
+	Documentation float64 `json:"documentation" validate:"gt=0"`
 	
 	// Government Fees
 	CustomsDuty   float64 `json:"customs_duty"` // Percentage
@@ -139,7 +151,7 @@ type CustomsRate struct {
 	PeakSeasonSurcharge float64 `json:"peak_season_surcharge"`
 	ExpressHandling    float64 `json:"express_handling"`
 	
-	Currency     string    `json:"currency"`
+	Currency     string    `json:"currency" validate:"required,iso4217"`
 	ValidFrom    time.Time `json:"valid_from"`
 	ValidUntil   time.Time `json:"valid_until"`
 	ProviderID   string    `json:"provider_id"`
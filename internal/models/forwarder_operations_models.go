@@ -11,12 +11,12 @@ type QuoteRequest struct {
 	CustomerID  string         `json:"customer_id"`
 	
 	// Transport Details
-	Mode        TransportMode  `json:"mode"`
+	Mode        TransportMode  `json:"mode" validate:"required,oneof=SEA AIR RAIL ROAD LAND"`
 	ServiceType ServiceCategory `json:"service_type"`
 	
 	// Route
-	Origin      Location       `json:"origin"`
-	Destination Location       `json:"destination"`
+	Origin      Location       `json:"origin" validate:"required"`
+	Destination Location       `json:"destination" validate:"required"`
 	ViaPoints   []Location    `json:"via_points,omitempty"`
 	
 	// Schedule
@@ -26,7 +26,7 @@ type QuoteRequest struct {
 	// Cargo Details
 	CargoType   string        `json:"cargo_type"`
 	Incoterms   string        `json:"incoterms"`
-	Cargo       []CargoUnit   `json:"cargo"`
+	Cargo       []CargoUnit   `json:"cargo" validate:"required,min=1,dive"`
 	
 	// Service Requirements
 	RequiredServices []string  `json:"required_services"` // customs, insurance, etc.
@@ -39,18 +39,18 @@ type QuoteRequest struct {
 // Location represents a service point in the transport chain
 type Location struct {
 	Type        string        `json:"type"` // AIRPORT, SEAPORT, DEPOT, ADDRESS
-	Code        string        `json:"code"` // IATA/UN LOCODE/Depot Code
+	Code        string        `json:"code" validate:"required"` // IATA/UN LOCODE/Depot Code
 	Name        string        `json:"name"`
-	Country     string        `json:"country"`
+	Country     string        `json:"country" validate:"omitempty,country"`
 	Address     string        `json:"address,omitempty"`
 }
 
 // CargoUnit represents individual cargo units
 type CargoUnit struct {
 	Type        string        `json:"type"` // CONTAINER, PALLET, CARTON, etc.
-	Quantity    int           `json:"quantity"`
-	Weight      float64       `json:"weight"`
-	Volume      float64       `json:"volume"`
+	Quantity    int           `json:"quantity" validate:"gt=0"`
+	Weight      float64       `json:"weight" validate:"gt=0"`
+	Volume      float64       `json:"volume" validate:"gte=0"`
 	Length      float64       `json:"length,omitempty"`
 	Width       float64       `json:"width,omitempty"`
 	Height      float64       `json:"height,omitempty"`
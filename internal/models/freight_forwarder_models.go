@@ -63,12 +63,12 @@ type RateRequest struct {
 	ShipperID       string         `json:"shipper_id"`
 	
 	// Route Information
-	Origin          string         `json:"origin"`
-	OriginCountry   string         `json:"origin_country"`      // ISO country code
-	Destination     string         `json:"destination"`
-	DestCountry     string         `json:"destination_country"` // ISO country code
-	TransportMode   TransportMode  `json:"transport_mode"`
-	ServiceType     ServiceCategory `json:"service_type"` // Import, Export, Transit, Transshipment
+	Origin          string         `json:"origin" validate:"required"`
+	OriginCountry   string         `json:"origin_country" validate:"required,country"`      // ISO country code
+	Destination     string         `json:"destination" validate:"required"`
+	DestCountry     string         `json:"destination_country" validate:"required,country"` // ISO country code
+	TransportMode   TransportMode  `json:"transport_mode" validate:"required,oneof=SEA AIR RAIL ROAD LAND"`
+	ServiceType     ServiceCategory `json:"service_type" validate:"required,oneof=IMPORT EXPORT TRANSIT TRANSSHIPMENT"` // Import, Export, Transit, Transshipment
 	
 	// Cargo Details
 	CargoDetails    CargoDetails   `json:"cargo_details"`
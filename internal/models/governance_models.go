@@ -34,6 +34,14 @@ const (
 	VoteTypeAbstain VoteType = "ABSTAIN"
 )
 
+// VotingMode represents how votes on a proposal are collected
+type VotingMode string
+
+const (
+	VotingModeOnChain  VotingMode = "ON_CHAIN"
+	VotingModeOffChain VotingMode = "OFF_CHAIN"
+)
+
 // GovernanceProposal represents a governance proposal in the system
 type GovernanceProposal struct {
 	BaseModel
@@ -50,6 +58,8 @@ type GovernanceProposal struct {
 	ExecutionTime   *time.Time    `json:"execution_time,omitempty"`
 	ProposalData    []byte        `json:"proposal_data"`
 	ContractAddress string        `json:"contract_address"`
+	VotingMode      VotingMode    `json:"voting_mode"`
+	BallotRoot      string        `json:"ballot_root,omitempty"`
 }
 
 // GovernanceVote represents a vote cast on a governance proposal
@@ -76,14 +86,51 @@ type GovernanceParameter struct {
 type ProposalCreateRequest struct {
 	Title        string       `json:"title" validate:"required"`
 	Description  string       `json:"description" validate:"required"`
-	ProposalType ProposalType `json:"proposal_type" validate:"required"`
+	ProposalType ProposalType `json:"proposal_type" validate:"required,oneof=PARAMETER_CHANGE CONTRACT_UPGRADE FUNDS_ALLOCATION SERVICE_UPDATE"`
 	ProposalData []byte       `json:"proposal_data"`
+	VotingMode   VotingMode   `json:"voting_mode" validate:"omitempty,oneof=ON_CHAIN OFF_CHAIN"`
 }
 
 // VoteCastRequest represents the request to cast a vote on a proposal
 type VoteCastRequest struct {
 	ProposalID string   `json:"proposal_id" validate:"required"`
-	VoteType   VoteType `json:"vote_type" validate:"required"`
+	VoteType   VoteType `json:"vote_type" validate:"required,oneof=FOR AGAINST ABSTAIN"`
+}
+
+// SignedVoteMessage represents the structured message a voter signs with
+// their Stellar key to cast an off-chain vote
+type SignedVoteMessage struct {
+	ProposalID string   `json:"proposal_id"`
+	Voter      string   `json:"voter"`
+	VoteType   VoteType `json:"vote_type"`
+	Timestamp  int64    `json:"timestamp"`
+}
+
+// SignedVoteRequest represents the request to cast an off-chain vote
+type SignedVoteRequest struct {
+	ProposalID string   `json:"proposal_id" validate:"required"`
+	VoteType   VoteType `json:"vote_type" validate:"required,oneof=FOR AGAINST ABSTAIN"`
+	Timestamp  int64    `json:"timestamp" validate:"required"`
+	Signature  string   `json:"signature" validate:"required"` // Base64 encoded ed25519 signature
+}
+
+// SignedBallot represents a verified off-chain vote
+type SignedBallot struct {
+	Message   SignedVoteMessage `json:"message"`
+	Signature string            `json:"signature"`
+	VotePower uint64            `json:"vote_power"`
+	LeafHash  string            `json:"leaf_hash"`
+}
+
+// BallotSetResponse represents the published set of off-chain ballots for a proposal
+type BallotSetResponse struct {
+	ProposalID   string         `json:"proposal_id"`
+	Ballots      []SignedBallot `json:"ballots"`
+	MerkleRoot   string         `json:"merkle_root"`
+	ForVotes     uint64         `json:"for_votes"`
+	AgainstVotes uint64         `json:"against_votes"`
+	AbstainVotes uint64         `json:"abstain_votes"`
+	Attested     bool           `json:"attested"`
 }
 
 // ProposalExecuteRequest represents the request to execute a proposal
@@ -91,15 +138,21 @@ type ProposalExecuteRequest struct {
 	ProposalID string `json:"proposal_id" validate:"required"`
 }
 
+// ProposalVoteSummary represents the tallied votes of a proposal
+type ProposalVoteSummary struct {
+	TotalVotes         uint64  `json:"total_votes"`
+	QuorumReached      bool    `json:"quorum_reached"`
+	MajorityReached    bool    `json:"majority_reached"`
+	ApprovalRate       float64 `json:"approval_rate"`
+	ParticipationRate  float64 `json:"participation_rate"`
+	QuorumPercentage   uint64  `json:"quorum_percentage"`
+	MajorityPercentage uint64  `json:"majority_percentage"`
+}
+
 // ProposalResponse represents the response for proposal-related operations
 type ProposalResponse struct {
-	Proposal    GovernanceProposal `json:"proposal"`
-	VoteSummary struct {
-		TotalVotes     uint64  `json:"total_votes"`
-		QuorumReached  bool    `json:"quorum_reached"`
-		ApprovalRate   float64 `json:"approval_rate"`
-		ParticipationRate float64 `json:"participation_rate"`
-	} `json:"vote_summary"`
+	Proposal    GovernanceProposal  `json:"proposal"`
+	VoteSummary ProposalVoteSummary `json:"vote_summary"`
 }
 
 // VoteResponse represents the response for vote-related operations
@@ -1,6 +1,7 @@
 package models
 
 import (
+    "fmt"
     "time"
 )
 
@@ -12,6 +13,20 @@ const (
     TierEnterprise = "ENTERPRISE"
 )
 
+// MembershipType identifies the kind of member holding a membership
+type MembershipType string
+
+// MembershipError represents an error when a member lacks a valid membership
+type MembershipError struct {
+    MemberID   string
+    MemberType MembershipType
+    Reason     string
+}
+
+func (e *MembershipError) Error() string {
+    return fmt.Sprintf("membership of %s %s is not valid: %s", e.MemberType, e.MemberID, e.Reason)
+}
+
 // MembershipStatus represents the status of a membership
 const (
     StatusActive    = "ACTIVE"
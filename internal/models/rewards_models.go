@@ -0,0 +1,93 @@
+package models
+
+import (
+	"time"
+)
+
+// RewardRuleType represents the behaviour a reward rule pays for
+type RewardRuleType string
+
+const (
+	RewardOnTimeDeparture RewardRuleType = "ON_TIME_DEPARTURE"
+	RewardOnTimeArrival   RewardRuleType = "ON_TIME_ARRIVAL"
+	RewardOnTimeDocuments RewardRuleType = "ON_TIME_DOCUMENTS"
+)
+
+// RewardEntryStatus represents the payout status of a rewards ledger entry
+type RewardEntryStatus string
+
+const (
+	RewardStatusPending      RewardEntryStatus = "PENDING"
+	RewardStatusDistributing RewardEntryStatus = "DISTRIBUTING" // Transfer submitted, not yet confirmed
+	RewardStatusDistributed  RewardEntryStatus = "DISTRIBUTED"
+)
+
+// RewardRule represents an LMT reward for on-time performance
+type RewardRule struct {
+	Type      RewardRuleType `json:"type"`
+	Amount    uint64         `json:"amount"`    // LMT per qualifying booking
+	Tolerance time.Duration  `json:"tolerance"` // Lateness still counted as on time
+}
+
+// DocumentSubmission represents a booking document and when it was submitted
+type DocumentSubmission struct {
+	DocumentType string     `json:"document_type"`
+	Deadline     time.Time  `json:"deadline"`
+	SubmittedAt  *time.Time `json:"submitted_at,omitempty"`
+}
+
+// ConfirmedBooking represents the recorded parties, confirmed schedule and
+// document filings of a booking
+type ConfirmedBooking struct {
+	BookingID          string               `json:"booking_id"`
+	ProviderAddress    string               `json:"provider_address"`
+	CustomerAddress    string               `json:"customer_address"`
+	ConfirmedDeparture time.Time            `json:"confirmed_departure"`
+	ConfirmedArrival   time.Time            `json:"confirmed_arrival"`
+	Documents          []DocumentSubmission `json:"documents"`
+}
+
+// ShipmentStatusUpdate represents a status recorded for a booking's shipment
+type ShipmentStatusUpdate struct {
+	Status    string    `json:"status"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// RewardEvaluationRequest represents a request to evaluate a delivered booking for rewards
+type RewardEvaluationRequest struct {
+	BookingID string `json:"booking_id" validate:"required"`
+}
+
+// BookingPerformance represents the confirmed and actual dates of a
+// delivered booking that reward rules are evaluated against
+type BookingPerformance struct {
+	BookingID          string               `json:"booking_id"`
+	ProviderAddress    string               `json:"provider_address"`
+	CustomerAddress    string               `json:"customer_address"`
+	ConfirmedDeparture time.Time            `json:"confirmed_departure"`
+	ConfirmedArrival   time.Time            `json:"confirmed_arrival"`
+	ActualDeparture    *time.Time           `json:"actual_departure,omitempty"`
+	ActualArrival      *time.Time           `json:"actual_arrival,omitempty"`
+	Documents          []DocumentSubmission `json:"documents"`
+}
+
+// RewardEntry represents a reward earned by an account for a booking
+type RewardEntry struct {
+	ID            string            `json:"id"`
+	Account       string            `json:"account"`
+	BookingID     string            `json:"booking_id"`
+	Rule          RewardRuleType    `json:"rule"`
+	Amount        uint64            `json:"amount"`
+	Status        RewardEntryStatus `json:"status"`
+	EarnedAt      time.Time         `json:"earned_at"`
+	DistributedAt *time.Time        `json:"distributed_at,omitempty"`
+}
+
+// RewardsLedger represents the rewards earned and paid out to an account
+type RewardsLedger struct {
+	Account          string        `json:"account"`
+	TotalEarned      uint64        `json:"total_earned"`
+	TotalDistributed uint64        `json:"total_distributed"`
+	Pending          uint64        `json:"pending"`
+	Entries          []RewardEntry `json:"entries"`
+}
@@ -0,0 +1,74 @@
+package models
+
+import (
+	"time"
+)
+
+// DisputeStatus represents the status of a booking dispute on the marketplace contract
+type DisputeStatus string
+
+const (
+	DisputeStatusOpen     DisputeStatus = "OPEN"
+	DisputeStatusUpheld   DisputeStatus = "UPHELD"
+	DisputeStatusRejected DisputeStatus = "REJECTED"
+)
+
+// SLABreachReason represents why provider stake was slashed
+type SLABreachReason string
+
+const (
+	SLABreachLateDelivery SLABreachReason = "LATE_DELIVERY"
+	SLABreachDispute      SLABreachReason = "DISPUTE"
+)
+
+// UnbondingEntry represents stake waiting out the unbonding period
+type UnbondingEntry struct {
+	Amount    uint64    `json:"amount"`
+	ReleaseAt time.Time `json:"release_at"`
+}
+
+// ProviderStake represents the LMT a provider has bonded to back its listings
+type ProviderStake struct {
+	Provider      string            `json:"provider"`
+	Bonded        uint64            `json:"bonded"`
+	RequiredStake uint64            `json:"required_stake"`
+	Unbonding     []UnbondingEntry  `json:"unbonding"`
+	Slashed       uint64            `json:"slashed"`
+	Listings      map[string]uint32 `json:"listings"` // service type -> active listings
+	OpenDisputes  uint32            `json:"open_disputes"`
+	LockedUntil   *time.Time        `json:"locked_until,omitempty"` // Open disputes stop blocking withdrawal after this time
+}
+
+// StakeRequest represents a request to bond or unbond stake
+type StakeRequest struct {
+	Amount uint64 `json:"amount" validate:"required"`
+}
+
+// ScheduleConfirmationRequest represents the schedule a provider commits to for a booking
+type ScheduleConfirmationRequest struct {
+	Pickup    time.Time `json:"pickup" validate:"required"`
+	Departure time.Time `json:"departure" validate:"required"`
+	Arrival   time.Time `json:"arrival" validate:"required"`
+	Delivery  time.Time `json:"delivery" validate:"required"`
+}
+
+// DisputeRequest represents a customer raising a dispute against a booking
+type DisputeRequest struct {
+	Reason string `json:"reason" validate:"required"`
+}
+
+// DisputeResolutionRequest represents an arbiter ruling on a dispute
+type DisputeResolutionRequest struct {
+	Upheld bool `json:"upheld"`
+}
+
+// MarketplaceDispute represents a booking dispute recorded on the marketplace contract
+type MarketplaceDispute struct {
+	BookingID  string        `json:"booking_id"`
+	Customer   string        `json:"customer"`
+	Provider   string        `json:"provider"`
+	Reason     string        `json:"reason"`
+	Status     DisputeStatus `json:"status"`
+	RaisedAt   time.Time     `json:"raised_at"`
+	ResolvedAt *time.Time    `json:"resolved_at,omitempty"`
+}
@@ -82,9 +82,9 @@ type Fee struct {
 type Cargo struct {
     Type            string    `json:"type"`
     Description     string    `json:"description"`
-    Weight          float64   `json:"weight"`
+    Weight          float64   `json:"weight" validate:"gt=0"`
     WeightUnit      string    `json:"weight_unit"`
-    Volume          float64   `json:"volume"`
+    Volume          float64   `json:"volume" validate:"gt=0"`
     VolumeUnit      string    `json:"volume_unit"`
     Pieces          int       `json:"pieces"`
     ContainerType   string    `json:"container_type,omitempty"`
@@ -0,0 +1,98 @@
+package models
+
+import (
+	"time"
+)
+
+// FundsAllocationKind represents what a FUNDS_ALLOCATION proposal does
+type FundsAllocationKind string
+
+const (
+	FundsAllocationSetBudget  FundsAllocationKind = "SET_BUDGET"
+	FundsAllocationAwardGrant FundsAllocationKind = "AWARD_GRANT"
+)
+
+// DisbursementType represents how a treasury grant is paid out
+type DisbursementType string
+
+const (
+	DisbursementStreaming DisbursementType = "STREAMING"
+	DisbursementMilestone DisbursementType = "MILESTONE"
+)
+
+// FundsAllocationData represents the proposal data of a FUNDS_ALLOCATION proposal
+type FundsAllocationData struct {
+	Kind         FundsAllocationKind `json:"kind"`
+	Category     string              `json:"category"`
+	Budget       uint64              `json:"budget,omitempty"`
+	Grantee      string              `json:"grantee,omitempty"`
+	Amount       uint64              `json:"amount,omitempty"`
+	Disbursement DisbursementType    `json:"disbursement,omitempty"`
+	StreamStart  int64               `json:"stream_start,omitempty"`
+	StreamEnd    int64               `json:"stream_end,omitempty"`
+	Reviewer     string              `json:"reviewer,omitempty"`
+	Milestones   []GrantMilestone    `json:"milestones,omitempty"`
+}
+
+// TreasuryBudgetCategory represents a treasury spending category
+type TreasuryBudgetCategory struct {
+	Name      string `json:"name"`
+	Budget    uint64 `json:"budget"`
+	Allocated uint64 `json:"allocated"`
+	Spent     uint64 `json:"spent"`
+	Remaining uint64 `json:"remaining"`
+}
+
+// GrantMilestone represents a tranche of a milestone-based grant
+type GrantMilestone struct {
+	Description string `json:"description"`
+	Amount      uint64 `json:"amount"`
+	Released    bool   `json:"released"`
+}
+
+// TreasuryGrant represents treasury funds awarded by an executed proposal
+type TreasuryGrant struct {
+	ID           string           `json:"id"`
+	ProposalID   string           `json:"proposal_id"`
+	Category     string           `json:"category"`
+	Grantee      string           `json:"grantee"`
+	Amount       uint64           `json:"amount"`
+	Released     uint64           `json:"released"`
+	Disbursement DisbursementType `json:"disbursement"`
+	StreamStart  *time.Time       `json:"stream_start,omitempty"`
+	StreamEnd    *time.Time       `json:"stream_end,omitempty"`
+	Reviewer     string           `json:"reviewer,omitempty"`
+	Milestones   []GrantMilestone `json:"milestones,omitempty"`
+}
+
+// TreasuryDisbursement represents a payment out of the treasury
+type TreasuryDisbursement struct {
+	GrantID   string    `json:"grant_id"`
+	Category  string    `json:"category"`
+	Grantee   string    `json:"grantee"`
+	Amount    uint64    `json:"amount"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// TreasurySpendReport represents treasury spending over a period
+type TreasurySpendReport struct {
+	From            time.Time                `json:"from"`
+	To              time.Time                `json:"to"`
+	Balance         uint64                   `json:"balance"`
+	TotalSpent      uint64                   `json:"total_spent"`
+	SpentByCategory map[string]uint64        `json:"spent_by_category"`
+	Categories      []TreasuryBudgetCategory `json:"categories"`
+	Disbursements   []TreasuryDisbursement   `json:"disbursements"`
+}
+
+// TreasuryDepositRequest represents a request to deposit tokens into the treasury
+type TreasuryDepositRequest struct {
+	Amount uint64 `json:"amount" validate:"required"`
+}
+
+// TreasuryDeposit represents tokens moved into the treasury
+type TreasuryDeposit struct {
+	From    string `json:"from"`
+	Amount  uint64 `json:"amount"`
+	Balance uint64 `json:"balance"` // Treasury balance after the deposit
+}
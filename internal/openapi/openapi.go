@@ -0,0 +1,297 @@
+// Package openapi describes the API as an OpenAPI 3 document generated from
+// the registered Gin routes and the Go types of their requests and responses,
+// and validates traffic against it.
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"logistics-marketplace/internal/problem"
+)
+
+// Version is the OpenAPI version of generated documents
+const Version = "3.0.3"
+
+// Document is an OpenAPI document
+type Document struct {
+	OpenAPI    string                `json:"openapi"`
+	Info       Info                  `json:"info"`
+	Paths      map[string]PathItem   `json:"paths"`
+	Components Components            `json:"components"`
+	Security   []SecurityRequirement `json:"security,omitempty"`
+}
+
+// Info describes the API
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// PathItem holds the operations of a path by lower-case method
+type PathItem map[string]*Operation
+
+// SecurityRequirement names the security schemes an operation accepts
+type SecurityRequirement map[string][]string
+
+// Operation is one method of one path
+type Operation struct {
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []SecurityRequirement `json:"security,omitempty"`
+
+	public bool
+}
+
+// MarshalJSON writes an empty security list for public operations, which
+// lifts the document-wide requirement
+func (o *Operation) MarshalJSON() ([]byte, error) {
+	type operation Operation
+	if !o.public {
+		return json.Marshal((*operation)(o))
+	}
+	return json.Marshal(struct {
+		*operation
+		Security []SecurityRequirement `json:"security"`
+	}{(*operation)(o), []SecurityRequirement{}})
+}
+
+// Parameter is a path or query parameter
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required,omitempty"`
+	Schema   *Schema `json:"schema"`
+}
+
+// RequestBody is the body an operation accepts
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response is a response an operation may answer
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType holds the schema of a body
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components holds the schemas shared between operations
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// SecurityScheme describes how callers authenticate
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	In           string `json:"in,omitempty"`
+	Name         string `json:"name,omitempty"`
+}
+
+// Route describes the request and response of a route. Request and Query
+// are values of the types bound from the body and the query string, and
+// Response a value of the type answered with Status.
+type Route struct {
+	Summary  string
+	Request  interface{}
+	Query    interface{}
+	Response interface{}
+	Status   int
+}
+
+// Spec generates the document of a router once every route is registered
+type Spec struct {
+	router  *gin.Engine
+	info    Info
+	routes  map[string]Route
+	public  map[string]bool
+	schemas *schemaBuilder
+
+	once       sync.Once
+	doc        *Document
+	operations map[string]*Operation // By method and Gin path
+}
+
+// NewSpec creates the spec of a router
+func NewSpec(router *gin.Engine, title string, version string) *Spec {
+	return &Spec{
+		router:  router,
+		info:    Info{Title: title, Version: version},
+		routes:  make(map[string]Route),
+		public:  make(map[string]bool),
+		schemas: newSchemaBuilder(),
+	}
+}
+
+// Describe sets the request and response of the route of a method and Gin path
+func (s *Spec) Describe(method string, path string, route Route) *Spec {
+	s.routes[method+" "+path] = route
+	return s
+}
+
+// Enum lists the values of an enumerated type, such as the constants of
+// models.TransportMode, for every schema using the type
+func (s *Spec) Enum(values ...interface{}) *Spec {
+	for _, value := range values {
+		t := reflect.TypeOf(value)
+		s.schemas.enums[t] = append(s.schemas.enums[t], value)
+	}
+	return s
+}
+
+// Public marks Gin paths that need no credentials
+func (s *Spec) Public(paths ...string) *Spec {
+	for _, path := range paths {
+		s.public[path] = true
+	}
+	return s
+}
+
+// Document returns the document, generating it on first use
+func (s *Spec) Document() *Document {
+	s.once.Do(s.build)
+	return s.doc
+}
+
+// Handler serves the document
+func (s *Spec) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, s.Document())
+	}
+}
+
+func (s *Spec) build() {
+	problemRef := s.schemas.schema(reflect.TypeOf(problem.Problem{}))
+
+	s.doc = &Document{
+		OpenAPI: Version,
+		Info:    s.info,
+		Paths:   make(map[string]PathItem),
+		Components: Components{
+			Schemas: s.schemas.components,
+			SecuritySchemes: map[string]SecurityScheme{
+				"bearerAuth": {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+				"apiKey":     {Type: "apiKey", In: "header", Name: "X-API-Key"},
+			},
+		},
+		Security: []SecurityRequirement{{"bearerAuth": {}}, {"apiKey": {}}},
+	}
+	s.operations = make(map[string]*Operation)
+
+	routes := s.router.Routes()
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+
+	for _, info := range routes {
+		path, params := documentPath(info.Path)
+		op := &Operation{
+			OperationID: operationID(info.Method, info.Path),
+			Tags:        []string{tag(info.Path)},
+			Parameters:  params,
+			Responses: map[string]*Response{
+				"default": {
+					Description: "Problem",
+					Content:     map[string]MediaType{problem.ContentType: {Schema: problemRef}},
+				},
+			},
+			public: s.public[info.Path],
+		}
+
+		route, described := s.routes[info.Method+" "+info.Path]
+		op.Summary = route.Summary
+		if route.Request != nil {
+			op.RequestBody = &RequestBody{
+				Required: true,
+				Content:  map[string]MediaType{gin.MIMEJSON: {Schema: s.schemas.schema(reflect.TypeOf(route.Request))}},
+			}
+		}
+		if route.Query != nil {
+			op.Parameters = append(op.Parameters, s.schemas.queryParameters(reflect.TypeOf(route.Query))...)
+		}
+
+		status := route.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		response := &Response{Description: http.StatusText(status)}
+		if route.Response != nil {
+			response.Content = map[string]MediaType{gin.MIMEJSON: {Schema: s.schemas.schema(reflect.TypeOf(route.Response))}}
+		} else if !described {
+			response.Description = "Undocumented response"
+		}
+		op.Responses[strconv.Itoa(status)] = response
+
+		if s.doc.Paths[path] == nil {
+			s.doc.Paths[path] = make(PathItem)
+		}
+		s.doc.Paths[path][strings.ToLower(info.Method)] = op
+		s.operations[info.Method+" "+info.Path] = op
+	}
+}
+
+// operation returns the operation of a method and Gin path
+func (s *Spec) operation(method string, path string) *Operation {
+	s.Document()
+	return s.operations[method+" "+path]
+}
+
+// documentPath turns a Gin path such as /bookings/:id into /bookings/{id},
+// returning its parameters
+func documentPath(ginPath string) (string, []Parameter) {
+	var params []Parameter
+	segments := strings.Split(ginPath, "/")
+	for i, segment := range segments {
+		if segment == "" || (segment[0] != ':' && segment[0] != '*') {
+			continue
+		}
+		name := segment[1:]
+		segments[i] = "{" + name + "}"
+		params = append(params, Parameter{Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"}})
+	}
+	return strings.Join(segments, "/"), params
+}
+
+// operationID names an operation after its method and path, such as
+// postGovernanceProposalsIdVote
+func operationID(method string, ginPath string) string {
+	id := strings.ToLower(method)
+	for _, segment := range strings.Split(strings.TrimPrefix(ginPath, "/api/v1"), "/") {
+		for _, word := range strings.FieldsFunc(segment, func(r rune) bool {
+			return !('a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9')
+		}) {
+			id += strings.ToUpper(word[:1]) + word[1:]
+		}
+	}
+	return id
+}
+
+// tag groups an operation by the first segment of its path below /api/v1
+func tag(ginPath string) string {
+	trimmed := strings.TrimPrefix(strings.TrimPrefix(ginPath, "/api/v1"), "/")
+	if segment := strings.SplitN(trimmed, "/", 2)[0]; segment != "" && !strings.HasPrefix(segment, ":") {
+		return strings.TrimPrefix(segment, ".")
+	}
+	return "default"
+}
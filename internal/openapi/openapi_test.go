@@ -0,0 +1,134 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"logistics-marketplace/internal/problem"
+)
+
+type transportMode string
+
+type base struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type cargoUnit struct {
+	Weight   float64 `json:"weight" validate:"gt=0" example:"1200.5"`
+	Quantity int     `json:"quantity"`
+}
+
+type quoteRequest struct {
+	base
+	Mode    transportMode `json:"mode" validate:"required"`
+	Country string        `json:"country" validate:"required,country"`
+	Cargo   []cargoUnit   `json:"cargo" validate:"required,min=1,dive"`
+	Notes   string        `json:"notes,omitempty"`
+	secret  string
+}
+
+func newTestSpec(t *testing.T) (*gin.Engine, *Spec) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	spec := NewSpec(router, "Logistics Marketplace API", "1.0.0").
+		Enum(transportMode("SEA"), transportMode("AIR")).
+		Describe(http.MethodPost, "/api/v1/quotes/:id", Route{
+			Summary:  "Request a quote",
+			Request:  quoteRequest{},
+			Response: quoteRequest{},
+			Status:   http.StatusCreated,
+		}).
+		Public("/health")
+	router.Use(spec.Middleware())
+
+	router.POST("/api/v1/quotes/:id", func(c *gin.Context) {
+		var req quoteRequest
+		require.NoError(t, c.ShouldBindJSON(&req))
+		c.JSON(http.StatusCreated, req)
+	})
+	router.GET("/health", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.GET("/api/v1/openapi.json", spec.Handler())
+	return router, spec
+}
+
+func TestDocumentDescribesRoutesAndModels(t *testing.T) {
+	router, _ := newTestSpec(t)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/openapi.json", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var doc map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &doc))
+	assert.Equal(t, Version, doc["openapi"])
+
+	paths := doc["paths"].(map[string]interface{})
+	op := paths["/api/v1/quotes/{id}"].(map[string]interface{})["post"].(map[string]interface{})
+	assert.Equal(t, "postQuotesId", op["operationId"])
+	assert.Equal(t, []interface{}{"quotes"}, op["tags"])
+	assert.Equal(t, "id", op["parameters"].([]interface{})[0].(map[string]interface{})["name"])
+	assert.Contains(t, op["responses"], "201")
+	assert.Contains(t, op["responses"], "default")
+	assert.NotContains(t, op, "security")
+
+	health := paths["/health"].(map[string]interface{})["get"].(map[string]interface{})
+	assert.Equal(t, []interface{}{}, health["security"])
+
+	schemas := doc["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+	quote := schemas["quoteRequest"].(map[string]interface{})
+	assert.ElementsMatch(t, []interface{}{"mode", "country", "cargo"}, quote["required"])
+
+	props := quote["properties"].(map[string]interface{})
+	assert.Contains(t, props, "id")
+	assert.Equal(t, map[string]interface{}{"type": "string", "format": "date-time"}, props["created_at"])
+	assert.Equal(t, []interface{}{"SEA", "AIR"}, props["mode"].(map[string]interface{})["enum"])
+	assert.Equal(t, "^[A-Z]{2}$", props["country"].(map[string]interface{})["pattern"])
+	assert.EqualValues(t, 1, props["cargo"].(map[string]interface{})["minItems"])
+	assert.NotContains(t, props, "secret")
+
+	weight := schemas["cargoUnit"].(map[string]interface{})["properties"].(map[string]interface{})["weight"].(map[string]interface{})
+	assert.Equal(t, true, weight["exclusiveMinimum"])
+	assert.Equal(t, 1200.5, weight["example"])
+	assert.Contains(t, schemas, "Problem")
+}
+
+func TestMiddlewareRejectsRequestsNotMatchingTheDocument(t *testing.T) {
+	router, _ := newTestSpec(t)
+
+	body := `{"mode": "TRAIN", "country": "Netherlands", "cargo": [{"weight": 0, "quantity": 1.5}]}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/quotes/Q1", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	var p problem.Problem
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &p))
+	assert.Equal(t, problem.CodeValidationFailed, p.Code)
+
+	fields := make(map[string]string)
+	for _, fieldErr := range p.Errors {
+		fields[fieldErr.Field] = fieldErr.Code
+	}
+	assert.Equal(t, map[string]string{
+		"mode":              "oneof",
+		"country":           "pattern",
+		"cargo[0].weight":   "gt",
+		"cargo[0].quantity": "type",
+	}, fields)
+
+	body = `{"mode": "SEA", "country": "NL", "cargo": [{"weight": 10, "quantity": 2}]}`
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/quotes/Q1", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+}
@@ -0,0 +1,315 @@
+package openapi
+
+import (
+	"encoding/json"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schema is a JSON schema as used by OpenAPI 3.0
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	ExclusiveMinimum     bool               `json:"exclusiveMinimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Example              interface{}        `json:"example,omitempty"`
+}
+
+// refPrefix starts the reference of a component schema
+const refPrefix = "#/components/schemas/"
+
+// Patterns of the marketplace's own validation rules
+var rulePatterns = map[string]string{
+	"country": `^[A-Z]{2}$`,
+	"hs_code": `^\d{4}\.?\d{2}(\.?\d{2}){0,2}$`,
+	"iso4217": `^[A-Z]{3}$`,
+}
+
+var (
+	timeType    = reflect.TypeOf(time.Time{})
+	rawJSONType = reflect.TypeOf(json.RawMessage{})
+)
+
+// schemaBuilder turns Go types into schemas, keeping named structs as
+// component schemas
+type schemaBuilder struct {
+	components map[string]*Schema
+	enums      map[reflect.Type][]interface{}
+	types      map[string]reflect.Type // Component name to the type it describes
+}
+
+func newSchemaBuilder() *schemaBuilder {
+	return &schemaBuilder{
+		components: make(map[string]*Schema),
+		enums:      make(map[reflect.Type][]interface{}),
+		types:      make(map[string]reflect.Type),
+	}
+}
+
+// schema describes a type, referring to the component schema of named structs
+func (b *schemaBuilder) schema(t reflect.Type) *Schema {
+	nullable := false
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+		nullable = true
+	}
+
+	var s *Schema
+	switch {
+	case t == timeType:
+		s = &Schema{Type: "string", Format: "date-time"}
+	case t == rawJSONType:
+		s = &Schema{}
+	case t.Kind() == reflect.Struct && t.Name() != "":
+		return b.component(t)
+	case t.Kind() == reflect.Struct:
+		s = b.object(t)
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		s = &Schema{Type: "string", Format: "byte"}
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		s = &Schema{Type: "array", Items: b.schema(t.Elem())}
+	case t.Kind() == reflect.Map:
+		s = &Schema{Type: "object", AdditionalProperties: b.schema(t.Elem())}
+	case t.Kind() == reflect.Interface:
+		s = &Schema{}
+	default:
+		s = primitive(t.Kind())
+	}
+
+	if values, ok := b.enums[t]; ok {
+		s.Enum = values
+	}
+	s.Nullable = nullable && s.Type != ""
+	return s
+}
+
+// component refers to the component schema of a named struct, describing it
+// on first use
+func (b *schemaBuilder) component(t reflect.Type) *Schema {
+	name := t.Name()
+	if existing, ok := b.types[name]; ok && existing != t {
+		// Tell apart structs of the same name from different packages
+		pkg := path.Base(t.PkgPath())
+		name = strings.ToUpper(pkg[:1]) + pkg[1:] + name
+	}
+	if _, ok := b.types[name]; !ok {
+		b.types[name] = t
+		b.components[name] = &Schema{} // Placeholder for recursive types
+		*b.components[name] = *b.object(t)
+	}
+	return &Schema{Ref: refPrefix + name}
+}
+
+// object describes the JSON fields of a struct, flattening embedded structs
+func (b *schemaBuilder) object(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, omitEmpty, ok := jsonName(field)
+		if !ok {
+			continue
+		}
+
+		if field.Anonymous && field.Tag.Get("json") == "" {
+			embedded := field.Type
+			for embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				inner := b.object(embedded)
+				for prop, schema := range inner.Properties {
+					s.Properties[prop] = schema
+				}
+				s.Required = append(s.Required, inner.Required...)
+				continue
+			}
+		}
+
+		prop := b.schema(field.Type)
+		required := b.applyRules(prop, field.Type, field.Tag.Get("validate"))
+		if example := field.Tag.Get("example"); example != "" && prop.Ref == "" {
+			prop.Example = exampleValue(field.Type, example)
+		}
+		s.Properties[name] = prop
+		if required && !omitEmpty {
+			s.Required = append(s.Required, name)
+		}
+	}
+	return s
+}
+
+// applyRules narrows a schema by the rules of a validate tag, reporting
+// whether the field is required. Rules after dive apply to the items.
+func (b *schemaBuilder) applyRules(s *Schema, t reflect.Type, tag string) bool {
+	required := false
+	rules := strings.Split(tag, ",")
+	for i, rule := range rules {
+		name, param, _ := strings.Cut(rule, "=")
+		if s.Ref != "" && name != "required" {
+			// A referenced component keeps its own schema
+			continue
+		}
+		switch name {
+		case "required":
+			required = true
+		case "dive":
+			if s.Items != nil {
+				for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+					t = t.Elem()
+				}
+				b.applyRules(s.Items, t, strings.Join(rules[i+1:], ","))
+			}
+			return required
+		case "oneof":
+			s.Enum = nil
+			for _, value := range strings.Fields(param) {
+				s.Enum = append(s.Enum, enumValue(s.Type, value))
+			}
+		case "gt", "gte":
+			if n, err := strconv.ParseFloat(param, 64); err == nil {
+				s.Minimum = &n
+				s.ExclusiveMinimum = name == "gt"
+			}
+		case "lte":
+			if n, err := strconv.ParseFloat(param, 64); err == nil {
+				s.Maximum = &n
+			}
+		case "min", "max", "len":
+			n, err := strconv.Atoi(param)
+			if err != nil {
+				continue
+			}
+			switch s.Type {
+			case "string":
+				if name != "max" {
+					s.MinLength = &n
+				}
+				if name != "min" {
+					s.MaxLength = &n
+				}
+			case "array":
+				if name != "max" {
+					s.MinItems = &n
+				}
+				if name != "min" {
+					s.MaxItems = &n
+				}
+			case "integer", "number":
+				f := float64(n)
+				if name != "max" {
+					s.Minimum = &f
+				}
+				if name != "min" {
+					s.Maximum = &f
+				}
+			}
+		case "email":
+			s.Format = "email"
+		default:
+			if pattern, ok := rulePatterns[name]; ok {
+				s.Pattern = pattern
+			}
+		}
+	}
+	return required
+}
+
+// queryParameters describes the form fields of a struct bound from the query string
+func (b *schemaBuilder) queryParameters(t reflect.Type) []Parameter {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	var params []Parameter
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.SplitN(field.Tag.Get("form"), ",", 2)[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		schema := b.schema(field.Type)
+		required := b.applyRules(schema, field.Type, field.Tag.Get("validate"))
+		params = append(params, Parameter{Name: name, In: "query", Required: required, Schema: schema})
+	}
+	return params
+}
+
+// jsonName is the name a field is marshalled under and whether it is omitted when empty
+func jsonName(field reflect.StructField) (string, bool, bool) {
+	if !field.IsExported() && !field.Anonymous {
+		return "", false, false
+	}
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false, false
+	}
+	name, options, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = field.Name
+	}
+	return name, strings.Contains(options, "omitempty"), true
+}
+
+func primitive(kind reflect.Kind) *Schema {
+	switch kind {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	}
+	return &Schema{}
+}
+
+// enumValue converts a oneof value to the type of the schema
+func enumValue(schemaType string, value string) interface{} {
+	switch schemaType {
+	case "integer", "number":
+		if n, err := strconv.ParseFloat(value, 64); err == nil {
+			return n
+		}
+	}
+	return value
+}
+
+// exampleValue parses the example tag of a field to the field's type
+func exampleValue(t reflect.Type, example string) interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch primitive(t.Kind()).Type {
+	case "integer", "number":
+		if n, err := strconv.ParseFloat(example, 64); err == nil {
+			return n
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(example); err == nil {
+			return b
+		}
+	}
+	return example
+}
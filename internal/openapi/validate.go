@@ -0,0 +1,304 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"logistics-marketplace/internal/problem"
+	"logistics-marketplace/internal/requestid"
+)
+
+// Middleware checks JSON request bodies and query parameters against the
+// document, answering 400 with every mismatch, and logs responses that do
+// not match their schema. It is meant for development and staging, where
+// it catches drift between the handlers and the document.
+func (s *Spec) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		op := s.operation(c.Request.Method, c.FullPath())
+		if op == nil {
+			c.Next()
+			return
+		}
+
+		if errs := s.validateRequest(c, op); len(errs) > 0 {
+			p := problem.New(http.StatusBadRequest, problem.CodeValidationFailed, "the request does not match the API specification")
+			p.Errors = errs
+			problem.Respond(c, p)
+			c.Abort()
+			return
+		}
+
+		writer := &capturingWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+
+		if errs := s.validateResponse(op, writer.Status(), writer.Header().Get("Content-Type"), writer.body.Bytes()); len(errs) > 0 {
+			for _, fieldErr := range errs {
+				slog.WarnContext(c.Request.Context(), "response does not match the API specification",
+					"request_id", requestid.FromContext(c),
+					"operation", op.OperationID,
+					"field", fieldErr.Field,
+					"error", fieldErr.Message)
+			}
+		}
+	}
+}
+
+// capturingWriter keeps a copy of the response body
+type capturingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *capturingWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *capturingWriter) WriteString(data string) (int, error) {
+	w.body.WriteString(data)
+	return w.ResponseWriter.WriteString(data)
+}
+
+func (s *Spec) validateRequest(c *gin.Context, op *Operation) []problem.FieldError {
+	var errs []problem.FieldError
+
+	query := c.Request.URL.Query()
+	for _, param := range op.Parameters {
+		if param.In != "query" {
+			continue
+		}
+		value, present := query[param.Name]
+		if !present {
+			if param.Required {
+				errs = append(errs, problem.FieldError{Field: param.Name, Code: "required", Message: "is required"})
+			}
+			continue
+		}
+		errs = append(errs, s.validate(param.Schema, parseQueryValue(param.Schema, value[0]), param.Name)...)
+	}
+
+	if op.RequestBody == nil || c.Request.Body == nil {
+		return errs
+	}
+	mediaType, _, _ := mime.ParseMediaType(c.ContentType())
+	content, ok := op.RequestBody.Content[mediaType]
+	if !ok {
+		return errs
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return append(errs, problem.FieldError{Code: "body", Message: "could not be read"})
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	value, err := decode(body)
+	if err != nil {
+		return append(errs, problem.FieldError{Code: "json", Message: "is not valid JSON"})
+	}
+	return append(errs, s.validate(content.Schema, value, "")...)
+}
+
+func (s *Spec) validateResponse(op *Operation, status int, contentType string, body []byte) []problem.FieldError {
+	response, ok := op.Responses[strconv.Itoa(status)]
+	if !ok {
+		response = op.Responses["default"]
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if response == nil || len(body) == 0 {
+		return nil
+	}
+	content, ok := response.Content[mediaType]
+	if !ok {
+		return nil
+	}
+
+	value, err := decode(body)
+	if err != nil {
+		return []problem.FieldError{{Code: "json", Message: "is not valid JSON"}}
+	}
+	return s.validate(content.Schema, value, "")
+}
+
+// decode parses JSON keeping numbers exact
+func decode(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// parseQueryValue reads a query parameter as the JSON value of its schema
+func parseQueryValue(schema *Schema, value string) interface{} {
+	switch schema.Type {
+	case "integer", "number":
+		return json.Number(value)
+	case "boolean":
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return value
+}
+
+// validate checks a decoded JSON value against a schema, naming invalid
+// fields by their path such as cargo[0].weight
+func (s *Spec) validate(schema *Schema, value interface{}, path string) []problem.FieldError {
+	if schema == nil {
+		return nil
+	}
+	if schema.Ref != "" {
+		return s.validate(s.doc.Components.Schemas[strings.TrimPrefix(schema.Ref, refPrefix)], value, path)
+	}
+	if value == nil {
+		// Go decodes null into the zero value of any field
+		return nil
+	}
+
+	fail := func(code string, message string) []problem.FieldError {
+		return []problem.FieldError{{Field: path, Code: code, Message: message}}
+	}
+
+	// An empty enum is left to the required rule, as Go marshals unset enums as ""
+	if len(schema.Enum) > 0 && value != "" && !inEnum(schema.Enum, value) {
+		values := make([]string, len(schema.Enum))
+		for i, v := range schema.Enum {
+			values[i] = fmt.Sprint(v)
+		}
+		return fail("oneof", "must be one of "+strings.Join(values, ", "))
+	}
+
+	switch schema.Type {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return fail("type", "must be an object")
+		}
+		var errs []problem.FieldError
+		for _, name := range schema.Required {
+			if _, present := object[name]; !present {
+				errs = append(errs, problem.FieldError{Field: join(path, name), Code: "required", Message: "is required"})
+			}
+		}
+		for name, property := range object {
+			if propSchema, ok := schema.Properties[name]; ok {
+				errs = append(errs, s.validate(propSchema, property, join(path, name))...)
+			} else if schema.AdditionalProperties != nil {
+				errs = append(errs, s.validate(schema.AdditionalProperties, property, join(path, name))...)
+			}
+		}
+		return errs
+
+	case "array":
+		array, ok := value.([]interface{})
+		if !ok {
+			return fail("type", "must be an array")
+		}
+		if schema.MinItems != nil && len(array) < *schema.MinItems {
+			return fail("min", fmt.Sprintf("must contain at least %d items", *schema.MinItems))
+		}
+		if schema.MaxItems != nil && len(array) > *schema.MaxItems {
+			return fail("max", fmt.Sprintf("must contain at most %d items", *schema.MaxItems))
+		}
+		var errs []problem.FieldError
+		for i, item := range array {
+			errs = append(errs, s.validate(schema.Items, item, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+		return errs
+
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			return fail("type", "must be a string")
+		}
+		if schema.MinLength != nil && len(str) < *schema.MinLength {
+			return fail("min", fmt.Sprintf("must be at least %d characters long", *schema.MinLength))
+		}
+		if schema.MaxLength != nil && len(str) > *schema.MaxLength {
+			return fail("max", fmt.Sprintf("must be at most %d characters long", *schema.MaxLength))
+		}
+		if schema.Pattern != "" && !compile(schema.Pattern).MatchString(str) {
+			return fail("pattern", "must match "+schema.Pattern)
+		}
+		if schema.Format == "date-time" {
+			if _, err := time.Parse(time.RFC3339, str); err != nil {
+				return fail("format", "must be an RFC 3339 date-time")
+			}
+		}
+
+	case "integer", "number":
+		number, ok := value.(json.Number)
+		if !ok {
+			return fail("type", "must be a "+schema.Type)
+		}
+		if schema.Type == "integer" {
+			if _, err := number.Int64(); err != nil {
+				return fail("type", "must be an integer")
+			}
+		}
+		n, err := number.Float64()
+		if err != nil {
+			return fail("type", "must be a number")
+		}
+		if schema.Minimum != nil {
+			if schema.ExclusiveMinimum && n <= *schema.Minimum {
+				return fail("gt", fmt.Sprintf("must be greater than %v", *schema.Minimum))
+			}
+			if !schema.ExclusiveMinimum && n < *schema.Minimum {
+				return fail("gte", fmt.Sprintf("must be at least %v", *schema.Minimum))
+			}
+		}
+		if schema.Maximum != nil && n > *schema.Maximum {
+			return fail("lte", fmt.Sprintf("must be at most %v", *schema.Maximum))
+		}
+
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fail("type", "must be a boolean")
+		}
+	}
+	return nil
+}
+
+// patterns caches compiled schema patterns
+var patterns sync.Map
+
+func compile(pattern string) *regexp.Regexp {
+	if re, ok := patterns.Load(pattern); ok {
+		return re.(*regexp.Regexp)
+	}
+	re := regexp.MustCompile(pattern)
+	patterns.Store(pattern, re)
+	return re
+}
+
+func inEnum(enum []interface{}, value interface{}) bool {
+	for _, v := range enum {
+		if fmt.Sprint(v) == fmt.Sprint(value) {
+			return true
+		}
+	}
+	return false
+}
+
+func join(path string, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
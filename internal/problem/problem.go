@@ -0,0 +1,209 @@
+// Package problem writes error responses as RFC 7807 problem details with a
+// stable machine-readable code and the ID of the request.
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.opentelemetry.io/otel/trace"
+	"logistics-marketplace/internal/requestid"
+	"logistics-marketplace/internal/validation"
+)
+
+// ContentType is the media type of problem responses
+const ContentType = "application/problem+json"
+
+// typePrefix starts the type URI of every problem, followed by its code
+const typePrefix = "urn:logistics-marketplace:problem:"
+
+// Stable error codes clients can branch on
+const (
+	CodeBadRequest         = "BAD_REQUEST"
+	CodeValidationFailed   = "VALIDATION_FAILED"
+	CodeUnauthorized       = "UNAUTHORIZED"
+	CodeForbidden          = "FORBIDDEN"
+	CodeNotFound           = "NOT_FOUND"
+	CodeConflict           = "CONFLICT"
+	CodeUnprocessable      = "UNPROCESSABLE_ENTITY"
+	CodeRateLimited        = "RATE_LIMITED"
+	CodeMembershipRequired = "MEMBERSHIP_REQUIRED"
+	CodeLicenseInvalid     = "LICENSE_INVALID"
+	CodeCountryRestricted  = "COUNTRY_RESTRICTED"
+	CodeInternal           = "INTERNAL_ERROR"
+)
+
+// statusCodes is the code of a problem that only has a status
+var statusCodes = map[int]string{
+	http.StatusBadRequest:          CodeBadRequest,
+	http.StatusUnauthorized:        CodeUnauthorized,
+	http.StatusForbidden:           CodeForbidden,
+	http.StatusNotFound:            CodeNotFound,
+	http.StatusConflict:            CodeConflict,
+	http.StatusUnprocessableEntity: CodeUnprocessable,
+	http.StatusTooManyRequests:     CodeRateLimited,
+	http.StatusInternalServerError: CodeInternal,
+}
+
+// titles summarize the codes that say more than their status
+var titles = map[string]string{
+	CodeValidationFailed:   "Request validation failed",
+	CodeRateLimited:        "Rate limit exceeded",
+	CodeMembershipRequired: "Active membership required",
+	CodeLicenseInvalid:     "License verification failed",
+	CodeCountryRestricted:  "Country not served",
+}
+
+// FieldError describes one invalid field of a request
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Problem is an RFC 7807 problem details body
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      string       `json:"code"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+// StatusError is implemented by errors that know the HTTP status they answer,
+// such as access denials; their message is shown to the client
+type StatusError interface {
+	error
+	StatusCode() int
+}
+
+// New creates a problem with a status, code and detail
+func New(status int, code string, detail string) *Problem {
+	return &Problem{Status: status, Code: code, Detail: detail}
+}
+
+// Respond writes a problem, filling in its type, title, instance and request ID
+func Respond(c *gin.Context, p *Problem) {
+	if p.Code == "" {
+		p.Code = statusCodes[p.Status]
+		if p.Code == "" {
+			p.Code = strings.ToUpper(strings.ReplaceAll(http.StatusText(p.Status), " ", "_"))
+		}
+	}
+	if p.Title == "" {
+		p.Title = titles[p.Code]
+		if p.Title == "" {
+			p.Title = http.StatusText(p.Status)
+		}
+	}
+	p.Type = typePrefix + strings.ToLower(strings.ReplaceAll(p.Code, "_", "-"))
+	p.Instance = c.Request.URL.Path
+	p.RequestID = requestid.FromContext(c)
+
+	c.Header("Content-Type", ContentType)
+	c.JSON(p.Status, p)
+}
+
+// Write responds with a problem of the given status
+func Write(c *gin.Context, status int, detail string) {
+	Respond(c, New(status, "", detail))
+}
+
+// Abort responds with a problem of the given status and stops the handler chain
+func Abort(c *gin.Context, status int, detail string) {
+	Write(c, status, detail)
+	c.Abort()
+}
+
+// Error responds with the problem of an error that knows its status.
+// Unexpected errors answer 500 without their detail, which is logged with
+// the request ID and recorded on the request's span.
+func Error(c *gin.Context, err error) {
+	if p := fromStatusError(err); p != nil {
+		Respond(c, p)
+		return
+	}
+
+	ctx := c.Request.Context()
+	trace.SpanFromContext(ctx).RecordError(err)
+	slog.ErrorContext(ctx, "request failed",
+		"request_id", requestid.FromContext(c),
+		"method", c.Request.Method,
+		"route", c.FullPath(),
+		"error", err)
+	Write(c, http.StatusInternalServerError, "an unexpected error occurred; quote the request ID to support")
+}
+
+// BadRequest responds to a request that could not be bound or was rejected,
+// listing every invalid field when the request failed validation
+func BadRequest(c *gin.Context, err error) {
+	if p := fromStatusError(err); p != nil {
+		Respond(c, p)
+		return
+	}
+	Respond(c, FromBindingError(err))
+}
+
+// FromBindingError describes a request body that failed to bind or validate
+func FromBindingError(err error) *Problem {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		p := New(http.StatusBadRequest, CodeValidationFailed, "one or more fields are invalid")
+		for _, fieldErr := range validationErrs {
+			p.Errors = append(p.Errors, FieldError{
+				Field:   fieldPath(fieldErr),
+				Code:    fieldErr.Tag(),
+				Message: validation.Message(fieldErr),
+			})
+		}
+		return p
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		p := New(http.StatusBadRequest, CodeValidationFailed, "one or more fields are invalid")
+		p.Errors = []FieldError{{
+			Field:   typeErr.Field,
+			Code:    "type",
+			Message: "must be a " + typeErr.Type.String(),
+		}}
+		return p
+	}
+
+	return New(http.StatusBadRequest, CodeBadRequest, err.Error())
+}
+
+// fieldPath is the path of an invalid field below the validated struct,
+// such as cargo[0].weight
+func fieldPath(fieldErr validator.FieldError) string {
+	namespace := fieldErr.Namespace()
+	if i := strings.IndexAny(namespace, ".["); i >= 0 {
+		return strings.TrimPrefix(namespace[i:], ".")
+	}
+	return namespace
+}
+
+// fromStatusError describes errors that know their status, and validation
+// errors returned outside of binding
+func fromStatusError(err error) *Problem {
+	var (
+		statusErr      StatusError
+		validationErrs validator.ValidationErrors
+	)
+
+	switch {
+	case errors.As(err, &statusErr):
+		return New(statusErr.StatusCode(), "", statusErr.Error())
+	case errors.As(err, &validationErrs):
+		return FromBindingError(err)
+	}
+	return nil
+}
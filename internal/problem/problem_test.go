@@ -0,0 +1,103 @@
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"logistics-marketplace/internal/requestid"
+	"logistics-marketplace/internal/validation"
+)
+
+type deniedError struct{}
+
+func (deniedError) Error() string   { return "only the provider may update" }
+func (deniedError) StatusCode() int { return http.StatusForbidden }
+
+func serve(t *testing.T, handler gin.HandlerFunc, body string) (*httptest.ResponseRecorder, Problem) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(requestid.Middleware())
+	router.POST("/bookings", handler)
+
+	req := httptest.NewRequest(http.MethodPost, "/bookings", strings.NewReader(body))
+	req.Header.Set(requestid.Header, "req-1")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var p Problem
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &p))
+	return w, p
+}
+
+func TestWriteDescribesStatus(t *testing.T) {
+	w, p := serve(t, func(c *gin.Context) {
+		Write(c, http.StatusNotFound, "booking B1 not found")
+	}, "")
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, ContentType, w.Header().Get("Content-Type"))
+	assert.Equal(t, Problem{
+		Type:      "urn:logistics-marketplace:problem:not-found",
+		Title:     "Not Found",
+		Status:    http.StatusNotFound,
+		Detail:    "booking B1 not found",
+		Instance:  "/bookings",
+		Code:      CodeNotFound,
+		RequestID: "req-1",
+	}, p)
+}
+
+func TestErrorMapsStatusErrorsAndHidesUnexpectedOnes(t *testing.T) {
+	w, p := serve(t, func(c *gin.Context) {
+		Error(c, fmt.Errorf("failed to update shipment: %w", deniedError{}))
+	}, "")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, CodeForbidden, p.Code)
+	assert.Contains(t, p.Detail, "only the provider")
+
+	w, p = serve(t, func(c *gin.Context) {
+		Error(c, errors.New("connection refused by horizon"))
+	}, "")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, CodeInternal, p.Code)
+	assert.NotContains(t, p.Detail, "horizon")
+	assert.Equal(t, "req-1", p.RequestID)
+}
+
+func TestBadRequestListsFieldErrors(t *testing.T) {
+	binding.Validator = validation.Binding()
+	handler := func(c *gin.Context) {
+		var req struct {
+			ListingID string  `json:"listing_id" validate:"required"`
+			Weight    float64 `json:"weight" validate:"gt=0"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			BadRequest(c, err)
+			return
+		}
+		c.Status(http.StatusCreated)
+	}
+
+	w, p := serve(t, handler, `{"weight": -1}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, CodeValidationFailed, p.Code)
+	require.Len(t, p.Errors, 2)
+	assert.Equal(t, FieldError{Field: "listing_id", Code: "required", Message: "is required"}, p.Errors[0])
+	assert.Equal(t, FieldError{Field: "weight", Code: "gt", Message: "must be greater than 0"}, p.Errors[1])
+
+	_, p = serve(t, handler, `{"weight": "heavy"}`)
+	assert.Equal(t, CodeValidationFailed, p.Code)
+	assert.Equal(t, "type", p.Errors[0].Code)
+
+	_, p = serve(t, handler, `not json`)
+	assert.Equal(t, CodeBadRequest, p.Code)
+}
@@ -0,0 +1,210 @@
+// Package ratelimit limits how many requests each caller may make to each
+// group of routes.
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"logistics-marketplace/internal/auth"
+	"logistics-marketplace/internal/problem"
+)
+
+// Budget is the number of requests allowed per window
+type Budget struct {
+	Requests int
+	Window   time.Duration
+}
+
+// PerMinute is a budget of n requests a minute
+func PerMinute(n int) Budget {
+	return Budget{Requests: n, Window: time.Minute}
+}
+
+// writeMethods are the HTTP methods that change state
+var writeMethods = []string{http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+
+// group is a set of routes sharing a budget
+type group struct {
+	name     string
+	prefixes []string
+	methods  []string // Empty applies to every method
+	budget   Budget
+}
+
+// match returns the length of the longest prefix of the group matching the
+// route, or -1
+func (g *group) match(method string, route string) int {
+	if len(g.methods) > 0 {
+		found := false
+		for _, m := range g.methods {
+			if m == method {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return -1
+		}
+	}
+
+	longest := -1
+	for _, prefix := range g.prefixes {
+		if route == prefix || strings.HasPrefix(route, strings.TrimSuffix(prefix, "/")+"/") {
+			if len(prefix) > longest {
+				longest = len(prefix)
+			}
+		}
+	}
+	return longest
+}
+
+// window counts the requests of one caller to one group
+type window struct {
+	count   int
+	resetAt time.Time
+}
+
+// Result is the outcome of taking a request from a budget
+type Result struct {
+	Group     string
+	Allowed   bool
+	Limit     int
+	Remaining int
+	ResetAt   time.Time
+}
+
+// Limiter keeps a fixed-window request budget per caller and route group.
+// Callers are told apart by API key, user or IP address, routes fall in the
+// group with the longest matching prefix, and budgets are scaled by the
+// caller's membership tier.
+type Limiter struct {
+	fallback Budget
+	groups   []*group
+	tiers    map[string]float64
+	now      func() time.Time
+
+	mu        sync.Mutex
+	windows   map[string]*window
+	nextPrune time.Time
+}
+
+// NewLimiter creates a limiter giving routes outside every group the fallback budget
+func NewLimiter(fallback Budget) *Limiter {
+	return &Limiter{
+		fallback: fallback,
+		tiers:    make(map[string]float64),
+		now:      time.Now,
+		windows:  make(map[string]*window),
+	}
+}
+
+// Group gives every method of the routes under the prefixes their own budget
+func (l *Limiter) Group(name string, budget Budget, prefixes ...string) *Limiter {
+	l.groups = append(l.groups, &group{name: name, prefixes: prefixes, budget: budget})
+	return l
+}
+
+// GroupWrite gives the state-changing methods of the routes under the prefixes their own budget
+func (l *Limiter) GroupWrite(name string, budget Budget, prefixes ...string) *Limiter {
+	l.groups = append(l.groups, &group{name: name, prefixes: prefixes, methods: writeMethods, budget: budget})
+	return l
+}
+
+// Tier multiplies every budget for callers of a membership tier
+func (l *Limiter) Tier(tier string, multiplier float64) *Limiter {
+	l.tiers[tier] = multiplier
+	return l
+}
+
+// Take takes one request of the caller from the budget of the route's group
+func (l *Limiter) Take(caller string, tier string, method string, route string) Result {
+	name, budget := "default", l.fallback
+	longest := -1
+	for _, g := range l.groups {
+		if n := g.match(method, route); n > longest {
+			name, budget = g.name, g.budget
+			longest = n
+		}
+	}
+
+	limit := budget.Requests
+	if multiplier, ok := l.tiers[tier]; ok {
+		limit = int(math.Ceil(float64(limit) * multiplier))
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.prune(now)
+
+	key := name + "|" + caller
+	w, ok := l.windows[key]
+	if !ok || !now.Before(w.resetAt) {
+		w = &window{resetAt: now.Add(budget.Window)}
+		l.windows[key] = w
+	}
+
+	result := Result{Group: name, Limit: limit, ResetAt: w.resetAt}
+	if w.count < limit {
+		w.count++
+		result.Allowed = true
+	}
+	result.Remaining = limit - w.count
+	return result
+}
+
+// prune forgets expired windows, at most once a minute
+func (l *Limiter) prune(now time.Time) {
+	if now.Before(l.nextPrune) {
+		return
+	}
+	for key, w := range l.windows {
+		if !now.Before(w.resetAt) {
+			delete(l.windows, key)
+		}
+	}
+	l.nextPrune = now.Add(time.Minute)
+}
+
+// Caller identifies who a request counts against: its API key, its user or
+// its IP address when unauthenticated
+func Caller(c *gin.Context) (string, string) {
+	id, ok := auth.FromContext(c)
+	if !ok {
+		return "ip:" + c.ClientIP(), ""
+	}
+	if id.APIKeyID != "" {
+		return "key:" + id.APIKeyID, id.Tier
+	}
+	return "user:" + id.Address, id.Tier
+}
+
+// Middleware enforces the budgets, reporting them in X-RateLimit-* headers
+// and answering 429 with Retry-After once a budget is spent
+func (l *Limiter) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		caller, tier := Caller(c)
+		result := l.Take(caller, tier, c.Request.Method, c.FullPath())
+
+		c.Header("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("X-RateLimit-Reset", strconv.FormatInt(result.ResetAt.Unix(), 10))
+
+		if !result.Allowed {
+			retryAfter := int(math.Ceil(result.ResetAt.Sub(l.now()).Seconds()))
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			problem.Abort(c, http.StatusTooManyRequests, fmt.Sprintf("rate limit exceeded for %s requests", result.Group))
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,78 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"logistics-marketplace/internal/auth"
+)
+
+func testLimiter() *Limiter {
+	return NewLimiter(PerMinute(3)).
+		Group("quotes", PerMinute(2), "/api/v1/users/shipper/quotes").
+		GroupWrite("tracking-writes", PerMinute(1), "/api/v1/tracking").
+		Tier("ENTERPRISE", 2)
+}
+
+func TestLimiterBudgetsPerCallerAndGroup(t *testing.T) {
+	limiter := testLimiter()
+
+	assert.True(t, limiter.Take("user:a", "", http.MethodPost, "/api/v1/users/shipper/quotes/request").Allowed)
+	assert.True(t, limiter.Take("user:a", "", http.MethodGet, "/api/v1/users/shipper/quotes/requests").Allowed)
+	result := limiter.Take("user:a", "", http.MethodPost, "/api/v1/users/shipper/quotes/request")
+	assert.False(t, result.Allowed)
+	assert.Equal(t, "quotes", result.Group)
+	assert.Equal(t, 0, result.Remaining)
+
+	// Other callers and other groups have their own budget
+	assert.True(t, limiter.Take("user:b", "", http.MethodPost, "/api/v1/users/shipper/quotes/request").Allowed)
+	assert.True(t, limiter.Take("user:a", "", http.MethodPost, "/api/v1/tracking/events").Allowed)
+	assert.False(t, limiter.Take("user:a", "", http.MethodPost, "/api/v1/tracking/events").Allowed)
+
+	// Tracking reads fall back to the default budget
+	result = limiter.Take("user:a", "", http.MethodGet, "/api/v1/tracking/:booking_id")
+	assert.True(t, result.Allowed)
+	assert.Equal(t, "default", result.Group)
+	assert.Equal(t, 2, result.Remaining)
+}
+
+func TestLimiterTierAndWindowReset(t *testing.T) {
+	limiter := testLimiter()
+	now := time.Now()
+	limiter.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		assert.True(t, limiter.Take("key:k1", "ENTERPRISE", http.MethodPost, "/api/v1/tracking/events").Allowed)
+	}
+	assert.False(t, limiter.Take("key:k1", "ENTERPRISE", http.MethodPost, "/api/v1/tracking/events").Allowed)
+
+	now = now.Add(time.Minute)
+	assert.True(t, limiter.Take("key:k1", "ENTERPRISE", http.MethodPost, "/api/v1/tracking/events").Allowed)
+}
+
+func TestMiddlewareHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		auth.SetIdentity(c, &auth.Identity{UserID: "u1", Address: "GADDR", Roles: []auth.Role{auth.RoleShipper}})
+	})
+	router.Use(testLimiter().Middleware())
+	router.POST("/api/v1/tracking/events", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/tracking/events", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "1", w.Header().Get("X-RateLimit-Limit"))
+	assert.Equal(t, "0", w.Header().Get("X-RateLimit-Remaining"))
+	assert.NotEmpty(t, w.Header().Get("X-RateLimit-Reset"))
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/tracking/events", nil))
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+}
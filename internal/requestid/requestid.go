@@ -0,0 +1,63 @@
+// Package requestid gives every request an ID that is echoed in responses
+// and logs, so support can find a failed request from what the client saw.
+package requestid
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Header carries the request ID in both directions
+const Header = "X-Request-ID"
+
+// contextKey is where the request ID is kept on the gin context
+const contextKey = "request_id"
+
+// maxLength bounds the IDs accepted from clients and proxies
+const maxLength = 128
+
+// Middleware keeps a well-formed X-Request-ID sent by the client or a proxy,
+// generates one otherwise, and returns it in the response
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(Header)
+		if !valid(id) {
+			id = generate()
+		}
+
+		c.Set(contextKey, id)
+		c.Header(Header, id)
+		c.Next()
+	}
+}
+
+// FromContext returns the ID of the request, or "" outside the middleware
+func FromContext(c *gin.Context) string {
+	return c.GetString(contextKey)
+}
+
+func generate() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(buf)
+}
+
+// valid accepts IDs made of letters, digits and - _ . : only
+func valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-' || r == '_' || r == '.' || r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"logistics-marketplace/internal/auth"
+	"logistics-marketplace/internal/ledger"
+	"logistics-marketplace/internal/stellar"
+	"logistics-marketplace/internal/telemetry"
+)
+
+// BookingAccessService resolves booking parties for access checks from where
+// bookings are recorded: the marketplace contract, or the records of a
+// private ledger backend
+type BookingAccessService struct {
+	marketplaceContract *stellar.Contract
+	records             ledger.RecordReader // Replaces the contract when set
+}
+
+// NewBookingAccessService creates a new BookingAccessService instance
+func NewBookingAccessService(marketplaceContract *stellar.Contract) *BookingAccessService {
+	return &BookingAccessService{
+		marketplaceContract: marketplaceContract,
+	}
+}
+
+// UseLedger resolves booking parties from the booking and listing records of a
+// ledger backend instead of the marketplace contract
+func (s *BookingAccessService) UseLedger(records ledger.RecordReader) {
+	s.records = records
+}
+
+// GetBookingParties retrieves the customer and provider of a booking
+func (s *BookingAccessService) GetBookingParties(ctx context.Context, bookingID string) (*auth.BookingParties, error) {
+	ctx, span := telemetry.Start(ctx, "BookingAccessService.GetBookingParties")
+	defer span.End()
+
+	if s.records != nil {
+		return s.getLedgerBookingParties(ctx, bookingID)
+	}
+
+	booking, err := s.marketplaceContract.GetBooking(bookingID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get booking: %w", err)
+	}
+	if booking.CustomerID == "" {
+		return nil, fmt.Errorf("%w: %s", auth.ErrBookingNotFound, bookingID)
+	}
+
+	return &auth.BookingParties{
+		BookingID:  bookingID,
+		CustomerID: booking.CustomerID,
+		ProviderID: booking.ProviderID,
+	}, nil
+}
+
+// getLedgerBookingParties takes the customer from the booking record and the
+// provider from the record of the booked listing
+func (s *BookingAccessService) getLedgerBookingParties(ctx context.Context, bookingID string) (*auth.BookingParties, error) {
+	booking, err := s.records.GetBooking(ctx, bookingID)
+	if errors.Is(err, ledger.ErrNotFound) {
+		return nil, fmt.Errorf("%w: %s", auth.ErrBookingNotFound, bookingID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get booking: %w", err)
+	}
+
+	listing, err := s.records.GetListing(ctx, booking.ServiceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get listing of booking %s: %w", bookingID, err)
+	}
+
+	return &auth.BookingParties{
+		BookingID:  bookingID,
+		CustomerID: booking.CustomerID,
+		ProviderID: listing.ProviderID,
+	}, nil
+}
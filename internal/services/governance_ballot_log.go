@@ -0,0 +1,125 @@
+package services
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"logistics-marketplace/internal/models"
+)
+
+// ErrStaleBallot is returned for a ballot signed no later than the voter's
+// recorded ballot
+var ErrStaleBallot = errors.New("a newer vote has already been recorded")
+
+// BallotStore keeps the off-chain ballots of proposals
+type BallotStore interface {
+	// Record stores a ballot once it is durable, replacing the voter's
+	// earlier ballot, or returns ErrStaleBallot
+	Record(ballot models.SignedBallot) error
+	// Ballots returns the current ballot of every voter on a proposal
+	Ballots(proposalID string) []models.SignedBallot
+}
+
+// BallotLog is a BallotStore appending ballots to a local file, so ballots
+// acknowledged to voters survive a restart
+type BallotLog struct {
+	mu      sync.RWMutex
+	file    *os.File
+	ballots map[string]map[string]models.SignedBallot // Proposal ID -> voter -> ballot
+}
+
+// OpenBallotLog opens or creates the log at path and replays its ballots
+func OpenBallotLog(path string) (*BallotLog, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create ballot log directory: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ballot log: %w", err)
+	}
+
+	l := &BallotLog{
+		file:    file,
+		ballots: make(map[string]map[string]models.SignedBallot),
+	}
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		var ballot models.SignedBallot
+		if err := json.Unmarshal(scanner.Bytes(), &ballot); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("failed to decode ballot on line %d: %w", line, err)
+		}
+		l.set(ballot)
+	}
+	if err := scanner.Err(); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to read ballot log: %w", err)
+	}
+
+	return l, nil
+}
+
+// Close closes the log
+func (l *BallotLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.file.Close()
+}
+
+// Record appends a ballot and syncs it to disk before it replaces the
+// voter's earlier ballot
+func (l *BallotLog) Record(ballot models.SignedBallot) error {
+	line, err := json.Marshal(ballot)
+	if err != nil {
+		return fmt.Errorf("failed to encode ballot: %w", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if previous, voted := l.ballots[ballot.Message.ProposalID][ballot.Message.Voter]; voted && previous.Message.Timestamp >= ballot.Message.Timestamp {
+		return fmt.Errorf("%w for %s", ErrStaleBallot, ballot.Message.Voter)
+	}
+
+	if _, err := l.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to append ballot: %w", err)
+	}
+	if err := l.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync ballot log: %w", err)
+	}
+
+	l.set(ballot)
+	return nil
+}
+
+// Ballots returns the current ballot of every voter on a proposal
+func (l *BallotLog) Ballots(proposalID string) []models.SignedBallot {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	ballots := make([]models.SignedBallot, 0, len(l.ballots[proposalID]))
+	for _, ballot := range l.ballots[proposalID] {
+		ballots = append(ballots, ballot)
+	}
+	return ballots
+}
+
+// set keeps the newest ballot of a voter
+func (l *BallotLog) set(ballot models.SignedBallot) {
+	ballots, exists := l.ballots[ballot.Message.ProposalID]
+	if !exists {
+		ballots = make(map[string]models.SignedBallot)
+		l.ballots[ballot.Message.ProposalID] = ballots
+	}
+	if previous, voted := ballots[ballot.Message.Voter]; voted && previous.Message.Timestamp >= ballot.Message.Timestamp {
+		return
+	}
+	ballots[ballot.Message.Voter] = ballot
+}
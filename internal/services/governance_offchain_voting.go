@@ -0,0 +1,269 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/stellar/go/keypair"
+
+	"logistics-marketplace/internal/models"
+	"logistics-marketplace/internal/telemetry"
+)
+
+// voteMessagePrefix domain-separates signed votes from other signed payloads
+const voteMessagePrefix = "LMT Governance Vote\n"
+
+// CastSignedVote verifies and records an off-chain vote signed with the
+// voter's Stellar key
+func (s *GovernanceService) CastSignedVote(ctx context.Context, req models.SignedVoteRequest, voterAddress string) (*models.SignedBallot, error) {
+	ctx, span := telemetry.Start(ctx, "GovernanceService.CastSignedVote")
+	defer span.End()
+
+	// Get proposal
+	proposal, err := s.GetProposal(ctx, req.ProposalID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get proposal: %w", err)
+	}
+
+	if proposal.Proposal.VotingMode != models.VotingModeOffChain {
+		return nil, fmt.Errorf("proposal does not use off-chain voting")
+	}
+
+	if proposal.Proposal.Status != models.ProposalStatusActive {
+		return nil, ErrProposalNotActive
+	}
+
+	// The signed timestamp must fall inside the voting period
+	signedAt := time.Unix(req.Timestamp, 0)
+	if signedAt.Before(proposal.Proposal.StartTime) || signedAt.After(proposal.Proposal.EndTime) {
+		return nil, fmt.Errorf("vote timestamp is outside the voting period")
+	}
+	if time.Now().After(proposal.Proposal.EndTime) {
+		return nil, fmt.Errorf("voting period has ended")
+	}
+
+	switch req.VoteType {
+	case models.VoteTypeFor, models.VoteTypeAgainst, models.VoteTypeAbstain:
+	default:
+		return nil, fmt.Errorf("invalid vote type: %s", req.VoteType)
+	}
+
+	message := models.SignedVoteMessage{
+		ProposalID: req.ProposalID,
+		Voter:      voterAddress,
+		VoteType:   req.VoteType,
+		Timestamp:  req.Timestamp,
+	}
+
+	if err := verifyVoteSignature(message, req.Signature); err != nil {
+		return nil, err
+	}
+
+	// Voting power is the voter's balance at the proposal snapshot
+	power, err := s.tokenContract.GetBalanceAt(voterAddress, proposal.Proposal.StartTime)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get token balance: %w", err)
+	}
+	if power == 0 {
+		return nil, fmt.Errorf("no voting power at proposal snapshot")
+	}
+
+	leaf, err := ballotLeafHash(message, req.Signature, power)
+	if err != nil {
+		return nil, err
+	}
+
+	ballot := models.SignedBallot{
+		Message:   message,
+		Signature: req.Signature,
+		VotePower: power,
+		LeafHash:  hex.EncodeToString(leaf),
+	}
+
+	// Only a newer signed message replaces an earlier vote
+	if err := s.ballots.Record(ballot); err != nil {
+		return nil, fmt.Errorf("failed to record ballot: %w", err)
+	}
+
+	return &ballot, nil
+}
+
+// GetBallotSet publishes the off-chain ballots of a proposal together with
+// their Merkle root and tally
+func (s *GovernanceService) GetBallotSet(ctx context.Context, proposalID string) (*models.BallotSetResponse, error) {
+	ctx, span := telemetry.Start(ctx, "GovernanceService.GetBallotSet")
+	defer span.End()
+
+	proposal, err := s.GetProposal(ctx, proposalID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get proposal: %w", err)
+	}
+
+	if proposal.Proposal.VotingMode != models.VotingModeOffChain {
+		return nil, fmt.Errorf("proposal does not use off-chain voting")
+	}
+
+	ballotSet, err := s.buildBallotSet(proposalID)
+	if err != nil {
+		return nil, err
+	}
+	ballotSet.Attested = proposal.Proposal.BallotRoot != ""
+
+	return ballotSet, nil
+}
+
+// attestOffChainResult anchors the ballot set root and final tally of a
+// closed off-chain proposal on-chain
+func (s *GovernanceService) attestOffChainResult(ctx context.Context, proposal *models.ProposalResponse) (*models.ProposalResponse, error) {
+	ballotSet, err := s.buildBallotSet(proposal.Proposal.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := s.governanceContract.AttestOffChainResult(
+		proposal.Proposal.ID,
+		ballotSet.MerkleRoot,
+		ballotSet.ForVotes,
+		ballotSet.AgainstVotes,
+		ballotSet.AbstainVotes,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to attest off-chain result on blockchain: %w", err)
+	}
+
+	proposal.Proposal.Status = models.ProposalStatus(status)
+	proposal.Proposal.BallotRoot = ballotSet.MerkleRoot
+	proposal.Proposal.ForVotes = ballotSet.ForVotes
+	proposal.Proposal.AgainstVotes = ballotSet.AgainstVotes
+	proposal.Proposal.AbstainVotes = ballotSet.AbstainVotes
+	proposal.Proposal.UpdatedAt = time.Now()
+	proposal.VoteSummary = s.summarizeVotes(&proposal.Proposal, s.tokenContract.GetTotalSupply())
+
+	return proposal, nil
+}
+
+// buildBallotSet orders the ballots of a proposal by voter and computes
+// their Merkle root and tally
+func (s *GovernanceService) buildBallotSet(proposalID string) (*models.BallotSetResponse, error) {
+	ballots := s.ballots.Ballots(proposalID)
+	sort.Slice(ballots, func(i, j int) bool {
+		return ballots[i].Message.Voter < ballots[j].Message.Voter
+	})
+
+	ballotSet := &models.BallotSetResponse{
+		ProposalID: proposalID,
+		Ballots:    ballots,
+	}
+
+	leaves := make([][]byte, 0, len(ballots))
+	for _, ballot := range ballots {
+		leaf, err := hex.DecodeString(ballot.LeafHash)
+		if err != nil {
+			return nil, fmt.Errorf("invalid leaf hash for voter %s: %w", ballot.Message.Voter, err)
+		}
+		leaves = append(leaves, leaf)
+
+		switch ballot.Message.VoteType {
+		case models.VoteTypeFor:
+			ballotSet.ForVotes += ballot.VotePower
+		case models.VoteTypeAgainst:
+			ballotSet.AgainstVotes += ballot.VotePower
+		case models.VoteTypeAbstain:
+			ballotSet.AbstainVotes += ballot.VotePower
+		}
+	}
+	ballotSet.MerkleRoot = hex.EncodeToString(merkleRoot(leaves))
+
+	return ballotSet, nil
+}
+
+// Helper functions
+
+// voteMessageBytes returns the canonical bytes a voter signs
+func voteMessageBytes(message models.SignedVoteMessage) ([]byte, error) {
+	encoded, err := json.Marshal(message)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode vote message: %w", err)
+	}
+	return append([]byte(voteMessagePrefix), encoded...), nil
+}
+
+func verifyVoteSignature(message models.SignedVoteMessage, signature string) error {
+	kp, err := keypair.ParseAddress(message.Voter)
+	if err != nil {
+		return fmt.Errorf("invalid voter address: %w", err)
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("invalid signature encoding: %w", err)
+	}
+
+	payload, err := voteMessageBytes(message)
+	if err != nil {
+		return err
+	}
+
+	if err := kp.Verify(payload, sig); err != nil {
+		return fmt.Errorf("invalid vote signature: %w", err)
+	}
+	return nil
+}
+
+// Merkle hash domain prefixes, so a leaf can never be passed off as an
+// internal node
+const (
+	merkleLeafPrefix = 0x00
+	merkleNodePrefix = 0x01
+)
+
+// ballotLeafHash hashes a ballot's signed message, signature and vote power
+func ballotLeafHash(message models.SignedVoteMessage, signature string, power uint64) ([]byte, error) {
+	payload, err := voteMessageBytes(message)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte(merkleLeafPrefix)
+	buf.Write(payload)
+	fmt.Fprintf(&buf, "\n%s\n%d", signature, power)
+
+	sum := sha256.Sum256(buf.Bytes())
+	return sum[:], nil
+}
+
+// merkleRoot builds a SHA-256 Merkle root over leaf hashes, carrying an odd
+// node up unchanged
+func merkleRoot(leaves [][]byte) []byte {
+	if len(leaves) == 0 {
+		sum := sha256.Sum256(nil)
+		return sum[:]
+	}
+
+	level := leaves
+	for len(level) > 1 {
+		next := make([][]byte, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			if i+1 == len(level) {
+				next = append(next, level[i])
+				continue
+			}
+			h := sha256.New()
+			h.Write([]byte{merkleNodePrefix})
+			h.Write(level[i])
+			h.Write(level[i+1])
+			next = append(next, h.Sum(nil))
+		}
+		level = next
+	}
+
+	return level[0]
+}
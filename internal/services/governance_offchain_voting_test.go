@@ -0,0 +1,219 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stellar/go/keypair"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"logistics-marketplace/internal/models"
+)
+
+func signVote(t *testing.T, kp *keypair.Full, message models.SignedVoteMessage) string {
+	payload, err := voteMessageBytes(message)
+	require.NoError(t, err)
+	sig, err := kp.Sign(payload)
+	require.NoError(t, err)
+	return base64.StdEncoding.EncodeToString(sig)
+}
+
+func openProposal(id string) models.GovernanceProposal {
+	proposal := models.GovernanceProposal{
+		Status:     models.ProposalStatusActive,
+		StartTime:  time.Now().Add(-time.Hour),
+		EndTime:    time.Now().Add(time.Hour),
+		VotingMode: models.VotingModeOffChain,
+	}
+	proposal.ID = id
+	return proposal
+}
+
+func TestVerifyVoteSignature(t *testing.T) {
+	voter := keypair.MustRandom()
+	other := keypair.MustRandom()
+	message := models.SignedVoteMessage{
+		ProposalID: "P1",
+		Voter:      voter.Address(),
+		VoteType:   models.VoteTypeFor,
+		Timestamp:  time.Now().Unix(),
+	}
+	tampered := message
+	tampered.VoteType = models.VoteTypeAgainst
+	wrongVoter := message
+	wrongVoter.Voter = "not-an-address"
+
+	tests := []struct {
+		name      string
+		message   models.SignedVoteMessage
+		signature string
+		wantErr   string
+	}{
+		{name: "valid", message: message, signature: signVote(t, voter, message)},
+		{name: "signed by another key", message: message, signature: signVote(t, other, message), wantErr: "invalid vote signature"},
+		{name: "tampered vote", message: tampered, signature: signVote(t, voter, message), wantErr: "invalid vote signature"},
+		{name: "bad encoding", message: message, signature: "%%%", wantErr: "invalid signature encoding"},
+		{name: "bad voter address", message: wrongVoter, signature: signVote(t, voter, message), wantErr: "invalid voter address"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verifyVoteSignature(tt.message, tt.signature)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestCastSignedVoteReplacesOnlyWithNewerBallot(t *testing.T) {
+	voter := keypair.MustRandom()
+	path := filepath.Join(t.TempDir(), "ballots.jsonl")
+	ballots, err := OpenBallotLog(path)
+	require.NoError(t, err)
+	contract := newFakeGovernanceContract(1000, openProposal("P1"))
+	token := &fakeGovernanceToken{balances: map[string]uint64{voter.Address(): 300}, totalSupply: 1000}
+	s := NewGovernanceService(nil, contract, token, ballots)
+
+	cast := func(voteType models.VoteType, timestamp int64) error {
+		message := models.SignedVoteMessage{ProposalID: "P1", Voter: voter.Address(), VoteType: voteType, Timestamp: timestamp}
+		_, err := s.CastSignedVote(context.Background(), models.SignedVoteRequest{
+			ProposalID: "P1",
+			VoteType:   voteType,
+			Timestamp:  timestamp,
+			Signature:  signVote(t, voter, message),
+		}, voter.Address())
+		return err
+	}
+
+	now := time.Now().Unix()
+	require.NoError(t, cast(models.VoteTypeFor, now-60))
+	require.NoError(t, cast(models.VoteTypeAgainst, now-30))
+	assert.ErrorIs(t, cast(models.VoteTypeFor, now-30), ErrStaleBallot)
+	assert.ErrorIs(t, cast(models.VoteTypeFor, now-45), ErrStaleBallot)
+
+	ballotSet, err := s.GetBallotSet(context.Background(), "P1")
+	require.NoError(t, err)
+	require.Len(t, ballotSet.Ballots, 1)
+	assert.Equal(t, models.VoteTypeAgainst, ballotSet.Ballots[0].Message.VoteType)
+	assert.Equal(t, uint64(300), ballotSet.AgainstVotes)
+	assert.Zero(t, ballotSet.ForVotes)
+
+	// Acknowledged ballots survive a restart
+	require.NoError(t, ballots.Close())
+	reopened, err := OpenBallotLog(path)
+	require.NoError(t, err)
+	defer reopened.Close()
+	assert.Equal(t, ballotSet.Ballots, reopened.Ballots("P1"))
+}
+
+func TestCastSignedVoteRejectsBadSignature(t *testing.T) {
+	voter := keypair.MustRandom()
+	s := newTestGovernanceService(t, newFakeGovernanceContract(1000, openProposal("P1")))
+	s.tokenContract = &fakeGovernanceToken{balances: map[string]uint64{voter.Address(): 300}, totalSupply: 1000}
+
+	timestamp := time.Now().Unix()
+	signed := models.SignedVoteMessage{ProposalID: "P1", Voter: voter.Address(), VoteType: models.VoteTypeFor, Timestamp: timestamp}
+	_, err := s.CastSignedVote(context.Background(), models.SignedVoteRequest{
+		ProposalID: "P1",
+		VoteType:   models.VoteTypeAgainst,
+		Timestamp:  timestamp,
+		Signature:  signVote(t, voter, signed),
+	}, voter.Address())
+	assert.ErrorContains(t, err, "invalid vote signature")
+	assert.Empty(t, s.ballots.Ballots("P1"))
+}
+
+func TestMerkleRoot(t *testing.T) {
+	leaf := func(b byte) []byte {
+		sum := sha256.Sum256([]byte{merkleLeafPrefix, b})
+		return sum[:]
+	}
+	node := func(left, right []byte) []byte {
+		h := sha256.New()
+		h.Write([]byte{merkleNodePrefix})
+		h.Write(left)
+		h.Write(right)
+		return h.Sum(nil)
+	}
+	empty := sha256.Sum256(nil)
+	a, b, c := leaf('a'), leaf('b'), leaf('c')
+
+	tests := []struct {
+		name   string
+		leaves [][]byte
+		root   []byte
+	}{
+		{name: "no ballots", leaves: nil, root: empty[:]},
+		{name: "single leaf", leaves: [][]byte{a}, root: a},
+		{name: "pair", leaves: [][]byte{a, b}, root: node(a, b)},
+		{name: "odd leaf carried up", leaves: [][]byte{a, b, c}, root: node(node(a, b), c)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, hex.EncodeToString(tt.root), hex.EncodeToString(merkleRoot(tt.leaves)))
+		})
+	}
+
+	// Without the node prefix a pair would hash like a leaf of their concatenation
+	unprefixed := sha256.Sum256(append(append([]byte{}, a...), b...))
+	assert.NotEqual(t, unprefixed[:], merkleRoot([][]byte{a, b}))
+}
+
+func TestBallotLeafHashIsDomainSeparated(t *testing.T) {
+	message := models.SignedVoteMessage{ProposalID: "P1", Voter: "GVOTER", VoteType: models.VoteTypeFor, Timestamp: 1}
+	leaf, err := ballotLeafHash(message, "sig", 10)
+	require.NoError(t, err)
+
+	payload, err := voteMessageBytes(message)
+	require.NoError(t, err)
+	unprefixed := sha256.Sum256(append(payload, []byte("\nsig\n10")...))
+	prefixed := sha256.Sum256(append([]byte{merkleLeafPrefix}, append(payload, []byte("\nsig\n10")...)...))
+
+	assert.NotEqual(t, unprefixed[:], leaf)
+	assert.Equal(t, prefixed[:], leaf)
+}
+
+func TestFinalizeOffChainProposalAttestsBallotRoot(t *testing.T) {
+	voters := []*keypair.Full{keypair.MustRandom(), keypair.MustRandom()}
+	contract := newFakeGovernanceContract(1000, openProposal("P1"))
+	s := newTestGovernanceService(t, contract)
+	s.tokenContract = &fakeGovernanceToken{
+		balances:    map[string]uint64{voters[0].Address(): 150, voters[1].Address(): 50},
+		totalSupply: 1000,
+	}
+
+	for i, voter := range voters {
+		voteType := []models.VoteType{models.VoteTypeFor, models.VoteTypeAgainst}[i]
+		message := models.SignedVoteMessage{ProposalID: "P1", Voter: voter.Address(), VoteType: voteType, Timestamp: time.Now().Unix()}
+		_, err := s.CastSignedVote(context.Background(), models.SignedVoteRequest{
+			ProposalID: "P1",
+			VoteType:   voteType,
+			Timestamp:  message.Timestamp,
+			Signature:  signVote(t, voter, message),
+		}, voter.Address())
+		require.NoError(t, err)
+	}
+
+	ballotSet, err := s.GetBallotSet(context.Background(), "P1")
+	require.NoError(t, err)
+
+	// Close the voting period
+	proposal := contract.proposals["P1"]
+	proposal.EndTime = time.Now().Add(-time.Second)
+	contract.proposals["P1"] = proposal
+
+	finalized, err := s.FinalizeProposal(context.Background(), "P1")
+	require.NoError(t, err)
+	assert.Equal(t, models.ProposalStatusPassed, finalized.Proposal.Status)
+	assert.Equal(t, ballotSet.MerkleRoot, contract.attested["P1"])
+	assert.Equal(t, uint64(150), finalized.Proposal.ForVotes)
+	assert.Equal(t, uint64(50), finalized.Proposal.AgainstVotes)
+}
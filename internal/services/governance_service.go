@@ -2,33 +2,68 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
 	"time"
 
 	"github.com/stellar/go/clients/horizonclient"
-	"github.com/stellar/go/protocols/horizon"
-	"github.com/stellar/go/txnbuild"
 
 	"logistics-marketplace/internal/models"
-	"logistics-marketplace/internal/stellar"
+	"logistics-marketplace/internal/telemetry"
 )
 
+var (
+	// ErrProposalNotActive is returned for votes on a proposal that has been finalized
+	ErrProposalNotActive = errors.New("proposal is not active")
+	// ErrVotingNotEnded is returned when finalizing a proposal whose voting period is open
+	ErrVotingNotEnded = errors.New("voting period has not ended")
+)
+
+// GovernanceContract is the governance contract client the service calls
+type GovernanceContract interface {
+	Address() string
+	SourceAddress() string
+	GetParameter(name string) interface{}
+	CreateProposal(creator, title, description, proposalType string, data []byte) (string, error)
+	CreateOffChainProposal(creator, title, description, proposalType string, data []byte, attestor string) (string, error)
+	GetProposal(proposalID string) (models.GovernanceProposal, error)
+	ListProposals() ([]models.GovernanceProposal, error)
+	CastVote(proposalID, voter, voteType string) error
+	GetVote(proposalID, voter string) (models.GovernanceVote, error)
+	FinalizeProposal(proposalID string) (string, error)
+	AttestOffChainResult(proposalID, ballotRoot string, forVotes, againstVotes, abstainVotes uint64) (string, error)
+	ExecuteProposal(proposalID string) error
+}
+
+// GovernanceToken is the LMT token contract client voting power comes from
+type GovernanceToken interface {
+	GetBalance(address string) (uint64, error)
+	GetBalanceAt(address string, at time.Time) (uint64, error)
+	GetTotalSupply() uint64
+}
+
 type GovernanceService struct {
-	stellarClient    *horizonclient.Client
-	governanceContract *stellar.Contract
-	tokenContract    *stellar.Contract
+	stellarClient      *horizonclient.Client
+	governanceContract GovernanceContract
+	tokenContract      GovernanceToken
+	ballots            BallotStore // Off-chain ballots
 }
 
-func NewGovernanceService(stellarClient *horizonclient.Client, governanceContract, tokenContract *stellar.Contract) *GovernanceService {
+func NewGovernanceService(stellarClient *horizonclient.Client, governanceContract GovernanceContract, tokenContract GovernanceToken, ballots BallotStore) *GovernanceService {
 	return &GovernanceService{
-		stellarClient:    stellarClient,
+		stellarClient:      stellarClient,
 		governanceContract: governanceContract,
-		tokenContract:    tokenContract,
+		tokenContract:      tokenContract,
+		ballots:            ballots,
 	}
 }
 
 // CreateProposal creates a new governance proposal
 func (s *GovernanceService) CreateProposal(ctx context.Context, req models.ProposalCreateRequest, creatorAddress string) (*models.ProposalResponse, error) {
+	_, span := telemetry.Start(ctx, "GovernanceService.CreateProposal")
+	defer span.End()
+
 	// Check if creator has enough tokens to create proposal
 	balance, err := s.tokenContract.GetBalance(creatorAddress)
 	if err != nil {
@@ -40,14 +75,41 @@ func (s *GovernanceService) CreateProposal(ctx context.Context, req models.Propo
 		return nil, fmt.Errorf("insufficient tokens to create proposal: required %d, got %d", minThreshold, balance)
 	}
 
+	// Funds allocations must describe a valid treasury action
+	if req.ProposalType == models.FundsAllocation {
+		if err := ValidateFundsAllocation(req.ProposalData); err != nil {
+			return nil, err
+		}
+	}
+
+	if req.VotingMode == "" {
+		req.VotingMode = models.VotingModeOnChain
+	}
+
 	// Create proposal on blockchain
-	proposalID, err := s.governanceContract.CreateProposal(
-		creatorAddress,
-		req.Title,
-		req.Description,
-		string(req.ProposalType),
-		req.ProposalData,
-	)
+	var proposalID string
+	switch req.VotingMode {
+	case models.VotingModeOnChain:
+		proposalID, err = s.governanceContract.CreateProposal(
+			creatorAddress,
+			req.Title,
+			req.Description,
+			string(req.ProposalType),
+			req.ProposalData,
+		)
+	case models.VotingModeOffChain:
+		// The service attests off-chain results with its own contract account
+		proposalID, err = s.governanceContract.CreateOffChainProposal(
+			creatorAddress,
+			req.Title,
+			req.Description,
+			string(req.ProposalType),
+			req.ProposalData,
+			s.governanceContract.SourceAddress(),
+		)
+	default:
+		return nil, fmt.Errorf("invalid voting mode: %s", req.VotingMode)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to create proposal on blockchain: %w", err)
 	}
@@ -63,29 +125,22 @@ func (s *GovernanceService) CreateProposal(ctx context.Context, req models.Propo
 		EndTime:         time.Now().Add(7 * 24 * time.Hour), // 7 days voting period
 		ProposalData:    req.ProposalData,
 		ContractAddress: s.governanceContract.Address(),
+		VotingMode:      req.VotingMode,
 	}
 
-	// Calculate vote summary
-	voteSummary := struct {
-		TotalVotes       uint64
-		QuorumReached    bool
-		ApprovalRate     float64
-		ParticipationRate float64
-	}{
-		TotalVotes:       0,
-		QuorumReached:    false,
-		ApprovalRate:     0,
-		ParticipationRate: 0,
-	}
+	proposal.ID = proposalID
 
 	return &models.ProposalResponse{
 		Proposal:    *proposal,
-		VoteSummary: voteSummary,
+		VoteSummary: s.summarizeVotes(proposal, s.tokenContract.GetTotalSupply()),
 	}, nil
 }
 
 // CastVote casts a vote on a proposal
 func (s *GovernanceService) CastVote(ctx context.Context, req models.VoteCastRequest, voterAddress string) (*models.VoteResponse, error) {
+	ctx, span := telemetry.Start(ctx, "GovernanceService.CastVote")
+	defer span.End()
+
 	// Get proposal
 	proposal, err := s.GetProposal(ctx, req.ProposalID)
 	if err != nil {
@@ -94,7 +149,11 @@ func (s *GovernanceService) CastVote(ctx context.Context, req models.VoteCastReq
 
 	// Check if proposal is active
 	if proposal.Proposal.Status != models.ProposalStatusActive {
-		return nil, fmt.Errorf("proposal is not active")
+		return nil, ErrProposalNotActive
+	}
+
+	if proposal.Proposal.VotingMode == models.VotingModeOffChain {
+		return nil, fmt.Errorf("proposal uses off-chain voting, submit a signed vote instead")
 	}
 
 	// Check if voting period is still open
@@ -131,12 +190,23 @@ func (s *GovernanceService) CastVote(ctx context.Context, req models.VoteCastReq
 
 // ExecuteProposal executes a passed proposal
 func (s *GovernanceService) ExecuteProposal(ctx context.Context, req models.ProposalExecuteRequest) (*models.ProposalResponse, error) {
+	ctx, span := telemetry.Start(ctx, "GovernanceService.ExecuteProposal")
+	defer span.End()
+
 	// Get proposal
 	proposal, err := s.GetProposal(ctx, req.ProposalID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get proposal: %w", err)
 	}
 
+	// Finalize the proposal first if the finalizer has not picked it up yet
+	if proposal.Proposal.Status == models.ProposalStatusActive {
+		proposal, err = s.FinalizeProposal(ctx, req.ProposalID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to finalize proposal: %w", err)
+		}
+	}
+
 	// Check if proposal can be executed
 	if proposal.Proposal.Status != models.ProposalStatusPassed {
 		return nil, fmt.Errorf("proposal is not in passed status")
@@ -164,46 +234,131 @@ func (s *GovernanceService) ExecuteProposal(ctx context.Context, req models.Prop
 
 // GetProposal gets a proposal by ID
 func (s *GovernanceService) GetProposal(ctx context.Context, proposalID string) (*models.ProposalResponse, error) {
+	_, span := telemetry.Start(ctx, "GovernanceService.GetProposal")
+	defer span.End()
+
 	// Get proposal from blockchain
 	proposal, err := s.governanceContract.GetProposal(proposalID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get proposal from blockchain: %w", err)
 	}
 
-	// Calculate vote summary
-	totalSupply := s.tokenContract.GetTotalSupply()
-	totalVotes := proposal.ForVotes + proposal.AgainstVotes + proposal.AbstainVotes
-	
-	quorumPercentage := s.governanceContract.GetParameter("quorumPercentage").(uint64)
-	quorumReached := (totalVotes * 100 / totalSupply) >= quorumPercentage
+	return &models.ProposalResponse{
+		Proposal:    proposal,
+		VoteSummary: s.summarizeVotes(&proposal, s.tokenContract.GetTotalSupply()),
+	}, nil
+}
 
-	var approvalRate float64
-	if totalVotes > 0 {
-		approvalRate = float64(proposal.ForVotes) / float64(totalVotes) * 100
+// FinalizeProposal tallies a proposal whose voting period has ended and
+// records whether it passed, was rejected or failed to reach quorum. A
+// proposal that is already finalized, for instance by the background
+// finalizer, is returned as it is.
+func (s *GovernanceService) FinalizeProposal(ctx context.Context, proposalID string) (*models.ProposalResponse, error) {
+	ctx, span := telemetry.Start(ctx, "GovernanceService.FinalizeProposal")
+	defer span.End()
+
+	proposal, err := s.GetProposal(ctx, proposalID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get proposal: %w", err)
 	}
 
-	participationRate := float64(totalVotes) / float64(totalSupply) * 100
+	if proposal.Proposal.Status != models.ProposalStatusActive {
+		return proposal, nil
+	}
 
-	voteSummary := struct {
-		TotalVotes       uint64
-		QuorumReached    bool
-		ApprovalRate     float64
-		ParticipationRate float64
-	}{
-		TotalVotes:       totalVotes,
-		QuorumReached:    quorumReached,
-		ApprovalRate:     approvalRate,
-		ParticipationRate: participationRate,
+	if time.Now().Before(proposal.Proposal.EndTime) {
+		return nil, ErrVotingNotEnded
 	}
 
-	return &models.ProposalResponse{
-		Proposal:    proposal,
-		VoteSummary: voteSummary,
-	}, nil
+	// Off-chain proposals are finalized by attesting the ballot set
+	if proposal.Proposal.VotingMode == models.VotingModeOffChain {
+		finalized, err := s.attestOffChainResult(ctx, proposal)
+		if err != nil {
+			return s.finalizedElsewhere(ctx, proposalID, err)
+		}
+		return finalized, nil
+	}
+
+	// Finalize proposal on blockchain
+	status, err := s.governanceContract.FinalizeProposal(proposalID)
+	if err != nil {
+		return s.finalizedElsewhere(ctx, proposalID, fmt.Errorf("failed to finalize proposal on blockchain: %w", err))
+	}
+
+	proposal.Proposal.Status = models.ProposalStatus(status)
+	proposal.Proposal.UpdatedAt = time.Now()
+
+	return proposal, nil
+}
+
+// finalizedElsewhere returns the proposal when a concurrent finalization
+// won the race that made finalizing it fail, and the failure otherwise
+func (s *GovernanceService) finalizedElsewhere(ctx context.Context, proposalID string, finalizeErr error) (*models.ProposalResponse, error) {
+	proposal, err := s.GetProposal(ctx, proposalID)
+	if err != nil || proposal.Proposal.Status == models.ProposalStatusActive {
+		return nil, finalizeErr
+	}
+	return proposal, nil
+}
+
+// FinalizeEndedProposals finalizes every active proposal whose voting period
+// has ended and returns the finalized proposals. A proposal that cannot be
+// finalized does not hold up the others; the failures are returned joined.
+func (s *GovernanceService) FinalizeEndedProposals(ctx context.Context) ([]models.ProposalResponse, error) {
+	ctx, span := telemetry.Start(ctx, "GovernanceService.FinalizeEndedProposals")
+	defer span.End()
+
+	proposals, err := s.ListProposals(ctx, models.ProposalStatusActive, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list active proposals: %w", err)
+	}
+
+	var finalized []models.ProposalResponse
+	var errs []error
+	for _, proposal := range proposals {
+		if time.Now().Before(proposal.Proposal.EndTime) {
+			continue
+		}
+
+		proposalResp, err := s.FinalizeProposal(ctx, proposal.Proposal.ID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to finalize proposal %s: %w", proposal.Proposal.ID, err))
+			continue
+		}
+
+		finalized = append(finalized, *proposalResp)
+	}
+
+	return finalized, errors.Join(errs...)
+}
+
+// RunProposalFinalizer periodically finalizes ended proposals until the
+// context is cancelled
+func (s *GovernanceService) RunProposalFinalizer(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			finalized, err := s.FinalizeEndedProposals(ctx)
+			if err != nil {
+				log.Printf("proposal finalizer: %v", err)
+			}
+			for _, proposal := range finalized {
+				log.Printf("proposal finalizer: proposal %s finalized as %s", proposal.Proposal.ID, proposal.Proposal.Status)
+			}
+		}
+	}
 }
 
 // ListProposals lists all proposals with optional filters
 func (s *GovernanceService) ListProposals(ctx context.Context, status models.ProposalStatus, proposalType models.ProposalType) ([]models.ProposalResponse, error) {
+	ctx, span := telemetry.Start(ctx, "GovernanceService.ListProposals")
+	defer span.End()
+
 	// Get proposals from blockchain
 	proposals, err := s.governanceContract.ListProposals()
 	if err != nil {
@@ -230,6 +385,9 @@ func (s *GovernanceService) ListProposals(ctx context.Context, status models.Pro
 
 // GetVote gets a vote by proposal ID and voter address
 func (s *GovernanceService) GetVote(ctx context.Context, proposalID string, voterAddress string) (*models.VoteResponse, error) {
+	ctx, span := telemetry.Start(ctx, "GovernanceService.GetVote")
+	defer span.End()
+
 	// Get vote from blockchain
 	vote, err := s.governanceContract.GetVote(proposalID, voterAddress)
 	if err != nil {
@@ -250,6 +408,9 @@ func (s *GovernanceService) GetVote(ctx context.Context, proposalID string, vote
 
 // GetParameter gets a governance parameter by name
 func (s *GovernanceService) GetParameter(ctx context.Context, name string) (*models.ParameterResponse, error) {
+	_, span := telemetry.Start(ctx, "GovernanceService.GetParameter")
+	defer span.End()
+
 	// Get parameter from blockchain
 	value := s.governanceContract.GetParameter(name)
 	if value == nil {
@@ -275,3 +436,30 @@ func (s *GovernanceService) GetParameter(ctx context.Context, name string) (*mod
 		History:   history,
 	}, nil
 }
+
+// Helper functions
+
+// summarizeVotes calculates the approval and participation rates of a
+// proposal using the quorum and majority rules of the governance contract
+func (s *GovernanceService) summarizeVotes(proposal *models.GovernanceProposal, totalSupply uint64) models.ProposalVoteSummary {
+	quorumPercentage := s.governanceContract.GetParameter("quorumPercentage").(uint64)
+	majorityPercentage := s.governanceContract.GetParameter("majorityPercentage").(uint64)
+
+	summary := models.ProposalVoteSummary{
+		TotalVotes:         proposal.ForVotes + proposal.AgainstVotes + proposal.AbstainVotes,
+		QuorumPercentage:   quorumPercentage,
+		MajorityPercentage: majorityPercentage,
+	}
+
+	if totalSupply > 0 {
+		summary.ParticipationRate = float64(summary.TotalVotes) / float64(totalSupply) * 100
+		summary.QuorumReached = summary.TotalVotes*100/totalSupply >= quorumPercentage
+	}
+
+	if summary.TotalVotes > 0 {
+		summary.ApprovalRate = float64(proposal.ForVotes) / float64(summary.TotalVotes) * 100
+		summary.MajorityReached = proposal.ForVotes*100/summary.TotalVotes >= majorityPercentage
+	}
+
+	return summary
+}
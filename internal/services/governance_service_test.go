@@ -0,0 +1,292 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"logistics-marketplace/internal/models"
+)
+
+// fakeGovernanceContract tallies proposals with the quorum and majority
+// rules of the governance contract
+type fakeGovernanceContract struct {
+	proposals   map[string]models.GovernanceProposal
+	totalSupply uint64
+	finalizeErr map[string]error
+	attested    map[string]string // Proposal ID to ballot root
+}
+
+func newFakeGovernanceContract(totalSupply uint64, proposals ...models.GovernanceProposal) *fakeGovernanceContract {
+	c := &fakeGovernanceContract{
+		proposals:   make(map[string]models.GovernanceProposal),
+		totalSupply: totalSupply,
+		finalizeErr: make(map[string]error),
+		attested:    make(map[string]string),
+	}
+	for _, proposal := range proposals {
+		c.proposals[proposal.ID] = proposal
+	}
+	return c
+}
+
+func (c *fakeGovernanceContract) Address() string       { return "CGOVERNANCE" }
+func (c *fakeGovernanceContract) SourceAddress() string { return "GSERVICE" }
+
+func (c *fakeGovernanceContract) GetParameter(name string) interface{} {
+	switch name {
+	case "quorumPercentage":
+		return uint64(10)
+	case "majorityPercentage":
+		return uint64(50)
+	case "executionDelay":
+		return int64(0)
+	}
+	return nil
+}
+
+func (c *fakeGovernanceContract) CreateProposal(creator, title, description, proposalType string, data []byte) (string, error) {
+	return "", errors.New("not implemented")
+}
+
+func (c *fakeGovernanceContract) CreateOffChainProposal(creator, title, description, proposalType string, data []byte, attestor string) (string, error) {
+	return "", errors.New("not implemented")
+}
+
+func (c *fakeGovernanceContract) GetProposal(proposalID string) (models.GovernanceProposal, error) {
+	proposal, ok := c.proposals[proposalID]
+	if !ok {
+		return models.GovernanceProposal{}, fmt.Errorf("proposal %s does not exist", proposalID)
+	}
+	return proposal, nil
+}
+
+func (c *fakeGovernanceContract) ListProposals() ([]models.GovernanceProposal, error) {
+	var proposals []models.GovernanceProposal
+	for _, proposal := range c.proposals {
+		proposals = append(proposals, proposal)
+	}
+	return proposals, nil
+}
+
+func (c *fakeGovernanceContract) CastVote(proposalID, voter, voteType string) error {
+	return errors.New("not implemented")
+}
+
+func (c *fakeGovernanceContract) GetVote(proposalID, voter string) (models.GovernanceVote, error) {
+	return models.GovernanceVote{}, errors.New("not implemented")
+}
+
+func (c *fakeGovernanceContract) FinalizeProposal(proposalID string) (string, error) {
+	if err := c.finalizeErr[proposalID]; err != nil {
+		return "", err
+	}
+	proposal := c.proposals[proposalID]
+	if proposal.Status != models.ProposalStatusActive {
+		return "", errors.New("Proposal is not active")
+	}
+	proposal.Status = c.tally(proposal)
+	c.proposals[proposalID] = proposal
+	return string(proposal.Status), nil
+}
+
+func (c *fakeGovernanceContract) AttestOffChainResult(proposalID, ballotRoot string, forVotes, againstVotes, abstainVotes uint64) (string, error) {
+	proposal := c.proposals[proposalID]
+	proposal.ForVotes, proposal.AgainstVotes, proposal.AbstainVotes = forVotes, againstVotes, abstainVotes
+	proposal.BallotRoot = ballotRoot
+	proposal.Status = c.tally(proposal)
+	c.proposals[proposalID] = proposal
+	c.attested[proposalID] = ballotRoot
+	return string(proposal.Status), nil
+}
+
+func (c *fakeGovernanceContract) ExecuteProposal(proposalID string) error {
+	return errors.New("not implemented")
+}
+
+func (c *fakeGovernanceContract) tally(proposal models.GovernanceProposal) models.ProposalStatus {
+	total := proposal.ForVotes + proposal.AgainstVotes + proposal.AbstainVotes
+	if c.totalSupply == 0 || total*100/c.totalSupply < 10 {
+		return models.ProposalStatusFailedQuorum
+	}
+	if proposal.ForVotes*100/total < 50 {
+		return models.ProposalStatusRejected
+	}
+	return models.ProposalStatusPassed
+}
+
+// fakeGovernanceToken reports fixed balances at any point in time
+type fakeGovernanceToken struct {
+	balances    map[string]uint64
+	totalSupply uint64
+}
+
+func (t *fakeGovernanceToken) GetBalance(address string) (uint64, error) {
+	return t.balances[address], nil
+}
+
+func (t *fakeGovernanceToken) GetBalanceAt(address string, at time.Time) (uint64, error) {
+	return t.balances[address], nil
+}
+
+func (t *fakeGovernanceToken) GetTotalSupply() uint64 {
+	return t.totalSupply
+}
+
+func endedProposal(id string, forVotes, againstVotes, abstainVotes uint64) models.GovernanceProposal {
+	proposal := models.GovernanceProposal{
+		Status:       models.ProposalStatusActive,
+		StartTime:    time.Now().Add(-8 * 24 * time.Hour),
+		EndTime:      time.Now().Add(-time.Hour),
+		ForVotes:     forVotes,
+		AgainstVotes: againstVotes,
+		AbstainVotes: abstainVotes,
+		VotingMode:   models.VotingModeOnChain,
+	}
+	proposal.ID = id
+	return proposal
+}
+
+func newTestGovernanceService(t *testing.T, contract *fakeGovernanceContract) *GovernanceService {
+	ballots, err := OpenBallotLog(filepath.Join(t.TempDir(), "ballots.jsonl"))
+	require.NoError(t, err)
+	t.Cleanup(func() { ballots.Close() })
+
+	return NewGovernanceService(nil, contract, &fakeGovernanceToken{totalSupply: contract.totalSupply}, ballots)
+}
+
+func TestSummarizeVotes(t *testing.T) {
+	s := newTestGovernanceService(t, newFakeGovernanceContract(1000))
+
+	tests := []struct {
+		name                   string
+		forVotes, against      uint64
+		totalSupply            uint64
+		quorum, majority       bool
+		participation, approve float64
+	}{
+		{name: "passes", forVotes: 60, against: 40, totalSupply: 1000, quorum: true, majority: true, participation: 10, approve: 60},
+		{name: "tie reaches majority", forVotes: 50, against: 50, totalSupply: 1000, quorum: true, majority: true, participation: 10, approve: 50},
+		{name: "below quorum", forVotes: 50, against: 49, totalSupply: 1000, quorum: false, majority: true, participation: 9.9, approve: 50.505},
+		{name: "rejected", forVotes: 40, against: 60, totalSupply: 1000, quorum: true, majority: false, participation: 10, approve: 40},
+		{name: "no votes", totalSupply: 1000},
+		{name: "no supply", forVotes: 10, totalSupply: 0, majority: true, approve: 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proposal := endedProposal("P1", tt.forVotes, tt.against, 0)
+			summary := s.summarizeVotes(&proposal, tt.totalSupply)
+
+			assert.Equal(t, tt.forVotes+tt.against, summary.TotalVotes)
+			assert.Equal(t, tt.quorum, summary.QuorumReached)
+			assert.Equal(t, tt.majority, summary.MajorityReached)
+			assert.InDelta(t, tt.participation, summary.ParticipationRate, 0.01)
+			assert.InDelta(t, tt.approve, summary.ApprovalRate, 0.01)
+			assert.Equal(t, uint64(10), summary.QuorumPercentage)
+			assert.Equal(t, uint64(50), summary.MajorityPercentage)
+		})
+	}
+}
+
+func TestFinalizeProposalRecordsTally(t *testing.T) {
+	tests := []struct {
+		name     string
+		proposal models.GovernanceProposal
+		status   models.ProposalStatus
+	}{
+		{name: "passed", proposal: endedProposal("P1", 80, 20, 0), status: models.ProposalStatusPassed},
+		{name: "rejected", proposal: endedProposal("P1", 20, 70, 10), status: models.ProposalStatusRejected},
+		{name: "failed quorum", proposal: endedProposal("P1", 5, 0, 0), status: models.ProposalStatusFailedQuorum},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestGovernanceService(t, newFakeGovernanceContract(1000, tt.proposal))
+
+			finalized, err := s.FinalizeProposal(context.Background(), "P1")
+			require.NoError(t, err)
+			assert.Equal(t, tt.status, finalized.Proposal.Status)
+			assert.Equal(t, tt.status == models.ProposalStatusPassed,
+				finalized.VoteSummary.QuorumReached && finalized.VoteSummary.MajorityReached)
+		})
+	}
+}
+
+func TestFinalizeProposalStates(t *testing.T) {
+	open := endedProposal("OPEN", 80, 0, 0)
+	open.EndTime = time.Now().Add(time.Hour)
+	done := endedProposal("DONE", 80, 0, 0)
+	done.Status = models.ProposalStatusPassed
+	s := newTestGovernanceService(t, newFakeGovernanceContract(1000, open, done))
+
+	_, err := s.FinalizeProposal(context.Background(), "OPEN")
+	assert.ErrorIs(t, err, ErrVotingNotEnded)
+
+	// A proposal finalized elsewhere is not an error
+	finalized, err := s.FinalizeProposal(context.Background(), "DONE")
+	require.NoError(t, err)
+	assert.Equal(t, models.ProposalStatusPassed, finalized.Proposal.Status)
+}
+
+func TestFinalizeProposalLosingRaceReturnsFinalizedProposal(t *testing.T) {
+	contract := newFakeGovernanceContract(1000, endedProposal("P1", 200, 0, 0))
+	s := newTestGovernanceService(t, contract)
+
+	// The background finalizer tallies the proposal after it was read
+	contract.finalizeErr["P1"] = errors.New("Proposal is not active")
+	proposal := contract.proposals["P1"]
+	proposal.Status = models.ProposalStatusPassed
+	contract.proposals["P1"] = proposal
+
+	s.governanceContract = &readActiveOnce{fakeGovernanceContract: contract}
+	finalized, err := s.FinalizeProposal(context.Background(), "P1")
+	require.NoError(t, err)
+	assert.Equal(t, models.ProposalStatusPassed, finalized.Proposal.Status)
+}
+
+// readActiveOnce reports the first proposal read as still active
+type readActiveOnce struct {
+	*fakeGovernanceContract
+	read bool
+}
+
+func (c *readActiveOnce) GetProposal(proposalID string) (models.GovernanceProposal, error) {
+	proposal, err := c.fakeGovernanceContract.GetProposal(proposalID)
+	if !c.read {
+		c.read = true
+		proposal.Status = models.ProposalStatusActive
+	}
+	return proposal, err
+}
+
+func TestFinalizeEndedProposalsContinuesPastFailures(t *testing.T) {
+	open := endedProposal("OPEN", 80, 0, 0)
+	open.EndTime = time.Now().Add(time.Hour)
+	contract := newFakeGovernanceContract(1000,
+		endedProposal("A", 200, 0, 0),
+		endedProposal("B", 200, 0, 0),
+		endedProposal("C", 0, 200, 0),
+		open,
+	)
+	contract.finalizeErr["B"] = errors.New("transaction timed out")
+	s := newTestGovernanceService(t, contract)
+
+	finalized, err := s.FinalizeEndedProposals(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to finalize proposal B")
+
+	statuses := make(map[string]models.ProposalStatus)
+	for _, proposal := range finalized {
+		statuses[proposal.Proposal.ID] = proposal.Proposal.Status
+	}
+	assert.Equal(t, map[string]models.ProposalStatus{
+		"A": models.ProposalStatusPassed,
+		"C": models.ProposalStatusRejected,
+	}, statuses)
+	assert.Equal(t, models.ProposalStatusActive, contract.proposals["B"].Status)
+	assert.Equal(t, models.ProposalStatusActive, contract.proposals["OPEN"].Status)
+}
@@ -1,23 +1,26 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"time"
 
+	"logistics-marketplace/internal/ledger"
 	"logistics-marketplace/internal/models"
 	"logistics-marketplace/internal/stellar"
+	"logistics-marketplace/internal/telemetry"
 )
 
 // MarketplaceService handles business logic for the marketplace
 type MarketplaceService struct {
-	txManager    *stellar.TransactionManager
+	ledger       ledger.LedgerBackend
 	tokenManager *stellar.TokenManager
 }
 
 // NewMarketplaceService creates a new MarketplaceService instance
-func NewMarketplaceService(txManager *stellar.TransactionManager, tokenManager *stellar.TokenManager) *MarketplaceService {
+func NewMarketplaceService(ledgerBackend ledger.LedgerBackend, tokenManager *stellar.TokenManager) *MarketplaceService {
 	return &MarketplaceService{
-		txManager:    txManager,
+		ledger:       ledgerBackend,
 		tokenManager: tokenManager,
 	}
 }
@@ -42,7 +45,10 @@ func (s *MarketplaceService) GetServiceItems(subcategoryID string) ([]models.Ser
 }
 
 // CreateServiceListing creates a new service listing
-func (s *MarketplaceService) CreateServiceListing(service *models.LogisticsService) error {
+func (s *MarketplaceService) CreateServiceListing(ctx context.Context, service *models.LogisticsService) error {
+	ctx, span := telemetry.Start(ctx, "MarketplaceService.CreateServiceListing")
+	defer span.End()
+
 	if err := s.validateServiceListing(service); err != nil {
 		return fmt.Errorf("invalid service listing: %w", err)
 	}
@@ -52,22 +58,22 @@ func (s *MarketplaceService) CreateServiceListing(service *models.LogisticsServi
 	service.CreatedAt = time.Now()
 	service.UpdatedAt = time.Now()
 
-	// Submit to blockchain
-	result, err := s.txManager.CreateServiceListing(
-		service.Provider.ID,
-		uint8(s.getCategoryIndex(string(service.Category))),
-		0, // shipment mode would come from service details
-		service.Origin,
-		service.Destination,
-		uint64(service.Item.BasePrice * 100), // Convert to smallest unit
-		service.Item.Description,
-	)
+	// Record on the ledger
+	receipt, err := s.ledger.RecordListing(ctx, &ledger.ListingRecord{
+		ProviderID:   service.Provider.ID,
+		Category:     uint8(s.getCategoryIndex(string(service.Category))),
+		ShipmentMode: 0, // shipment mode would come from service details
+		Origin:       service.Origin,
+		Destination:  service.Destination,
+		Price:        uint64(service.Item.BasePrice * 100), // Convert to smallest unit
+		Description:  service.Item.Description,
+	})
 	if err != nil {
 		return fmt.Errorf("failed to create service listing: %w", err)
 	}
 
-	// Update with blockchain transaction details
-	service.ID = result.TxID
+	// Update with ledger transaction details
+	service.ID = receipt.TxID
 
 	return nil
 }
@@ -98,29 +104,32 @@ func (s *MarketplaceService) GetQuotation(serviceID string, cargo *models.CargoD
 }
 
 // CreateBooking creates a new booking
-func (s *MarketplaceService) CreateBooking(booking *models.Booking) error {
+func (s *MarketplaceService) CreateBooking(ctx context.Context, booking *models.Booking) error {
+	ctx, span := telemetry.Start(ctx, "MarketplaceService.CreateBooking")
+	defer span.End()
+
 	if err := s.validateBooking(booking); err != nil {
 		return fmt.Errorf("invalid booking: %w", err)
 	}
 
-	// Submit to blockchain
-	result, err := s.txManager.CreateBooking(
-		booking.CustomerID,
-		booking.ServiceID,
-		map[string]interface{}{
+	// Record on the ledger
+	receipt, err := s.ledger.RecordBooking(ctx, &ledger.BookingRecord{
+		CustomerID: booking.CustomerID,
+		ServiceID:  booking.ServiceID,
+		Cargo: map[string]interface{}{
 			"weight":      booking.CargoDetails.Weight,
 			"volume":      booking.CargoDetails.Volume,
 			"type":        booking.CargoDetails.CargoType,
 			"description": booking.CargoDetails.Description,
 			"hazardous":   booking.CargoDetails.IsHazardous,
 		},
-	)
+	})
 	if err != nil {
 		return fmt.Errorf("failed to create booking: %w", err)
 	}
 
-	// Update booking with blockchain transaction details
-	booking.ID = result.TxID
+	// Update booking with ledger transaction details
+	booking.ID = receipt.TxID
 	booking.Status = "PENDING"
 	booking.PaymentStatus = "UNPAID"
 	booking.CreatedAt = time.Now()
@@ -130,12 +139,19 @@ func (s *MarketplaceService) CreateBooking(booking *models.Booking) error {
 }
 
 // ProcessPayment handles payment for a booking
-func (s *MarketplaceService) ProcessPayment(bookingID string, customerID string, amount float64) error {
+func (s *MarketplaceService) ProcessPayment(ctx context.Context, bookingID string, customerID string, amount float64) error {
+	ctx, span := telemetry.Start(ctx, "MarketplaceService.ProcessPayment")
+	defer span.End()
+
 	// Convert amount to token amount
 	tokenAmount := fmt.Sprintf("%.0f", amount*100) // Convert to smallest unit
 
-	// Process payment on blockchain
-	result, err := s.txManager.ProcessPayment(customerID, bookingID, tokenAmount)
+	// Record payment on the ledger
+	_, err := s.ledger.RecordPayment(ctx, &ledger.PaymentRecord{
+		CustomerID: customerID,
+		BookingID:  bookingID,
+		Amount:     tokenAmount,
+	})
 	if err != nil {
 		return fmt.Errorf("failed to process payment: %w", err)
 	}
@@ -144,20 +160,23 @@ func (s *MarketplaceService) ProcessPayment(bookingID string, customerID string,
 }
 
 // UpdateShipmentStatus updates the status of a shipment
-func (s *MarketplaceService) UpdateShipmentStatus(event *models.TrackingEvent) error {
-	// Submit status update to blockchain
-	result, err := s.txManager.UpdateShipmentStatus(
-		event.BookingID,
-		event.Location,
-		event.Status,
-		event.Description,
-	)
+func (s *MarketplaceService) UpdateShipmentStatus(ctx context.Context, event *models.TrackingEvent) error {
+	ctx, span := telemetry.Start(ctx, "MarketplaceService.UpdateShipmentStatus")
+	defer span.End()
+
+	// Record status update on the ledger
+	receipt, err := s.ledger.RecordTrackingEvent(ctx, &ledger.TrackingEventRecord{
+		BookingID:   event.BookingID,
+		Location:    event.Location,
+		Status:      event.Status,
+		Description: event.Description,
+	})
 	if err != nil {
 		return fmt.Errorf("failed to update shipment status: %w", err)
 	}
 
-	event.ID = result.TxID
-	event.Timestamp = time.Now()
+	event.ID = receipt.TxID
+	event.Timestamp = receipt.RecordedAt
 
 	return nil
 }
@@ -0,0 +1,443 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"logistics-marketplace/internal/models"
+	"logistics-marketplace/internal/telemetry"
+)
+
+// DefaultRewardRules are the on-time performance rewards paid from the rewards pool
+var DefaultRewardRules = []models.RewardRule{
+	{Type: models.RewardOnTimeDeparture, Amount: 50, Tolerance: 2 * time.Hour},
+	{Type: models.RewardOnTimeArrival, Amount: 100, Tolerance: 6 * time.Hour},
+	{Type: models.RewardOnTimeDocuments, Amount: 25},
+}
+
+var (
+	// ErrBookingEvaluated is returned for a booking whose rewards were already recorded
+	ErrBookingEvaluated = errors.New("booking has already been evaluated for rewards")
+	// ErrBookingNotDelivered is returned when evaluating a booking still in progress
+	ErrBookingNotDelivered = errors.New("booking has not been delivered")
+)
+
+// BookingRecords reads the recorded bookings and tracking history that
+// rewards are earned on
+type BookingRecords interface {
+	// GetBookingRecord returns the parties, confirmed schedule and document filings of a booking
+	GetBookingRecord(bookingID string) (*models.ConfirmedBooking, error)
+	// GetTrackingHistory returns the statuses recorded for a booking's shipment
+	GetTrackingHistory(bookingID string) ([]models.ShipmentStatusUpdate, error)
+}
+
+// RewardsToken pays rewards in LMT
+type RewardsToken interface {
+	GetTokenBalance(ctx context.Context, account string) (string, error)
+	TransferTokens(ctx context.Context, fromAccount, toAccount string, amount string) error
+}
+
+// rewardsState is the rewards ledger as stored on disk
+type rewardsState struct {
+	Evaluated []string             `json:"evaluated"`
+	Entries   []models.RewardEntry `json:"entries"`
+}
+
+// RewardsService pays LMT loyalty rewards for on-time performance out of a
+// rewards pool account funded by governance treasury grants. The rewards
+// ledger is persisted to a JSON file when the service has a path.
+type RewardsService struct {
+	token       RewardsToken
+	records     BookingRecords
+	poolAccount string
+	rules       []models.RewardRule
+	path        string
+
+	distributing sync.Mutex // Held for a whole distribution run
+
+	mu        sync.RWMutex
+	entries   map[string][]models.RewardEntry // account -> ledger entries
+	evaluated map[string]bool                 // booking ID -> already evaluated
+}
+
+// NewRewardsService creates a new RewardsService instance, loading the
+// rewards ledger in path if it exists
+func NewRewardsService(token RewardsToken, records BookingRecords, poolAccount string, rules []models.RewardRule, path string) (*RewardsService, error) {
+	s := &RewardsService{
+		token:       token,
+		records:     records,
+		poolAccount: poolAccount,
+		rules:       rules,
+		path:        path,
+		entries:     make(map[string][]models.RewardEntry),
+		evaluated:   make(map[string]bool),
+	}
+	if path == "" {
+		return s, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create rewards ledger directory: %w", err)
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rewards ledger: %w", err)
+	}
+
+	var state rewardsState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse rewards ledger: %w", err)
+	}
+	for _, bookingID := range state.Evaluated {
+		s.evaluated[bookingID] = true
+	}
+	for _, entry := range state.Entries {
+		s.entries[entry.Account] = append(s.entries[entry.Account], entry)
+	}
+	return s, nil
+}
+
+// EvaluateBooking runs the reward rules over a delivered booking's records
+// and records the rewards earned by its provider and customer
+func (s *RewardsService) EvaluateBooking(ctx context.Context, bookingID string) ([]models.RewardEntry, error) {
+	_, span := telemetry.Start(ctx, "RewardsService.EvaluateBooking")
+	defer span.End()
+
+	s.mu.RLock()
+	evaluated := s.evaluated[bookingID]
+	s.mu.RUnlock()
+	if evaluated {
+		return nil, fmt.Errorf("%w: %s", ErrBookingEvaluated, bookingID)
+	}
+
+	performance, err := s.loadPerformance(bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Another evaluation may have finished while the records were loading
+	if s.evaluated[bookingID] {
+		return nil, fmt.Errorf("%w: %s", ErrBookingEvaluated, bookingID)
+	}
+
+	now := time.Now()
+	var earned []models.RewardEntry
+	for _, rule := range s.rules {
+		account, ok := evaluateRewardRule(rule, performance)
+		if !ok {
+			continue
+		}
+
+		earned = append(earned, models.RewardEntry{
+			ID:        fmt.Sprintf("RWD-%s-%s", bookingID, rule.Type),
+			Account:   account,
+			BookingID: bookingID,
+			Rule:      rule.Type,
+			Amount:    rule.Amount,
+			Status:    models.RewardStatusPending,
+			EarnedAt:  now,
+		})
+	}
+
+	previous := make(map[string][]models.RewardEntry, len(earned))
+	for _, entry := range earned {
+		if _, saved := previous[entry.Account]; !saved {
+			previous[entry.Account] = s.entries[entry.Account]
+		}
+		s.entries[entry.Account] = append(s.entries[entry.Account], entry)
+	}
+	s.evaluated[bookingID] = true
+
+	if err := s.save(); err != nil {
+		for account, entries := range previous {
+			s.entries[account] = entries
+		}
+		delete(s.evaluated, bookingID)
+		return nil, err
+	}
+
+	return earned, nil
+}
+
+// loadPerformance measures a delivered booking against its confirmed schedule
+func (s *RewardsService) loadPerformance(bookingID string) (*models.BookingPerformance, error) {
+	record, err := s.records.GetBookingRecord(bookingID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get booking: %w", err)
+	}
+
+	history, err := s.records.GetTrackingHistory(bookingID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tracking history: %w", err)
+	}
+
+	performance := &models.BookingPerformance{
+		BookingID:          bookingID,
+		ProviderAddress:    record.ProviderAddress,
+		CustomerAddress:    record.CustomerAddress,
+		ConfirmedDeparture: record.ConfirmedDeparture,
+		ConfirmedArrival:   record.ConfirmedArrival,
+		Documents:          record.Documents,
+	}
+
+	// The first time a status was reached counts
+	reached := make(map[string]time.Time)
+	for _, update := range history {
+		if first, ok := reached[update.Status]; !ok || update.Timestamp.Before(first) {
+			reached[update.Status] = update.Timestamp
+		}
+	}
+
+	if _, delivered := reached["DELIVERED"]; !delivered {
+		return nil, fmt.Errorf("%w: %s", ErrBookingNotDelivered, bookingID)
+	}
+	if departed, ok := reached["DEPARTED"]; ok {
+		performance.ActualDeparture = &departed
+	}
+	if arrived, ok := reached["ARRIVED"]; ok {
+		performance.ActualArrival = &arrived
+	}
+
+	return performance, nil
+}
+
+// evaluateRewardRule returns the account a rule rewards for a booking, if any
+func evaluateRewardRule(rule models.RewardRule, performance *models.BookingPerformance) (string, bool) {
+	switch rule.Type {
+	case models.RewardOnTimeDeparture:
+		if performance.ActualDeparture == nil || performance.ConfirmedDeparture.IsZero() {
+			return "", false
+		}
+		return performance.ProviderAddress, !performance.ActualDeparture.After(performance.ConfirmedDeparture.Add(rule.Tolerance))
+	case models.RewardOnTimeArrival:
+		if performance.ActualArrival == nil || performance.ConfirmedArrival.IsZero() {
+			return "", false
+		}
+		return performance.ProviderAddress, !performance.ActualArrival.After(performance.ConfirmedArrival.Add(rule.Tolerance))
+	case models.RewardOnTimeDocuments:
+		if len(performance.Documents) == 0 {
+			return "", false
+		}
+		for _, document := range performance.Documents {
+			if document.SubmittedAt == nil || document.SubmittedAt.After(document.Deadline.Add(rule.Tolerance)) {
+				return "", false
+			}
+		}
+		return performance.CustomerAddress, true
+	default:
+		return "", false
+	}
+}
+
+// DistributeRewards pays every account its pending rewards from the rewards
+// pool. Entries are marked DISTRIBUTING before their transfer is submitted;
+// entries left in that state by a crash are not paid again automatically and
+// need to be reconciled against the pool's payments.
+func (s *RewardsService) DistributeRewards(ctx context.Context) (uint64, error) {
+	ctx, span := telemetry.Start(ctx, "RewardsService.DistributeRewards")
+	defer span.End()
+
+	s.distributing.Lock()
+	defer s.distributing.Unlock()
+
+	// Rewards earned while this run pays out wait for the next run
+	s.mu.RLock()
+	pending := make(map[string][]string) // account -> pending entry IDs
+	amounts := make(map[string]uint64)
+	var total uint64
+	for account, entries := range s.entries {
+		for _, entry := range entries {
+			if entry.Status == models.RewardStatusPending {
+				pending[account] = append(pending[account], entry.ID)
+				amounts[account] += entry.Amount
+				total += entry.Amount
+			}
+		}
+	}
+	s.mu.RUnlock()
+
+	if total == 0 {
+		return 0, nil
+	}
+
+	balance, err := s.token.GetTokenBalance(ctx, s.poolAccount)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rewards pool balance: %w", err)
+	}
+
+	available, err := strconv.ParseFloat(balance, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse rewards pool balance: %w", err)
+	}
+
+	if available < float64(total) {
+		return 0, fmt.Errorf("rewards pool holds %s LMT, %d LMT pending", balance, total)
+	}
+
+	// Pay accounts in a stable order so partial failures are reproducible
+	accounts := make([]string, 0, len(pending))
+	for account := range pending {
+		accounts = append(accounts, account)
+	}
+	sort.Strings(accounts)
+
+	var distributed uint64
+	for _, account := range accounts {
+		if err := s.setStatus(account, pending[account], models.RewardStatusDistributing, nil); err != nil {
+			return distributed, err
+		}
+
+		amount := amounts[account]
+		if err := s.token.TransferTokens(ctx, s.poolAccount, account, strconv.FormatUint(amount, 10)); err != nil {
+			if resetErr := s.setStatus(account, pending[account], models.RewardStatusPending, nil); resetErr != nil {
+				err = errors.Join(err, resetErr)
+			}
+			return distributed, fmt.Errorf("failed to distribute rewards to %s: %w", account, err)
+		}
+
+		now := time.Now()
+		if err := s.setStatus(account, pending[account], models.RewardStatusDistributed, &now); err != nil {
+			return distributed + amount, err
+		}
+		distributed += amount
+	}
+
+	return distributed, nil
+}
+
+// setStatus moves entries of an account to a status and saves the ledger
+func (s *RewardsService) setStatus(account string, ids []string, status models.RewardEntryStatus, distributedAt *time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	update := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		update[id] = true
+	}
+
+	entries := s.entries[account]
+	previous := append([]models.RewardEntry(nil), entries...)
+	for i := range entries {
+		if update[entries[i].ID] {
+			entries[i].Status = status
+			entries[i].DistributedAt = distributedAt
+		}
+	}
+
+	if err := s.save(); err != nil {
+		copy(entries, previous)
+		return err
+	}
+	return nil
+}
+
+// RunRewardDistribution periodically distributes pending rewards until the
+// context is cancelled
+func (s *RewardsService) RunRewardDistribution(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			distributed, err := s.DistributeRewards(ctx)
+			if err != nil {
+				log.Printf("reward distribution: %v", err)
+			}
+			if distributed > 0 {
+				log.Printf("reward distribution: distributed %d LMT", distributed)
+			}
+		}
+	}
+}
+
+// GetLedger retrieves the rewards ledger of an account
+func (s *RewardsService) GetLedger(ctx context.Context, account string) (*models.RewardsLedger, error) {
+	_, span := telemetry.Start(ctx, "RewardsService.GetLedger")
+	defer span.End()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ledger := &models.RewardsLedger{
+		Account: account,
+		Entries: append([]models.RewardEntry{}, s.entries[account]...),
+	}
+
+	for _, entry := range ledger.Entries {
+		ledger.TotalEarned += entry.Amount
+		if entry.Status == models.RewardStatusDistributed {
+			ledger.TotalDistributed += entry.Amount
+		} else {
+			ledger.Pending += entry.Amount
+		}
+	}
+
+	return ledger, nil
+}
+
+// save writes the rewards ledger to disk; callers hold the write lock
+func (s *RewardsService) save() error {
+	if s.path == "" {
+		return nil
+	}
+
+	state := rewardsState{
+		Evaluated: make([]string, 0, len(s.evaluated)),
+		Entries:   []models.RewardEntry{},
+	}
+	for bookingID := range s.evaluated {
+		state.Evaluated = append(state.Evaluated, bookingID)
+	}
+	sort.Strings(state.Evaluated)
+	for _, entries := range s.entries {
+		state.Entries = append(state.Entries, entries...)
+	}
+	sort.Slice(state.Entries, func(i, j int) bool {
+		return state.Entries[i].ID < state.Entries[j].ID
+	})
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode rewards ledger: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".rewards-*")
+	if err != nil {
+		return fmt.Errorf("failed to save rewards ledger: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save rewards ledger: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save rewards ledger: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to save rewards ledger: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to save rewards ledger: %w", err)
+	}
+	return nil
+}
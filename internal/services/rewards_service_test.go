@@ -0,0 +1,212 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"logistics-marketplace/internal/models"
+)
+
+// fakeBookingRecords serves recorded bookings and their tracking history
+type fakeBookingRecords struct {
+	bookings map[string]*models.ConfirmedBooking
+	tracking map[string][]models.ShipmentStatusUpdate
+}
+
+func (r *fakeBookingRecords) GetBookingRecord(bookingID string) (*models.ConfirmedBooking, error) {
+	booking, ok := r.bookings[bookingID]
+	if !ok {
+		return nil, fmt.Errorf("booking %s does not exist", bookingID)
+	}
+	return booking, nil
+}
+
+func (r *fakeBookingRecords) GetTrackingHistory(bookingID string) ([]models.ShipmentStatusUpdate, error) {
+	return r.tracking[bookingID], nil
+}
+
+// fakeRewardsToken pays out of a pool balance
+type fakeRewardsToken struct {
+	pool       uint64
+	paid       map[string]uint64
+	failFor    string
+	onTransfer func()
+}
+
+func (t *fakeRewardsToken) GetTokenBalance(ctx context.Context, account string) (string, error) {
+	return strconv.FormatUint(t.pool, 10), nil
+}
+
+func (t *fakeRewardsToken) TransferTokens(ctx context.Context, fromAccount, toAccount string, amount string) error {
+	if t.onTransfer != nil {
+		t.onTransfer()
+	}
+	if toAccount == t.failFor {
+		return errors.New("transaction failed")
+	}
+	value, err := strconv.ParseUint(amount, 10, 64)
+	if err != nil {
+		return err
+	}
+	t.pool -= value
+	t.paid[toAccount] += value
+	return nil
+}
+
+var rewardsConfirmed = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// deliveredBooking records a booking that departed and arrived after the given delays
+func deliveredBooking(records *fakeBookingRecords, bookingID string, departureDelay, arrivalDelay time.Duration, documentsDelay time.Duration) {
+	submitted := rewardsConfirmed.Add(-24 * time.Hour).Add(documentsDelay)
+	records.bookings[bookingID] = &models.ConfirmedBooking{
+		BookingID:          bookingID,
+		ProviderAddress:    "GPROVIDER",
+		CustomerAddress:    "GCUSTOMER",
+		ConfirmedDeparture: rewardsConfirmed,
+		ConfirmedArrival:   rewardsConfirmed.Add(48 * time.Hour),
+		Documents: []models.DocumentSubmission{
+			{DocumentType: "COMMERCIAL_INVOICE", Deadline: rewardsConfirmed.Add(-24 * time.Hour), SubmittedAt: &submitted},
+		},
+	}
+	records.tracking[bookingID] = []models.ShipmentStatusUpdate{
+		{Status: "PICKED_UP", Timestamp: rewardsConfirmed.Add(-time.Hour)},
+		{Status: "DEPARTED", Timestamp: rewardsConfirmed.Add(departureDelay)},
+		{Status: "ARRIVED", Timestamp: rewardsConfirmed.Add(48*time.Hour + arrivalDelay)},
+		{Status: "DELIVERED", Timestamp: rewardsConfirmed.Add(50*time.Hour + arrivalDelay)},
+	}
+}
+
+func newTestRewardsService(t *testing.T, path string) (*RewardsService, *fakeBookingRecords, *fakeRewardsToken) {
+	records := &fakeBookingRecords{
+		bookings: make(map[string]*models.ConfirmedBooking),
+		tracking: make(map[string][]models.ShipmentStatusUpdate),
+	}
+	token := &fakeRewardsToken{pool: 1_000, paid: make(map[string]uint64)}
+	s, err := NewRewardsService(token, records, "GPOOL", DefaultRewardRules, path)
+	require.NoError(t, err)
+	return s, records, token
+}
+
+func TestEvaluateBookingUsesRecordedPerformance(t *testing.T) {
+	tests := []struct {
+		name           string
+		departureDelay time.Duration
+		arrivalDelay   time.Duration
+		documentsDelay time.Duration
+		rules          []models.RewardRuleType
+	}{
+		{name: "on time", rules: []models.RewardRuleType{models.RewardOnTimeDeparture, models.RewardOnTimeArrival, models.RewardOnTimeDocuments}},
+		{name: "within tolerance", departureDelay: 2 * time.Hour, arrivalDelay: 6 * time.Hour, rules: []models.RewardRuleType{models.RewardOnTimeDeparture, models.RewardOnTimeArrival, models.RewardOnTimeDocuments}},
+		{name: "late departure", departureDelay: 3 * time.Hour, rules: []models.RewardRuleType{models.RewardOnTimeArrival, models.RewardOnTimeDocuments}},
+		{name: "late arrival and documents", arrivalDelay: 7 * time.Hour, documentsDelay: time.Minute, rules: []models.RewardRuleType{models.RewardOnTimeDeparture}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, records, _ := newTestRewardsService(t, "")
+			deliveredBooking(records, "B1", tt.departureDelay, tt.arrivalDelay, tt.documentsDelay)
+
+			earned, err := s.EvaluateBooking(context.Background(), "B1")
+			require.NoError(t, err)
+
+			var rules []models.RewardRuleType
+			for _, entry := range earned {
+				rules = append(rules, entry.Rule)
+				assert.Equal(t, models.RewardStatusPending, entry.Status)
+			}
+			assert.Equal(t, tt.rules, rules)
+		})
+	}
+}
+
+func TestEvaluateBookingStates(t *testing.T) {
+	s, records, _ := newTestRewardsService(t, "")
+	deliveredBooking(records, "B1", 0, 0, 0)
+	deliveredBooking(records, "IN_TRANSIT", 0, 0, 0)
+	records.tracking["IN_TRANSIT"] = records.tracking["IN_TRANSIT"][:2]
+
+	_, err := s.EvaluateBooking(context.Background(), "IN_TRANSIT")
+	assert.ErrorIs(t, err, ErrBookingNotDelivered)
+
+	_, err = s.EvaluateBooking(context.Background(), "UNKNOWN")
+	assert.ErrorContains(t, err, "failed to get booking")
+
+	_, err = s.EvaluateBooking(context.Background(), "B1")
+	require.NoError(t, err)
+	_, err = s.EvaluateBooking(context.Background(), "B1")
+	assert.ErrorIs(t, err, ErrBookingEvaluated)
+}
+
+func TestRewardsLedgerSurvivesRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rewards", "rewards.json")
+	s, records, _ := newTestRewardsService(t, path)
+	deliveredBooking(records, "B1", 0, 0, 0)
+
+	_, err := s.EvaluateBooking(context.Background(), "B1")
+	require.NoError(t, err)
+	_, err = s.DistributeRewards(context.Background())
+	require.NoError(t, err)
+
+	reopened, records, _ := newTestRewardsService(t, path)
+	deliveredBooking(records, "B1", 0, 0, 0)
+
+	_, err = reopened.EvaluateBooking(context.Background(), "B1")
+	assert.ErrorIs(t, err, ErrBookingEvaluated)
+
+	ledger, err := reopened.GetLedger(context.Background(), "GPROVIDER")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(150), ledger.TotalDistributed)
+	assert.Zero(t, ledger.Pending)
+}
+
+func TestDistributeRewards(t *testing.T) {
+	s, records, token := newTestRewardsService(t, "")
+	deliveredBooking(records, "B1", 0, 0, 0)
+	_, err := s.EvaluateBooking(context.Background(), "B1")
+	require.NoError(t, err)
+
+	// Transfers run without the ledger lock
+	token.onTransfer = func() {
+		_, err := s.GetLedger(context.Background(), "GPROVIDER")
+		assert.NoError(t, err)
+	}
+
+	// A failed transfer leaves the account's rewards pending
+	token.failFor = "GPROVIDER"
+	distributed, err := s.DistributeRewards(context.Background())
+	assert.ErrorContains(t, err, "failed to distribute rewards to GPROVIDER")
+	assert.Equal(t, uint64(25), distributed)
+
+	ledger, err := s.GetLedger(context.Background(), "GPROVIDER")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(150), ledger.Pending)
+	for _, entry := range ledger.Entries {
+		assert.Equal(t, models.RewardStatusPending, entry.Status)
+	}
+
+	token.failFor = ""
+	distributed, err = s.DistributeRewards(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, uint64(150), distributed)
+	assert.Equal(t, map[string]uint64{"GCUSTOMER": 25, "GPROVIDER": 150}, token.paid)
+
+	// Nothing is paid twice
+	distributed, err = s.DistributeRewards(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, distributed)
+
+	// An underfunded pool pays nobody
+	deliveredBooking(records, "B2", 0, 0, 0)
+	_, err = s.EvaluateBooking(context.Background(), "B2")
+	require.NoError(t, err)
+	token.pool = 100
+	_, err = s.DistributeRewards(context.Background())
+	assert.ErrorContains(t, err, "100 LMT, 175 LMT pending")
+	assert.Equal(t, map[string]uint64{"GCUSTOMER": 25, "GPROVIDER": 150}, token.paid)
+}
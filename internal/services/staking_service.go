@@ -0,0 +1,172 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"logistics-marketplace/internal/models"
+	"logistics-marketplace/internal/stellar"
+	"logistics-marketplace/internal/telemetry"
+)
+
+// StakingService handles provider stakes and SLA disputes on the marketplace contract
+type StakingService struct {
+	marketplaceContract *stellar.Contract
+}
+
+// NewStakingService creates a new StakingService instance
+func NewStakingService(marketplaceContract *stellar.Contract) *StakingService {
+	return &StakingService{
+		marketplaceContract: marketplaceContract,
+	}
+}
+
+// GetStake retrieves the stake of a provider
+func (s *StakingService) GetStake(ctx context.Context, providerAddress string) (*models.ProviderStake, error) {
+	_, span := telemetry.Start(ctx, "StakingService.GetStake")
+	defer span.End()
+
+	stake, err := s.marketplaceContract.GetStake(providerAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stake: %w", err)
+	}
+
+	return &stake, nil
+}
+
+// Stake bonds LMT from a provider
+func (s *StakingService) Stake(ctx context.Context, providerAddress string, amount uint64) (*models.ProviderStake, error) {
+	ctx, span := telemetry.Start(ctx, "StakingService.Stake")
+	defer span.End()
+
+	if amount == 0 {
+		return nil, fmt.Errorf("stake amount must be positive")
+	}
+
+	if err := s.marketplaceContract.Stake(providerAddress, amount); err != nil {
+		return nil, fmt.Errorf("failed to bond stake: %w", err)
+	}
+
+	return s.GetStake(ctx, providerAddress)
+}
+
+// Unstake starts unbonding stake not required by the provider's active listings
+func (s *StakingService) Unstake(ctx context.Context, providerAddress string, amount uint64) (*models.ProviderStake, error) {
+	ctx, span := telemetry.Start(ctx, "StakingService.Unstake")
+	defer span.End()
+
+	stake, err := s.GetStake(ctx, providerAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	if amount == 0 || amount > stake.Bonded {
+		return nil, fmt.Errorf("invalid unbonding amount")
+	}
+
+	if stake.Bonded-amount < stake.RequiredStake {
+		return nil, fmt.Errorf("stake would fall below %d LMT required by active listings", stake.RequiredStake)
+	}
+
+	if err := s.marketplaceContract.Unstake(providerAddress, amount); err != nil {
+		return nil, fmt.Errorf("failed to unbond stake: %w", err)
+	}
+
+	return s.GetStake(ctx, providerAddress)
+}
+
+// WithdrawUnbonded pays out stake whose unbonding period has ended
+func (s *StakingService) WithdrawUnbonded(ctx context.Context, providerAddress string) (*models.ProviderStake, error) {
+	ctx, span := telemetry.Start(ctx, "StakingService.WithdrawUnbonded")
+	defer span.End()
+
+	stake, err := s.GetStake(ctx, providerAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	if stake.OpenDisputes > 0 && stake.LockedUntil != nil && time.Now().Before(*stake.LockedUntil) {
+		return nil, fmt.Errorf("stake is locked by %d open disputes until %s", stake.OpenDisputes, stake.LockedUntil.Format(time.RFC3339))
+	}
+
+	if err := s.marketplaceContract.WithdrawUnbonded(providerAddress); err != nil {
+		return nil, fmt.Errorf("failed to withdraw unbonded stake: %w", err)
+	}
+
+	return s.GetStake(ctx, providerAddress)
+}
+
+// ConfirmSchedule records the schedule a provider commits to, which late
+// deliveries are measured against. A booking's schedule is confirmed once.
+func (s *StakingService) ConfirmSchedule(ctx context.Context, bookingID string, providerAddress string, req *models.ScheduleConfirmationRequest) error {
+	_, span := telemetry.Start(ctx, "StakingService.ConfirmSchedule")
+	defer span.End()
+
+	if req.Delivery.Before(req.Arrival) || req.Arrival.Before(req.Departure) || req.Departure.Before(req.Pickup) {
+		return fmt.Errorf("confirmed schedule is out of order")
+	}
+	if req.Pickup.Before(time.Now()) {
+		return fmt.Errorf("confirmed schedule is in the past")
+	}
+
+	err := s.marketplaceContract.ConfirmBookingSchedule(
+		bookingID,
+		providerAddress,
+		req.Pickup.Unix(),
+		req.Departure.Unix(),
+		req.Arrival.Unix(),
+		req.Delivery.Unix(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to confirm schedule: %w", err)
+	}
+
+	return nil
+}
+
+// RaiseDispute opens a dispute against the provider of a booking
+func (s *StakingService) RaiseDispute(ctx context.Context, bookingID string, customerAddress string, reason string) (*models.MarketplaceDispute, error) {
+	ctx, span := telemetry.Start(ctx, "StakingService.RaiseDispute")
+	defer span.End()
+
+	if err := s.marketplaceContract.RaiseDispute(bookingID, customerAddress, reason); err != nil {
+		return nil, fmt.Errorf("failed to raise dispute: %w", err)
+	}
+
+	return s.GetDispute(ctx, bookingID)
+}
+
+// ResolveDispute rules on an open dispute, slashing the provider if it is upheld
+func (s *StakingService) ResolveDispute(ctx context.Context, bookingID string, arbiterAddress string, upheld bool) (*models.MarketplaceDispute, error) {
+	ctx, span := telemetry.Start(ctx, "StakingService.ResolveDispute")
+	defer span.End()
+
+	dispute, err := s.GetDispute(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	if dispute.Status != models.DisputeStatusOpen {
+		return nil, fmt.Errorf("dispute for booking %s is already resolved", bookingID)
+	}
+
+	if err := s.marketplaceContract.ResolveDispute(bookingID, arbiterAddress, upheld); err != nil {
+		return nil, fmt.Errorf("failed to resolve dispute: %w", err)
+	}
+
+	return s.GetDispute(ctx, bookingID)
+}
+
+// GetDispute retrieves the dispute raised for a booking
+func (s *StakingService) GetDispute(ctx context.Context, bookingID string) (*models.MarketplaceDispute, error) {
+	_, span := telemetry.Start(ctx, "StakingService.GetDispute")
+	defer span.End()
+
+	dispute, err := s.marketplaceContract.GetDispute(bookingID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dispute: %w", err)
+	}
+
+	return &dispute, nil
+}
@@ -1,51 +1,86 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"time"
 
+	"logistics-marketplace/internal/fabric"
+	"logistics-marketplace/internal/ledger"
 	"logistics-marketplace/internal/models"
 	"logistics-marketplace/internal/stellar"
+	"logistics-marketplace/internal/telemetry"
 )
 
+// fabricShipmentStatuses maps tracking statuses to logistics chaincode statuses
+var fabricShipmentStatuses = map[string]string{
+	"BOOKED":     "Booked",
+	"PICKED_UP":  "PickedUp",
+	"IN_TRANSIT": "InTransit",
+	"ARRIVED":    "Arrived",
+	"DELIVERED":  "Delivered",
+	"CANCELLED":  "Cancelled",
+}
+
 // TrackingService handles shipment tracking and routing operations
 type TrackingService struct {
-	txManager    *stellar.TransactionManager
+	ledger       ledger.LedgerBackend
 	tokenManager *stellar.TokenManager
+	fabric       *fabric.LogisticsClient // Drives the chaincode shipment lifecycle instead of the ledger when set
 }
 
 // NewTrackingService creates a new TrackingService instance
-func NewTrackingService(txManager *stellar.TransactionManager, tokenManager *stellar.TokenManager) *TrackingService {
+func NewTrackingService(ledgerBackend ledger.LedgerBackend, tokenManager *stellar.TokenManager) *TrackingService {
 	return &TrackingService{
-		txManager:    txManager,
+		ledger:       ledgerBackend,
 		tokenManager: tokenManager,
 	}
 }
 
+// UseFabric moves shipments through the logistics chaincode's shipment
+// lifecycle as tracking events are recorded, and reads their tracking history
+// from the chaincode. Shipments must be booked on the chaincode under their
+// booking ID.
+func (s *TrackingService) UseFabric(client *fabric.LogisticsClient) {
+	s.fabric = client
+}
+
 // AddTrackingEvent adds a new tracking event for a shipment
-func (s *TrackingService) AddTrackingEvent(event *models.TrackingEvent) error {
+func (s *TrackingService) AddTrackingEvent(ctx context.Context, event *models.TrackingEvent) error {
+	ctx, span := telemetry.Start(ctx, "TrackingService.AddTrackingEvent")
+	defer span.End()
+
 	if err := s.validateTrackingEvent(event); err != nil {
 		return fmt.Errorf("invalid tracking event: %w", err)
 	}
 
-	// Update shipment status on blockchain
-	result, err := s.txManager.UpdateShipmentStatus(
-		event.BookingID,
-		event.Location,
-		event.Status,
-		event.Description,
-	)
+	if s.fabric != nil {
+		if err := s.updateFabricShipment(event); err != nil {
+			return err
+		}
+	}
+
+	// Record status update on the ledger
+	receipt, err := s.ledger.RecordTrackingEvent(ctx, &ledger.TrackingEventRecord{
+		BookingID:   event.BookingID,
+		Location:    event.Location,
+		Status:      event.Status,
+		Description: event.Description,
+	})
 	if err != nil {
 		return fmt.Errorf("failed to update tracking status: %w", err)
 	}
 
-	event.ID = result.TxID
-	event.Timestamp = time.Now()
+	event.ID = receipt.TxID
+	event.Timestamp = receipt.RecordedAt
 	return nil
 }
 
 // AddTransshipmentPoint adds a new transshipment point to the route
-func (s *TrackingService) AddTransshipmentPoint(point *models.TransshipmentPoint) error {
+func (s *TrackingService) AddTransshipmentPoint(ctx context.Context, point *models.TransshipmentPoint) error {
+	ctx, span := telemetry.Start(ctx, "TrackingService.AddTransshipmentPoint")
+	defer span.End()
+
 	if err := s.validateTransshipmentPoint(point); err != nil {
 		return fmt.Errorf("invalid transshipment point: %w", err)
 	}
@@ -59,7 +94,7 @@ func (s *TrackingService) AddTransshipmentPoint(point *models.TransshipmentPoint
 		Timestamp:   time.Now(),
 	}
 
-	if err := s.AddTrackingEvent(event); err != nil {
+	if err := s.AddTrackingEvent(ctx, event); err != nil {
 		return fmt.Errorf("failed to add transshipment event: %w", err)
 	}
 
@@ -68,12 +103,16 @@ func (s *TrackingService) AddTransshipmentPoint(point *models.TransshipmentPoint
 
 // UpdateRouting updates the routing information for a shipment
 func (s *TrackingService) UpdateRouting(
+	ctx context.Context,
 	bookingID string,
 	transshipmentPoints []models.TransshipmentPoint,
 ) error {
+	ctx, span := telemetry.Start(ctx, "TrackingService.UpdateRouting")
+	defer span.End()
+
 	for _, point := range transshipmentPoints {
 		point.BookingID = bookingID
-		if err := s.AddTransshipmentPoint(&point); err != nil {
+		if err := s.AddTransshipmentPoint(ctx, &point); err != nil {
 			return fmt.Errorf("failed to update routing: %w", err)
 		}
 	}
@@ -82,7 +121,14 @@ func (s *TrackingService) UpdateRouting(
 }
 
 // GetShipmentTracking retrieves tracking history for a shipment
-func (s *TrackingService) GetShipmentTracking(bookingID string) ([]models.TrackingEvent, error) {
+func (s *TrackingService) GetShipmentTracking(ctx context.Context, bookingID string) ([]models.TrackingEvent, error) {
+	_, span := telemetry.Start(ctx, "TrackingService.GetShipmentTracking")
+	defer span.End()
+
+	if s.fabric != nil {
+		return s.getFabricShipmentTracking(bookingID)
+	}
+
 	// In a real implementation, this would query a database
 	// This is a placeholder that would be replaced with actual storage logic
 	return []models.TrackingEvent{}, nil
@@ -117,6 +163,52 @@ func (s *TrackingService) CalculateETA(
 
 // Helper functions
 
+// updateFabricShipment moves the chaincode shipment to the status of lifecycle tracking events
+func (s *TrackingService) updateFabricShipment(event *models.TrackingEvent) error {
+	status, ok := fabricShipmentStatuses[event.Status]
+	if !ok {
+		return nil
+	}
+
+	if err := s.fabric.UpdateShipmentStatus(event.BookingID, status); err != nil {
+		return fmt.Errorf("failed to update Fabric shipment %s: %w", event.BookingID, err)
+	}
+	return nil
+}
+
+func (s *TrackingService) getFabricShipmentTracking(bookingID string) ([]models.TrackingEvent, error) {
+	history, err := s.fabric.GetShipmentHistory(bookingID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shipment history: %w", err)
+	}
+
+	// Only versions that changed the status are tracking events
+	events := []models.TrackingEvent{}
+	lastStatus := ""
+	for _, entry := range history {
+		if entry.Shipment == nil || entry.Shipment.Status == lastStatus {
+			continue
+		}
+		lastStatus = entry.Shipment.Status
+
+		timestamp, _ := time.Parse(time.RFC3339, entry.Timestamp)
+		event := models.TrackingEvent{
+			ID:        entry.TxID,
+			BookingID: bookingID,
+			Status:    lastStatus,
+			Timestamp: timestamp,
+		}
+		for trackingStatus, fabricStatus := range fabricShipmentStatuses {
+			if fabricStatus == lastStatus {
+				event.Status = trackingStatus
+			}
+		}
+		events = append(events, event)
+	}
+
+	return events, nil
+}
+
 func (s *TrackingService) validateTrackingEvent(event *models.TrackingEvent) error {
 	if event.BookingID == "" {
 		return fmt.Errorf("booking ID is required")
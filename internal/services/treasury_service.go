@@ -0,0 +1,237 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"logistics-marketplace/internal/models"
+	"logistics-marketplace/internal/telemetry"
+)
+
+// TreasuryContract is the governance contract client holding the treasury
+type TreasuryContract interface {
+	TreasuryBalance() (uint64, error)
+	DepositToTreasury(from string, amount uint64) error
+	ListBudgetCategories() ([]models.TreasuryBudgetCategory, error)
+	GetGrant(grantID string) (models.TreasuryGrant, error)
+	GetDisbursements(from, to int64) ([]models.TreasuryDisbursement, error)
+	ClaimStreamedFunds(grantID string, grantee string) error
+	ApproveMilestone(grantID string, index uint32, reviewer string) error
+}
+
+// TreasuryService handles the governance-managed treasury
+type TreasuryService struct {
+	governanceContract TreasuryContract
+}
+
+// NewTreasuryService creates a new TreasuryService instance
+func NewTreasuryService(governanceContract TreasuryContract) *TreasuryService {
+	return &TreasuryService{
+		governanceContract: governanceContract,
+	}
+}
+
+// GetSpendReport summarizes treasury spending between two points in time
+func (s *TreasuryService) GetSpendReport(ctx context.Context, from, to time.Time) (*models.TreasurySpendReport, error) {
+	ctx, span := telemetry.Start(ctx, "TreasuryService.GetSpendReport")
+	defer span.End()
+
+	if to.Before(from) {
+		return nil, fmt.Errorf("report end must not be before report start")
+	}
+
+	balance, err := s.governanceContract.TreasuryBalance()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get treasury balance: %w", err)
+	}
+
+	categories, err := s.ListBudgetCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	disbursements, err := s.governanceContract.GetDisbursements(from.Unix(), to.Unix())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get treasury disbursements: %w", err)
+	}
+
+	report := &models.TreasurySpendReport{
+		From:            from,
+		To:              to,
+		Balance:         balance,
+		SpentByCategory: make(map[string]uint64),
+		Categories:      categories,
+		Disbursements:   disbursements,
+	}
+
+	for _, disbursement := range disbursements {
+		report.TotalSpent += disbursement.Amount
+		report.SpentByCategory[disbursement.Category] += disbursement.Amount
+	}
+
+	return report, nil
+}
+
+// DepositToTreasury moves tokens from a holder into the treasury
+func (s *TreasuryService) DepositToTreasury(ctx context.Context, fromAddress string, amount uint64) (*models.TreasuryDeposit, error) {
+	_, span := telemetry.Start(ctx, "TreasuryService.DepositToTreasury")
+	defer span.End()
+
+	if amount == 0 {
+		return nil, fmt.Errorf("deposit amount must be greater than 0")
+	}
+
+	if err := s.governanceContract.DepositToTreasury(fromAddress, amount); err != nil {
+		return nil, fmt.Errorf("failed to deposit to treasury: %w", err)
+	}
+
+	balance, err := s.governanceContract.TreasuryBalance()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get treasury balance: %w", err)
+	}
+
+	return &models.TreasuryDeposit{
+		From:    fromAddress,
+		Amount:  amount,
+		Balance: balance,
+	}, nil
+}
+
+// ListBudgetCategories retrieves all treasury budget categories
+func (s *TreasuryService) ListBudgetCategories(ctx context.Context) ([]models.TreasuryBudgetCategory, error) {
+	_, span := telemetry.Start(ctx, "TreasuryService.ListBudgetCategories")
+	defer span.End()
+
+	categories, err := s.governanceContract.ListBudgetCategories()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list budget categories: %w", err)
+	}
+
+	for i := range categories {
+		categories[i].Remaining = categories[i].Budget - categories[i].Allocated
+	}
+
+	return categories, nil
+}
+
+// GetGrant retrieves a treasury grant by ID
+func (s *TreasuryService) GetGrant(ctx context.Context, grantID string) (*models.TreasuryGrant, error) {
+	_, span := telemetry.Start(ctx, "TreasuryService.GetGrant")
+	defer span.End()
+
+	grant, err := s.governanceContract.GetGrant(grantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get grant: %w", err)
+	}
+
+	return &grant, nil
+}
+
+// ClaimStreamedFunds releases the vested part of a streaming grant to its grantee
+func (s *TreasuryService) ClaimStreamedFunds(ctx context.Context, grantID string, granteeAddress string) (*models.TreasuryGrant, error) {
+	ctx, span := telemetry.Start(ctx, "TreasuryService.ClaimStreamedFunds")
+	defer span.End()
+
+	grant, err := s.GetGrant(ctx, grantID)
+	if err != nil {
+		return nil, err
+	}
+
+	if grant.Disbursement != models.DisbursementStreaming {
+		return nil, fmt.Errorf("grant %s is not streamed", grantID)
+	}
+
+	if grant.Grantee != granteeAddress {
+		return nil, fmt.Errorf("only the grantee can claim streamed funds")
+	}
+
+	if err := s.governanceContract.ClaimStreamedFunds(grantID, granteeAddress); err != nil {
+		return nil, fmt.Errorf("failed to claim streamed funds: %w", err)
+	}
+
+	return s.GetGrant(ctx, grantID)
+}
+
+// ApproveMilestone releases a milestone tranche of a grant
+func (s *TreasuryService) ApproveMilestone(ctx context.Context, grantID string, index int, reviewerAddress string) (*models.TreasuryGrant, error) {
+	ctx, span := telemetry.Start(ctx, "TreasuryService.ApproveMilestone")
+	defer span.End()
+
+	grant, err := s.GetGrant(ctx, grantID)
+	if err != nil {
+		return nil, err
+	}
+
+	if grant.Disbursement != models.DisbursementMilestone {
+		return nil, fmt.Errorf("grant %s is not milestone based", grantID)
+	}
+
+	if grant.Reviewer != reviewerAddress {
+		return nil, fmt.Errorf("only the grant reviewer can approve milestones")
+	}
+
+	if index < 0 || index >= len(grant.Milestones) {
+		return nil, fmt.Errorf("milestone %d does not exist", index)
+	}
+
+	if grant.Milestones[index].Released {
+		return nil, fmt.Errorf("milestone %d already released", index)
+	}
+
+	if err := s.governanceContract.ApproveMilestone(grantID, uint32(index), reviewerAddress); err != nil {
+		return nil, fmt.Errorf("failed to approve milestone: %w", err)
+	}
+
+	return s.GetGrant(ctx, grantID)
+}
+
+// ValidateFundsAllocation checks the proposal data of a FUNDS_ALLOCATION proposal
+func ValidateFundsAllocation(data []byte) error {
+	var allocation models.FundsAllocationData
+	if err := json.Unmarshal(data, &allocation); err != nil {
+		return fmt.Errorf("invalid funds allocation data: %w", err)
+	}
+
+	if allocation.Category == "" {
+		return fmt.Errorf("budget category is required")
+	}
+
+	switch allocation.Kind {
+	case models.FundsAllocationSetBudget:
+		return nil
+	case models.FundsAllocationAwardGrant:
+	default:
+		return fmt.Errorf("invalid funds allocation kind: %s", allocation.Kind)
+	}
+
+	if allocation.Grantee == "" {
+		return fmt.Errorf("grantee is required")
+	}
+	if allocation.Amount == 0 {
+		return fmt.Errorf("grant amount must be greater than 0")
+	}
+
+	switch allocation.Disbursement {
+	case models.DisbursementStreaming:
+		if allocation.StreamEnd <= allocation.StreamStart {
+			return fmt.Errorf("stream end must be after stream start")
+		}
+	case models.DisbursementMilestone:
+		if allocation.Reviewer == "" {
+			return fmt.Errorf("milestone reviewer is required")
+		}
+		var total uint64
+		for _, milestone := range allocation.Milestones {
+			total += milestone.Amount
+		}
+		if total != allocation.Amount {
+			return fmt.Errorf("milestone amounts must add up to the grant amount")
+		}
+	default:
+		return fmt.Errorf("invalid disbursement type: %s", allocation.Disbursement)
+	}
+
+	return nil
+}
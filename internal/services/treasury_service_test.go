@@ -0,0 +1,225 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"logistics-marketplace/internal/models"
+)
+
+// fakeTreasuryContract keeps treasury state the way the governance contract does
+type fakeTreasuryContract struct {
+	balance       uint64
+	balances      map[string]uint64
+	categories    []models.TreasuryBudgetCategory
+	grants        map[string]models.TreasuryGrant
+	disbursements []models.TreasuryDisbursement
+}
+
+func (c *fakeTreasuryContract) TreasuryBalance() (uint64, error) {
+	return c.balance, nil
+}
+
+func (c *fakeTreasuryContract) DepositToTreasury(from string, amount uint64) error {
+	if c.balances[from] < amount {
+		return errors.New("insufficient balance")
+	}
+	c.balances[from] -= amount
+	c.balance += amount
+	return nil
+}
+
+func (c *fakeTreasuryContract) ListBudgetCategories() ([]models.TreasuryBudgetCategory, error) {
+	return append([]models.TreasuryBudgetCategory(nil), c.categories...), nil
+}
+
+func (c *fakeTreasuryContract) GetGrant(grantID string) (models.TreasuryGrant, error) {
+	grant, ok := c.grants[grantID]
+	if !ok {
+		return models.TreasuryGrant{}, fmt.Errorf("grant %s does not exist", grantID)
+	}
+	return grant, nil
+}
+
+func (c *fakeTreasuryContract) GetDisbursements(from, to int64) ([]models.TreasuryDisbursement, error) {
+	var disbursements []models.TreasuryDisbursement
+	for _, disbursement := range c.disbursements {
+		if unix := disbursement.Timestamp.Unix(); unix >= from && unix <= to {
+			disbursements = append(disbursements, disbursement)
+		}
+	}
+	return disbursements, nil
+}
+
+func (c *fakeTreasuryContract) ClaimStreamedFunds(grantID string, grantee string) error {
+	grant := c.grants[grantID]
+	grant.Released = grant.Amount
+	c.grants[grantID] = grant
+	return nil
+}
+
+func (c *fakeTreasuryContract) ApproveMilestone(grantID string, index uint32, reviewer string) error {
+	grant := c.grants[grantID]
+	grant.Milestones[index].Released = true
+	grant.Released += grant.Milestones[index].Amount
+	c.grants[grantID] = grant
+	return nil
+}
+
+func newFakeTreasuryContract() *fakeTreasuryContract {
+	now := time.Now()
+	return &fakeTreasuryContract{
+		balance:  1_000,
+		balances: map[string]uint64{"GHOLDER": 500},
+		categories: []models.TreasuryBudgetCategory{
+			{Name: "DEVELOPMENT", Budget: 800, Allocated: 600, Spent: 150},
+			{Name: "MARKETING", Budget: 200, Allocated: 50, Spent: 50},
+		},
+		grants: map[string]models.TreasuryGrant{
+			"STREAM": {ID: "STREAM", Category: "DEVELOPMENT", Grantee: "GGRANTEE", Amount: 400, Disbursement: models.DisbursementStreaming},
+			"MILESTONE": {ID: "MILESTONE", Category: "DEVELOPMENT", Grantee: "GGRANTEE", Amount: 200, Released: 100, Disbursement: models.DisbursementMilestone,
+				Reviewer: "GREVIEWER", Milestones: []models.GrantMilestone{{Amount: 100, Released: true}, {Amount: 100}}},
+		},
+		disbursements: []models.TreasuryDisbursement{
+			{GrantID: "STREAM", Category: "DEVELOPMENT", Amount: 50, Timestamp: now.AddDate(0, 0, -40)},
+			{GrantID: "STREAM", Category: "DEVELOPMENT", Amount: 100, Timestamp: now.AddDate(0, 0, -10)},
+			{GrantID: "ADS", Category: "MARKETING", Amount: 50, Timestamp: now.AddDate(0, 0, -5)},
+		},
+	}
+}
+
+func TestTreasurySpendReport(t *testing.T) {
+	s := NewTreasuryService(newFakeTreasuryContract())
+	to := time.Now()
+
+	report, err := s.GetSpendReport(context.Background(), to.AddDate(0, 0, -30), to)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1_000), report.Balance)
+	assert.Equal(t, uint64(150), report.TotalSpent)
+	assert.Equal(t, map[string]uint64{"DEVELOPMENT": 100, "MARKETING": 50}, report.SpentByCategory)
+	assert.Len(t, report.Disbursements, 2)
+	require.Len(t, report.Categories, 2)
+	assert.Equal(t, uint64(200), report.Categories[0].Remaining)
+	assert.Equal(t, uint64(150), report.Categories[1].Remaining)
+
+	_, err = s.GetSpendReport(context.Background(), to, to.AddDate(0, 0, -1))
+	assert.ErrorContains(t, err, "report end must not be before report start")
+}
+
+func TestTreasuryDeposit(t *testing.T) {
+	contract := newFakeTreasuryContract()
+	s := NewTreasuryService(contract)
+
+	deposit, err := s.DepositToTreasury(context.Background(), "GHOLDER", 300)
+	require.NoError(t, err)
+	assert.Equal(t, &models.TreasuryDeposit{From: "GHOLDER", Amount: 300, Balance: 1_300}, deposit)
+	assert.Equal(t, uint64(200), contract.balances["GHOLDER"])
+
+	_, err = s.DepositToTreasury(context.Background(), "GHOLDER", 0)
+	assert.ErrorContains(t, err, "deposit amount must be greater than 0")
+
+	_, err = s.DepositToTreasury(context.Background(), "GHOLDER", 201)
+	assert.ErrorContains(t, err, "failed to deposit to treasury")
+	assert.Equal(t, uint64(1_300), contract.balance)
+}
+
+func TestTreasuryGrantDisbursement(t *testing.T) {
+	tests := []struct {
+		name    string
+		release func(s *TreasuryService) (*models.TreasuryGrant, error)
+		wantErr string
+	}{
+		{
+			name: "grantee claims stream",
+			release: func(s *TreasuryService) (*models.TreasuryGrant, error) {
+				return s.ClaimStreamedFunds(context.Background(), "STREAM", "GGRANTEE")
+			},
+		},
+		{
+			name: "stranger claims stream",
+			release: func(s *TreasuryService) (*models.TreasuryGrant, error) {
+				return s.ClaimStreamedFunds(context.Background(), "STREAM", "GSTRANGER")
+			},
+			wantErr: "only the grantee can claim streamed funds",
+		},
+		{
+			name: "claim on milestone grant",
+			release: func(s *TreasuryService) (*models.TreasuryGrant, error) {
+				return s.ClaimStreamedFunds(context.Background(), "MILESTONE", "GGRANTEE")
+			},
+			wantErr: "is not streamed",
+		},
+		{
+			name: "reviewer approves milestone",
+			release: func(s *TreasuryService) (*models.TreasuryGrant, error) {
+				return s.ApproveMilestone(context.Background(), "MILESTONE", 1, "GREVIEWER")
+			},
+		},
+		{
+			name: "grantee approves own milestone",
+			release: func(s *TreasuryService) (*models.TreasuryGrant, error) {
+				return s.ApproveMilestone(context.Background(), "MILESTONE", 1, "GGRANTEE")
+			},
+			wantErr: "only the grant reviewer can approve milestones",
+		},
+		{
+			name: "released milestone",
+			release: func(s *TreasuryService) (*models.TreasuryGrant, error) {
+				return s.ApproveMilestone(context.Background(), "MILESTONE", 0, "GREVIEWER")
+			},
+			wantErr: "milestone 0 already released",
+		},
+		{
+			name: "unknown milestone",
+			release: func(s *TreasuryService) (*models.TreasuryGrant, error) {
+				return s.ApproveMilestone(context.Background(), "MILESTONE", 2, "GREVIEWER")
+			},
+			wantErr: "milestone 2 does not exist",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewTreasuryService(newFakeTreasuryContract())
+
+			grant, err := tt.release(s)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, grant.Amount, grant.Released)
+		})
+	}
+}
+
+func TestValidateFundsAllocation(t *testing.T) {
+	tests := []struct {
+		name       string
+		allocation string
+		wantErr    string
+	}{
+		{name: "budget", allocation: `{"kind":"SET_BUDGET","category":"DEVELOPMENT","budget":100}`},
+		{name: "streaming grant", allocation: `{"kind":"AWARD_GRANT","category":"DEVELOPMENT","grantee":"G1","amount":10,"disbursement":"STREAMING","stream_start":1,"stream_end":2}`},
+		{name: "milestone grant", allocation: `{"kind":"AWARD_GRANT","category":"DEVELOPMENT","grantee":"G1","amount":10,"disbursement":"MILESTONE","reviewer":"G2","milestones":[{"amount":4},{"amount":6}]}`},
+		{name: "no category", allocation: `{"kind":"SET_BUDGET","budget":100}`, wantErr: "budget category is required"},
+		{name: "unknown kind", allocation: `{"kind":"SPEND","category":"DEVELOPMENT"}`, wantErr: "invalid funds allocation kind"},
+		{name: "empty stream", allocation: `{"kind":"AWARD_GRANT","category":"DEVELOPMENT","grantee":"G1","amount":10,"disbursement":"STREAMING","stream_start":2,"stream_end":2}`, wantErr: "stream end must be after stream start"},
+		{name: "milestones short", allocation: `{"kind":"AWARD_GRANT","category":"DEVELOPMENT","grantee":"G1","amount":10,"disbursement":"MILESTONE","reviewer":"G2","milestones":[{"amount":4}]}`, wantErr: "milestone amounts must add up to the grant amount"},
+		{name: "not json", allocation: `budget`, wantErr: "invalid funds allocation data"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateFundsAllocation([]byte(tt.allocation))
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
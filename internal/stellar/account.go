@@ -1,17 +1,21 @@
 package stellar
 
 import (
+	"context"
 	"fmt"
+	"net/http"
 
 	"github.com/stellar/go/clients/horizonclient"
 	"github.com/stellar/go/keypair"
 	"github.com/stellar/go/network"
 	"github.com/stellar/go/txnbuild"
+	"logistics-marketplace/internal/telemetry"
 )
 
 // AccountManager handles Stellar account operations
 type AccountManager struct {
 	client       *horizonclient.Client
+	httpClient   *http.Client
 	networkPassphrase string
 }
 
@@ -28,14 +32,31 @@ func NewAccountManager(isTestnet bool) *AccountManager {
 		networkPassphrase = network.PublicNetworkPassphrase
 	}
 
+	// Horizon requests are traced as client spans
+	httpClient := &http.Client{Transport: telemetry.Transport("horizon", http.DefaultTransport)}
+	client = &horizonclient.Client{
+		HorizonURL: client.HorizonURL,
+		HTTP:       httpClient,
+	}
+
 	return &AccountManager{
 		client:           client,
+		httpClient:       httpClient,
 		networkPassphrase: networkPassphrase,
 	}
 }
 
+// horizon returns a Horizon client tracing its requests under the span in
+// ctx; the shared client sends requests with a context of its own
+func (am *AccountManager) horizon(ctx context.Context) *horizonclient.Client {
+	return &horizonclient.Client{
+		HorizonURL: am.client.HorizonURL,
+		HTTP:       telemetry.WithParent(ctx, am.httpClient),
+	}
+}
+
 // CreateAccount generates a new Stellar account
-func (am *AccountManager) CreateAccount() (*keypair.Full, error) {
+func (am *AccountManager) CreateAccount(ctx context.Context) (*keypair.Full, error) {
 	// Generate new keypair
 	kp, err := keypair.Random()
 	if err != nil {
@@ -44,7 +65,12 @@ func (am *AccountManager) CreateAccount() (*keypair.Full, error) {
 
 	// For testnet, we can fund the account using friendbot
 	if am.networkPassphrase == network.TestNetworkPassphrase {
-		resp, err := http.Get("https://friendbot.stellar.org/?addr=" + kp.Address())
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://friendbot.stellar.org/?addr="+kp.Address(), nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fund account: %w", err)
+		}
+		friendbot := &http.Client{Transport: telemetry.Transport("friendbot", http.DefaultTransport)}
+		resp, err := friendbot.Do(req)
 		if err != nil {
 			return nil, fmt.Errorf("failed to fund account: %w", err)
 		}
@@ -55,8 +81,8 @@ func (am *AccountManager) CreateAccount() (*keypair.Full, error) {
 }
 
 // GetAccountDetails retrieves account information from the network
-func (am *AccountManager) GetAccountDetails(address string) (*horizonclient.Account, error) {
-	account, err := am.client.AccountDetail(horizonclient.AccountRequest{
+func (am *AccountManager) GetAccountDetails(ctx context.Context, address string) (*horizonclient.Account, error) {
+	account, err := am.horizon(ctx).AccountDetail(horizonclient.AccountRequest{
 		AccountID: address,
 	})
 	if err != nil {
@@ -67,8 +93,8 @@ func (am *AccountManager) GetAccountDetails(address string) (*horizonclient.Acco
 }
 
 // BuildTransaction creates a new transaction with the given operations
-func (am *AccountManager) BuildTransaction(sourceAccount string, operations ...txnbuild.Operation) (*txnbuild.Transaction, error) {
-	account, err := am.GetAccountDetails(sourceAccount)
+func (am *AccountManager) BuildTransaction(ctx context.Context, sourceAccount string, operations ...txnbuild.Operation) (*txnbuild.Transaction, error) {
+	account, err := am.GetAccountDetails(ctx, sourceAccount)
 	if err != nil {
 		return nil, err
 	}
@@ -105,8 +131,8 @@ func (am *AccountManager) SignTransaction(tx *txnbuild.Transaction, secretKey st
 }
 
 // SubmitTransaction submits a signed transaction to the network
-func (am *AccountManager) SubmitTransaction(tx *txnbuild.Transaction) (*horizonclient.Transaction, error) {
-	result, err := am.client.SubmitTransaction(tx)
+func (am *AccountManager) SubmitTransaction(ctx context.Context, tx *txnbuild.Transaction) (*horizonclient.Transaction, error) {
+	result, err := am.horizon(ctx).SubmitTransaction(tx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to submit transaction: %w", err)
 	}
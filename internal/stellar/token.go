@@ -1,6 +1,7 @@
 package stellar
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/stellar/go/clients/horizonclient"
@@ -25,7 +26,7 @@ func NewTokenManager(accountManager *AccountManager, tokenCode, issuerAccount st
 }
 
 // CreateToken issues a new token on the Stellar network
-func (tm *TokenManager) CreateToken(distributorAccount string) error {
+func (tm *TokenManager) CreateToken(ctx context.Context, distributorAccount string) error {
 	// Create trust line operation
 	trustLineOp := &txnbuild.ChangeTrust{
 		Line: txnbuild.CreditAsset{
@@ -36,7 +37,7 @@ func (tm *TokenManager) CreateToken(distributorAccount string) error {
 	}
 
 	// Build and submit trust line transaction
-	tx, err := tm.accountManager.BuildTransaction(distributorAccount, trustLineOp)
+	tx, err := tm.accountManager.BuildTransaction(ctx, distributorAccount, trustLineOp)
 	if err != nil {
 		return fmt.Errorf("failed to build trust line transaction: %w", err)
 	}
@@ -52,7 +53,7 @@ func (tm *TokenManager) CreateToken(distributorAccount string) error {
 	}
 
 	// Build and submit payment transaction
-	tx, err = tm.accountManager.BuildTransaction(tm.issuerAccount, paymentOp)
+	tx, err = tm.accountManager.BuildTransaction(ctx, tm.issuerAccount, paymentOp)
 	if err != nil {
 		return fmt.Errorf("failed to build payment transaction: %w", err)
 	}
@@ -61,7 +62,7 @@ func (tm *TokenManager) CreateToken(distributorAccount string) error {
 }
 
 // TransferTokens transfers tokens between accounts
-func (tm *TokenManager) TransferTokens(fromAccount, toAccount string, amount string) error {
+func (tm *TokenManager) TransferTokens(ctx context.Context, fromAccount, toAccount string, amount string) error {
 	paymentOp := &txnbuild.Payment{
 		Destination: toAccount,
 		Asset: txnbuild.CreditAsset{
@@ -72,7 +73,7 @@ func (tm *TokenManager) TransferTokens(fromAccount, toAccount string, amount str
 	}
 
 	// Build transaction
-	tx, err := tm.accountManager.BuildTransaction(fromAccount, paymentOp)
+	tx, err := tm.accountManager.BuildTransaction(ctx, fromAccount, paymentOp)
 	if err != nil {
 		return fmt.Errorf("failed to build transfer transaction: %w", err)
 	}
@@ -81,8 +82,8 @@ func (tm *TokenManager) TransferTokens(fromAccount, toAccount string, amount str
 }
 
 // GetTokenBalance retrieves the token balance for an account
-func (tm *TokenManager) GetTokenBalance(account string) (string, error) {
-	accountDetails, err := tm.accountManager.GetAccountDetails(account)
+func (tm *TokenManager) GetTokenBalance(ctx context.Context, account string) (string, error) {
+	accountDetails, err := tm.accountManager.GetAccountDetails(ctx, account)
 	if err != nil {
 		return "", fmt.Errorf("failed to get account details: %w", err)
 	}
@@ -97,7 +98,7 @@ func (tm *TokenManager) GetTokenBalance(account string) (string, error) {
 }
 
 // EstablishTrustLine creates a trust line for the token
-func (tm *TokenManager) EstablishTrustLine(account string) error {
+func (tm *TokenManager) EstablishTrustLine(ctx context.Context, account string) error {
 	trustLineOp := &txnbuild.ChangeTrust{
 		Line: txnbuild.CreditAsset{
 			Code:   tm.tokenCode,
@@ -107,7 +108,7 @@ func (tm *TokenManager) EstablishTrustLine(account string) error {
 	}
 
 	// Build and submit transaction
-	tx, err := tm.accountManager.BuildTransaction(account, trustLineOp)
+	tx, err := tm.accountManager.BuildTransaction(ctx, account, trustLineOp)
 	if err != nil {
 		return fmt.Errorf("failed to build trust line transaction: %w", err)
 	}
@@ -116,13 +117,13 @@ func (tm *TokenManager) EstablishTrustLine(account string) error {
 }
 
 // GetTokenTransactions retrieves token transfer history for an account
-func (tm *TokenManager) GetTokenTransactions(account string) ([]horizon.Transaction, error) {
+func (tm *TokenManager) GetTokenTransactions(ctx context.Context, account string) ([]horizon.Transaction, error) {
 	txRequest := horizonclient.TransactionRequest{
 		ForAccount: account,
 		Limit:      50,
 	}
 
-	txs, err := tm.accountManager.client.Transactions(txRequest)
+	txs, err := tm.accountManager.horizon(ctx).Transactions(txRequest)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get transactions: %w", err)
 	}
@@ -131,7 +132,7 @@ func (tm *TokenManager) GetTokenTransactions(account string) ([]horizon.Transact
 }
 
 // LockTokens implements token locking for escrow during booking process
-func (tm *TokenManager) LockTokens(account string, amount string, duration uint64) error {
+func (tm *TokenManager) LockTokens(ctx context.Context, account string, amount string, duration uint64) error {
 	// Create a claimable balance with time-based clawback
 	claimant := txnbuild.NewClaimant(account, &txnbuild.UnixTimePredicate{TimePoint: duration})
 	
@@ -145,7 +146,7 @@ func (tm *TokenManager) LockTokens(account string, amount string, duration uint6
 	}
 
 	// Build and submit transaction
-	tx, err := tm.accountManager.BuildTransaction(account, createClaimableBalance)
+	tx, err := tm.accountManager.BuildTransaction(ctx, account, createClaimableBalance)
 	if err != nil {
 		return fmt.Errorf("failed to build lock transaction: %w", err)
 	}
@@ -154,13 +155,13 @@ func (tm *TokenManager) LockTokens(account string, amount string, duration uint6
 }
 
 // UnlockTokens releases locked tokens after service completion
-func (tm *TokenManager) UnlockTokens(claimableBalanceID string, account string) error {
+func (tm *TokenManager) UnlockTokens(ctx context.Context, claimableBalanceID string, account string) error {
 	claimBalance := &txnbuild.ClaimClaimableBalance{
 		BalanceID: claimableBalanceID,
 	}
 
 	// Build and submit transaction
-	tx, err := tm.accountManager.BuildTransaction(account, claimBalance)
+	tx, err := tm.accountManager.BuildTransaction(ctx, account, claimBalance)
 	if err != nil {
 		return fmt.Errorf("failed to build unlock transaction: %w", err)
 	}
@@ -0,0 +1,140 @@
+// Package telemetry sets up structured JSON logs and OpenTelemetry tracing,
+// so a request can be followed from its access log line through the service
+// calls, ledger records and Horizon requests it made.
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Exporters of finished spans, chosen with OTEL_TRACES_EXPORTER
+const (
+	ExporterNone    = "none"    // Spans only correlate logs
+	ExporterConsole = "console" // Spans are written to stdout
+	ExporterOTLP    = "otlp"    // Spans are sent to a collector over OTLP/HTTP
+)
+
+// Config selects where logs and spans go
+type Config struct {
+	ServiceName string
+	Exporter    string     // One of the Exporter constants, ExporterNone when empty
+	LogLevel    slog.Level // Records below the level are dropped
+	LogOutput   io.Writer  // Stdout when nil
+}
+
+// ConfigFromEnv reads OTEL_TRACES_EXPORTER and LOG_LEVEL. The OTLP exporter
+// itself honours OTEL_EXPORTER_OTLP_ENDPOINT, which defaults to a collector
+// on localhost:4318.
+func ConfigFromEnv(serviceName string) (Config, error) {
+	cfg := Config{
+		ServiceName: serviceName,
+		Exporter:    strings.ToLower(os.Getenv("OTEL_TRACES_EXPORTER")),
+	}
+	if cfg.Exporter == "stdout" {
+		cfg.Exporter = ExporterConsole
+	}
+	if level := os.Getenv("LOG_LEVEL"); level != "" {
+		if err := cfg.LogLevel.UnmarshalText([]byte(level)); err != nil {
+			return cfg, fmt.Errorf("failed to parse LOG_LEVEL: %w", err)
+		}
+	}
+	return cfg, nil
+}
+
+// Setup installs the JSON logger as the default of both slog and the log
+// package, and a tracer provider exporting spans as configured. W3C trace
+// context and baggage are propagated. The returned function flushes the
+// pending spans.
+func Setup(ctx context.Context, cfg Config) (func(context.Context) error, error) {
+	output := cfg.LogOutput
+	if output == nil {
+		output = os.Stdout
+	}
+	slog.SetDefault(slog.New(NewLogHandler(output, cfg.LogLevel)))
+
+	res, err := resource.New(ctx,
+		resource.WithTelemetrySDK(),
+		resource.WithAttributes(semconv.ServiceName(cfg.ServiceName)),
+		resource.WithFromEnv(), // OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES win
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace resource: %w", err)
+	}
+
+	options := []sdktrace.TracerProviderOption{sdktrace.WithResource(res)}
+	switch cfg.Exporter {
+	case "", ExporterNone:
+	case ExporterConsole:
+		exporter, err := stdouttrace.New()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create console exporter: %w", err)
+		}
+		options = append(options, sdktrace.WithBatcher(exporter))
+	case ExporterOTLP:
+		exporter, err := otlptracehttp.New(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+		}
+		options = append(options, sdktrace.WithBatcher(exporter))
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q", cfg.Exporter)
+	}
+
+	provider := sdktrace.NewTracerProvider(options...)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		slog.Error("telemetry failure", "error", err)
+	}))
+
+	return provider.Shutdown, nil
+}
+
+// LogHandler writes JSON records, adding the trace and span IDs of the
+// span in the record's context
+type LogHandler struct {
+	slog.Handler
+}
+
+// NewLogHandler creates a LogHandler writing records of at least level to w
+func NewLogHandler(w io.Writer, level slog.Level) *LogHandler {
+	return &LogHandler{Handler: slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level})}
+}
+
+// Handle adds trace_id and span_id before writing the record
+func (h *LogHandler) Handle(ctx context.Context, record slog.Record) error {
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		record.AddAttrs(
+			slog.String("trace_id", spanContext.TraceID().String()),
+			slog.String("span_id", spanContext.SpanID().String()),
+		)
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+// WithAttrs keeps trace correlation on derived loggers
+func (h *LogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &LogHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup keeps trace correlation on derived loggers
+func (h *LogHandler) WithGroup(name string) slog.Handler {
+	return &LogHandler{Handler: h.Handler.WithGroup(name)}
+}
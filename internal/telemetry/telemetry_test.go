@@ -0,0 +1,143 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"logistics-marketplace/internal/requestid"
+)
+
+const (
+	callerTraceID   = "4bf92f3577b34da6a3ce929d0e0e4736"
+	callerParent    = "00-" + callerTraceID + "-00f067aa0ba902b7-01"
+	horizonResponse = `{"id": "GABC"}`
+)
+
+func setupTest(t *testing.T) (*tracetest.SpanRecorder, *bytes.Buffer) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	logs := &bytes.Buffer{}
+	previous := slog.Default()
+	slog.SetDefault(slog.New(NewLogHandler(logs, slog.LevelInfo)))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+	return recorder, logs
+}
+
+func TestMiddlewareTracesAndLogsRequests(t *testing.T) {
+	recorder, logs := setupTest(t)
+
+	var forwarded string
+	horizon := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		forwarded = r.Header.Get("traceparent")
+		w.Write([]byte(horizonResponse))
+	}))
+	defer horizon.Close()
+	client := &http.Client{Transport: Transport("horizon", nil)}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.ContextWithFallback = true
+	router.Use(requestid.Middleware(), Middleware())
+	router.GET("/api/v1/accounts/:id", func(c *gin.Context) {
+		c.Set("user_id", "user-1")
+
+		ctx, span := Start(c, "AccountService.Get")
+		defer span.End()
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, horizon.URL+"/accounts/GABC", nil)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		c.Status(http.StatusNoContent)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/accounts/GABC", nil)
+	req.Header.Set("traceparent", callerParent)
+	req.Header.Set(requestid.Header, "req-42")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+	names := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range spans {
+		assert.Equal(t, callerTraceID, span.SpanContext().TraceID().String())
+		names[span.Name()] = span
+	}
+	require.Contains(t, names, "GET /api/v1/accounts/:id")
+	require.Contains(t, names, "AccountService.Get")
+	require.Contains(t, names, "horizon GET")
+
+	server := names["GET /api/v1/accounts/:id"]
+	assert.Equal(t, trace.SpanKindServer, server.SpanKind())
+	assert.Equal(t, "00f067aa0ba902b7", server.Parent().SpanID().String())
+	assert.Equal(t, server.SpanContext().SpanID(), names["AccountService.Get"].Parent().SpanID())
+	assert.Contains(t, forwarded, names["horizon GET"].SpanContext().SpanID().String())
+
+	var record map[string]interface{}
+	require.NoError(t, json.Unmarshal(logs.Bytes(), &record))
+	assert.Equal(t, "request", record["msg"])
+	assert.Equal(t, "req-42", record["request_id"])
+	assert.Equal(t, "/api/v1/accounts/:id", record["route"])
+	assert.Equal(t, "/api/v1/accounts/GABC", record["path"])
+	assert.EqualValues(t, http.StatusNoContent, record["status"])
+	assert.Equal(t, "user-1", record["user"])
+	assert.Contains(t, record, "latency_ms")
+	assert.Equal(t, callerTraceID, record["trace_id"])
+	assert.Equal(t, server.SpanContext().SpanID().String(), record["span_id"])
+}
+
+func TestWithParentKeepsSpanUnderReplacedContext(t *testing.T) {
+	recorder, _ := setupTest(t)
+	horizon := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(horizonResponse))
+	}))
+	defer horizon.Close()
+
+	ctx, span := Start(context.Background(), "TokenManager.GetTokenBalance")
+	client := WithParent(ctx, &http.Client{Transport: Transport("horizon", nil)})
+
+	// The request carries a context of its own, as the Horizon client's do
+	requestCtx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(requestCtx, http.MethodGet, horizon.URL+"/accounts/GABC", nil)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	span.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "horizon GET", spans[0].Name())
+	assert.Equal(t, span.SpanContext().SpanID(), spans[0].Parent().SpanID())
+	assert.Equal(t, span.SpanContext().TraceID(), spans[0].SpanContext().TraceID())
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("OTEL_TRACES_EXPORTER", "stdout")
+	t.Setenv("LOG_LEVEL", "warn")
+
+	cfg, err := ConfigFromEnv("marketplace")
+	require.NoError(t, err)
+	assert.Equal(t, ExporterConsole, cfg.Exporter)
+	assert.Equal(t, slog.LevelWarn, cfg.LogLevel)
+
+	t.Setenv("LOG_LEVEL", "loud")
+	_, err = ConfigFromEnv("marketplace")
+	assert.Error(t, err)
+}
@@ -0,0 +1,146 @@
+// Package validation checks requests against the rules in their validate
+// struct tags, reporting every invalid field at once.
+package validation
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// hsCodePattern matches a six digit Harmonized System code, optionally
+// extended to a national tariff line of eight or ten digits, with or without
+// dots (847130, 8471.30, 8471.30.00)
+var hsCodePattern = regexp.MustCompile(`^\d{4}\.?\d{2}(\.?\d{2}){0,2}$`)
+
+var validate = newValidator()
+
+// newValidator creates a validator reading validate tags, naming fields by
+// their JSON or form name, with the marketplace's own rules:
+//
+//	country  an ISO 3166-1 alpha-2 country code
+//	hs_code  a Harmonized System code
+func newValidator() *validator.Validate {
+	v := validator.New()
+	v.SetTagName("validate")
+	v.RegisterTagNameFunc(fieldName)
+	v.RegisterAlias("country", "iso3166_1_alpha2")
+	if err := v.RegisterValidation("hs_code", isHSCode); err != nil {
+		panic(fmt.Sprintf("failed to register hs_code validation: %v", err))
+	}
+	return v
+}
+
+// fieldName is the name a client sends a field under
+func fieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "form"} {
+		name := strings.SplitN(field.Tag.Get(tag), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// isHSCode reports whether a field holds a Harmonized System code
+func isHSCode(fl validator.FieldLevel) bool {
+	return hsCodePattern.MatchString(fl.Field().String())
+}
+
+// Struct validates a struct, returning validator.ValidationErrors listing
+// every invalid field
+func Struct(s interface{}) error {
+	return validate.Struct(s)
+}
+
+// Var validates a single value against a tag such as "required,hs_code"
+func Var(field interface{}, tag string) error {
+	return validate.Var(field, tag)
+}
+
+// Message describes in words why a field failed validation
+func Message(fieldErr validator.FieldError) string {
+	param := fieldErr.Param()
+	switch fieldErr.Tag() {
+	case "required":
+		return "is required"
+	case "oneof":
+		return "must be one of " + strings.Join(strings.Fields(param), ", ")
+	case "gt":
+		return "must be greater than " + param
+	case "gte":
+		return "must be at least " + param
+	case "lte":
+		return "must be at most " + param
+	case "min":
+		if isString(fieldErr.Kind()) {
+			return "must be at least " + param + " characters long"
+		}
+		if isCollection(fieldErr.Kind()) {
+			return "must contain at least " + param + " items"
+		}
+		return "must be at least " + param
+	case "max":
+		if isString(fieldErr.Kind()) {
+			return "must be at most " + param + " characters long"
+		}
+		if isCollection(fieldErr.Kind()) {
+			return "must contain at most " + param + " items"
+		}
+		return "must be at most " + param
+	case "country":
+		return "must be an ISO 3166-1 alpha-2 country code such as NL"
+	case "iso4217":
+		return "must be an ISO 4217 currency code such as USD"
+	case "hs_code":
+		return "must be an HS code of 6, 8 or 10 digits such as 8471.30"
+	}
+	return fmt.Sprintf("failed the %s rule", fieldErr.Tag())
+}
+
+func isString(kind reflect.Kind) bool {
+	return kind == reflect.String
+}
+
+func isCollection(kind reflect.Kind) bool {
+	return kind == reflect.Slice || kind == reflect.Array || kind == reflect.Map
+}
+
+// bindingValidator lets gin validate bound requests with the validate tags
+type bindingValidator struct{}
+
+// Binding is the validator gin runs on every bound request
+func Binding() binding.StructValidator {
+	return bindingValidator{}
+}
+
+// ValidateStruct validates a bound struct, or each struct of a bound slice
+func (bindingValidator) ValidateStruct(obj interface{}) error {
+	value := reflect.ValueOf(obj)
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+
+	switch value.Kind() {
+	case reflect.Struct:
+		return validate.Struct(value.Interface())
+	case reflect.Slice, reflect.Array:
+		return validate.Var(value.Interface(), "dive")
+	}
+	return nil
+}
+
+// Engine returns the underlying validator
+func (bindingValidator) Engine() interface{} {
+	return validate
+}
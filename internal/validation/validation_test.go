@@ -0,0 +1,61 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type cargoUnit struct {
+	Weight float64 `json:"weight" validate:"gt=0"`
+}
+
+type quoteRequest struct {
+	Mode    string      `json:"mode" validate:"required,oneof=SEA AIR RAIL ROAD LAND"`
+	Country string      `json:"country" validate:"required,country"`
+	HSCode  string      `json:"hs_code" validate:"omitempty,hs_code"`
+	Cargo   []cargoUnit `json:"cargo" validate:"required,min=1,dive"`
+}
+
+func TestStructReportsEveryInvalidField(t *testing.T) {
+	err := Struct(quoteRequest{
+		Mode:    "BOAT",
+		Country: "Netherlands",
+		HSCode:  "84A1",
+		Cargo:   []cargoUnit{{Weight: 12}, {Weight: -3}},
+	})
+
+	var errs validator.ValidationErrors
+	require.ErrorAs(t, err, &errs)
+	require.Len(t, errs, 4)
+
+	fields := make(map[string]string)
+	for _, fieldErr := range errs {
+		fields[fieldErr.Namespace()] = Message(fieldErr)
+	}
+	assert.Equal(t, map[string]string{
+		"quoteRequest.mode":            "must be one of SEA, AIR, RAIL, ROAD, LAND",
+		"quoteRequest.country":         "must be an ISO 3166-1 alpha-2 country code such as NL",
+		"quoteRequest.hs_code":         "must be an HS code of 6, 8 or 10 digits such as 8471.30",
+		"quoteRequest.cargo[1].weight": "must be greater than 0",
+	}, fields)
+}
+
+func TestStructAcceptsValidRequest(t *testing.T) {
+	for _, code := range []string{"847130", "8471.30", "8471.30.00", "8471300000"} {
+		assert.NoError(t, Struct(quoteRequest{
+			Mode:    "SEA",
+			Country: "NL",
+			HSCode:  code,
+			Cargo:   []cargoUnit{{Weight: 12}},
+		}), code)
+	}
+}
+
+func TestBindingValidatesSlices(t *testing.T) {
+	assert.NoError(t, Binding().ValidateStruct(&[]cargoUnit{{Weight: 1}}))
+	assert.Error(t, Binding().ValidateStruct(&[]cargoUnit{{Weight: 1}, {Weight: 0}}))
+	assert.NoError(t, Binding().ValidateStruct(nil))
+}
@@ -3,8 +3,12 @@
 package main
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"time"
+
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
 )
 
@@ -15,178 +19,586 @@ type LogisticsContract struct {
 
 // Shipper and Consignee structure
 type Shipper struct {
-	ID   string `json:"id"`
-	Name string `json:"name"`
+	DocType string `json:"docType"`
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	MSPID   string `json:"mspId"`
 }
 
 type Consignee struct {
-	ID   string `json:"id"`
-	Name string `json:"name"`
+	DocType string `json:"docType"`
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	MSPID   string `json:"mspId"`
 }
 
-// Freight Quotation structure
+// Freight Quotation structure, public to the channel; the commercial terms
+// live in a private data collection and are only referenced by hash
 type FreightQuotation struct {
-	ID       string `json:"id"`
-	Carrier  string `json:"carrier"`
-	Amount   string `json:"amount"`
-	Confirmed bool   `json:"confirmed"`
+	DocType      string `json:"docType"`
+	ID           string `json:"id"`
+	Carrier      string `json:"carrier"`
+	CarrierMSPID string `json:"carrierMspId"`
+	ShipperID    string `json:"shipperId"`
+	ShipperMSPID string `json:"shipperMspId"`
+	Collection   string `json:"collection"`
+	TermsHash    string `json:"termsHash"`
+	Confirmed    bool   `json:"confirmed"`
+}
+
+// Quotation Terms structure, stored in the carrier and shipper's private data
+// collection. The salt keeps the public hash of the few likely amounts and
+// terms from being guessed; it is chosen by the client, since every endorsing
+// peer must compute the same hash.
+type QuotationTerms struct {
+	QuotationID string   `json:"quotationId"`
+	Amount      string   `json:"amount"`
+	Currency    string   `json:"currency"`
+	ValidUntil  string   `json:"validUntil"`
+	Terms       []string `json:"terms"`
+	Salt        string   `json:"salt"`
+}
+
+// minQuotationSaltLength is the shortest salt accepted, in characters
+const minQuotationSaltLength = 32
+
+// quotationCollections are the private data collections defined in
+// collections_config.json; quotations between other organizations need a
+// collection added there first
+var quotationCollections = map[string]bool{
+	"quotations_Org1MSP_Org2MSP": true,
+	"quotations_Org1MSP":         true,
+	"quotations_Org2MSP":         true,
+}
+
+// Shipment statuses
+const (
+	StatusBooked    = "Booked"
+	StatusPickedUp  = "PickedUp"
+	StatusInTransit = "InTransit"
+	StatusArrived   = "Arrived"
+	StatusDelivered = "Delivered"
+	StatusCancelled = "Cancelled"
+)
+
+// allowedTransitions lists the statuses a shipment may move to from each status
+var allowedTransitions = map[string][]string{
+	StatusBooked:    {StatusPickedUp, StatusCancelled},
+	StatusPickedUp:  {StatusInTransit, StatusCancelled},
+	StatusInTransit: {StatusArrived},
+	StatusArrived:   {StatusInTransit, StatusDelivered},
+	StatusDelivered: {},
+	StatusCancelled: {},
+}
+
+// RouteLeg structure
+type RouteLeg struct {
+	Sequence    int    `json:"sequence"`
+	Origin      string `json:"origin"`
+	Destination string `json:"destination"`
+	Mode        string `json:"mode"`
+	Carrier     string `json:"carrier"`
+	ETD         string `json:"etd,omitempty"`
+	ETA         string `json:"eta,omitempty"`
 }
 
 // Shipment structure
 type Shipment struct {
-	ID          string `json:"id"`
-	ShipperID   string `json:"shipperId"`
-	ConsigneeID string `json:"consigneeId"`
-	Status      string `json:"status"`
+	DocType      string                `json:"docType"`
+	ID           string                `json:"id"`
+	ShipperID    string                `json:"shipperId"`
+	ConsigneeID  string                `json:"consigneeId"`
+	Status       string                `json:"status"`
+	Route        []RouteLeg            `json:"route"`
+	Assignments  []LogisticsAssignment `json:"assignments"`
+	Declarations []string              `json:"declarations"`
+	UpdatedAt    string                `json:"updatedAt"`
 }
 
-// Function to create a new shipper
+// Shipment history entry structure
+type ShipmentHistoryEntry struct {
+	TxID      string    `json:"txId"`
+	Timestamp string    `json:"timestamp"`
+	IsDelete  bool      `json:"isDelete"`
+	Shipment  *Shipment `json:"shipment,omitempty"`
+}
+
+// Function to create a new shipper, owned by the calling organization
 func (c *LogisticsContract) CreateShipper(ctx contractapi.TransactionContextInterface, id string, name string) error {
-	shipper := Shipper{
-		ID:   id,
-		Name: name,
+	exists, err := assetExists(ctx, DocTypeShipper, id)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("shipper already exists: %s", id)
 	}
-	shipperJSON, err := json.Marshal(shipper)
+
+	mspID, err := clientMSPID(ctx)
 	if err != nil {
 		return err
 	}
-	return ctx.GetStub().PutState(id, shipperJSON)
+
+	shipper := Shipper{
+		DocType: DocTypeShipper,
+		ID:      id,
+		Name:    name,
+		MSPID:   mspID,
+	}
+	return writeAsset(ctx, DocTypeShipper, id, shipper)
 }
 
-// Function to create a new consignee
+// Function to create a new consignee, owned by the calling organization
 func (c *LogisticsContract) CreateConsignee(ctx contractapi.TransactionContextInterface, id string, name string) error {
-	consignee := Consignee{
-		ID:   id,
-		Name: name,
+	exists, err := assetExists(ctx, DocTypeConsignee, id)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("consignee already exists: %s", id)
 	}
-	consigneeJSON, err := json.Marshal(consignee)
+
+	mspID, err := clientMSPID(ctx)
 	if err != nil {
 		return err
 	}
-	return ctx.GetStub().PutState(id, consigneeJSON)
+
+	consignee := Consignee{
+		DocType: DocTypeConsignee,
+		ID:      id,
+		Name:    name,
+		MSPID:   mspID,
+	}
+	return writeAsset(ctx, DocTypeConsignee, id, consignee)
 }
 
-// Function to create a freight quotation
-func (c *LogisticsContract) CreateFreightQuotation(ctx contractapi.TransactionContextInterface, id string, carrier string, amount string) error {
-	quotation := FreightQuotation{
-		ID:       id,
-		Carrier:  carrier,
-		Amount:   amount,
-		Confirmed: false,
+// Function to create a freight quotation on behalf of the calling carrier for
+// a shipper; the amount and terms are passed in the "quotation" transient field
+func (c *LogisticsContract) CreateFreightQuotation(ctx contractapi.TransactionContextInterface, id string, carrier string, shipperID string) error {
+	exists, err := assetExists(ctx, DocTypeQuotation, id)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("quotation already exists: %s", id)
+	}
+
+	terms, err := readTransientQuotationTerms(ctx)
+	if err != nil {
+		return err
+	}
+	if terms.Amount == "" {
+		return fmt.Errorf("quotation amount is required")
+	}
+	if len(terms.Salt) < minQuotationSaltLength {
+		return fmt.Errorf("quotation terms need a random salt of at least %d characters", minQuotationSaltLength)
+	}
+	terms.QuotationID = id
+
+	// Store the canonical encoding so confirmations can reproduce its hash
+	termsJSON, err := json.Marshal(terms)
+	if err != nil {
+		return err
+	}
+
+	var shipper Shipper
+	if err := readAsset(ctx, DocTypeShipper, shipperID, &shipper); err != nil {
+		return err
+	}
+
+	carrierMSPID, err := clientMSPID(ctx)
+	if err != nil {
+		return err
 	}
-	quotationJSON, err := json.Marshal(quotation)
+
+	collection := quotationCollection(carrierMSPID, shipper.MSPID)
+	if !quotationCollections[collection] {
+		return fmt.Errorf("no private data collection %s is configured for quotations between %s and %s", collection, carrierMSPID, shipper.MSPID)
+	}
+	key, err := assetKey(ctx, DocTypeQuotation, id)
 	if err != nil {
 		return err
 	}
-	return ctx.GetStub().PutState(id, quotationJSON)
+	if err := ctx.GetStub().PutPrivateData(collection, key, termsJSON); err != nil {
+		return fmt.Errorf("could not store quotation terms in %s: %v", collection, err)
+	}
+
+	quotation := FreightQuotation{
+		DocType:      DocTypeQuotation,
+		ID:           id,
+		Carrier:      carrier,
+		CarrierMSPID: carrierMSPID,
+		ShipperID:    shipperID,
+		ShipperMSPID: shipper.MSPID,
+		Collection:   collection,
+		TermsHash:    hashHex(termsJSON),
+		Confirmed:    false,
+	}
+	return writeAsset(ctx, DocTypeQuotation, id, quotation)
 }
 
-// Function to book a shipment
+// ReadFreightQuotationTerms returns the private amount and terms of a
+// quotation, readable only by peers of the carrier and shipper organizations
+func (c *LogisticsContract) ReadFreightQuotationTerms(ctx contractapi.TransactionContextInterface, id string) (*QuotationTerms, error) {
+	var quotation FreightQuotation
+	if err := readAsset(ctx, DocTypeQuotation, id, &quotation); err != nil {
+		return nil, err
+	}
+
+	key, err := assetKey(ctx, DocTypeQuotation, id)
+	if err != nil {
+		return nil, err
+	}
+	termsJSON, err := ctx.GetStub().GetPrivateData(quotation.Collection, key)
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve quotation terms: %v", err)
+	}
+	if termsJSON == nil {
+		return nil, fmt.Errorf("quotation terms not found: %s", id)
+	}
+
+	var terms QuotationTerms
+	if err := json.Unmarshal(termsJSON, &terms); err != nil {
+		return nil, err
+	}
+	return &terms, nil
+}
+
+// Function to book a shipment, only by the organization owning the shipper
 func (c *LogisticsContract) BookShipment(ctx contractapi.TransactionContextInterface, id string, shipperID string, consigneeID string) error {
+	exists, err := assetExists(ctx, DocTypeShipment, id)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("shipment already exists: %s", id)
+	}
+
+	var shipper Shipper
+	if err := readAsset(ctx, DocTypeShipper, shipperID, &shipper); err != nil {
+		return err
+	}
+	if err := requireClientMSP(ctx, "book shipments for shipper "+shipperID, shipper.MSPID); err != nil {
+		return err
+	}
+
+	consigneeExists, err := assetExists(ctx, DocTypeConsignee, consigneeID)
+	if err != nil {
+		return err
+	}
+	if !consigneeExists {
+		return fmt.Errorf("consignee not found: %s", consigneeID)
+	}
+
 	shipment := Shipment{
+		DocType:     DocTypeShipment,
 		ID:          id,
 		ShipperID:   shipperID,
 		ConsigneeID: consigneeID,
-		Status:      "Booked",
+		Status:      StatusBooked,
+		Route:       []RouteLeg{},
+		Assignments: []LogisticsAssignment{},
 	}
-	shipmentJSON, err := json.Marshal(shipment)
-	if err != nil {
-		return err
-	}
-	return ctx.GetStub().PutState(id, shipmentJSON)
+	return putShipment(ctx, &shipment)
 }
 
+// ConfirmFreightQuotation confirms a quotation, only by the carrier that
+// issued it; the agreed terms are passed in the "quotation" transient field and
+// must match the private data on the ledger
 func (c *LogisticsContract) ConfirmFreightQuotation(ctx contractapi.TransactionContextInterface, id string) error {
-	quotationJSON, err := ctx.GetStub().GetState(id)
+	var quotation FreightQuotation
+	if err := readAsset(ctx, DocTypeQuotation, id, &quotation); err != nil {
+		return err
+	}
+
+	if err := requireClientMSP(ctx, "confirm quotation "+id, quotation.CarrierMSPID); err != nil {
+		return err
+	}
+	if quotation.Confirmed {
+		return fmt.Errorf("quotation already confirmed: %s", id)
+	}
+
+	terms, err := readTransientQuotationTerms(ctx)
 	if err != nil {
-		return fmt.Errorf("could not retrieve quotation: %v", err)
+		return err
+	}
+	termsJSON, err := json.Marshal(terms)
+	if err != nil {
+		return err
 	}
-	if quotationJSON == nil {
-		return fmt.Errorf("quotation not found: %s", id)
+	termsHash := hashHex(termsJSON)
+	if termsHash != quotation.TermsHash {
+		return fmt.Errorf("quotation terms do not match the public hash of %s", id)
 	}
 
-	var quotation FreightQuotation
-	err = json.Unmarshal(quotationJSON, &quotation)
+	// The private data hash is visible to every peer, collection member or not
+	key, err := assetKey(ctx, DocTypeQuotation, id)
 	if err != nil {
 		return err
 	}
+	privateHash, err := ctx.GetStub().GetPrivateDataHash(quotation.Collection, key)
+	if err != nil {
+		return fmt.Errorf("could not retrieve quotation terms hash: %v", err)
+	}
+	if hex.EncodeToString(privateHash) != termsHash {
+		return fmt.Errorf("quotation terms do not match the private data of %s", id)
+	}
 
 	quotation.Confirmed = true
-	updatedQuotationJSON, err := json.Marshal(quotation)
-	if err != nil {
+	if err := writeAsset(ctx, DocTypeQuotation, id, quotation); err != nil {
 		return err
 	}
-	return ctx.GetStub().PutState(id, updatedQuotationJSON)
+	return emitEvent(ctx, EventQuotationConfirmed, quotation)
 }
 
 func (c *LogisticsContract) TrackShipment(ctx contractapi.TransactionContextInterface, id string) (string, error) {
-	shipmentJSON, err := ctx.GetStub().GetState(id)
+	shipment, err := getShipment(ctx, id)
 	if err != nil {
-		return "", fmt.Errorf("could not retrieve shipment: %v", err)
-	}
-	if shipmentJSON == nil {
-		return "", fmt.Errorf("shipment not found: %s", id)
+		return "", err
 	}
 
+	shipmentJSON, err := json.Marshal(shipment)
+	if err != nil {
+		return "", err
+	}
 	return string(shipmentJSON), nil
 }
 
+// QueryShipmentsByShipper returns the shipments booked for a shipper
+func (c *LogisticsContract) QueryShipmentsByShipper(ctx contractapi.TransactionContextInterface, shipperID string) ([]*Shipment, error) {
+	return queryAssets[Shipment](ctx, map[string]interface{}{
+		"docType":   DocTypeShipment,
+		"shipperId": shipperID,
+	})
+}
+
+// QueryQuotationsByCarrier returns the quotations issued by a carrier
+func (c *LogisticsContract) QueryQuotationsByCarrier(ctx contractapi.TransactionContextInterface, carrier string) ([]*FreightQuotation, error) {
+	return queryAssets[FreightQuotation](ctx, map[string]interface{}{
+		"docType": DocTypeQuotation,
+		"carrier": carrier,
+	})
+}
+
+// QueryUnconfirmedQuotations returns the quotations not yet confirmed
+func (c *LogisticsContract) QueryUnconfirmedQuotations(ctx contractapi.TransactionContextInterface) ([]*FreightQuotation, error) {
+	return queryAssets[FreightQuotation](ctx, map[string]interface{}{
+		"docType":   DocTypeQuotation,
+		"confirmed": false,
+	})
+}
+
 func (c *LogisticsContract) UpdateShipmentStatus(ctx contractapi.TransactionContextInterface, id string, status string) error {
-	shipmentJSON, err := ctx.GetStub().GetState(id)
+	shipment, err := getShipment(ctx, id)
 	if err != nil {
-		return fmt.Errorf("could not retrieve shipment: %v", err)
-	}
-	if shipmentJSON == nil {
-		return fmt.Errorf("shipment not found: %s", id)
+		return err
 	}
 
-	var shipment Shipment
-	err = json.Unmarshal(shipmentJSON, &shipment)
-	if err != nil {
+	if err := requireShipmentParty(ctx, shipment, "update shipment "+id); err != nil {
 		return err
 	}
 
+	if !canTransition(shipment.Status, status) {
+		return fmt.Errorf("invalid status transition from %s to %s", shipment.Status, status)
+	}
+
+	// Shipments cannot be delivered before customs releases them
+	if status == StatusDelivered {
+		for _, declarationNumber := range shipment.Declarations {
+			declaration, err := getCustomsDeclaration(ctx, declarationNumber)
+			if err != nil {
+				return err
+			}
+			if declaration.Status != CustomsReleased {
+				return fmt.Errorf("customs declaration %s is %s", declarationNumber, declaration.Status)
+			}
+		}
+	}
+
 	shipment.Status = status
-	updatedShipmentJSON, err := json.Marshal(shipment)
+	return putShipment(ctx, shipment)
+}
+
+// SetRoutingInfo replaces the route of a shipment with the given legs, passed as a JSON array
+func (c *LogisticsContract) SetRoutingInfo(ctx contractapi.TransactionContextInterface, id string, routingInfo string) error {
+	shipment, err := getShipment(ctx, id)
 	if err != nil {
 		return err
 	}
-	return ctx.GetStub().PutState(id, updatedShipmentJSON)
+
+	if err := requireShipmentParty(ctx, shipment, "route shipment "+id); err != nil {
+		return err
+	}
+
+	if shipment.Status == StatusDelivered || shipment.Status == StatusCancelled {
+		return fmt.Errorf("cannot route shipment in status %s", shipment.Status)
+	}
+
+	var legs []RouteLeg
+	if err := json.Unmarshal([]byte(routingInfo), &legs); err != nil {
+		return fmt.Errorf("invalid routing info: %v", err)
+	}
+	if len(legs) == 0 {
+		return fmt.Errorf("route must have at least one leg")
+	}
+
+	for i := range legs {
+		if legs[i].Origin == "" || legs[i].Destination == "" {
+			return fmt.Errorf("route leg %d must have an origin and destination", i+1)
+		}
+		if i > 0 && legs[i-1].Destination != legs[i].Origin {
+			return fmt.Errorf("route leg %d does not start where leg %d ends", i+1, i)
+		}
+		legs[i].Sequence = i + 1
+	}
+
+	shipment.Route = legs
+	return putShipment(ctx, shipment)
 }
 
-func (c *LogisticsContract) SetRoutingInfo(ctx contractapi.TransactionContextInterface, id string, routingInfo string) error {
-	shipmentJSON, err := ctx.GetStub().GetState(id)
+// GetShipmentHistory returns every committed version of a shipment, oldest first
+func (c *LogisticsContract) GetShipmentHistory(ctx contractapi.TransactionContextInterface, id string) ([]ShipmentHistoryEntry, error) {
+	key, err := assetKey(ctx, DocTypeShipment, id)
 	if err != nil {
-		return fmt.Errorf("could not retrieve shipment: %v", err)
+		return nil, err
 	}
-	if shipmentJSON == nil {
-		return fmt.Errorf("shipment not found: %s", id)
+
+	iterator, err := ctx.GetStub().GetHistoryForKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve shipment history: %v", err)
 	}
+	defer iterator.Close()
 
-	var shipment Shipment
-	err = json.Unmarshal(shipmentJSON, &shipment)
+	var history []ShipmentHistoryEntry
+	for iterator.HasNext() {
+		modification, err := iterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		entry := ShipmentHistoryEntry{
+			TxID:     modification.TxId,
+			IsDelete: modification.IsDelete,
+		}
+		if modification.Timestamp != nil {
+			entry.Timestamp = modification.Timestamp.AsTime().UTC().Format(time.RFC3339)
+		}
+		if !modification.IsDelete {
+			var shipment Shipment
+			if err := json.Unmarshal(modification.Value, &shipment); err != nil {
+				return nil, err
+			}
+			entry.Shipment = &shipment
+		}
+		history = append(history, entry)
+	}
+
+	if history == nil {
+		return nil, fmt.Errorf("shipment not found: %s", id)
+	}
+
+	return history, nil
+}
+
+// quotationCollection returns the private data collection shared by a carrier
+// and a shipper organization, named after both MSP IDs in sorted order
+func quotationCollection(carrierMSPID string, shipperMSPID string) string {
+	if carrierMSPID == shipperMSPID {
+		return "quotations_" + carrierMSPID
+	}
+	if carrierMSPID > shipperMSPID {
+		carrierMSPID, shipperMSPID = shipperMSPID, carrierMSPID
+	}
+	return "quotations_" + carrierMSPID + "_" + shipperMSPID
+}
+
+// readTransientQuotationTerms reads the quotation terms passed in the
+// "quotation" transient field
+func readTransientQuotationTerms(ctx contractapi.TransactionContextInterface) (*QuotationTerms, error) {
+	transient, err := ctx.GetStub().GetTransient()
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("could not retrieve transient data: %v", err)
+	}
+
+	termsJSON, ok := transient["quotation"]
+	if !ok {
+		return nil, fmt.Errorf("quotation terms must be passed in the transient field \"quotation\"")
+	}
+
+	var terms QuotationTerms
+	if err := json.Unmarshal(termsJSON, &terms); err != nil {
+		return nil, fmt.Errorf("invalid quotation terms: %v", err)
 	}
+	return &terms, nil
+}
+
+func hashHex(data []byte) string {
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
+
+// canTransition reports whether a shipment may move from one status to another
+func canTransition(from string, to string) bool {
+	for _, allowed := range allowedTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+func getShipment(ctx contractapi.TransactionContextInterface, id string) (*Shipment, error) {
+	var shipment Shipment
+	if err := readAsset(ctx, DocTypeShipment, id, &shipment); err != nil {
+		return nil, err
+	}
+	return &shipment, nil
+}
+
+// shipmentOwners returns the organizations owning a shipment's shipper and consignee
+func shipmentOwners(ctx contractapi.TransactionContextInterface, shipment *Shipment) ([]string, error) {
+	var shipper Shipper
+	if err := readAsset(ctx, DocTypeShipper, shipment.ShipperID, &shipper); err != nil {
+		return nil, err
+	}
+	var consignee Consignee
+	if err := readAsset(ctx, DocTypeConsignee, shipment.ConsigneeID, &consignee); err != nil {
+		return nil, err
+	}
+	return []string{shipper.MSPID, consignee.MSPID}, nil
+}
 
-	// Assuming routingInfo is a string that contains routing details
-	shipment.Status = routingInfo
-	updatedShipmentJSON, err := json.Marshal(shipment)
+// requireShipmentParty fails unless the client belongs to the shipper's or
+// consignee's organization or to a 3PL provider assigned to the shipment
+func requireShipmentParty(ctx contractapi.TransactionContextInterface, shipment *Shipment, action string) error {
+	parties, err := shipmentOwners(ctx, shipment)
 	if err != nil {
 		return err
 	}
-	return ctx.GetStub().PutState(id, updatedShipmentJSON)
-}
 
-func (c *LogisticsContract) AddThirdPartyLogistics(ctx contractapi.TransactionContextInterface, id string, providerName string) error {
-	// Logic to add third-party logistics provider
-	// This could involve creating a new record in the ledger
-	return nil
+	for _, assignment := range shipment.Assignments {
+		provider, err := getThirdPartyLogistics(ctx, assignment.ProviderID)
+		if err != nil {
+			return err
+		}
+		parties = append(parties, provider.MSPID)
+	}
+
+	return requireClientMSP(ctx, action, parties...)
 }
 
-func (c *LogisticsContract) HandleCustomsOperations(ctx contractapi.TransactionContextInterface, shipmentID string, customsInfo string) error {
-	// Logic to handle customs operations for a shipment
-	return nil
+// putShipment stamps a shipment with the transaction time and writes it to the ledger
+func putShipment(ctx contractapi.TransactionContextInterface, shipment *Shipment) error {
+	timestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return fmt.Errorf("could not retrieve transaction timestamp: %v", err)
+	}
+	shipment.UpdatedAt = timestamp.AsTime().UTC().Format(time.RFC3339)
+
+	shipment.DocType = DocTypeShipment
+	if err := writeAsset(ctx, DocTypeShipment, shipment.ID, shipment); err != nil {
+		return err
+	}
+	return emitEvent(ctx, EventShipmentUpdated, shipment)
 }
 
 func main() {
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/pkg/cid"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testStub adds the private data hashes MockStub does not implement
+type testStub struct {
+	*shimtest.MockStub
+}
+
+func (s *testStub) GetPrivateDataHash(collection, key string) ([]byte, error) {
+	value, err := s.GetPrivateData(collection, key)
+	if err != nil || value == nil {
+		return nil, err
+	}
+	hash := sha256.Sum256(value)
+	return hash[:], nil
+}
+
+// testIdentity is a client of an organization
+type testIdentity struct {
+	cid.ClientIdentity
+	mspID string
+}
+
+func (i testIdentity) GetMSPID() (string, error) {
+	return i.mspID, nil
+}
+
+// testContext submits transactions to a mock ledger as a client of an organization
+type testContext struct {
+	stub  *testStub
+	mspID string
+}
+
+func (c *testContext) GetStub() shim.ChaincodeStubInterface {
+	return c.stub
+}
+
+func (c *testContext) GetClientIdentity() cid.ClientIdentity {
+	return testIdentity{mspID: c.mspID}
+}
+
+// as switches the organization submitting transactions
+func (c *testContext) as(mspID string) *testContext {
+	c.mspID = mspID
+	return c
+}
+
+func newTestContext() *testContext {
+	stub := shimtest.NewMockStub("logistics", nil)
+	stub.MockTransactionStart("tx1")
+	return &testContext{stub: &testStub{MockStub: stub}, mspID: "Org1MSP"}
+}
+
+// bookTestShipment books shipment S1 for a shipper of Org1MSP and a consignee of Org2MSP
+func bookTestShipment(t *testing.T, c *LogisticsContract, ctx *testContext) {
+	t.Helper()
+	require.NoError(t, c.CreateShipper(ctx.as("Org1MSP"), "SHIPPER", "Shipper Co"))
+	require.NoError(t, c.CreateConsignee(ctx.as("Org2MSP"), "CONSIGNEE", "Consignee Co"))
+	require.NoError(t, c.BookShipment(ctx.as("Org1MSP"), "S1", "SHIPPER", "CONSIGNEE"))
+}
+
+func TestCanTransition(t *testing.T) {
+	tests := []struct {
+		from, to string
+		allowed  bool
+	}{
+		{StatusBooked, StatusPickedUp, true},
+		{StatusBooked, StatusCancelled, true},
+		{StatusBooked, StatusInTransit, false},
+		{StatusBooked, StatusDelivered, false},
+		{StatusPickedUp, StatusInTransit, true},
+		{StatusPickedUp, StatusBooked, false},
+		{StatusInTransit, StatusArrived, true},
+		{StatusInTransit, StatusCancelled, false},
+		{StatusArrived, StatusInTransit, true}, // Transshipment
+		{StatusArrived, StatusDelivered, true},
+		{StatusDelivered, StatusInTransit, false},
+		{StatusCancelled, StatusBooked, false},
+		{"Unknown", StatusBooked, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.from+"->"+tt.to, func(t *testing.T) {
+			assert.Equal(t, tt.allowed, canTransition(tt.from, tt.to))
+		})
+	}
+}
+
+func TestUpdateShipmentStatus(t *testing.T) {
+	c := new(LogisticsContract)
+	ctx := newTestContext()
+	bookTestShipment(t, c, ctx)
+
+	for _, status := range []string{StatusPickedUp, StatusInTransit, StatusArrived, StatusDelivered} {
+		require.NoError(t, c.UpdateShipmentStatus(ctx, "S1", status), status)
+	}
+
+	shipment, err := getShipment(ctx, "S1")
+	require.NoError(t, err)
+	assert.Equal(t, StatusDelivered, shipment.Status)
+	assert.NotEmpty(t, shipment.UpdatedAt)
+
+	err = c.UpdateShipmentStatus(ctx, "S1", StatusInTransit)
+	assert.EqualError(t, err, "invalid status transition from Delivered to InTransit")
+
+	err = c.UpdateShipmentStatus(ctx, "MISSING", StatusPickedUp)
+	assert.EqualError(t, err, "shipment not found: MISSING")
+}
+
+func TestSetRoutingInfo(t *testing.T) {
+	tests := []struct {
+		name    string
+		route   string
+		wantErr string
+	}{
+		{name: "connected legs", route: `[{"origin":"CNSHA","destination":"SGSIN","mode":"Sea"},{"origin":"SGSIN","destination":"NLRTM","mode":"Sea"}]`},
+		{name: "disconnected legs", route: `[{"origin":"CNSHA","destination":"SGSIN"},{"origin":"MYPKG","destination":"NLRTM"}]`, wantErr: "route leg 2 does not start where leg 1 ends"},
+		{name: "missing destination", route: `[{"origin":"CNSHA"}]`, wantErr: "route leg 1 must have an origin and destination"},
+		{name: "no legs", route: `[]`, wantErr: "route must have at least one leg"},
+		{name: "not json", route: `CNSHA-NLRTM`, wantErr: "invalid routing info"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := new(LogisticsContract)
+			ctx := newTestContext()
+			bookTestShipment(t, c, ctx)
+
+			err := c.SetRoutingInfo(ctx, "S1", tt.route)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			shipmentJSON, err := c.TrackShipment(ctx, "S1")
+			require.NoError(t, err)
+			var shipment Shipment
+			require.NoError(t, json.Unmarshal([]byte(shipmentJSON), &shipment))
+			require.Len(t, shipment.Route, 2)
+			assert.Equal(t, 1, shipment.Route[0].Sequence)
+			assert.Equal(t, 2, shipment.Route[1].Sequence)
+		})
+	}
+}
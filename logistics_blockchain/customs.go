@@ -0,0 +1,201 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Customs declaration statuses
+const (
+	CustomsFiled    = "Filed"
+	CustomsHeld     = "Held"
+	CustomsReleased = "Released"
+)
+
+// allowedCustomsTransitions lists the statuses a declaration may move to from each status
+var allowedCustomsTransitions = map[string][]string{
+	CustomsFiled:    {CustomsReleased, CustomsHeld},
+	CustomsHeld:     {CustomsReleased},
+	CustomsReleased: {},
+}
+
+var hsCodePattern = regexp.MustCompile(`^[0-9]{6,10}$`)
+
+// HS line structure
+type HSLine struct {
+	HSCode        string  `json:"hsCode"`
+	Description   string  `json:"description"`
+	Quantity      float64 `json:"quantity"`
+	Value         float64 `json:"value"`
+	OriginCountry string  `json:"originCountry"`
+}
+
+// Customs declaration structure
+type CustomsDeclaration struct {
+	DocType           string   `json:"docType"`
+	DeclarationNumber string   `json:"declarationNumber"`
+	ShipmentID        string   `json:"shipmentId"`
+	Type              string   `json:"type"` // Import or Export
+	Lines             []HSLine `json:"lines"`
+	Status            string   `json:"status"`
+	BrokerMSPID       string   `json:"brokerMspId"`
+	HoldReason        string   `json:"holdReason,omitempty"`
+	FiledAt           string   `json:"filedAt"`
+	UpdatedAt         string   `json:"updatedAt"`
+}
+
+// HandleCustomsOperations files a customs declaration for a shipment on
+// behalf of the calling broker's organization, which must be the 3PL assigned
+// to the shipment's customs brokerage; customsInfo is the JSON declaration
+func (c *LogisticsContract) HandleCustomsOperations(ctx contractapi.TransactionContextInterface, shipmentID string, customsInfo string) error {
+	var declaration CustomsDeclaration
+	if err := json.Unmarshal([]byte(customsInfo), &declaration); err != nil {
+		return fmt.Errorf("invalid customs info: %v", err)
+	}
+
+	if declaration.DeclarationNumber == "" {
+		return fmt.Errorf("declaration number is required")
+	}
+	if declaration.Type != "Import" && declaration.Type != "Export" {
+		return fmt.Errorf("declaration type must be Import or Export")
+	}
+	if len(declaration.Lines) == 0 {
+		return fmt.Errorf("declaration must have at least one HS line")
+	}
+	for i, line := range declaration.Lines {
+		if !hsCodePattern.MatchString(line.HSCode) {
+			return fmt.Errorf("line %d has invalid HS code: %s", i+1, line.HSCode)
+		}
+	}
+
+	exists, err := assetExists(ctx, DocTypeDeclaration, declaration.DeclarationNumber)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("customs declaration already exists: %s", declaration.DeclarationNumber)
+	}
+
+	shipment, err := getShipment(ctx, shipmentID)
+	if err != nil {
+		return err
+	}
+
+	if err := requireCustomsBroker(ctx, shipment); err != nil {
+		return err
+	}
+
+	brokerMSPID, err := clientMSPID(ctx)
+	if err != nil {
+		return err
+	}
+
+	timestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return fmt.Errorf("could not retrieve transaction timestamp: %v", err)
+	}
+	now := timestamp.AsTime().UTC().Format(time.RFC3339)
+
+	declaration.ShipmentID = shipmentID
+	declaration.Status = CustomsFiled
+	declaration.BrokerMSPID = brokerMSPID
+	declaration.HoldReason = ""
+	declaration.FiledAt = now
+	declaration.UpdatedAt = now
+
+	if err := putCustomsDeclaration(ctx, &declaration); err != nil {
+		return err
+	}
+
+	shipment.Declarations = append(shipment.Declarations, declaration.DeclarationNumber)
+	return putShipment(ctx, shipment)
+}
+
+// UpdateCustomsStatus records a customs decision on a declaration; only the
+// filing broker's organization may update it
+func (c *LogisticsContract) UpdateCustomsStatus(ctx contractapi.TransactionContextInterface, declarationNumber string, status string, reason string) error {
+	declaration, err := getCustomsDeclaration(ctx, declarationNumber)
+	if err != nil {
+		return err
+	}
+
+	if err := requireClientMSP(ctx, "update declaration "+declarationNumber, declaration.BrokerMSPID); err != nil {
+		return err
+	}
+
+	allowed := false
+	for _, next := range allowedCustomsTransitions[declaration.Status] {
+		if next == status {
+			allowed = true
+		}
+	}
+	if !allowed {
+		return fmt.Errorf("invalid customs status transition from %s to %s", declaration.Status, status)
+	}
+	if status == CustomsHeld && reason == "" {
+		return fmt.Errorf("a reason is required to hold a declaration")
+	}
+
+	timestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return fmt.Errorf("could not retrieve transaction timestamp: %v", err)
+	}
+
+	declaration.Status = status
+	declaration.HoldReason = reason
+	declaration.UpdatedAt = timestamp.AsTime().UTC().Format(time.RFC3339)
+
+	if err := putCustomsDeclaration(ctx, declaration); err != nil {
+		return err
+	}
+	return emitEvent(ctx, EventCustomsStatusUpdated, declaration)
+}
+
+// requireCustomsBroker checks that the client is the organization of the 3PL
+// assigned to a shipment's customs brokerage. The shipper and consignee cannot
+// broker their own shipment, as the broker releases it from customs.
+func requireCustomsBroker(ctx contractapi.TransactionContextInterface, shipment *Shipment) error {
+	owners, err := shipmentOwners(ctx, shipment)
+	if err != nil {
+		return err
+	}
+
+	var brokers []string
+	for _, assignment := range shipment.Assignments {
+		if assignment.Role != RoleCustoms {
+			continue
+		}
+		provider, err := getThirdPartyLogistics(ctx, assignment.ProviderID)
+		if err != nil {
+			return err
+		}
+		if provider.MSPID == owners[0] || provider.MSPID == owners[1] {
+			continue
+		}
+		brokers = append(brokers, provider.MSPID)
+	}
+
+	return requireClientMSP(ctx, "file customs declarations for shipment "+shipment.ID, brokers...)
+}
+
+// GetCustomsDeclaration returns a customs declaration
+func (c *LogisticsContract) GetCustomsDeclaration(ctx contractapi.TransactionContextInterface, declarationNumber string) (*CustomsDeclaration, error) {
+	return getCustomsDeclaration(ctx, declarationNumber)
+}
+
+func getCustomsDeclaration(ctx contractapi.TransactionContextInterface, declarationNumber string) (*CustomsDeclaration, error) {
+	var declaration CustomsDeclaration
+	if err := readAsset(ctx, DocTypeDeclaration, declarationNumber, &declaration); err != nil {
+		return nil, err
+	}
+	return &declaration, nil
+}
+
+func putCustomsDeclaration(ctx contractapi.TransactionContextInterface, declaration *CustomsDeclaration) error {
+	declaration.DocType = DocTypeDeclaration
+	return writeAsset(ctx, DocTypeDeclaration, declaration.DeclarationNumber, declaration)
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testDeclaration = `{"declarationNumber":"D1","type":"Import","lines":[{"hsCode":"847130","description":"Laptops","quantity":10,"value":9000,"originCountry":"CN"}]}`
+
+// assignTestBroker has the shipper assign the customs broker of Org3MSP to shipment S1
+func assignTestBroker(t *testing.T, c *LogisticsContract, ctx *testContext) {
+	t.Helper()
+	require.NoError(t, c.AddThirdPartyLogistics(ctx.as("Org3MSP"), "BROKER", "Brokers", `["CustomsBrokerage"]`))
+	require.NoError(t, c.AssignThirdPartyLogistics(ctx.as("Org1MSP"), "S1", "BROKER", RoleCustoms))
+}
+
+// fileTestDeclaration books shipment S1 and has its broker of Org3MSP file declaration D1
+func fileTestDeclaration(t *testing.T, c *LogisticsContract, ctx *testContext) {
+	t.Helper()
+	bookTestShipment(t, c, ctx)
+	assignTestBroker(t, c, ctx)
+	require.NoError(t, c.HandleCustomsOperations(ctx.as("Org3MSP"), "S1", testDeclaration))
+}
+
+func TestHandleCustomsOperations(t *testing.T) {
+	tests := []struct {
+		name        string
+		mspID       string
+		declaration string
+		wantErr     string
+	}{
+		{name: "assigned broker files", mspID: "Org3MSP", declaration: testDeclaration},
+		{name: "shipper files", mspID: "Org1MSP", declaration: testDeclaration, wantErr: "client Org1MSP is not allowed to file customs declarations for shipment S1"},
+		{name: "consignee files", mspID: "Org2MSP", declaration: testDeclaration, wantErr: "client Org2MSP is not allowed to file customs declarations for shipment S1"},
+		{name: "trucking 3PL files", mspID: "Org4MSP", declaration: testDeclaration, wantErr: "client Org4MSP is not allowed to file customs declarations for shipment S1"},
+		{name: "stranger files", mspID: "Org5MSP", declaration: testDeclaration, wantErr: "client Org5MSP is not allowed to file customs declarations for shipment S1"},
+		{name: "invalid HS code", mspID: "Org3MSP", declaration: `{"declarationNumber":"D1","type":"Import","lines":[{"hsCode":"84A1"}]}`, wantErr: "line 1 has invalid HS code: 84A1"},
+		{name: "no lines", mspID: "Org3MSP", declaration: `{"declarationNumber":"D1","type":"Export","lines":[]}`, wantErr: "declaration must have at least one HS line"},
+		{name: "unknown type", mspID: "Org3MSP", declaration: `{"declarationNumber":"D1","type":"Transit","lines":[{"hsCode":"847130"}]}`, wantErr: "declaration type must be Import or Export"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := new(LogisticsContract)
+			ctx := newTestContext()
+			bookTestShipment(t, c, ctx)
+			assignTestBroker(t, c, ctx)
+			require.NoError(t, c.AddThirdPartyLogistics(ctx.as("Org4MSP"), "TRUCKS", "Truckers", `["Trucking"]`))
+			require.NoError(t, c.AssignThirdPartyLogistics(ctx.as("Org1MSP"), "S1", "TRUCKS", RoleTrucking))
+
+			err := c.HandleCustomsOperations(ctx.as(tt.mspID), "S1", tt.declaration)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			declaration, err := c.GetCustomsDeclaration(ctx, "D1")
+			require.NoError(t, err)
+			assert.Equal(t, CustomsFiled, declaration.Status)
+			assert.Equal(t, tt.mspID, declaration.BrokerMSPID)
+
+			shipment, err := getShipment(ctx, "S1")
+			require.NoError(t, err)
+			assert.Equal(t, []string{"D1"}, shipment.Declarations)
+		})
+	}
+}
+
+func TestUpdateCustomsStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		steps   [][2]string // Status and reason applied before the checked update
+		mspID   string
+		status  string
+		reason  string
+		wantErr string
+	}{
+		{name: "filed to released", mspID: "Org3MSP", status: CustomsReleased},
+		{name: "filed to held", mspID: "Org3MSP", status: CustomsHeld, reason: "Inspection"},
+		{name: "held without reason", mspID: "Org3MSP", status: CustomsHeld, wantErr: "a reason is required to hold a declaration"},
+		{name: "held to released", steps: [][2]string{{CustomsHeld, "Inspection"}}, mspID: "Org3MSP", status: CustomsReleased},
+		{name: "released to held", steps: [][2]string{{CustomsReleased, ""}}, mspID: "Org3MSP", status: CustomsHeld, reason: "Audit", wantErr: "invalid customs status transition from Released to Held"},
+		{name: "back to filed", mspID: "Org3MSP", status: CustomsFiled, wantErr: "invalid customs status transition from Filed to Filed"},
+		{name: "shipper releases", mspID: "Org1MSP", status: CustomsReleased, wantErr: "client Org1MSP is not allowed to update declaration D1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := new(LogisticsContract)
+			ctx := newTestContext()
+			fileTestDeclaration(t, c, ctx)
+			for _, step := range tt.steps {
+				require.NoError(t, c.UpdateCustomsStatus(ctx.as("Org3MSP"), "D1", step[0], step[1]))
+			}
+
+			err := c.UpdateCustomsStatus(ctx.as(tt.mspID), "D1", tt.status, tt.reason)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			declaration, err := c.GetCustomsDeclaration(ctx, "D1")
+			require.NoError(t, err)
+			assert.Equal(t, tt.status, declaration.Status)
+			assert.Equal(t, tt.reason, declaration.HoldReason)
+		})
+	}
+}
+
+func TestDeliveryWaitsForCustomsRelease(t *testing.T) {
+	c := new(LogisticsContract)
+	ctx := newTestContext()
+	fileTestDeclaration(t, c, ctx)
+	for _, status := range []string{StatusPickedUp, StatusInTransit, StatusArrived} {
+		require.NoError(t, c.UpdateShipmentStatus(ctx.as("Org1MSP"), "S1", status))
+	}
+
+	require.NoError(t, c.UpdateCustomsStatus(ctx.as("Org3MSP"), "D1", CustomsHeld, "Inspection"))
+	assert.EqualError(t, c.UpdateShipmentStatus(ctx.as("Org1MSP"), "S1", StatusDelivered), "customs declaration D1 is Held")
+
+	require.NoError(t, c.UpdateCustomsStatus(ctx.as("Org3MSP"), "D1", CustomsReleased, ""))
+	require.NoError(t, c.UpdateShipmentStatus(ctx.as("Org1MSP"), "S1", StatusDelivered))
+}
+
+func TestShipperCannotBrokerOwnShipment(t *testing.T) {
+	c := new(LogisticsContract)
+	ctx := newTestContext()
+	bookTestShipment(t, c, ctx)
+
+	// The shipper's organization registers and assigns a broker of its own
+	require.NoError(t, c.AddThirdPartyLogistics(ctx.as("Org1MSP"), "INHOUSE", "In-house Brokers", `["CustomsBrokerage"]`))
+	require.NoError(t, c.AssignThirdPartyLogistics(ctx.as("Org1MSP"), "S1", "INHOUSE", RoleCustoms))
+
+	err := c.HandleCustomsOperations(ctx.as("Org1MSP"), "S1", testDeclaration)
+	assert.EqualError(t, err, "client Org1MSP is not allowed to file customs declarations for shipment S1")
+}
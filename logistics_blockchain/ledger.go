@@ -0,0 +1,147 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Asset document types, used as composite key object types and as the
+// docType field CouchDB queries select on
+const (
+	DocTypeShipper     = "shipper"
+	DocTypeConsignee   = "consignee"
+	DocTypeQuotation   = "quotation"
+	DocTypeShipment    = "shipment"
+	DocTypeThirdParty  = "thirdPartyLogistics"
+	DocTypeDeclaration = "customsDeclaration"
+)
+
+// assetKey returns the composite key of an asset, keeping each asset type in its own key space
+func assetKey(ctx contractapi.TransactionContextInterface, docType string, id string) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(docType, []string{id})
+	if err != nil {
+		return "", fmt.Errorf("could not create %s key: %v", docType, err)
+	}
+	return key, nil
+}
+
+// assetExists reports whether an asset of the given type exists
+func assetExists(ctx contractapi.TransactionContextInterface, docType string, id string) (bool, error) {
+	key, err := assetKey(ctx, docType, id)
+	if err != nil {
+		return false, err
+	}
+
+	assetJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return false, fmt.Errorf("could not retrieve %s: %v", docType, err)
+	}
+	return assetJSON != nil, nil
+}
+
+// readAsset reads an asset of the given type into asset
+func readAsset(ctx contractapi.TransactionContextInterface, docType string, id string, asset interface{}) error {
+	key, err := assetKey(ctx, docType, id)
+	if err != nil {
+		return err
+	}
+
+	assetJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return fmt.Errorf("could not retrieve %s: %v", docType, err)
+	}
+	if assetJSON == nil {
+		return fmt.Errorf("%s not found: %s", docType, id)
+	}
+
+	return json.Unmarshal(assetJSON, asset)
+}
+
+// writeAsset writes an asset of the given type to the world state
+func writeAsset(ctx contractapi.TransactionContextInterface, docType string, id string, asset interface{}) error {
+	key, err := assetKey(ctx, docType, id)
+	if err != nil {
+		return err
+	}
+
+	assetJSON, err := json.Marshal(asset)
+	if err != nil {
+		return err
+	}
+	return ctx.GetStub().PutState(key, assetJSON)
+}
+
+// queryAssets runs a CouchDB selector query and decodes every matching asset
+func queryAssets[T any](ctx contractapi.TransactionContextInterface, selector map[string]interface{}) ([]*T, error) {
+	query, err := json.Marshal(map[string]interface{}{"selector": selector})
+	if err != nil {
+		return nil, err
+	}
+
+	iterator, err := ctx.GetStub().GetQueryResult(string(query))
+	if err != nil {
+		return nil, fmt.Errorf("could not run query: %v", err)
+	}
+	defer iterator.Close()
+
+	assets := []*T{}
+	for iterator.HasNext() {
+		result, err := iterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var asset T
+		if err := json.Unmarshal(result.Value, &asset); err != nil {
+			return nil, err
+		}
+		assets = append(assets, &asset)
+	}
+
+	return assets, nil
+}
+
+// clientMSPID returns the MSP ID of the organization submitting the transaction
+func clientMSPID(ctx contractapi.TransactionContextInterface) (string, error) {
+	mspID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return "", fmt.Errorf("could not retrieve client MSP: %v", err)
+	}
+	return mspID, nil
+}
+
+// requireClientMSP fails unless the transaction is submitted by one of the given organizations
+func requireClientMSP(ctx contractapi.TransactionContextInterface, action string, mspIDs ...string) error {
+	mspID, err := clientMSPID(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, allowed := range mspIDs {
+		if allowed != "" && allowed == mspID {
+			return nil
+		}
+	}
+	return fmt.Errorf("client %s is not allowed to %s", mspID, action)
+}
+
+// Chaincode event names; a transaction carries at most one event, so the last one set wins
+const (
+	EventShipmentUpdated      = "ShipmentUpdated"
+	EventQuotationConfirmed   = "QuotationConfirmed"
+	EventCustomsStatusUpdated = "CustomsStatusUpdated"
+)
+
+// emitEvent sets the chaincode event of the transaction with the asset as payload
+func emitEvent(ctx contractapi.TransactionContextInterface, name string, asset interface{}) error {
+	payload, err := json.Marshal(asset)
+	if err != nil {
+		return err
+	}
+	if err := ctx.GetStub().SetEvent(name, payload); err != nil {
+		return fmt.Errorf("could not set %s event: %v", name, err)
+	}
+	return nil
+}
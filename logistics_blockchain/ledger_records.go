@@ -0,0 +1,154 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// DocTypeLedgerRecord is the document type of records written by the API's ledger backend
+const DocTypeLedgerRecord = "ledgerRecord"
+
+// ledgerRecordTxIndex maps the kind and transaction ID of a record to its key
+const ledgerRecordTxIndex = "ledgerRecordTx"
+
+// EventLedgerRecorded is emitted for every ledger record
+const EventLedgerRecorded = "LedgerRecorded"
+
+// Ledger record kinds, matching the marketplace facts the API records
+var ledgerRecordKinds = map[string]bool{
+	"listing":       true,
+	"booking":       true,
+	"payment":       true,
+	"trackingEvent": true,
+	"documentHash":  true,
+}
+
+// Ledger record structure; records are write-once, one per transaction
+type LedgerRecord struct {
+	DocType     string `json:"docType"`
+	Kind        string `json:"kind"`
+	SubjectID   string `json:"subjectId"`
+	TxID        string `json:"txId"`
+	MSPID       string `json:"mspId"`
+	Payload     string `json:"payload"`
+	PayloadHash string `json:"payloadHash"`
+	RecordedAt  string `json:"recordedAt"`
+}
+
+// RecordEntry appends a marketplace fact about a subject (listing, booking,
+// payment, tracking event or document hash) as a JSON payload
+func (c *LogisticsContract) RecordEntry(ctx contractapi.TransactionContextInterface, kind string, subjectID string, payload string) (*LedgerRecord, error) {
+	if !ledgerRecordKinds[kind] {
+		return nil, fmt.Errorf("invalid ledger record kind: %s", kind)
+	}
+	if subjectID == "" {
+		return nil, fmt.Errorf("subject ID is required")
+	}
+	if !json.Valid([]byte(payload)) {
+		return nil, fmt.Errorf("ledger record payload must be JSON")
+	}
+
+	mspID, err := clientMSPID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	timestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve transaction timestamp: %v", err)
+	}
+
+	txID := ctx.GetStub().GetTxID()
+	key, err := ctx.GetStub().CreateCompositeKey(DocTypeLedgerRecord, []string{kind, subjectID, txID})
+	if err != nil {
+		return nil, fmt.Errorf("could not create ledger record key: %v", err)
+	}
+
+	record := &LedgerRecord{
+		DocType:     DocTypeLedgerRecord,
+		Kind:        kind,
+		SubjectID:   subjectID,
+		TxID:        txID,
+		MSPID:       mspID,
+		Payload:     payload,
+		PayloadHash: hashHex([]byte(payload)),
+		RecordedAt:  timestamp.AsTime().UTC().Format(time.RFC3339),
+	}
+
+	recordJSON, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.GetStub().PutState(key, recordJSON); err != nil {
+		return nil, err
+	}
+	indexKey, err := ctx.GetStub().CreateCompositeKey(ledgerRecordTxIndex, []string{kind, txID})
+	if err != nil {
+		return nil, fmt.Errorf("could not create ledger record index key: %v", err)
+	}
+	if err := ctx.GetStub().PutState(indexKey, []byte(key)); err != nil {
+		return nil, err
+	}
+	if err := emitEvent(ctx, EventLedgerRecorded, record); err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+// FindLedgerRecord returns the record of a kind written by a transaction, as a
+// list that is empty when there is none
+func (c *LogisticsContract) FindLedgerRecord(ctx contractapi.TransactionContextInterface, kind string, txID string) ([]*LedgerRecord, error) {
+	indexKey, err := ctx.GetStub().CreateCompositeKey(ledgerRecordTxIndex, []string{kind, txID})
+	if err != nil {
+		return nil, fmt.Errorf("could not create ledger record index key: %v", err)
+	}
+	key, err := ctx.GetStub().GetState(indexKey)
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve ledger record: %v", err)
+	}
+	if key == nil {
+		return []*LedgerRecord{}, nil
+	}
+
+	recordJSON, err := ctx.GetStub().GetState(string(key))
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve ledger record: %v", err)
+	}
+	if recordJSON == nil {
+		return []*LedgerRecord{}, nil
+	}
+
+	var record LedgerRecord
+	if err := json.Unmarshal(recordJSON, &record); err != nil {
+		return nil, err
+	}
+	return []*LedgerRecord{&record}, nil
+}
+
+// GetLedgerRecords returns the records of a subject, in transaction ID order
+func (c *LogisticsContract) GetLedgerRecords(ctx contractapi.TransactionContextInterface, kind string, subjectID string) ([]*LedgerRecord, error) {
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(DocTypeLedgerRecord, []string{kind, subjectID})
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve ledger records: %v", err)
+	}
+	defer iterator.Close()
+
+	records := []*LedgerRecord{}
+	for iterator.HasNext() {
+		result, err := iterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var record LedgerRecord
+		if err := json.Unmarshal(result.Value, &record); err != nil {
+			return nil, err
+		}
+		records = append(records, &record)
+	}
+
+	return records, nil
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindLedgerRecord(t *testing.T) {
+	c := new(LogisticsContract)
+	ctx := newTestContext()
+
+	recorded, err := c.RecordEntry(ctx, "booking", "SVC-1", `{"customerId":"C1","serviceId":"SVC-1"}`)
+	require.NoError(t, err)
+	assert.Equal(t, "tx1", recorded.TxID)
+
+	records, err := c.FindLedgerRecord(ctx, "booking", "tx1")
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, recorded, records[0])
+
+	// Records are found by kind and transaction only
+	records, err = c.FindLedgerRecord(ctx, "listing", "tx1")
+	require.NoError(t, err)
+	assert.Empty(t, records)
+
+	records, err = c.FindLedgerRecord(ctx, "booking", "tx2")
+	require.NoError(t, err)
+	assert.Empty(t, records)
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequireClientMSP(t *testing.T) {
+	tests := []struct {
+		name    string
+		client  string
+		allowed []string
+		wantErr string
+	}{
+		{name: "single organization", client: "Org1MSP", allowed: []string{"Org1MSP"}},
+		{name: "one of several", client: "Org2MSP", allowed: []string{"Org1MSP", "Org2MSP"}},
+		{name: "other organization", client: "Org3MSP", allowed: []string{"Org1MSP", "Org2MSP"}, wantErr: "client Org3MSP is not allowed to test"},
+		{name: "nobody allowed", client: "Org1MSP", wantErr: "client Org1MSP is not allowed to test"},
+		{name: "unowned asset", client: "", allowed: []string{""}, wantErr: "client  is not allowed to test"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := requireClientMSP(newTestContext().as(tt.client), "test", tt.allowed...)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestRequireShipmentParty(t *testing.T) {
+	tests := []struct {
+		name    string
+		client  string
+		wantErr string
+	}{
+		{name: "shipper", client: "Org1MSP"},
+		{name: "consignee", client: "Org2MSP"},
+		{name: "assigned 3PL", client: "Org3MSP"},
+		{name: "unassigned 3PL", client: "Org4MSP", wantErr: "client Org4MSP is not allowed to update shipment S1"},
+		{name: "stranger", client: "Org5MSP", wantErr: "client Org5MSP is not allowed to update shipment S1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := new(LogisticsContract)
+			ctx := newTestContext()
+			bookTestShipment(t, c, ctx)
+			require.NoError(t, c.AddThirdPartyLogistics(ctx.as("Org3MSP"), "ASSIGNED", "Hauliers", `["Trucking"]`))
+			require.NoError(t, c.AddThirdPartyLogistics(ctx.as("Org4MSP"), "IDLE", "Truckers", `["Trucking"]`))
+			require.NoError(t, c.AssignThirdPartyLogistics(ctx.as("Org1MSP"), "S1", "ASSIGNED", RoleTrucking))
+
+			shipment, err := getShipment(ctx, "S1")
+			require.NoError(t, err)
+
+			err = requireShipmentParty(ctx.as(tt.client), shipment, "update shipment S1")
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				assert.Error(t, c.UpdateShipmentStatus(ctx, "S1", StatusPickedUp))
+				return
+			}
+			assert.NoError(t, err)
+			assert.NoError(t, c.UpdateShipmentStatus(ctx, "S1", StatusPickedUp))
+		})
+	}
+}
+
+func TestBookShipmentRequiresShipperOrganization(t *testing.T) {
+	c := new(LogisticsContract)
+	ctx := newTestContext()
+	require.NoError(t, c.CreateShipper(ctx.as("Org1MSP"), "SHIPPER", "Shipper Co"))
+	require.NoError(t, c.CreateConsignee(ctx.as("Org2MSP"), "CONSIGNEE", "Consignee Co"))
+
+	err := c.BookShipment(ctx.as("Org2MSP"), "S1", "SHIPPER", "CONSIGNEE")
+	assert.EqualError(t, err, "client Org2MSP is not allowed to book shipments for shipper SHIPPER")
+
+	err = c.BookShipment(ctx.as("Org1MSP"), "S1", "SHIPPER", "MISSING")
+	assert.EqualError(t, err, "consignee not found: MISSING")
+}
+
+func TestAssetKeysSeparateDocTypes(t *testing.T) {
+	c := new(LogisticsContract)
+	ctx := newTestContext()
+	require.NoError(t, c.CreateShipper(ctx, "ACME", "Acme Shipping"))
+
+	// The same ID is free in every other asset type
+	require.NoError(t, c.CreateConsignee(ctx, "ACME", "Acme Imports"))
+	assert.EqualError(t, c.CreateShipper(ctx, "ACME", "Acme Again"), "shipper already exists: ACME")
+
+	var shipper Shipper
+	require.NoError(t, readAsset(ctx, DocTypeShipper, "ACME", &shipper))
+	assert.Equal(t, Shipper{DocType: DocTypeShipper, ID: "ACME", Name: "Acme Shipping", MSPID: "Org1MSP"}, shipper)
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSalt = "5f0c7e2a9b41d8e36a7f1c0b2d4e6f8a"
+
+// withQuotationTerms passes quotation terms in the transient map of the next transaction
+func withQuotationTerms(t *testing.T, ctx *testContext, terms QuotationTerms) *testContext {
+	t.Helper()
+	termsJSON, err := json.Marshal(terms)
+	require.NoError(t, err)
+	ctx.stub.TransientMap = map[string][]byte{"quotation": termsJSON}
+	return ctx
+}
+
+// createTestQuotation has a carrier of Org2MSP quote Q1 to the shipper of Org1MSP
+func createTestQuotation(t *testing.T, c *LogisticsContract, ctx *testContext) QuotationTerms {
+	t.Helper()
+	require.NoError(t, c.CreateShipper(ctx.as("Org1MSP"), "SHIPPER", "Shipper Co"))
+	terms := QuotationTerms{Amount: "1500.00", Currency: "USD", ValidUntil: "2026-12-31", Terms: []string{"FOB"}, Salt: testSalt}
+	require.NoError(t, c.CreateFreightQuotation(withQuotationTerms(t, ctx, terms).as("Org2MSP"), "Q1", "CARRIER", "SHIPPER"))
+	terms.QuotationID = "Q1"
+	return terms
+}
+
+func TestCreateFreightQuotation(t *testing.T) {
+	tests := []struct {
+		name    string
+		mspID   string
+		terms   QuotationTerms
+		wantErr string
+	}{
+		{name: "salted terms", mspID: "Org2MSP", terms: QuotationTerms{Amount: "1500.00", Salt: testSalt}},
+		{name: "own organization", mspID: "Org1MSP", terms: QuotationTerms{Amount: "1500.00", Salt: testSalt}},
+		{name: "no salt", mspID: "Org2MSP", terms: QuotationTerms{Amount: "1500.00"}, wantErr: "quotation terms need a random salt of at least 32 characters"},
+		{name: "short salt", mspID: "Org2MSP", terms: QuotationTerms{Amount: "1500.00", Salt: "abc"}, wantErr: "quotation terms need a random salt of at least 32 characters"},
+		{name: "no amount", mspID: "Org2MSP", terms: QuotationTerms{Salt: testSalt}, wantErr: "quotation amount is required"},
+		{name: "no collection", mspID: "Org3MSP", terms: QuotationTerms{Amount: "1500.00", Salt: testSalt}, wantErr: "no private data collection quotations_Org1MSP_Org3MSP is configured for quotations between Org3MSP and Org1MSP"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := new(LogisticsContract)
+			ctx := newTestContext()
+			require.NoError(t, c.CreateShipper(ctx, "SHIPPER", "Shipper Co"))
+
+			err := c.CreateFreightQuotation(withQuotationTerms(t, ctx, tt.terms).as(tt.mspID), "Q1", "CARRIER", "SHIPPER")
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			terms, err := c.ReadFreightQuotationTerms(ctx, "Q1")
+			require.NoError(t, err)
+			assert.Equal(t, testSalt, terms.Salt)
+
+			// The public record carries only the hash of the salted terms
+			var quotation FreightQuotation
+			require.NoError(t, readAsset(ctx, DocTypeQuotation, "Q1", &quotation))
+			tt.terms.QuotationID = "Q1"
+			termsJSON, err := json.Marshal(tt.terms)
+			require.NoError(t, err)
+			assert.Equal(t, hashHex(termsJSON), quotation.TermsHash)
+		})
+	}
+}
+
+func TestConfirmFreightQuotation(t *testing.T) {
+	tests := []struct {
+		name    string
+		mspID   string
+		tamper  func(terms *QuotationTerms)
+		wantErr string
+	}{
+		{name: "matching terms", mspID: "Org2MSP"},
+		{name: "different amount", mspID: "Org2MSP", tamper: func(terms *QuotationTerms) { terms.Amount = "900.00" }, wantErr: "quotation terms do not match the public hash of Q1"},
+		{name: "without salt", mspID: "Org2MSP", tamper: func(terms *QuotationTerms) { terms.Salt = "" }, wantErr: "quotation terms do not match the public hash of Q1"},
+		{name: "not the carrier", mspID: "Org1MSP", wantErr: "client Org1MSP is not allowed to confirm quotation Q1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := new(LogisticsContract)
+			ctx := newTestContext()
+			terms := createTestQuotation(t, c, ctx)
+			if tt.tamper != nil {
+				tt.tamper(&terms)
+			}
+
+			err := c.ConfirmFreightQuotation(withQuotationTerms(t, ctx, terms).as(tt.mspID), "Q1")
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			var quotation FreightQuotation
+			require.NoError(t, readAsset(ctx, DocTypeQuotation, "Q1", &quotation))
+			assert.True(t, quotation.Confirmed)
+
+			err = c.ConfirmFreightQuotation(ctx, "Q1")
+			assert.EqualError(t, err, "quotation already confirmed: Q1")
+		})
+	}
+}
+
+func TestConfirmFreightQuotationChecksPrivateData(t *testing.T) {
+	c := new(LogisticsContract)
+	ctx := newTestContext()
+	terms := createTestQuotation(t, c, ctx)
+
+	// Terms matching the public hash are still refused when the collection holds others
+	key, err := assetKey(ctx, DocTypeQuotation, "Q1")
+	require.NoError(t, err)
+	altered := terms
+	altered.Amount = "900.00"
+	alteredJSON, err := json.Marshal(altered)
+	require.NoError(t, err)
+	require.NoError(t, ctx.stub.PutPrivateData("quotations_Org1MSP_Org2MSP", key, alteredJSON))
+
+	err = c.ConfirmFreightQuotation(withQuotationTerms(t, ctx, terms).as("Org2MSP"), "Q1")
+	assert.EqualError(t, err, "quotation terms do not match the private data of Q1")
+
+	// Nor are they accepted once the private data has been purged
+	delete(ctx.stub.PvtState["quotations_Org1MSP_Org2MSP"], key)
+	err = c.ConfirmFreightQuotation(ctx, "Q1")
+	assert.EqualError(t, err, "quotation terms do not match the private data of Q1")
+}
+
+func TestQuotationCollectionsAreConfigured(t *testing.T) {
+	configJSON, err := os.ReadFile("collections_config.json")
+	require.NoError(t, err)
+	var config []struct {
+		Name string `json:"name"`
+	}
+	require.NoError(t, json.Unmarshal(configJSON, &config))
+
+	configured := make(map[string]bool)
+	for _, collection := range config {
+		if strings.HasPrefix(collection.Name, "quotations_") {
+			configured[collection.Name] = true
+		}
+	}
+	assert.Equal(t, configured, quotationCollections)
+	assert.True(t, quotationCollections[quotationCollection("Org2MSP", "Org1MSP")])
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Third-party logistics roles
+const (
+	RoleWarehousing = "Warehousing"
+	RoleTrucking    = "Trucking"
+	RoleCrossDock   = "CrossDock"
+	RoleLastMile    = "LastMile"
+	RoleCustoms     = "CustomsBrokerage"
+)
+
+var thirdPartyRoles = map[string]bool{
+	RoleWarehousing: true,
+	RoleTrucking:    true,
+	RoleCrossDock:   true,
+	RoleLastMile:    true,
+	RoleCustoms:     true,
+}
+
+// Third-party logistics provider structure
+type ThirdPartyLogistics struct {
+	DocType string   `json:"docType"`
+	ID      string   `json:"id"`
+	Name    string   `json:"name"`
+	MSPID   string   `json:"mspId"`
+	Roles   []string `json:"roles"`
+	Active  bool     `json:"active"`
+}
+
+// Logistics assignment structure
+type LogisticsAssignment struct {
+	ProviderID string `json:"providerId"`
+	Role       string `json:"role"`
+	AssignedAt string `json:"assignedAt"`
+}
+
+// AddThirdPartyLogistics registers the calling organization as a 3PL provider
+// offering the given roles, passed as a JSON array
+func (c *LogisticsContract) AddThirdPartyLogistics(ctx contractapi.TransactionContextInterface, id string, providerName string, roles string) error {
+	exists, err := assetExists(ctx, DocTypeThirdParty, id)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("3PL provider already exists: %s", id)
+	}
+
+	var providerRoles []string
+	if err := json.Unmarshal([]byte(roles), &providerRoles); err != nil {
+		return fmt.Errorf("invalid roles: %v", err)
+	}
+	if len(providerRoles) == 0 {
+		return fmt.Errorf("3PL provider must offer at least one role")
+	}
+	for _, role := range providerRoles {
+		if !thirdPartyRoles[role] {
+			return fmt.Errorf("unknown 3PL role: %s", role)
+		}
+	}
+
+	mspID, err := clientMSPID(ctx)
+	if err != nil {
+		return err
+	}
+
+	provider := ThirdPartyLogistics{
+		DocType: DocTypeThirdParty,
+		ID:      id,
+		Name:    providerName,
+		MSPID:   mspID,
+		Roles:   providerRoles,
+		Active:  true,
+	}
+	return writeAsset(ctx, DocTypeThirdParty, id, provider)
+}
+
+// GetThirdPartyLogistics returns a 3PL provider
+func (c *LogisticsContract) GetThirdPartyLogistics(ctx contractapi.TransactionContextInterface, id string) (*ThirdPartyLogistics, error) {
+	return getThirdPartyLogistics(ctx, id)
+}
+
+// AssignThirdPartyLogistics assigns a 3PL provider to a role on a shipment,
+// only by the shipper's or consignee's organization
+func (c *LogisticsContract) AssignThirdPartyLogistics(ctx contractapi.TransactionContextInterface, shipmentID string, providerID string, role string) error {
+	provider, err := getThirdPartyLogistics(ctx, providerID)
+	if err != nil {
+		return err
+	}
+	if !provider.Active {
+		return fmt.Errorf("3PL provider is inactive: %s", providerID)
+	}
+	if !hasRole(provider.Roles, role) {
+		return fmt.Errorf("3PL provider %s does not offer %s", providerID, role)
+	}
+
+	shipment, err := getShipment(ctx, shipmentID)
+	if err != nil {
+		return err
+	}
+	owners, err := shipmentOwners(ctx, shipment)
+	if err != nil {
+		return err
+	}
+	if err := requireClientMSP(ctx, "assign 3PL providers to shipment "+shipmentID, owners...); err != nil {
+		return err
+	}
+
+	if shipment.Status == StatusDelivered || shipment.Status == StatusCancelled {
+		return fmt.Errorf("cannot assign 3PL to shipment in status %s", shipment.Status)
+	}
+
+	timestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return fmt.Errorf("could not retrieve transaction timestamp: %v", err)
+	}
+	assignment := LogisticsAssignment{
+		ProviderID: providerID,
+		Role:       role,
+		AssignedAt: timestamp.AsTime().UTC().Format(time.RFC3339),
+	}
+
+	// One provider per role, reassigning replaces the previous provider
+	replaced := false
+	for i := range shipment.Assignments {
+		if shipment.Assignments[i].Role == role {
+			shipment.Assignments[i] = assignment
+			replaced = true
+		}
+	}
+	if !replaced {
+		shipment.Assignments = append(shipment.Assignments, assignment)
+	}
+
+	return putShipment(ctx, shipment)
+}
+
+func getThirdPartyLogistics(ctx contractapi.TransactionContextInterface, id string) (*ThirdPartyLogistics, error) {
+	var provider ThirdPartyLogistics
+	if err := readAsset(ctx, DocTypeThirdParty, id, &provider); err != nil {
+		return nil, err
+	}
+	return &provider, nil
+}
+
+func hasRole(roles []string, role string) bool {
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
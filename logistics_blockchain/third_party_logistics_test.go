@@ -0,0 +1,94 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddThirdPartyLogistics(t *testing.T) {
+	tests := []struct {
+		name    string
+		roles   string
+		wantErr string
+	}{
+		{name: "known roles", roles: `["Trucking","LastMile"]`},
+		{name: "unknown role", roles: `["Trucking","Airline"]`, wantErr: "unknown 3PL role: Airline"},
+		{name: "no roles", roles: `[]`, wantErr: "3PL provider must offer at least one role"},
+		{name: "not json", roles: `Trucking`, wantErr: "invalid roles"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := new(LogisticsContract)
+			ctx := newTestContext().as("Org3MSP")
+
+			err := c.AddThirdPartyLogistics(ctx, "3PL", "Hauliers", tt.roles)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			provider, err := c.GetThirdPartyLogistics(ctx, "3PL")
+			require.NoError(t, err)
+			assert.Equal(t, "Org3MSP", provider.MSPID)
+			assert.True(t, provider.Active)
+		})
+	}
+}
+
+func TestAssignThirdPartyLogistics(t *testing.T) {
+	tests := []struct {
+		name    string
+		mspID   string
+		role    string
+		status  string
+		wantErr string
+	}{
+		{name: "shipper assigns", mspID: "Org1MSP", role: RoleTrucking},
+		{name: "consignee assigns", mspID: "Org2MSP", role: RoleLastMile},
+		{name: "stranger assigns", mspID: "Org4MSP", role: RoleTrucking, wantErr: "client Org4MSP is not allowed to assign 3PL providers to shipment S1"},
+		{name: "role not offered", mspID: "Org1MSP", role: RoleWarehousing, wantErr: "3PL provider 3PL does not offer Warehousing"},
+		{name: "cancelled shipment", mspID: "Org1MSP", role: RoleTrucking, status: StatusCancelled, wantErr: "cannot assign 3PL to shipment in status Cancelled"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := new(LogisticsContract)
+			ctx := newTestContext()
+			bookTestShipment(t, c, ctx)
+			require.NoError(t, c.AddThirdPartyLogistics(ctx.as("Org3MSP"), "3PL", "Hauliers", `["Trucking","LastMile"]`))
+			if tt.status != "" {
+				require.NoError(t, c.UpdateShipmentStatus(ctx.as("Org1MSP"), "S1", tt.status))
+			}
+
+			err := c.AssignThirdPartyLogistics(ctx.as(tt.mspID), "S1", "3PL", tt.role)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			shipment, err := getShipment(ctx, "S1")
+			require.NoError(t, err)
+			require.Len(t, shipment.Assignments, 1)
+			assert.Equal(t, LogisticsAssignment{ProviderID: "3PL", Role: tt.role, AssignedAt: shipment.UpdatedAt}, shipment.Assignments[0])
+		})
+	}
+}
+
+func TestReassigningRoleReplacesProvider(t *testing.T) {
+	c := new(LogisticsContract)
+	ctx := newTestContext()
+	bookTestShipment(t, c, ctx)
+	require.NoError(t, c.AddThirdPartyLogistics(ctx.as("Org3MSP"), "FIRST", "Hauliers", `["Trucking"]`))
+	require.NoError(t, c.AddThirdPartyLogistics(ctx.as("Org4MSP"), "SECOND", "Truckers", `["Trucking"]`))
+
+	require.NoError(t, c.AssignThirdPartyLogistics(ctx.as("Org1MSP"), "S1", "FIRST", RoleTrucking))
+	require.NoError(t, c.AssignThirdPartyLogistics(ctx.as("Org1MSP"), "S1", "SECOND", RoleTrucking))
+
+	shipment, err := getShipment(ctx, "S1")
+	require.NoError(t, err)
+	require.Len(t, shipment.Assignments, 1)
+	assert.Equal(t, "SECOND", shipment.Assignments[0].ProviderID)
+}
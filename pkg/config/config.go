@@ -30,6 +30,22 @@ type Config struct {
 		AirFreight        bool `json:"air_freight"`
 	} `json:"services"`
 
+	// Ledger Configuration
+	Ledger struct {
+		Backend       string `json:"backend"`        // stellar, fabric or hashchain
+		HashChainPath string `json:"hashchain_path"` // Log file of the hashchain backend
+	} `json:"ledger"`
+
+	// Hyperledger Fabric Configuration
+	Fabric struct {
+		ConnectionProfile string `json:"connection_profile"`
+		CertPath          string `json:"cert_path"`
+		KeyPath           string `json:"key_path"`
+		Channel           string `json:"channel"`
+		Chaincode         string `json:"chaincode"`
+		Tracking          bool   `json:"tracking"` // Drive the chaincode shipment lifecycle from tracking updates
+	} `json:"fabric"`
+
 	// Development Settings
 	Development struct {
 		Debug       bool   `json:"debug"`
@@ -66,6 +82,15 @@ func GetConfig() *Config {
 	return globalConfig
 }
 
+// FromEnv builds a configuration from environment variables only, for
+// deployments without a config file
+func FromEnv() *Config {
+	config := &Config{}
+	config.Ledger.Backend = "stellar"
+	overrideWithEnv(config)
+	return config
+}
+
 func overrideWithEnv(config *Config) {
 	if val := os.Getenv("NETWORK_PASSPHRASE"); val != "" {
 		config.Network.NetworkPassphrase = val
@@ -100,6 +125,30 @@ func overrideWithEnv(config *Config) {
 	if val := os.Getenv("SERVICE_AIR_FREIGHT"); val != "" {
 		config.Services.AirFreight, _ = strconv.ParseBool(val)
 	}
+	if val := os.Getenv("LEDGER_BACKEND"); val != "" {
+		config.Ledger.Backend = val
+	}
+	if val := os.Getenv("LEDGER_HASHCHAIN_PATH"); val != "" {
+		config.Ledger.HashChainPath = val
+	}
+	if val := os.Getenv("FABRIC_CONNECTION_PROFILE"); val != "" {
+		config.Fabric.ConnectionProfile = val
+	}
+	if val := os.Getenv("FABRIC_CERT_PATH"); val != "" {
+		config.Fabric.CertPath = val
+	}
+	if val := os.Getenv("FABRIC_KEY_PATH"); val != "" {
+		config.Fabric.KeyPath = val
+	}
+	if val := os.Getenv("FABRIC_CHANNEL"); val != "" {
+		config.Fabric.Channel = val
+	}
+	if val := os.Getenv("FABRIC_CHAINCODE"); val != "" {
+		config.Fabric.Chaincode = val
+	}
+	if val := os.Getenv("FABRIC_TRACKING"); val != "" {
+		config.Fabric.Tracking, _ = strconv.ParseBool(val)
+	}
 	if val := os.Getenv("DEV_DEBUG"); val != "" {
 		config.Development.Debug, _ = strconv.ParseBool(val)
 	}